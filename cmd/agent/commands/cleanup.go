@@ -1,29 +1,132 @@
 package commands
 
 import (
+	"errors"
+	"fmt"
 	"syscall"
+	"time"
 
+	"github.com/grafana/xk6-disruptor/internal/multierr"
+	"github.com/grafana/xk6-disruptor/pkg/agent/exhaust"
+	"github.com/grafana/xk6-disruptor/pkg/agent/network"
+	"github.com/grafana/xk6-disruptor/pkg/agent/process"
+	"github.com/grafana/xk6-disruptor/pkg/iptables"
 	"github.com/grafana/xk6-disruptor/pkg/runtime"
 	"github.com/spf13/cobra"
 )
 
+// agentExitTimeout is the maximum time the cleanup waits for the running agent to exit after stopping it
+const agentExitTimeout = 30 * time.Second
+
 // BuiltCleanupCmd returns a cobra command with the specification of the kill command
 func BuiltCleanupCmd(env runtime.Environment) *cobra.Command {
+	var resumeContainerID string
+	var resetNetwork bool
+	var resetPartition bool
+	var iface string
+	var diskFillContainerID string
+	var diskFillPath string
+	var releaseFDs bool
+
 	cmd := &cobra.Command{
 		Use:   "cleanup",
 		Short: "stops any ongoing fault injection and cleans resources",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			runningProcess := env.Lock().Owner()
-			// no instance is currently running
-			if runningProcess == -1 {
-				return nil
+			// stop the running instance, if any, so it does not restore the target at the same time
+			errs := []error{stopAgent(env.Lock())}
+
+			// resume the processes of a frozen container in case the running instance could not do it
+			if resumeContainerID != "" {
+				errs = append(errs, process.Resume(env.Executor(), process.DefaultFinder(), resumeContainerID))
+			}
+
+			// remove network faults in case the running instance could not do it
+			if resetNetwork {
+				errs = append(errs, resetInterface(env.Executor(), iface))
+			}
+
+			// restore the connectivity with the peers in case the running instance could not do it
+			if resetPartition {
+				errs = append(errs, iptables.ResetPartition(env.Executor()))
 			}
 
-			return syscall.Kill(runningProcess, syscall.SIGTERM)
+			// remove the disk fill in case the running instance could not do it
+			if diskFillContainerID != "" && diskFillPath != "" {
+				errs = append(
+					errs,
+					exhaust.RemoveDiskFill(process.DefaultFinder(), procRoot, diskFillContainerID, diskFillPath),
+				)
+			}
+
+			// close the descriptors opened in the target process in case the running instance could not do it
+			if releaseFDs {
+				errs = append(
+					errs,
+					exhaust.ReleaseFDs(process.DefaultSyscallInjector(), procRoot, exhaust.DefaultFDFile()),
+				)
+			}
+
+			// every resource is cleaned even if cleaning another fails
+			return multierr.Join(errs...)
 
 			// TODO: cleanup resources (e.g iptables)
 		},
 	}
 
+	cmd.Flags().StringVar(&resumeContainerID, "resume-container-id", "",
+		"id of a container whose processes must be resumed")
+	cmd.Flags().BoolVar(&resetNetwork, "reset-network", false, "remove network faults from the interface")
+	cmd.Flags().BoolVar(&resetPartition, "reset-partition", false, "remove the rules of a network partition")
+	cmd.Flags().StringVarP(&iface, "interface", "i", "", "interface to reset (defaults to the default route's)")
+	cmd.Flags().StringVar(&diskFillContainerID, "disk-fill-container-id", "",
+		"id of a container whose disk fill must be removed")
+	cmd.Flags().StringVar(&diskFillPath, "disk-fill-path", "", "path of the disk fill to be removed")
+	cmd.Flags().BoolVar(&releaseFDs, "release-fds", false, "close the descriptors opened in a process")
+
 	return cmd
 }
+
+// stopAgent stops the running instance of the agent, if any, and waits for it to exit, for at most agentExitTimeout
+func stopAgent(lock runtime.Lock) error {
+	owner := lock.Owner()
+	if owner == -1 {
+		return nil
+	}
+
+	err := syscall.Kill(owner, syscall.SIGTERM)
+	// the lock may be left by an agent that was terminated abruptly
+	if errors.Is(err, syscall.ESRCH) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("stopping agent process %d: %w", owner, err)
+	}
+
+	// the agent releases the lock when it ends
+	deadline := time.Now().Add(agentExitTimeout)
+	for lock.Owner() == owner && syscall.Kill(owner, 0) == nil {
+		if time.Now().After(deadline) {
+			return fmt.Errorf("agent process %d did not end after %s", owner, agentExitTimeout)
+		}
+
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	return nil
+}
+
+// resetInterface removes the network faults from the given interface, or from the interface of the default route
+// if none is given. It is not an error if the interface does not have any fault.
+func resetInterface(executor runtime.Executor, iface string) error {
+	if iface == "" {
+		var err error
+		iface, err = network.DefaultInterface(executor)
+		if err != nil {
+			return err
+		}
+	}
+
+	_ = network.Reset(executor, iface)
+
+	return nil
+}
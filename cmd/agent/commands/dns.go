@@ -0,0 +1,105 @@
+package commands
+
+import (
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/grafana/xk6-disruptor/pkg/agent"
+	"github.com/grafana/xk6-disruptor/pkg/agent/protocol"
+	"github.com/grafana/xk6-disruptor/pkg/agent/protocol/dns"
+	"github.com/grafana/xk6-disruptor/pkg/iptables"
+	"github.com/grafana/xk6-disruptor/pkg/runtime"
+	"github.com/spf13/cobra"
+)
+
+// resolvConf is the path of the resolver configuration of the target
+const resolvConf = "/etc/resolv.conf"
+
+// proxyAddress is the address the proxy listens to. The queries sent by the target are redirected to it.
+const proxyAddress = "127.0.0.1"
+
+// BuildDNSCmd returns a cobra command with the specification of the dns command
+func BuildDNSCmd(env runtime.Environment, config *agent.Config) *cobra.Command {
+	disruption := dns.Disruption{}
+	var duration time.Duration
+	var port uint
+	var upstream string
+
+	cmd := &cobra.Command{
+		Use:   "dns",
+		Short: "dns disruptor",
+		Long: "Disrupts the DNS queries sent by the target by introducing delays and errors." +
+			" Queries are redirected to a proxy that forwards them to the upstream resolver." +
+			" Requires NET_ADMIN capabilities for setting iptable rules.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if upstream == "" {
+				var err error
+				upstream, err = dns.Resolver(resolvConf)
+				if err != nil {
+					return err
+				}
+			}
+
+			agent, err := agent.Start(env, config)
+			if err != nil {
+				return fmt.Errorf("initializing agent: %w", err)
+			}
+
+			defer agent.Stop()
+
+			// the queries are redirected to the loopback address. Listening on it ensures the responses to UDP
+			// queries are sent from the address the queries were redirected to, so they can be matched by conntrack.
+			listenAddress := net.JoinHostPort(proxyAddress, fmt.Sprint(port))
+
+			udp, err := net.ListenPacket("udp", listenAddress)
+			if err != nil {
+				return fmt.Errorf("setting up udp listener at %q: %w", listenAddress, err)
+			}
+
+			tcp, err := net.Listen("tcp", listenAddress)
+			if err != nil {
+				_ = udp.Close()
+				return fmt.Errorf("setting up tcp listener at %q: %w", listenAddress, err)
+			}
+
+			proxy, err := dns.NewProxy(udp, tcp, upstream, disruption)
+			if err != nil {
+				return err
+			}
+
+			redirector, err := iptables.NewDNSTrafficRedirector(
+				&iptables.DNSRedirectionSpec{RedirectPort: port},
+				env.Executor(),
+			)
+			if err != nil {
+				return err
+			}
+
+			disruptor, err := protocol.NewDisruptor(
+				env.Executor(),
+				proxy,
+				redirector,
+				cmd.OutOrStdout(),
+			)
+			if err != nil {
+				return err
+			}
+
+			return agent.ApplyDisruption(cmd.Context(), disruptor, duration)
+		},
+	}
+
+	cmd.Flags().DurationVarP(&duration, "duration", "d", 0, "duration of the disruptions")
+	cmd.Flags().DurationVarP(&disruption.AverageDelay, "average-delay", "a", 0, "average query delay")
+	cmd.Flags().DurationVarP(&disruption.DelayVariation, "delay-variation", "v", 0, "variation in query delay")
+	cmd.Flags().StringVarP(&disruption.Error, "error", "e", "", "error returned: servfail, nxdomain or empty")
+	cmd.Flags().Float32VarP(&disruption.ErrorRate, "rate", "r", 0, "error rate")
+	cmd.Flags().StringSliceVar(&disruption.Names, "names", []string{}, "comma-separated list of names (and"+
+		" their subdomains) to be disrupted")
+	cmd.Flags().StringVar(&upstream, "upstream", "", "address of the upstream resolver"+
+		" (defaults to the first nameserver in "+resolvConf+")")
+	cmd.Flags().UintVarP(&port, "port", "p", 8053, "port the proxy will listen to")
+
+	return cmd
+}
@@ -0,0 +1,214 @@
+package commands
+
+import (
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/grafana/xk6-disruptor/pkg/agent"
+	"github.com/grafana/xk6-disruptor/pkg/agent/protocol"
+	"github.com/grafana/xk6-disruptor/pkg/agent/protocol/grpc"
+	"github.com/grafana/xk6-disruptor/pkg/agent/protocol/http"
+	"github.com/grafana/xk6-disruptor/pkg/agent/protocol/tcp"
+	"github.com/grafana/xk6-disruptor/pkg/iptables"
+	"github.com/grafana/xk6-disruptor/pkg/runtime"
+
+	"github.com/spf13/cobra"
+)
+
+// BuildEgressCmd returns a cobra command with the specification of the egress command
+func BuildEgressCmd(env runtime.Environment, config *agent.Config) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "egress",
+		Short: "egress traffic disruptors",
+		Long: "Disrupts the traffic the target sends to an external destination." +
+			" Requires NET_ADMIN capabilities for setting iptable rules.",
+	}
+
+	cmd.AddCommand(buildEgressHTTPCmd(env, config))
+	cmd.AddCommand(buildEgressGrpcCmd(env, config))
+	cmd.AddCommand(buildEgressTCPCmd(env, config))
+
+	return cmd
+}
+
+// egressTarget defines the traffic intercepted by the egress commands
+type egressTarget struct {
+	duration        time.Duration
+	destination     string
+	destinationPort uint
+	port            uint
+}
+
+func (t *egressTarget) addFlags(cmd *cobra.Command) {
+	cmd.Flags().DurationVarP(&t.duration, "duration", "d", 0, "duration of the disruptions")
+	cmd.Flags().StringVar(&t.destination, "destination", "", "host, IP address or CIDR the traffic is sent to")
+	cmd.Flags().UintVar(&t.destinationPort, "destination-port", 0, "port the traffic is sent to")
+	cmd.Flags().UintVarP(&t.port, "port", "p", 8000, "port the proxy will listen to")
+}
+
+// resolveDestinations returns the IPv4 addresses or CIDRs of the destination
+func (t *egressTarget) resolveDestinations() ([]string, error) {
+	if net.ParseIP(t.destination) != nil {
+		return []string{t.destination}, nil
+	}
+
+	if _, _, err := net.ParseCIDR(t.destination); err == nil {
+		return []string{t.destination}, nil
+	}
+
+	ips, err := net.LookupIP(t.destination)
+	if err != nil {
+		return nil, fmt.Errorf("resolving destination %q: %w", t.destination, err)
+	}
+
+	destinations := []string{}
+	for _, ip := range ips {
+		if ip.To4() != nil {
+			destinations = append(destinations, ip.String())
+		}
+	}
+
+	if len(destinations) == 0 {
+		return nil, fmt.Errorf("destination %q does not have any IPv4 address", t.destination)
+	}
+
+	return destinations, nil
+}
+
+// applyEgressDisruption redirects the traffic sent to the target's destination to the proxy returned by the given
+// function and applies the disruption
+func applyEgressDisruption(
+	cmd *cobra.Command,
+	env runtime.Environment,
+	config *agent.Config,
+	target *egressTarget,
+	buildProxy func(listener *protocol.EgressListener) (protocol.Proxy, error),
+) error {
+	if target.destination == "" || target.destinationPort == 0 {
+		return fmt.Errorf("destination and destination port are required")
+	}
+
+	destinations, err := target.resolveDestinations()
+	if err != nil {
+		return err
+	}
+
+	agent, err := agent.Start(env, config)
+	if err != nil {
+		return fmt.Errorf("initializing agent: %w", err)
+	}
+
+	defer agent.Stop()
+
+	listenAddress := net.JoinHostPort("", fmt.Sprint(target.port))
+	listener, err := net.Listen("tcp", listenAddress)
+	if err != nil {
+		return fmt.Errorf("setting up listener at %q: %w", listenAddress, err)
+	}
+
+	proxy, err := buildProxy(protocol.NewEgressListener(listener))
+	if err != nil {
+		return err
+	}
+
+	tr := &iptables.EgressRedirectionSpec{
+		Destinations:    destinations,           // Redirect traffic sent to the destination...
+		DestinationPort: target.destinationPort, // and port...
+		RedirectPort:    target.port,            // to the proxy port.
+	}
+
+	redirector, err := iptables.NewEgressTrafficRedirector(tr, env.Executor())
+	if err != nil {
+		return err
+	}
+
+	disruptor, err := protocol.NewDisruptor(
+		env.Executor(),
+		proxy,
+		redirector,
+		cmd.OutOrStdout(),
+	)
+	if err != nil {
+		return err
+	}
+
+	return agent.ApplyDisruption(cmd.Context(), disruptor, target.duration)
+}
+
+func buildEgressHTTPCmd(env runtime.Environment, config *agent.Config) *cobra.Command {
+	disruption := http.Disruption{}
+	target := &egressTarget{}
+
+	cmd := &cobra.Command{
+		Use:   "http",
+		Short: "egress http disruptor",
+		Long:  "Disrupts the http requests sent to the destination by introducing delays and errors.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return applyEgressDisruption(cmd, env, config, target, func(l *protocol.EgressListener) (protocol.Proxy, error) {
+				return http.NewEgressProxy(l, disruption)
+			})
+		},
+	}
+
+	target.addFlags(cmd)
+	cmd.Flags().DurationVarP(&disruption.AverageDelay, "average-delay", "a", 0, "average request delay")
+	cmd.Flags().DurationVarP(&disruption.DelayVariation, "delay-variation", "v", 0, "variation in request delay")
+	cmd.Flags().UintVarP(&disruption.ErrorCode, "error", "e", 0, "error code")
+	cmd.Flags().Float32VarP(&disruption.ErrorRate, "rate", "r", 0, "error rate")
+	cmd.Flags().StringVarP(&disruption.ErrorBody, "body", "b", "", "body for injected faults")
+	cmd.Flags().StringSliceVarP(&disruption.Excluded, "exclude", "x", []string{}, "comma-separated list of path(s)"+
+		" to be excluded from disruption")
+
+	return cmd
+}
+
+func buildEgressGrpcCmd(env runtime.Environment, config *agent.Config) *cobra.Command {
+	disruption := grpc.Disruption{}
+	target := &egressTarget{}
+
+	cmd := &cobra.Command{
+		Use:   "grpc",
+		Short: "egress grpc disruptor",
+		Long:  "Disrupts the grpc requests sent to the destination by introducing delays and errors.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return applyEgressDisruption(cmd, env, config, target, func(l *protocol.EgressListener) (protocol.Proxy, error) {
+				return grpc.NewEgressProxy(l, disruption)
+			})
+		},
+	}
+
+	target.addFlags(cmd)
+	cmd.Flags().DurationVarP(&disruption.AverageDelay, "average-delay", "a", 0, "average request delay")
+	cmd.Flags().DurationVarP(&disruption.DelayVariation, "delay-variation", "v", 0, "variation in request delay")
+	cmd.Flags().Int32VarP(&disruption.StatusCode, "status", "s", 0, "status code")
+	cmd.Flags().Float32VarP(&disruption.ErrorRate, "rate", "r", 0, "error rate")
+	cmd.Flags().StringVarP(&disruption.StatusMessage, "message", "m", "", "error message for injected faults")
+	cmd.Flags().StringSliceVarP(&disruption.Excluded, "exclude", "x", []string{}, "comma-separated list of grpc services"+
+		" to be excluded from disruption")
+
+	return cmd
+}
+
+func buildEgressTCPCmd(env runtime.Environment, config *agent.Config) *cobra.Command {
+	disruption := tcp.Disruption{}
+	target := &egressTarget{}
+
+	cmd := &cobra.Command{
+		Use:   "tcp",
+		Short: "egress tcp disruptor",
+		Long:  "Disrupts the tcp connections opened to the destination by introducing delays and resets.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return applyEgressDisruption(cmd, env, config, target, func(l *protocol.EgressListener) (protocol.Proxy, error) {
+				return tcp.NewEgressProxy(l, disruption)
+			})
+		},
+	}
+
+	target.addFlags(cmd)
+	cmd.Flags().DurationVarP(&disruption.AverageDelay, "average-delay", "a", 0, "average connection delay")
+	cmd.Flags().DurationVarP(&disruption.DelayVariation, "delay-variation", "v", 0, "variation in connection delay")
+	cmd.Flags().Float32VarP(&disruption.ErrorRate, "rate", "r", 0, "fraction of connections to be reset")
+
+	return cmd
+}
@@ -0,0 +1,121 @@
+package commands
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/grafana/xk6-disruptor/pkg/agent"
+	"github.com/grafana/xk6-disruptor/pkg/agent/exhaust"
+	"github.com/grafana/xk6-disruptor/pkg/agent/process"
+	"github.com/grafana/xk6-disruptor/pkg/runtime"
+
+	"github.com/spf13/cobra"
+)
+
+// procRoot is the path where the proc filesystem is mounted
+const procRoot = "/proc"
+
+// BuildExhaustCmd returns a cobra command with the specification of the exhaust command
+func BuildExhaustCmd(env runtime.Environment, config *agent.Config) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "exhaust",
+		Short: "resource exhaustion disruptors",
+		Long: "Exhausts resources of a target container. Requires the agent to share the process namespace" +
+			" with the target container.",
+	}
+
+	cmd.AddCommand(buildExhaustDiskCmd(env, config))
+	cmd.AddCommand(buildExhaustFDCmd(env, config))
+
+	return cmd
+}
+
+func buildExhaustDiskCmd(env runtime.Environment, config *agent.Config) *cobra.Command {
+	fill := exhaust.DiskFill{}
+	var duration time.Duration
+	var containerID string
+
+	cmd := &cobra.Command{
+		Use:   "disk",
+		Short: "disk fill disruptor",
+		Long: "Fills the filesystem that contains a path of the target container up to a percentage of its size" +
+			" for the duration of the disruption.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if containerID == "" {
+				return fmt.Errorf("target container id is required")
+			}
+
+			agent, err := agent.Start(env, config)
+			if err != nil {
+				return fmt.Errorf("initializing agent: %w", err)
+			}
+
+			defer agent.Stop()
+
+			disruptor, err := exhaust.NewDiskFiller(
+				process.DefaultFinder(),
+				procRoot,
+				containerID,
+				exhaust.FilesystemUsage,
+				fill,
+			)
+			if err != nil {
+				return err
+			}
+
+			return agent.ApplyDisruption(cmd.Context(), disruptor, duration)
+		},
+	}
+
+	cmd.Flags().DurationVarP(&duration, "duration", "d", 0, "duration of the disruptions")
+	cmd.Flags().StringVarP(&containerID, "container-id", "c", "", "id of the target container")
+	cmd.Flags().StringVar(&fill.Path, "path", "", "path in the target container of the filesystem to fill")
+	cmd.Flags().UintVar(&fill.Percentage, "percentage", 0, "percentage of the filesystem used once filled")
+
+	return cmd
+}
+
+func buildExhaustFDCmd(env runtime.Environment, config *agent.Config) *cobra.Command {
+	exhaustion := exhaust.FDExhaustion{}
+	var duration time.Duration
+	var containerID string
+
+	cmd := &cobra.Command{
+		Use:   "fd",
+		Short: "file descriptor exhaustion disruptor",
+		Long: "Opens descriptors in the main process of the target container until its open files limit is" +
+			" reached, and closes them after the duration of the disruption. Requires the SYS_PTRACE capability.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if containerID == "" {
+				return fmt.Errorf("target container id is required")
+			}
+
+			agent, err := agent.Start(env, config)
+			if err != nil {
+				return fmt.Errorf("initializing agent: %w", err)
+			}
+
+			defer agent.Stop()
+
+			disruptor, err := exhaust.NewFDExhauster(
+				process.DefaultSyscallInjector(),
+				process.DefaultFinder(),
+				procRoot,
+				containerID,
+				exhaust.DefaultFDFile(),
+				exhaustion,
+			)
+			if err != nil {
+				return err
+			}
+
+			return agent.ApplyDisruption(cmd.Context(), disruptor, duration)
+		},
+	}
+
+	cmd.Flags().DurationVarP(&duration, "duration", "d", 0, "duration of the disruptions")
+	cmd.Flags().StringVarP(&containerID, "container-id", "c", "", "id of the target container")
+	cmd.Flags().UintVar(&exhaustion.Remaining, "remaining", 0, "number of descriptors the process can still open")
+
+	return cmd
+}
@@ -0,0 +1,53 @@
+package commands
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/grafana/xk6-disruptor/pkg/agent"
+	"github.com/grafana/xk6-disruptor/pkg/agent/process"
+	"github.com/grafana/xk6-disruptor/pkg/runtime"
+
+	"github.com/spf13/cobra"
+)
+
+// BuildFreezeCmd returns a cobra command with the specification of the freeze command
+func BuildFreezeCmd(env runtime.Environment, config *agent.Config) *cobra.Command {
+	var duration time.Duration
+	var containerID string
+
+	cmd := &cobra.Command{
+		Use:   "freeze",
+		Short: "freeze disruptor",
+		Long: "Stops all the processes of a target container for the duration of the disruption and resumes them" +
+			" afterwards. Requires the agent to share the process namespace with the target container.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if containerID == "" {
+				return fmt.Errorf("target container id is required")
+			}
+
+			agent, err := agent.Start(env, config)
+			if err != nil {
+				return fmt.Errorf("initializing agent: %w", err)
+			}
+
+			defer agent.Stop()
+
+			disruptor, err := process.NewFreezeDisruptor(
+				env.Executor(),
+				process.DefaultFinder(),
+				containerID,
+			)
+			if err != nil {
+				return err
+			}
+
+			return agent.ApplyDisruption(cmd.Context(), disruptor, duration)
+		},
+	}
+
+	cmd.Flags().DurationVarP(&duration, "duration", "d", 0, "duration of the disruptions")
+	cmd.Flags().StringVarP(&containerID, "container-id", "c", "", "id of the target container")
+
+	return cmd
+}
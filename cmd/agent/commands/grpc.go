@@ -82,6 +82,7 @@ func BuildGrpcCmd(env runtime.Environment, config *agent.Config) *cobra.Command
 				env.Executor(),
 				proxy,
 				redirector,
+				cmd.OutOrStdout(),
 			)
 			if err != nil {
 				return err
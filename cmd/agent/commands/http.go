@@ -81,6 +81,7 @@ func BuildHTTPCmd(env runtime.Environment, config *agent.Config) *cobra.Command
 				env.Executor(),
 				proxy,
 				redirector,
+				cmd.OutOrStdout(),
 			)
 			if err != nil {
 				return err
@@ -0,0 +1,59 @@
+package commands
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/grafana/xk6-disruptor/pkg/agent"
+	"github.com/grafana/xk6-disruptor/pkg/agent/network"
+	"github.com/grafana/xk6-disruptor/pkg/runtime"
+
+	"github.com/spf13/cobra"
+)
+
+// BuildNetworkCmd returns a cobra command with the specification of the network command
+func BuildNetworkCmd(env runtime.Environment, config *agent.Config) *cobra.Command {
+	disruption := network.Disruption{}
+	filter := network.Filter{}
+	var duration time.Duration
+	var iface string
+
+	cmd := &cobra.Command{
+		Use:   "network",
+		Short: "network disruptor",
+		Long: "Disrupts the network traffic of an interface by introducing delays, packet loss, duplication," +
+			" corruption and reordering. The disruption can be restricted to a port or a peer." +
+			" Requires NET_ADMIN capabilities for setting queue disciplines.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			agent, err := agent.Start(env, config)
+			if err != nil {
+				return fmt.Errorf("initializing agent: %w", err)
+			}
+
+			defer agent.Stop()
+
+			disruptor, err := network.NewDisruptor(env.Executor(), iface, disruption, filter)
+			if err != nil {
+				return err
+			}
+
+			return agent.ApplyDisruption(cmd.Context(), disruptor, duration)
+		},
+	}
+
+	cmd.Flags().DurationVarP(&duration, "duration", "d", 0, "duration of the disruptions")
+	cmd.Flags().DurationVarP(&disruption.AverageDelay, "average-delay", "a", 0, "average packet delay")
+	cmd.Flags().DurationVarP(&disruption.DelayVariation, "delay-variation", "v", 0, "variation in packet delay")
+	cmd.Flags().Float32Var(&disruption.DelayCorrelation, "delay-correlation", 0,
+		"correlation of the delay with the delay of the previous packet")
+	cmd.Flags().Float32VarP(&disruption.LossRate, "loss-rate", "l", 0, "fraction of packets dropped")
+	cmd.Flags().Float32Var(&disruption.DuplicationRate, "duplication-rate", 0, "fraction of packets duplicated")
+	cmd.Flags().Float32Var(&disruption.CorruptionRate, "corruption-rate", 0, "fraction of packets corrupted")
+	cmd.Flags().Float32Var(&disruption.ReorderRate, "reorder-rate", 0,
+		"fraction of packets sent ahead of the delayed ones")
+	cmd.Flags().UintVar(&filter.Port, "port", 0, "disrupt only packets sent from or to this port")
+	cmd.Flags().StringVar(&filter.Peer, "peer", "", "disrupt only packets sent to this IPv4 address or CIDR")
+	cmd.Flags().StringVarP(&iface, "interface", "i", "", "interface to disrupt (defaults to the default route's)")
+
+	return cmd
+}
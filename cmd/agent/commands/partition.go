@@ -0,0 +1,87 @@
+package commands
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/grafana/xk6-disruptor/pkg/agent"
+	"github.com/grafana/xk6-disruptor/pkg/agent/partition"
+	"github.com/grafana/xk6-disruptor/pkg/iptables"
+	"github.com/grafana/xk6-disruptor/pkg/runtime"
+
+	"github.com/spf13/cobra"
+)
+
+// BuildPartitionCmd returns a cobra command with the specification of the partition command
+func BuildPartitionCmd(env runtime.Environment, config *agent.Config) *cobra.Command {
+	var duration time.Duration
+	var direction string
+	var peers []string
+	var peersFile string
+
+	cmd := &cobra.Command{
+		Use:   "partition",
+		Short: "network partition disruptor",
+		Long: "Isolates the target from a set of peers by dropping the traffic exchanged with them." +
+			" Requires NET_ADMIN capabilities for setting iptable rules.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			agent, err := agent.Start(env, config)
+			if err != nil {
+				return fmt.Errorf("initializing agent: %w", err)
+			}
+
+			defer agent.Stop()
+
+			disruption := partition.Disruption{
+				Direction: iptables.PartitionDirection(direction),
+				Peers:     peers,
+			}
+
+			disruptor, err := partition.NewDisruptor(
+				env.Executor(),
+				disruption,
+				peersFile,
+				partition.DefaultRefreshInterval,
+			)
+			if err != nil {
+				return err
+			}
+
+			return agent.ApplyDisruption(cmd.Context(), disruptor, duration)
+		},
+	}
+
+	cmd.Flags().DurationVarP(&duration, "duration", "d", 0, "duration of the disruptions")
+	cmd.Flags().StringVar(&direction, "direction", string(iptables.PartitionBoth),
+		"connections blocked: 'both', 'outgoing' or 'incoming'")
+	cmd.Flags().StringSliceVar(&peers, "peers", []string{}, "comma-separated list of the peers' IP addresses")
+	cmd.Flags().StringVar(&peersFile, "peers-file", partition.DefaultPeersFile(), "file used for updating the peers")
+
+	cmd.AddCommand(buildPartitionUpdateCmd())
+
+	return cmd
+}
+
+// buildPartitionUpdateCmd returns a command that updates the peers of a running partition.
+// It does not start an agent, as it must run while the partition command holds the agent's lock.
+func buildPartitionUpdateCmd() *cobra.Command {
+	var peers []string
+	var peersFile string
+
+	cmd := &cobra.Command{
+		Use:   "update",
+		Short: "updates the peers of a running partition",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(peers) == 0 {
+				return fmt.Errorf("at least one peer must be specified")
+			}
+
+			return partition.WritePeers(peersFile, peers)
+		},
+	}
+
+	cmd.Flags().StringSliceVar(&peers, "peers", []string{}, "comma-separated list of the peers' IP addresses")
+	cmd.Flags().StringVar(&peersFile, "peers-file", partition.DefaultPeersFile(), "file used for updating the peers")
+
+	return cmd
+}
@@ -26,6 +26,14 @@ func NewRootCommand(env runtime.Environment) *RootCommand {
 	rootCmd := buildRootCmd(config)
 	rootCmd.AddCommand(BuildHTTPCmd(env, config))
 	rootCmd.AddCommand(BuildGrpcCmd(env, config))
+	rootCmd.AddCommand(BuildEgressCmd(env, config))
+	rootCmd.AddCommand(BuildDNSCmd(env, config))
+	rootCmd.AddCommand(BuildSignalCmd(env, config))
+	rootCmd.AddCommand(BuildFreezeCmd(env, config))
+	rootCmd.AddCommand(BuildNetworkCmd(env, config))
+	rootCmd.AddCommand(BuildPartitionCmd(env, config))
+	rootCmd.AddCommand(BuildStressCmd(env, config))
+	rootCmd.AddCommand(BuildExhaustCmd(env, config))
 	rootCmd.AddCommand(BuiltCleanupCmd(env))
 
 	return &RootCommand{
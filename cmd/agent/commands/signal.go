@@ -0,0 +1,53 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/grafana/xk6-disruptor/pkg/agent"
+	"github.com/grafana/xk6-disruptor/pkg/agent/process"
+	"github.com/grafana/xk6-disruptor/pkg/runtime"
+
+	"github.com/spf13/cobra"
+)
+
+// BuildSignalCmd returns a cobra command with the specification of the signal command
+func BuildSignalCmd(env runtime.Environment, config *agent.Config) *cobra.Command {
+	var containerID string
+	var signal string
+
+	cmd := &cobra.Command{
+		Use:   "signal",
+		Short: "signal disruptor",
+		Long: "Sends a signal to the main process of a target container." +
+			" Requires the agent to share the process namespace with the target container.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if containerID == "" {
+				return fmt.Errorf("target container id is required")
+			}
+
+			agent, err := agent.Start(env, config)
+			if err != nil {
+				return fmt.Errorf("initializing agent: %w", err)
+			}
+
+			defer agent.Stop()
+
+			disruptor, err := process.NewSignalDisruptor(
+				env.Executor(),
+				process.DefaultFinder(),
+				containerID,
+				signal,
+			)
+			if err != nil {
+				return err
+			}
+
+			return agent.ApplyDisruption(cmd.Context(), disruptor, 0)
+		},
+	}
+
+	cmd.Flags().StringVarP(&signal, "signal", "s", process.DefaultSignal, "signal to send to the target process")
+	cmd.Flags().StringVarP(&containerID, "container-id", "c", "", "id of the target container")
+
+	return cmd
+}
@@ -0,0 +1,116 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/grafana/xk6-disruptor/pkg/agent"
+	"github.com/grafana/xk6-disruptor/pkg/agent/process"
+	"github.com/grafana/xk6-disruptor/pkg/agent/stress"
+	"github.com/grafana/xk6-disruptor/pkg/runtime"
+
+	"github.com/spf13/cobra"
+)
+
+// BuildStressCmd returns a cobra command with the specification of the stress command
+func BuildStressCmd(env runtime.Environment, config *agent.Config) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "stress",
+		Short: "resource stress disruptors",
+		Long:  "Consumes resources in the target where the agent runs.",
+	}
+
+	cmd.AddCommand(buildStressCPUCmd(env, config))
+	cmd.AddCommand(buildStressMemoryCmd(env, config))
+
+	return cmd
+}
+
+func buildStressCPUCmd(env runtime.Environment, config *agent.Config) *cobra.Command {
+	cpuStress := stress.CPUStress{}
+	var duration time.Duration
+	var containerID string
+
+	cmd := &cobra.Command{
+		Use:   "cpu",
+		Short: "cpu stress disruptor",
+		Long: "Keeps a number of cores busy for the given percentage of the time for the duration of the disruption." +
+			" If a target container is given, the load is generated in its cgroup, competing with its processes." +
+			" This requires the agent to share the process namespace of the container and to run privileged.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			agent, err := agent.Start(env, config)
+			if err != nil {
+				return fmt.Errorf("initializing agent: %w", err)
+			}
+
+			defer agent.Stop()
+
+			if containerID != "" {
+				err = process.DefaultCgroupJoiner().Join(containerID, os.Getpid())
+				if err != nil {
+					return fmt.Errorf("joining cgroup of container %q: %w", containerID, err)
+				}
+			}
+
+			disruptor, err := stress.NewCPUStressor(cpuStress)
+			if err != nil {
+				return err
+			}
+
+			return agent.ApplyDisruption(cmd.Context(), disruptor, duration)
+		},
+	}
+
+	cmd.Flags().DurationVarP(&duration, "duration", "d", 0, "duration of the disruptions")
+	cmd.Flags().UintVarP(&cpuStress.Cores, "cores", "c", 1, "number of cores to keep busy")
+	cmd.Flags().UintVarP(&cpuStress.Load, "load", "l", 100, "percentage of the time each core is kept busy")
+	cmd.Flags().StringVar(&containerID, "container-id", "", "id of the container in whose cgroup the load is generated")
+
+	return cmd
+}
+
+func buildStressMemoryCmd(env runtime.Environment, config *agent.Config) *cobra.Command {
+	memoryStress := stress.MemoryStress{}
+	var duration time.Duration
+	var containerID string
+
+	cmd := &cobra.Command{
+		Use:   "memory",
+		Short: "memory stress disruptor",
+		Long: "Allocates an amount of memory for the duration of the disruption, optionally ramping it up." +
+			" In OOM mode, keeps allocating memory after reaching the amount until the process is killed." +
+			" If a target container is given, the memory is allocated in its cgroup, subject to its memory limit." +
+			" This requires the agent to share the process namespace of the container and to run privileged.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			agent, err := agent.Start(env, config)
+			if err != nil {
+				return fmt.Errorf("initializing agent: %w", err)
+			}
+
+			defer agent.Stop()
+
+			if containerID != "" {
+				err = process.DefaultCgroupJoiner().Join(containerID, os.Getpid())
+				if err != nil {
+					return fmt.Errorf("joining cgroup of container %q: %w", containerID, err)
+				}
+			}
+
+			disruptor, err := stress.NewMemoryStressor(memoryStress)
+			if err != nil {
+				return err
+			}
+
+			return agent.ApplyDisruption(cmd.Context(), disruptor, duration)
+		},
+	}
+
+	cmd.Flags().DurationVarP(&duration, "duration", "d", 0, "duration of the disruptions")
+	cmd.Flags().Uint64VarP(&memoryStress.Bytes, "bytes", "b", 0, "number of bytes to allocate")
+	cmd.Flags().DurationVar(&memoryStress.Ramp, "ramp", 0, "time taken to allocate the number of bytes")
+	cmd.Flags().BoolVar(&memoryStress.OOM, "oom", false, "keep allocating memory after reaching the number of bytes")
+	cmd.Flags().StringVar(&containerID, "container-id", "", "id of the container in whose cgroup the memory is allocated")
+
+	return cmd
+}
@@ -53,8 +53,21 @@ func (*RootModule) NewModuleInstance(vu modules.VU) modules.Instance {
 func (m *ModuleInstance) Exports() modules.Exports {
 	return modules.Exports{
 		Named: map[string]interface{}{
-			"PodDisruptor":     m.newPodDisruptor,
-			"ServiceDisruptor": m.newServiceDisruptor,
+			"PodDisruptor":         m.newPodDisruptor,
+			"ServiceDisruptor":     m.newServiceDisruptor,
+			"NodeDisruptor":        m.newNodeDisruptor,
+			"DependencyDisruptor":  m.newDependencyDisruptor,
+			"WorkloadDisruptor":    m.newWorkloadDisruptor,
+			"WorkloadPodDisruptor": m.newWorkloadPodDisruptor,
+			"Timeline":             m.newTimeline,
+			// asynchronous versions of the constructors, which return promises
+			"createPodDisruptor":         m.newDisruptorAsync("PodDisruptor"),
+			"createServiceDisruptor":     m.newDisruptorAsync("ServiceDisruptor"),
+			"createNodeDisruptor":        m.newDisruptorAsync("NodeDisruptor"),
+			"createDependencyDisruptor":  m.newDisruptorAsync("DependencyDisruptor"),
+			"createWorkloadDisruptor":    m.newDisruptorAsync("WorkloadDisruptor"),
+			"createWorkloadPodDisruptor": m.newDisruptorAsync("WorkloadPodDisruptor"),
+			"runScenario":                m.runScenario,
 		},
 	}
 }
@@ -71,6 +84,19 @@ func (m *ModuleInstance) newPodDisruptor(c goja.ConstructorCall) *goja.Object {
 	return disruptor
 }
 
+// creates an instance of a PodDisruptor for the pods of a workload
+func (m *ModuleInstance) newWorkloadPodDisruptor(c goja.ConstructorCall) *goja.Object {
+	rt := m.vu.Runtime()
+	ctx := m.vu.Context()
+
+	disruptor, err := api.NewWorkloadPodDisruptor(ctx, rt, c, m.k8s)
+	if err != nil {
+		common.Throw(rt, fmt.Errorf("error creating PodDisruptor: %w", err))
+	}
+
+	return disruptor
+}
+
 // creates an instance of a ServiceDisruptor
 func (m *ModuleInstance) newServiceDisruptor(c goja.ConstructorCall) *goja.Object {
 	rt := m.vu.Runtime()
@@ -83,3 +109,83 @@ func (m *ModuleInstance) newServiceDisruptor(c goja.ConstructorCall) *goja.Objec
 
 	return disruptor
 }
+
+// creates an instance of a NodeDisruptor
+func (m *ModuleInstance) newNodeDisruptor(c goja.ConstructorCall) *goja.Object {
+	rt := m.vu.Runtime()
+	ctx := m.vu.Context()
+
+	disruptor, err := api.NewNodeDisruptor(ctx, rt, c, m.k8s)
+	if err != nil {
+		common.Throw(rt, fmt.Errorf("error creating NodeDisruptor: %w", err))
+	}
+
+	return disruptor
+}
+
+// creates an instance of a DependencyDisruptor
+func (m *ModuleInstance) newDependencyDisruptor(c goja.ConstructorCall) *goja.Object {
+	rt := m.vu.Runtime()
+	ctx := m.vu.Context()
+
+	disruptor, err := api.NewDependencyDisruptor(ctx, rt, c, m.k8s)
+	if err != nil {
+		common.Throw(rt, fmt.Errorf("error creating DependencyDisruptor: %w", err))
+	}
+
+	return disruptor
+}
+
+// creates an instance of a WorkloadDisruptor
+func (m *ModuleInstance) newWorkloadDisruptor(c goja.ConstructorCall) *goja.Object {
+	rt := m.vu.Runtime()
+	ctx := m.vu.Context()
+
+	disruptor, err := api.NewWorkloadDisruptor(ctx, rt, c, m.k8s)
+	if err != nil {
+		common.Throw(rt, fmt.Errorf("error creating WorkloadDisruptor: %w", err))
+	}
+
+	return disruptor
+}
+
+// creates an instance of a Timeline
+func (m *ModuleInstance) newTimeline(c goja.ConstructorCall) *goja.Object {
+	rt := m.vu.Runtime()
+	ctx := m.vu.Context()
+
+	timeline, err := api.NewTimeline(ctx, rt, c)
+	if err != nil {
+		common.Throw(rt, fmt.Errorf("error creating Timeline: %w", err))
+	}
+
+	return timeline
+}
+
+// returns a function that creates an instance of the named disruptor asynchronously. The function returns a promise
+// for the disruptor, whose methods also return promises.
+func (m *ModuleInstance) newDisruptorAsync(name string) func(c goja.FunctionCall) goja.Value {
+	return func(c goja.FunctionCall) goja.Value {
+		rt := m.vu.Runtime()
+
+		promise, err := api.NewDisruptorAsync(m.vu, name, c.Arguments, m.k8s)
+		if err != nil {
+			common.Throw(rt, fmt.Errorf("error creating %s: %w", name, err))
+		}
+
+		return rt.ToValue(promise)
+	}
+}
+
+// runs the scenario defined in a YAML or JSON file
+func (m *ModuleInstance) runScenario(c goja.FunctionCall) goja.Value {
+	rt := m.vu.Runtime()
+	ctx := m.vu.Context()
+
+	err := api.RunScenario(ctx, rt, c.Arguments, m.k8s)
+	if err != nil {
+		common.Throw(rt, fmt.Errorf("error running scenario: %w", err))
+	}
+
+	return goja.Undefined()
+}
@@ -13,6 +13,7 @@ import (
 
 	"github.com/grafana/xk6-disruptor/pkg/agent/protocol"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
 
 	"github.com/grafana/xk6-disruptor/pkg/disruptors"
@@ -42,6 +43,7 @@ func Test_PodDisruptor(t *testing.T) {
 	err = cluster.Load(
 		fixtures.BuildHttpbinPod().Spec.Containers[0].Image,
 		fixtures.BuildGrpcpbinPod().Spec.Containers[0].Image,
+		fixtures.BuildNginxPod().Spec.Containers[0].Image,
 	)
 	if err != nil {
 		t.Fatalf("preloading test pod images: %v", err)
@@ -210,6 +212,131 @@ func Test_PodDisruptor(t *testing.T) {
 		}
 	})
 
+	t.Run("Signal fault in pod not sharing its process namespace", func(t *testing.T) {
+		t.Parallel()
+
+		namespace, err := namespace.CreateTestNamespace(context.TODO(), t, k8s.Client())
+		if err != nil {
+			t.Fatalf("failed to create test namespace: %v", err)
+		}
+
+		pod := fixtures.BuildNginxPod()
+		if pod.Spec.ShareProcessNamespace != nil && *pod.Spec.ShareProcessNamespace {
+			t.Fatalf("test pod must not share its process namespace")
+		}
+
+		err = deploy.RunPod(k8s, namespace, pod, 30*time.Second)
+		if err != nil {
+			t.Fatalf("error deploying pod: %v", err)
+		}
+
+		selector := disruptors.PodSelector{
+			Namespace: namespace,
+			Select: disruptors.PodAttributes{
+				Labels: pod.Labels,
+			},
+		}
+		disruptor, err := disruptors.NewPodDisruptor(context.TODO(), k8s, selector, disruptors.PodDisruptorOptions{})
+		if err != nil {
+			t.Fatalf("error creating disruptor: %v", err)
+		}
+
+		// the main process of the container is the init process of its pid namespace
+		err = disruptor.InjectSignalFault(context.TODO(), disruptors.SignalFault{Signal: "SIGKILL"})
+		if err != nil {
+			t.Fatalf("error injecting signal fault: %v", err)
+		}
+
+		// the container is restarted after its main process is killed
+		deadline := time.Now().Add(30 * time.Second)
+		for {
+			current, getErr := k8s.Client().CoreV1().Pods(namespace).Get(context.TODO(), pod.Name, metav1.GetOptions{})
+			if getErr != nil {
+				t.Fatalf("error getting pod: %v", getErr)
+			}
+
+			if len(current.Status.ContainerStatuses) > 0 && current.Status.ContainerStatuses[0].RestartCount > 0 {
+				return
+			}
+
+			if time.Now().After(deadline) {
+				t.Fatalf("container was not restarted")
+			}
+
+			time.Sleep(time.Second)
+		}
+	})
+
+	t.Run("DNS fault in UDP queries", func(t *testing.T) {
+		t.Parallel()
+
+		namespace, err := namespace.CreateTestNamespace(context.TODO(), t, k8s.Client())
+		if err != nil {
+			t.Fatalf("failed to create test namespace: %v", err)
+		}
+
+		pod := fixtures.BuildBusyBoxPod()
+		err = deploy.RunPod(k8s, namespace, pod, 30*time.Second)
+		if err != nil {
+			t.Fatalf("error deploying pod: %v", err)
+		}
+
+		selector := disruptors.PodSelector{
+			Namespace: namespace,
+			Select: disruptors.PodAttributes{
+				Labels: pod.Labels,
+			},
+		}
+		disruptor, err := disruptors.NewPodDisruptor(context.TODO(), k8s, selector, disruptors.PodDisruptorOptions{})
+		if err != nil {
+			t.Fatalf("error creating disruptor: %v", err)
+		}
+
+		// the name is resolved before injecting the fault for ensuring the cluster's resolver works
+		name := "kubernetes.default.svc.cluster.local"
+		lookup := []string{"nslookup", name}
+		_, stderr, err := k8s.PodHelper(namespace).Exec(context.TODO(), pod.Name, "busybox", lookup, []byte{})
+		if err != nil {
+			t.Fatalf("error resolving %q before injecting the fault: %v %s", name, err, string(stderr))
+		}
+
+		fault := disruptors.DNSFault{
+			ErrorRate: 1.0,
+			Error:     "nxdomain",
+			Names:     name,
+		}
+
+		// apply disruption in a go-routine as it is a blocking function
+		go func() {
+			injectErr := disruptor.InjectDNSFaults(context.TODO(), fault, 20*time.Second, disruptors.DNSDisruptionOptions{})
+			if injectErr != nil {
+				t.Logf("failed to inject DNS fault: %v", injectErr)
+			}
+		}()
+
+		// busybox's nslookup sends the queries over UDP. It fails when the response is NXDOMAIN.
+		deadline := time.Now().Add(15 * time.Second)
+		for {
+			stdout, stderr, lookupErr := k8s.PodHelper(namespace).Exec(
+				context.TODO(),
+				pod.Name,
+				"busybox",
+				lookup,
+				[]byte{},
+			)
+			output := string(stdout) + string(stderr)
+			if lookupErr != nil && strings.Contains(output, "NXDOMAIN") {
+				return
+			}
+
+			if time.Now().After(deadline) {
+				t.Fatalf("query was not disrupted. Output: %s", output)
+			}
+
+			time.Sleep(time.Second)
+		}
+	})
+
 	t.Run("Disruptor errors out if no requests are received", func(t *testing.T) {
 		t.Parallel()
 
@@ -0,0 +1,41 @@
+// Package multierr provides the aggregation of multiple errors into one
+package multierr
+
+import (
+	"strings"
+)
+
+// joinedErrors is an error that wraps multiple errors
+type joinedErrors []error
+
+// Error returns the messages of the errors separated by "; "
+func (e joinedErrors) Error() string {
+	messages := make([]string, 0, len(e))
+	for _, err := range e {
+		messages = append(messages, err.Error())
+	}
+
+	return strings.Join(messages, "; ")
+}
+
+// Unwrap returns the wrapped errors
+func (e joinedErrors) Unwrap() []error {
+	return e
+}
+
+// Join returns an error that wraps the given errors, discarding nil values. Returns nil if all the errors are nil.
+// TODO: Replace with errors.Join when we upgrade from Go 1.19 to 1.20.
+func Join(errs ...error) error {
+	joined := joinedErrors{}
+	for _, err := range errs {
+		if err != nil {
+			joined = append(joined, err)
+		}
+	}
+
+	if len(joined) == 0 {
+		return nil
+	}
+
+	return joined
+}
@@ -0,0 +1,64 @@
+package multierr
+
+import (
+	"errors"
+	"testing"
+)
+
+func Test_Join(t *testing.T) {
+	t.Parallel()
+
+	first := errors.New("first")
+	second := errors.New("second")
+
+	testCases := []struct {
+		title    string
+		errs     []error
+		expected string
+	}{
+		{
+			title:    "no errors",
+			errs:     nil,
+			expected: "",
+		},
+		{
+			title:    "only nil errors",
+			errs:     []error{nil, nil},
+			expected: "",
+		},
+		{
+			title:    "one error",
+			errs:     []error{nil, first},
+			expected: "first",
+		},
+		{
+			title:    "multiple errors",
+			errs:     []error{first, nil, second},
+			expected: "first; second",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			err := Join(tc.errs...)
+			if tc.expected == "" {
+				if err != nil {
+					t.Errorf("expected nil got %v", err)
+				}
+				return
+			}
+
+			if err == nil {
+				t.Fatalf("should had failed")
+			}
+
+			if err.Error() != tc.expected {
+				t.Errorf("expected %q got %q", tc.expected, err.Error())
+			}
+		})
+	}
+}
@@ -9,7 +9,6 @@ import (
 	"syscall"
 	"time"
 
-	"github.com/grafana/xk6-disruptor/pkg/agent/protocol"
 	"github.com/grafana/xk6-disruptor/pkg/runtime"
 	"github.com/grafana/xk6-disruptor/pkg/runtime/profiler"
 )
@@ -19,6 +18,12 @@ type Config struct {
 	Profiler *profiler.Config
 }
 
+// Disruptor defines the interface of the disruptions applied by the agent to a target
+type Disruptor interface {
+	// Apply applies the disruption for the given duration or until the context is cancelled
+	Apply(context.Context, time.Duration) error
+}
+
 // Agent maintains the state required for executing an agent command
 type Agent struct {
 	env           runtime.Environment
@@ -65,7 +70,7 @@ func (a *Agent) start(config *Config) error {
 }
 
 // ApplyDisruption applies a disruption to the target
-func (a *Agent) ApplyDisruption(ctx context.Context, disruptor protocol.Disruptor, duration time.Duration) error {
+func (a *Agent) ApplyDisruption(ctx context.Context, disruptor Disruptor, duration time.Duration) error {
 	// set context for command
 	ctx, cancel := context.WithCancel(ctx)
 
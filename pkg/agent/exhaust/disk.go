@@ -0,0 +1,191 @@
+// Package exhaust implements disruptors that exhaust resources of a target container, such as the space in its
+// filesystems or its file descriptors.
+// The agent must share the process namespace with the target container, for example because it runs in an
+// ephemeral container that targets it.
+package exhaust
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/grafana/xk6-disruptor/pkg/agent"
+	"github.com/grafana/xk6-disruptor/pkg/agent/process"
+)
+
+// FillFileName is the name of the file created for filling a path
+const FillFileName = ".xk6-disruptor-fill"
+
+// fillChunkSize is the size of the writes made for filling a path
+const fillChunkSize = 1024 * 1024
+
+// DiskFill specifies the filling of a filesystem of the target container
+type DiskFill struct {
+	// Path in the target container of the filesystem to fill
+	Path string
+	// Percentage (in the range 1 to 100) of the filesystem that will be used once filled
+	Percentage uint
+}
+
+// UsageFunc returns the total and used bytes of the filesystem that contains the given path
+type UsageFunc func(path string) (total uint64, used uint64, err error)
+
+// FilesystemUsage returns the total and used bytes of the filesystem that contains the given path
+func FilesystemUsage(path string) (uint64, uint64, error) {
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs(path, &stat); err != nil {
+		return 0, 0, fmt.Errorf("getting usage of %q: %w", path, err)
+	}
+
+	//nolint:unconvert // the type of the fields depends on the platform
+	total := uint64(stat.Blocks) * uint64(stat.Bsize)
+	//nolint:unconvert // the type of the fields depends on the platform
+	used := (uint64(stat.Blocks) - uint64(stat.Bfree)) * uint64(stat.Bsize)
+
+	return total, used, nil
+}
+
+// containerPath returns the path of the given path of the target container as seen from the agent, through the
+// root of the container's main process
+func containerPath(finder process.Finder, procRoot string, containerID string, path string) (string, error) {
+	processes, err := finder.Find(containerID)
+	if err != nil {
+		return "", fmt.Errorf("finding processes of container %q: %w", containerID, err)
+	}
+
+	main, err := process.MainProcess(processes)
+	if err != nil {
+		return "", fmt.Errorf("finding main process of container %q: %w", containerID, err)
+	}
+
+	return filepath.Join(procRoot, strconv.Itoa(main.PID), "root", path), nil
+}
+
+// diskFiller is an instance of a Disruptor that fills a filesystem of the target container
+type diskFiller struct {
+	finder      process.Finder
+	procRoot    string
+	containerID string
+	usage       UsageFunc
+	fill        DiskFill
+}
+
+// NewDiskFiller returns a Disruptor that fills the filesystem of the target container that contains the given path,
+// using the proc filesystem mounted at procRoot for accessing the filesystem of the container.
+func NewDiskFiller(
+	finder process.Finder,
+	procRoot string,
+	containerID string,
+	usage UsageFunc,
+	fill DiskFill,
+) (agent.Disruptor, error) {
+	if containerID == "" {
+		return nil, fmt.Errorf("container ID must be specified")
+	}
+
+	if fill.Path == "" {
+		return nil, fmt.Errorf("path must be specified")
+	}
+
+	if fill.Percentage == 0 || fill.Percentage > 100 {
+		return nil, fmt.Errorf("percentage must be in the range [1, 100]")
+	}
+
+	return &diskFiller{
+		finder:      finder,
+		procRoot:    procRoot,
+		containerID: containerID,
+		usage:       usage,
+		fill:        fill,
+	}, nil
+}
+
+// write writes the given number of bytes to the file. The filesystem becoming full is not considered an error.
+func write(ctx context.Context, file *os.File, size uint64) error {
+	chunk := make([]byte, fillChunkSize)
+	for written := uint64(0); written < size; written += uint64(len(chunk)) {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		if remaining := size - written; remaining < uint64(len(chunk)) {
+			chunk = chunk[:remaining]
+		}
+
+		_, err := file.Write(chunk)
+		if errors.Is(err, syscall.ENOSPC) {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("filling %q: %w", file.Name(), err)
+		}
+	}
+
+	return file.Sync()
+}
+
+// Apply fills the filesystem up to the percentage for the given duration and removes the fill file afterwards
+func (d *diskFiller) Apply(ctx context.Context, duration time.Duration) error {
+	if duration < time.Second {
+		return fmt.Errorf("duration must be at least one second")
+	}
+
+	dir, err := containerPath(d.finder, d.procRoot, d.containerID, d.fill.Path)
+	if err != nil {
+		return err
+	}
+
+	total, used, err := d.usage(dir)
+	if err != nil {
+		return err
+	}
+
+	target := total * uint64(d.fill.Percentage) / 100
+	if used >= target {
+		return fmt.Errorf("usage of %q is already above %d%%", d.fill.Path, d.fill.Percentage)
+	}
+
+	fillFile := filepath.Join(dir, FillFileName)
+	file, err := os.OpenFile(fillFile, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("creating fill file: %w", err)
+	}
+
+	// the fill file is removed even if filling fails
+	defer func() {
+		_ = file.Close()
+		_ = os.Remove(fillFile)
+	}()
+
+	if err = write(ctx, file, target-used); err != nil {
+		return err
+	}
+
+	select {
+	case <-time.After(duration):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// RemoveDiskFill removes the fill file from the given path of the target container, in case it was left by an agent
+// that was terminated abruptly. It is not an error if the file does not exist.
+func RemoveDiskFill(finder process.Finder, procRoot string, containerID string, path string) error {
+	dir, err := containerPath(finder, procRoot, containerID, path)
+	if err != nil {
+		return err
+	}
+
+	err = os.Remove(filepath.Join(dir, FillFileName))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("removing fill file: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,531 @@
+package exhaust
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/grafana/xk6-disruptor/pkg/agent/process"
+)
+
+// fakeFinder is a Finder that returns a predefined list of processes
+type fakeFinder struct {
+	processes []process.Process
+	err       error
+}
+
+func (f fakeFinder) Find(_ string) ([]process.Process, error) {
+	return f.processes, f.err
+}
+
+// mainProcess is the main process of the target container in the fake proc filesystem
+var mainProcess = []process.Process{{PID: 7, PPID: 0}}
+
+// fakeUsage returns a UsageFunc that reports a filesystem with the given total and used bytes
+func fakeUsage(total uint64, used uint64) UsageFunc {
+	return func(_ string) (uint64, uint64, error) {
+		return total, used, nil
+	}
+}
+
+// buildProcRoot creates a fake proc filesystem with the main process of the target container, which has the given
+// number of open descriptors
+func buildProcRoot(t *testing.T, fds int) string {
+	t.Helper()
+
+	procRoot := t.TempDir()
+	processDir := filepath.Join(procRoot, "7")
+
+	if err := os.MkdirAll(filepath.Join(processDir, "root", "data"), 0o700); err != nil {
+		t.Fatalf("creating fake proc filesystem: %v", err)
+	}
+
+	if err := os.MkdirAll(filepath.Join(processDir, "fd"), 0o700); err != nil {
+		t.Fatalf("creating fake proc filesystem: %v", err)
+	}
+
+	for i := 0; i < fds; i++ {
+		if err := os.Symlink("/dev/null", filepath.Join(processDir, "fd", fmt.Sprint(i))); err != nil {
+			t.Fatalf("creating fake proc filesystem: %v", err)
+		}
+	}
+
+	return procRoot
+}
+
+// writeLimits creates the limits file of the main process in the fake proc filesystem with the given open files limit
+func writeLimits(t *testing.T, procRoot string, limit int) {
+	t.Helper()
+
+	limits := "Limit                     Soft Limit           Hard Limit           Units     \n" +
+		fmt.Sprintf("Max open files            %-20d %-20d files     \n", limit, limit)
+	if err := os.WriteFile(filepath.Join(procRoot, "7", "limits"), []byte(limits), 0o600); err != nil {
+		t.Fatalf("creating fake proc filesystem: %v", err)
+	}
+}
+
+// fakeInjector is a SyscallInjector that emulates the system calls that open and close descriptors in the descriptors
+// directory of the process in a fake proc filesystem
+type fakeInjector struct {
+	procRoot string
+	// open files limit of the process
+	limit int
+	// emulates a kernel without the close_range system call
+	noCloseRange bool
+	// error returned by the system calls that open descriptors
+	err error
+	// descriptors open in the process, read from the fake proc filesystem on the first injection
+	open map[int]bool
+}
+
+func (f *fakeInjector) Inject(pid int, inject func(call process.SyscallFunc) error) error {
+	fdDir := filepath.Join(f.procRoot, fmt.Sprint(pid), "fd")
+	if f.open == nil {
+		entries, err := os.ReadDir(fdDir)
+		if err != nil {
+			return err
+		}
+
+		f.open = map[int]bool{}
+		for _, entry := range entries {
+			fd, _ := strconv.Atoi(entry.Name())
+			f.open[fd] = true
+		}
+	}
+
+	return inject(func(nr uintptr, args ...uintptr) (uintptr, error) {
+		switch nr {
+		case sysEventFD2, sysFcntl:
+			if f.err != nil {
+				return 0, f.err
+			}
+
+			// descriptors are only duplicated keeping the close-on-exec flag
+			if nr == sysFcntl && args[1] != fDupFDCloexec {
+				return 0, syscall.EINVAL
+			}
+
+			// the lowest descriptor available is opened
+			for fd := 0; fd < f.limit; fd++ {
+				if f.open[fd] {
+					continue
+				}
+
+				if err := os.Symlink(eventFDLink, filepath.Join(fdDir, fmt.Sprint(fd))); err != nil {
+					return 0, err
+				}
+
+				f.open[fd] = true
+				return uintptr(fd), nil
+			}
+
+			return 0, syscall.EMFILE
+		case sysCloseRange:
+			if f.noCloseRange {
+				return 0, syscall.ENOSYS
+			}
+
+			for fd := args[0]; fd <= args[1]; fd++ {
+				_ = os.Remove(filepath.Join(fdDir, fmt.Sprint(fd)))
+				delete(f.open, int(fd))
+			}
+
+			return 0, nil
+		case sysClose:
+			if err := os.Remove(filepath.Join(fdDir, fmt.Sprint(args[0]))); err != nil {
+				return 0, syscall.EBADF
+			}
+
+			delete(f.open, int(args[0]))
+			return 0, nil
+		default:
+			return 0, syscall.ENOSYS
+		}
+	})
+}
+
+// countFDs returns the number of descriptors open in the fake proc filesystem
+func countFDs(t *testing.T, procRoot string) int {
+	t.Helper()
+
+	fds, err := os.ReadDir(filepath.Join(procRoot, "7", "fd"))
+	if err != nil {
+		t.Fatalf("reading descriptors: %v", err)
+	}
+
+	return len(fds)
+}
+
+func Test_DiskFiller(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		title        string
+		finder       process.Finder
+		fill         DiskFill
+		usage        UsageFunc
+		duration     time.Duration
+		cancel       bool
+		expectedSize int64
+		expectedErr  error
+		expectError  bool
+	}{
+		{
+			title:        "fill up to percentage",
+			finder:       fakeFinder{processes: mainProcess},
+			fill:         DiskFill{Path: "/data", Percentage: 60},
+			usage:        fakeUsage(10*fillChunkSize, 5*fillChunkSize),
+			duration:     time.Second,
+			expectedSize: fillChunkSize,
+		},
+		{
+			title:        "fill file is removed when cancelled",
+			finder:       fakeFinder{processes: mainProcess},
+			fill:         DiskFill{Path: "/data", Percentage: 100},
+			usage:        fakeUsage(10*fillChunkSize, 9*fillChunkSize+10),
+			duration:     10 * time.Second,
+			cancel:       true,
+			expectedSize: fillChunkSize - 10,
+			expectedErr:  context.Canceled,
+			expectError:  true,
+		},
+		{
+			title:       "usage above percentage",
+			finder:      fakeFinder{processes: mainProcess},
+			fill:        DiskFill{Path: "/data", Percentage: 40},
+			usage:       fakeUsage(10*fillChunkSize, 5*fillChunkSize),
+			duration:    time.Second,
+			expectError: true,
+		},
+		{
+			title:       "container without processes",
+			finder:      fakeFinder{processes: []process.Process{}},
+			fill:        DiskFill{Path: "/data", Percentage: 60},
+			usage:       fakeUsage(10*fillChunkSize, 5*fillChunkSize),
+			duration:    time.Second,
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			procRoot := buildProcRoot(t, 0)
+			fillFile := filepath.Join(procRoot, "7", "root", "data", FillFileName)
+
+			disruptor, err := NewDiskFiller(tc.finder, procRoot, "abcdef", tc.usage, tc.fill)
+			if err != nil {
+				t.Fatalf("failed creating disruptor: %v", err)
+			}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			// check the size of the fill file while the disruption is applied
+			sizes := make(chan int64, 1)
+			go func() {
+				time.Sleep(500 * time.Millisecond)
+				size := int64(0)
+				if info, serr := os.Stat(fillFile); serr == nil {
+					size = info.Size()
+				}
+				sizes <- size
+				if tc.cancel {
+					cancel()
+				}
+			}()
+
+			err = disruptor.Apply(ctx, tc.duration)
+			if tc.expectError && err == nil {
+				t.Fatalf("should had failed")
+			}
+
+			if !tc.expectError && err != nil {
+				t.Fatalf("failed: %v", err)
+			}
+
+			if tc.expectedErr != nil && !errors.Is(err, tc.expectedErr) {
+				t.Fatalf("expected %v got %v", tc.expectedErr, err)
+			}
+
+			if size := <-sizes; size != tc.expectedSize {
+				t.Errorf("expected fill file of %d bytes got %d", tc.expectedSize, size)
+			}
+
+			if _, err = os.Stat(fillFile); !errors.Is(err, os.ErrNotExist) {
+				t.Errorf("fill file was not removed")
+			}
+		})
+	}
+}
+
+func Test_DiskFillerValidation(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		title       string
+		containerID string
+		fill        DiskFill
+		expectError bool
+	}{
+		{
+			title:       "valid fill",
+			containerID: "abcdef",
+			fill:        DiskFill{Path: "/data", Percentage: 90},
+			expectError: false,
+		},
+		{
+			title:       "no container",
+			containerID: "",
+			fill:        DiskFill{Path: "/data", Percentage: 90},
+			expectError: true,
+		},
+		{
+			title:       "no path",
+			containerID: "abcdef",
+			fill:        DiskFill{Percentage: 90},
+			expectError: true,
+		},
+		{
+			title:       "percentage over 100",
+			containerID: "abcdef",
+			fill:        DiskFill{Path: "/data", Percentage: 101},
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := NewDiskFiller(fakeFinder{}, "/proc", tc.containerID, FilesystemUsage, tc.fill)
+			if tc.expectError && err == nil {
+				t.Errorf("should had failed")
+			}
+
+			if !tc.expectError && err != nil {
+				t.Errorf("failed: %v", err)
+			}
+		})
+	}
+}
+
+func Test_RemoveDiskFill(t *testing.T) {
+	t.Parallel()
+
+	procRoot := buildProcRoot(t, 0)
+	fillFile := filepath.Join(procRoot, "7", "root", "data", FillFileName)
+	if err := os.WriteFile(fillFile, []byte("fill"), 0o600); err != nil {
+		t.Fatalf("creating fill file: %v", err)
+	}
+
+	finder := fakeFinder{processes: mainProcess}
+	if err := RemoveDiskFill(finder, procRoot, "abcdef", "/data"); err != nil {
+		t.Fatalf("failed: %v", err)
+	}
+
+	if _, err := os.Stat(fillFile); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("fill file was not removed")
+	}
+
+	// removing a fill that does not exist is not an error
+	if err := RemoveDiskFill(finder, procRoot, "abcdef", "/data"); err != nil {
+		t.Errorf("failed: %v", err)
+	}
+}
+
+func Test_FDExhauster(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		title        string
+		exhaustion   FDExhaustion
+		fds          int
+		limit        int
+		noCloseRange bool
+		err          error
+		// open files limit reported in the proc filesystem, if different from the limit of the injector
+		reportedLimit int
+		// descriptors open while the disruptor is applied
+		expectedFDs int
+		expectError bool
+	}{
+		{
+			title:       "no descriptors left",
+			exhaustion:  FDExhaustion{},
+			fds:         3,
+			limit:       1024,
+			expectedFDs: 1024,
+		},
+		{
+			title:       "remaining descriptors",
+			exhaustion:  FDExhaustion{Remaining: 2},
+			fds:         3,
+			limit:       1024,
+			expectedFDs: 1022,
+		},
+		{
+			title:        "descriptors closed one by one",
+			exhaustion:   FDExhaustion{Remaining: 2},
+			fds:          3,
+			limit:        1024,
+			noCloseRange: true,
+			expectedFDs:  1022,
+		},
+		{
+			title:       "limit higher than a batch of descriptors",
+			exhaustion:  FDExhaustion{},
+			fds:         3,
+			limit:       fdBatchSize + 100,
+			expectedFDs: fdBatchSize + 100,
+		},
+		{
+			title:         "limit higher than the maximum of descriptors",
+			exhaustion:    FDExhaustion{},
+			fds:           3,
+			limit:         1024,
+			reportedLimit: maxExhaustedFDs + 1,
+			expectError:   true,
+		},
+		{
+			title:       "limit already reached",
+			exhaustion:  FDExhaustion{Remaining: 2},
+			fds:         3,
+			limit:       4,
+			expectError: true,
+		},
+		{
+			title:       "descriptors cannot be opened",
+			exhaustion:  FDExhaustion{},
+			fds:         3,
+			limit:       1024,
+			err:         syscall.EPERM,
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			procRoot := buildProcRoot(t, tc.fds)
+			reportedLimit := tc.limit
+			if tc.reportedLimit != 0 {
+				reportedLimit = tc.reportedLimit
+			}
+			writeLimits(t, procRoot, reportedLimit)
+
+			fdFile := filepath.Join(t.TempDir(), "fds")
+			injector := &fakeInjector{
+				procRoot:     procRoot,
+				limit:        tc.limit,
+				noCloseRange: tc.noCloseRange,
+				err:          tc.err,
+			}
+
+			disruptor, err := NewFDExhauster(
+				injector,
+				fakeFinder{processes: mainProcess},
+				procRoot,
+				"abcdef",
+				fdFile,
+				tc.exhaustion,
+			)
+			if err != nil {
+				t.Fatalf("failed creating disruptor: %v", err)
+			}
+
+			// check the descriptors open once the process is exhausted
+			exhauster, _ := disruptor.(*fdExhauster)
+			err = exhauster.exhaust(context.TODO(), 7)
+			if tc.expectError && err == nil {
+				t.Fatalf("should had failed")
+			}
+
+			if !tc.expectError && err != nil {
+				t.Fatalf("failed: %v", err)
+			}
+
+			if open := countFDs(t, procRoot); !tc.expectError && open != tc.expectedFDs {
+				t.Errorf("expected %d descriptors open got %d", tc.expectedFDs, open)
+			}
+
+			if err = ReleaseFDs(injector, procRoot, fdFile); err != nil {
+				t.Fatalf("failed releasing descriptors: %v", err)
+			}
+
+			// applying the disruptor closes the descriptors it opens
+			err = disruptor.Apply(context.TODO(), time.Second)
+			if tc.expectError && err == nil {
+				t.Fatalf("should had failed")
+			}
+
+			if !tc.expectError && err != nil {
+				t.Fatalf("failed: %v", err)
+			}
+
+			if open := countFDs(t, procRoot); open != tc.fds {
+				t.Errorf("expected %d descriptors open got %d", tc.fds, open)
+			}
+
+			if _, err = os.Stat(fdFile); !errors.Is(err, os.ErrNotExist) {
+				t.Errorf("descriptors file was not removed")
+			}
+		})
+	}
+}
+
+func Test_ReleaseFDs(t *testing.T) {
+	t.Parallel()
+
+	procRoot := buildProcRoot(t, 3)
+	injector := &fakeInjector{procRoot: procRoot, limit: 1024}
+
+	// descriptors 3 to 5 were opened by the disruptor
+	for fd := 3; fd <= 5; fd++ {
+		if err := os.Symlink(eventFDLink, filepath.Join(procRoot, "7", "fd", fmt.Sprint(fd))); err != nil {
+			t.Fatalf("creating fake proc filesystem: %v", err)
+		}
+	}
+
+	// descriptor 7 is reported as opened by the disruptor but was closed and reused by the process
+	if err := os.Symlink("/dev/null", filepath.Join(procRoot, "7", "fd", "7")); err != nil {
+		t.Fatalf("creating fake proc filesystem: %v", err)
+	}
+
+	fdFile := filepath.Join(t.TempDir(), "fds")
+	if err := os.WriteFile(fdFile, []byte("7 3-5 7-7"), 0o600); err != nil {
+		t.Fatalf("creating descriptors file: %v", err)
+	}
+
+	if err := ReleaseFDs(injector, procRoot, fdFile); err != nil {
+		t.Fatalf("failed: %v", err)
+	}
+
+	// only the descriptors of the process remain open
+	if open := countFDs(t, procRoot); open != 4 {
+		t.Errorf("expected %d descriptors open got %d", 4, open)
+	}
+
+	if _, err := os.Stat(fdFile); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("descriptors file was not removed")
+	}
+
+	// releasing without a descriptors file is not an error
+	if err := ReleaseFDs(injector, procRoot, fdFile); err != nil {
+		t.Errorf("failed: %v", err)
+	}
+}
@@ -0,0 +1,374 @@
+package exhaust
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/grafana/xk6-disruptor/pkg/agent"
+	"github.com/grafana/xk6-disruptor/pkg/agent/process"
+)
+
+// fdBatchSize is the maximum number of system calls injected each time the target process is stopped, for limiting
+// the time it is stopped when its open files limit is high
+const fdBatchSize = 4096
+
+// maxExhaustedFDs is the maximum number of descriptors opened in the target process. Exhausting a higher open files
+// limit would stop the process too many times.
+const maxExhaustedFDs = 16 * fdBatchSize
+
+// eventFDLink is the target of the links in the proc filesystem of the descriptors opened in the target process
+const eventFDLink = "anon_inode:[eventfd]"
+
+// FDExhaustion specifies the exhaustion of the file descriptors of the target container.
+// As file descriptor limits apply to each process, the agent cannot exhaust them by opening descriptors itself.
+// Instead, it injects system calls in the main process of the container that duplicate an event descriptor until
+// the open files limit of the process is reached, and closes them once the disruption ends.
+type FDExhaustion struct {
+	// Number of descriptors the process can still open
+	Remaining uint
+}
+
+// fdRange is a range of consecutive descriptors
+type fdRange struct {
+	first int
+	last  int
+}
+
+// DefaultFDFile returns the path of the file used for keeping the descriptors opened in the target process
+func DefaultFDFile() string {
+	return filepath.Join(os.TempDir(), "xk6-disruptor-fds")
+}
+
+// openFilesLimit returns the soft open files limit of the process, read from the proc filesystem
+func openFilesLimit(procRoot string, pid int) (int, error) {
+	content, err := os.ReadFile(filepath.Join(procRoot, strconv.Itoa(pid), "limits"))
+	if err != nil {
+		return 0, err
+	}
+
+	for _, line := range strings.Split(string(content), "\n") {
+		if !strings.HasPrefix(line, "Max open files") {
+			continue
+		}
+
+		fields := strings.Fields(strings.TrimPrefix(line, "Max open files"))
+		if len(fields) == 0 {
+			break
+		}
+
+		return strconv.Atoi(fields[0])
+	}
+
+	return 0, fmt.Errorf("open files limit not found in limits of process %d", pid)
+}
+
+// fdRanges returns the ranges of consecutive descriptors in the given list
+func fdRanges(fds []int) []fdRange {
+	sorted := append([]int{}, fds...)
+	sort.Ints(sorted)
+
+	ranges := []fdRange{}
+	for _, fd := range sorted {
+		if len(ranges) > 0 && ranges[len(ranges)-1].last+1 == fd {
+			ranges[len(ranges)-1].last = fd
+			continue
+		}
+
+		ranges = append(ranges, fdRange{first: fd, last: fd})
+	}
+
+	return ranges
+}
+
+// writeFDFile keeps the descriptors opened in the process in the given file, as a list of ranges
+func writeFDFile(path string, pid int, fds []int) error {
+	content := strconv.Itoa(pid)
+	for _, r := range fdRanges(fds) {
+		content += fmt.Sprintf(" %d-%d", r.first, r.last)
+	}
+
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		return fmt.Errorf("writing descriptors file: %w", err)
+	}
+
+	return nil
+}
+
+// readFDFile returns the process and the descriptors kept in the given file
+func readFDFile(path string) (int, []int, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	fields := strings.Fields(string(content))
+	if len(fields) == 0 {
+		return 0, nil, fmt.Errorf("malformed descriptors file %q", string(content))
+	}
+
+	pid, err := strconv.Atoi(fields[0])
+	if err != nil {
+		return 0, nil, fmt.Errorf("malformed descriptors file %q", string(content))
+	}
+
+	fds := []int{}
+	for _, field := range fields[1:] {
+		var r fdRange
+		if _, err = fmt.Sscanf(field, "%d-%d", &r.first, &r.last); err != nil || r.first > r.last {
+			return 0, nil, fmt.Errorf("malformed descriptors file %q", string(content))
+		}
+
+		for fd := r.first; fd <= r.last; fd++ {
+			fds = append(fds, fd)
+		}
+	}
+
+	return pid, fds, nil
+}
+
+// openedFDs returns the descriptors of the list that are still open in the process and were opened by the
+// disruptor, so descriptors opened by the process reusing the same numbers are not closed
+func openedFDs(procRoot string, pid int, fds []int) []int {
+	opened := []int{}
+	for _, fd := range fds {
+		link, err := os.Readlink(filepath.Join(procRoot, strconv.Itoa(pid), "fd", strconv.Itoa(fd)))
+		if err == nil && link == eventFDLink {
+			opened = append(opened, fd)
+		}
+	}
+
+	return opened
+}
+
+// closeFDs closes the given descriptors of the process, if they are still open
+func closeFDs(injector process.SyscallInjector, procRoot string, pid int, fds []int) error {
+	ranges := fdRanges(openedFDs(procRoot, pid, fds))
+
+	// close_range is not available before linux 5.9, in which case the descriptors are closed one by one
+	closeRange := true
+	for len(ranges) > 0 {
+		err := injector.Inject(pid, func(call process.SyscallFunc) error {
+			for calls := 0; calls < fdBatchSize && len(ranges) > 0; calls++ {
+				r := ranges[0]
+				if closeRange {
+					_, err := call(sysCloseRange, uintptr(r.first), uintptr(r.last), 0)
+					if errors.Is(err, syscall.ENOSYS) {
+						closeRange = false
+						continue
+					}
+					if err != nil {
+						return err
+					}
+
+					ranges = ranges[1:]
+					continue
+				}
+
+				if _, err := call(sysClose, uintptr(r.first)); err != nil && !errors.Is(err, syscall.EBADF) {
+					return err
+				}
+
+				ranges[0].first++
+				if ranges[0].first > ranges[0].last {
+					ranges = ranges[1:]
+				}
+			}
+
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("closing descriptors of process %d: %w", pid, err)
+		}
+	}
+
+	return nil
+}
+
+// fdExhauster is an instance of a Disruptor that exhausts the file descriptors of the target container
+type fdExhauster struct {
+	injector    process.SyscallInjector
+	finder      process.Finder
+	procRoot    string
+	containerID string
+	fdFile      string
+	exhaustion  FDExhaustion
+}
+
+// NewFDExhauster returns a Disruptor that exhausts the file descriptors of the main process of the target container,
+// using the proc filesystem mounted at procRoot for inspecting the process. The descriptors opened in the process
+// are kept in the descriptors file, so they can be closed by ReleaseFDs if the disruptor is terminated abruptly.
+func NewFDExhauster(
+	injector process.SyscallInjector,
+	finder process.Finder,
+	procRoot string,
+	containerID string,
+	fdFile string,
+	exhaustion FDExhaustion,
+) (agent.Disruptor, error) {
+	if containerID == "" {
+		return nil, fmt.Errorf("container ID must be specified")
+	}
+
+	if fdFile == "" {
+		return nil, fmt.Errorf("descriptors file must be specified")
+	}
+
+	return &fdExhauster{
+		injector:    injector,
+		finder:      finder,
+		procRoot:    procRoot,
+		containerID: containerID,
+		fdFile:      fdFile,
+		exhaustion:  exhaustion,
+	}, nil
+}
+
+// Apply opens descriptors in the process until its open files limit is reached and closes them after the duration
+func (d *fdExhauster) Apply(ctx context.Context, duration time.Duration) error {
+	if duration < time.Second {
+		return fmt.Errorf("duration must be at least one second")
+	}
+
+	processes, err := d.finder.Find(d.containerID)
+	if err != nil {
+		return fmt.Errorf("finding processes of container %q: %w", d.containerID, err)
+	}
+
+	main, err := process.MainProcess(processes)
+	if err != nil {
+		return fmt.Errorf("finding main process of container %q: %w", d.containerID, err)
+	}
+
+	// the descriptors are closed even if opening them fails
+	defer func() {
+		_ = ReleaseFDs(d.injector, d.procRoot, d.fdFile)
+	}()
+
+	if err = d.exhaust(ctx, main.PID); err != nil {
+		return err
+	}
+
+	select {
+	case <-time.After(duration):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// exhaust opens descriptors in the process until its limit is reached, and then closes the descriptors that must
+// remain available. The opened descriptors are kept in the descriptors file as they are opened.
+func (d *fdExhauster) exhaust(ctx context.Context, pid int) error {
+	// the limit is checked before stopping the process when it can be read from the proc filesystem
+	if limit, err := openFilesLimit(d.procRoot, pid); err == nil && limit > maxExhaustedFDs {
+		return fmt.Errorf(
+			"open files limit of process %d (%d) is higher than the maximum of %d descriptors that can be exhausted",
+			pid,
+			limit,
+			maxExhaustedFDs,
+		)
+	}
+
+	opened := []int{}
+	exhausted := false
+	for !exhausted {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		if len(opened) >= maxExhaustedFDs {
+			return fmt.Errorf(
+				"process %d can open more than the maximum of %d descriptors that can be exhausted",
+				pid,
+				maxExhaustedFDs,
+			)
+		}
+
+		// the process is resumed after each batch of descriptors
+		err := d.injector.Inject(pid, func(call process.SyscallFunc) error {
+			var err error
+			for calls := 0; calls < fdBatchSize; calls++ {
+				var fd uintptr
+				// the descriptors are duplicates of an event descriptor, which does not hold any other resource.
+				// They are closed on exec so they are not inherited by the programs the process executes.
+				if len(opened) == 0 {
+					fd, err = call(sysEventFD2, 0, syscall.O_CLOEXEC)
+				} else {
+					fd, err = call(sysFcntl, uintptr(opened[0]), fDupFDCloexec, 0)
+				}
+
+				if errors.Is(err, syscall.EMFILE) {
+					exhausted = true
+					return nil
+				}
+				if err != nil {
+					return err
+				}
+
+				opened = append(opened, int(fd))
+			}
+
+			return nil
+		})
+
+		// the descriptors are kept even if opening them fails
+		if writeErr := writeFDFile(d.fdFile, pid, opened); err == nil {
+			err = writeErr
+		}
+		if err != nil {
+			return fmt.Errorf("opening descriptors in process %d: %w", pid, err)
+		}
+	}
+
+	if uint(len(opened)) <= d.exhaustion.Remaining {
+		return fmt.Errorf("process %d can only open %d descriptors", pid, len(opened))
+	}
+
+	if d.exhaustion.Remaining == 0 {
+		return nil
+	}
+
+	// the last descriptors opened are closed for leaving them available
+	kept := len(opened) - int(d.exhaustion.Remaining)
+	if err := closeFDs(d.injector, d.procRoot, pid, opened[kept:]); err != nil {
+		return err
+	}
+
+	return writeFDFile(d.fdFile, pid, opened[:kept])
+}
+
+// ReleaseFDs closes the descriptors kept in the descriptors file and removes it.
+// It is not an error if the descriptors file does not exist.
+// The descriptors file is claimed by renaming it before closing the descriptors, so when the agent and the cleanup
+// command release the descriptors at the same time only one of them closes them.
+func ReleaseFDs(injector process.SyscallInjector, procRoot string, fdFile string) error {
+	claimed := fmt.Sprintf("%s.%d", fdFile, os.Getpid())
+	err := os.Rename(fdFile, claimed)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("claiming descriptors file: %w", err)
+	}
+
+	pid, fds, err := readFDFile(claimed)
+	if err != nil {
+		return fmt.Errorf("reading descriptors file: %w", err)
+	}
+
+	// the descriptors file is restored if the descriptors cannot be closed, so they can be released later
+	if err = closeFDs(injector, procRoot, pid, fds); err != nil {
+		_ = os.Rename(claimed, fdFile)
+		return err
+	}
+
+	return os.Remove(claimed)
+}
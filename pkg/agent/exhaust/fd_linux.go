@@ -0,0 +1,15 @@
+package exhaust
+
+import "syscall"
+
+// numbers of the system calls injected in the target process
+const (
+	sysEventFD2 = syscall.SYS_EVENTFD2
+	sysFcntl    = syscall.SYS_FCNTL
+	sysClose    = syscall.SYS_CLOSE
+	// close_range is not defined in the syscall package. It has the same number in all architectures.
+	sysCloseRange = 436
+)
+
+// fDupFDCloexec is the fcntl command that duplicates a descriptor keeping the close-on-exec flag
+const fDupFDCloexec = syscall.F_DUPFD_CLOEXEC
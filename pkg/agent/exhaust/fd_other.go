@@ -0,0 +1,15 @@
+//go:build !linux
+
+package exhaust
+
+// system calls cannot be injected in the target process in this platform. The numbers are placeholders that
+// only need to be distinct.
+const (
+	sysEventFD2 = iota + 1
+	sysFcntl
+	sysClose
+	sysCloseRange
+)
+
+// fDupFDCloexec is a placeholder for the fcntl command that duplicates a descriptor keeping the close-on-exec flag
+const fDupFDCloexec = 0
@@ -0,0 +1,315 @@
+// Package network implements a disruptor that injects faults in the network traffic of a target using the
+// netem queue discipline.
+// Requires the tc and ip commands to be installed.
+// Requires 'NET_ADMIN' capabilities for manipulating the queue disciplines.
+package network
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/xk6-disruptor/pkg/agent"
+	"github.com/grafana/xk6-disruptor/pkg/runtime"
+)
+
+// Disruption specifies the faults to be injected in the network traffic
+type Disruption struct {
+	// Average delay added to packets
+	AverageDelay time.Duration
+	// Variation in the delay (with respect of the average delay)
+	DelayVariation time.Duration
+	// Correlation (in the range 0.0 to 1.0) of the delay of a packet with the delay of the previous one
+	DelayCorrelation float32
+	// Fraction (in the range 0.0 to 1.0) of packets that will be dropped
+	LossRate float32
+	// Fraction (in the range 0.0 to 1.0) of packets that will be duplicated
+	DuplicationRate float32
+	// Fraction (in the range 0.0 to 1.0) of packets that will have a bit corrupted
+	CorruptionRate float32
+	// Fraction (in the range 0.0 to 1.0) of packets that will be sent immediately, ahead of the delayed ones
+	ReorderRate float32
+}
+
+// Filter restricts the packets the disruption is applied to. An empty filter matches all packets.
+type Filter struct {
+	// Port used by the packets, either as source or as destination
+	Port uint
+	// IPv4 address or CIDR of the peer the packets are sent to
+	Peer string
+}
+
+// isEmpty returns true if the filter matches all packets
+func (f Filter) isEmpty() bool {
+	return f.Port == 0 && f.Peer == ""
+}
+
+// disruptor is an instance of a Disruptor that injects network faults in an interface
+type disruptor struct {
+	executor   runtime.Executor
+	iface      string
+	disruption Disruption
+	filter     Filter
+}
+
+// validateRate checks a rate is in the range [0.0, 1.0]
+func validateRate(name string, rate float32) error {
+	if rate < 0 || rate > 1 {
+		return fmt.Errorf("%s must be in the range [0.0, 1.0]", name)
+	}
+
+	return nil
+}
+
+func validateDisruption(d Disruption) error {
+	if d.AverageDelay == 0 && d.LossRate == 0 && d.DuplicationRate == 0 && d.CorruptionRate == 0 {
+		return fmt.Errorf("at least one of delay, loss, duplication or corruption rate must be specified")
+	}
+
+	rates := []struct {
+		name string
+		rate float32
+	}{
+		{"delay correlation", d.DelayCorrelation},
+		{"loss rate", d.LossRate},
+		{"duplication rate", d.DuplicationRate},
+		{"corruption rate", d.CorruptionRate},
+		{"reorder rate", d.ReorderRate},
+	}
+	for _, r := range rates {
+		if err := validateRate(r.name, r.rate); err != nil {
+			return err
+		}
+	}
+
+	if d.DelayVariation > d.AverageDelay {
+		return fmt.Errorf("delay variation cannot be larger than the average delay")
+	}
+
+	if d.DelayCorrelation > 0 && d.DelayVariation == 0 {
+		return fmt.Errorf("delay correlation requires a delay variation")
+	}
+
+	if d.ReorderRate > 0 && d.AverageDelay == 0 {
+		return fmt.Errorf("reordering requires a delay")
+	}
+
+	return nil
+}
+
+// peerCIDR returns the peer of the filter as a CIDR
+func (f Filter) peerCIDR() (string, error) {
+	if ip := net.ParseIP(f.Peer); ip != nil {
+		if ip.To4() == nil {
+			return "", fmt.Errorf("peer %q is not an IPv4 address", f.Peer)
+		}
+		return f.Peer + "/32", nil
+	}
+
+	ip, cidr, err := net.ParseCIDR(f.Peer)
+	if err != nil {
+		return "", fmt.Errorf("peer %q is not a valid IP address or CIDR", f.Peer)
+	}
+
+	if ip.To4() == nil {
+		return "", fmt.Errorf("peer %q is not an IPv4 CIDR", f.Peer)
+	}
+
+	return cidr.String(), nil
+}
+
+// NewDisruptor returns a Disruptor that injects network faults in the packets that match the filter in the given
+// interface. If the interface is empty, the interface of the default route is used.
+func NewDisruptor(
+	executor runtime.Executor,
+	iface string,
+	disruption Disruption,
+	filter Filter,
+) (agent.Disruptor, error) {
+	if err := validateDisruption(disruption); err != nil {
+		return nil, err
+	}
+
+	if filter.Port > 65535 {
+		return nil, fmt.Errorf("invalid port %d", filter.Port)
+	}
+
+	if filter.Peer != "" {
+		cidr, err := filter.peerCIDR()
+		if err != nil {
+			return nil, err
+		}
+		filter.Peer = cidr
+	}
+
+	return &disruptor{
+		executor:   executor,
+		iface:      iface,
+		disruption: disruption,
+		filter:     filter,
+	}, nil
+}
+
+// DefaultInterface returns the name of the interface used by the default route
+func DefaultInterface(executor runtime.Executor) (string, error) {
+	out, err := executor.Exec("ip", "route", "show", "default")
+	if err != nil {
+		return "", fmt.Errorf("getting default route: %w %s", err, string(out))
+	}
+
+	// output has the form "default via 10.0.0.1 dev eth0 ..."
+	fields := strings.Fields(string(out))
+	for i := 0; i < len(fields)-1; i++ {
+		if fields[i] == "dev" {
+			return fields[i+1], nil
+		}
+	}
+
+	return "", fmt.Errorf("default route not found")
+}
+
+// Reset removes any queue discipline added to the root of the interface, restoring the default one
+func Reset(executor runtime.Executor, iface string) error {
+	out, err := executor.Exec("tc", "qdisc", "del", "dev", iface, "root")
+	if err != nil {
+		return fmt.Errorf("removing queue discipline from %q: %w %s", iface, err, string(out))
+	}
+
+	return nil
+}
+
+// netemArgs returns the arguments for the netem queue discipline
+func (d *disruptor) netemArgs() []string {
+	args := []string{"netem"}
+
+	if d.disruption.AverageDelay > 0 {
+		args = append(args, "delay", fmt.Sprintf("%dms", d.disruption.AverageDelay.Milliseconds()))
+		if d.disruption.DelayVariation > 0 {
+			args = append(args, fmt.Sprintf("%dms", d.disruption.DelayVariation.Milliseconds()))
+			if d.disruption.DelayCorrelation > 0 {
+				args = append(args, percentage(d.disruption.DelayCorrelation))
+			}
+		}
+	}
+
+	if d.disruption.LossRate > 0 {
+		args = append(args, "loss", percentage(d.disruption.LossRate))
+	}
+
+	if d.disruption.DuplicationRate > 0 {
+		args = append(args, "duplicate", percentage(d.disruption.DuplicationRate))
+	}
+
+	if d.disruption.CorruptionRate > 0 {
+		args = append(args, "corrupt", percentage(d.disruption.CorruptionRate))
+	}
+
+	if d.disruption.ReorderRate > 0 {
+		args = append(args, "reorder", percentage(d.disruption.ReorderRate))
+	}
+
+	return args
+}
+
+// filterMatches returns the u32 selectors for the packets matched by the filter. As filters
+// are ORed, the packets sent from or to the port require a separate filter each.
+func (d *disruptor) filterMatches() [][]string {
+	peer := []string{}
+	if d.filter.Peer != "" {
+		peer = []string{"match", "ip", "dst", d.filter.Peer}
+	}
+
+	if d.filter.Port == 0 {
+		return [][]string{peer}
+	}
+
+	port := fmt.Sprint(d.filter.Port)
+	return [][]string{
+		append(append([]string{}, peer...), "match", "ip", "dport", port, "0xffff"),
+		append(append([]string{}, peer...), "match", "ip", "sport", port, "0xffff"),
+	}
+}
+
+// prioBands is the number of bands of the prio queue discipline used when the disruption is filtered. The priomap
+// only uses the first three bands, so the last one receives only the packets matched by the filter.
+const prioBands = 4
+
+// priomap is the default priomap of the prio queue discipline, which maps the priorities of the packets to the first
+// three bands
+var priomap = []string{"1", "2", "2", "2", "1", "2", "0", "0", "1", "1", "1", "1", "1", "1", "1", "1"}
+
+// qdiscCommands returns the tc commands for applying the disruption to the interface. If the filter is not empty,
+// the netem queue discipline is attached to a band of a prio queue discipline that only receives the matching
+// packets.
+func (d *disruptor) qdiscCommands(iface string) [][]string {
+	if d.filter.isEmpty() {
+		return [][]string{
+			append([]string{"qdisc", "add", "dev", iface, "root"}, d.netemArgs()...),
+		}
+	}
+
+	// the class of the band that only receives the matching packets
+	band := fmt.Sprintf("1:%d", prioBands)
+
+	prio := []string{"qdisc", "add", "dev", iface, "root", "handle", "1:", "prio", "bands", fmt.Sprint(prioBands)}
+	prio = append(prio, "priomap")
+	prio = append(prio, priomap...)
+
+	cmds := [][]string{
+		prio,
+		append([]string{"qdisc", "add", "dev", iface, "parent", band, "handle", "40:"}, d.netemArgs()...),
+	}
+
+	for _, match := range d.filterMatches() {
+		cmd := []string{"filter", "add", "dev", iface, "parent", "1:0", "protocol", "ip", "prio", "1", "u32"}
+		cmd = append(cmd, match...)
+		cmd = append(cmd, "flowid", band)
+		cmds = append(cmds, cmd)
+	}
+
+	return cmds
+}
+
+// percentage returns a rate in the range [0.0, 1.0] as a percentage (e.g. "10%")
+func percentage(rate float32) string {
+	return strconv.FormatFloat(float64(rate*100), 'f', -1, 32) + "%"
+}
+
+// Apply adds the netem queue discipline to the interface and removes it when the duration expires
+// or the context is cancelled
+func (d *disruptor) Apply(ctx context.Context, duration time.Duration) error {
+	if duration < time.Second {
+		return fmt.Errorf("duration must be at least one second")
+	}
+
+	iface := d.iface
+	if iface == "" {
+		var err error
+		iface, err = DefaultInterface(d.executor)
+		if err != nil {
+			return err
+		}
+	}
+
+	// remove the queue disciplines even if they were partially added
+	defer func() {
+		_ = Reset(d.executor, iface)
+	}()
+
+	for _, args := range d.qdiscCommands(iface) {
+		out, err := d.executor.Exec("tc", args...)
+		if err != nil {
+			return fmt.Errorf("adding queue discipline to %q: %w %s", iface, err, string(out))
+		}
+	}
+
+	select {
+	case <-time.After(duration):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,291 @@
+package network
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/grafana/xk6-disruptor/pkg/runtime"
+)
+
+func Test_Validation(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		title       string
+		disruption  Disruption
+		filter      Filter
+		expectError bool
+	}{
+		{
+			title:       "valid delay",
+			disruption:  Disruption{AverageDelay: 100 * time.Millisecond, DelayVariation: 10 * time.Millisecond},
+			expectError: false,
+		},
+		{
+			title:       "valid loss",
+			disruption:  Disruption{LossRate: 0.1},
+			expectError: false,
+		},
+		{
+			title:       "empty disruption",
+			disruption:  Disruption{},
+			expectError: true,
+		},
+		{
+			title:       "invalid loss",
+			disruption:  Disruption{LossRate: 1.5},
+			expectError: true,
+		},
+		{
+			title:       "variation larger than delay",
+			disruption:  Disruption{AverageDelay: 10 * time.Millisecond, DelayVariation: 100 * time.Millisecond},
+			expectError: true,
+		},
+		{
+			title:       "valid duplication and corruption",
+			disruption:  Disruption{DuplicationRate: 0.01, CorruptionRate: 0.001},
+			expectError: false,
+		},
+		{
+			title:       "invalid duplication",
+			disruption:  Disruption{DuplicationRate: -0.1},
+			expectError: true,
+		},
+		{
+			title:       "correlation without variation",
+			disruption:  Disruption{AverageDelay: 100 * time.Millisecond, DelayCorrelation: 0.25},
+			expectError: true,
+		},
+		{
+			title:       "reorder without delay",
+			disruption:  Disruption{LossRate: 0.1, ReorderRate: 0.25},
+			expectError: true,
+		},
+		{
+			title:       "valid filter",
+			disruption:  Disruption{LossRate: 0.1},
+			filter:      Filter{Port: 80, Peer: "10.0.0.0/8"},
+			expectError: false,
+		},
+		{
+			title:       "invalid peer",
+			disruption:  Disruption{LossRate: 0.1},
+			filter:      Filter{Peer: "my-service"},
+			expectError: true,
+		},
+		{
+			title:       "IPv6 peer",
+			disruption:  Disruption{LossRate: 0.1},
+			filter:      Filter{Peer: "2001:db8::1"},
+			expectError: true,
+		},
+		{
+			title:       "invalid port",
+			disruption:  Disruption{LossRate: 0.1},
+			filter:      Filter{Port: 70000},
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := NewDisruptor(runtime.NewFakeExecutor(nil, nil), "eth0", tc.disruption, tc.filter)
+			if tc.expectError && err == nil {
+				t.Errorf("should had failed")
+			}
+
+			if !tc.expectError && err != nil {
+				t.Errorf("failed: %v", err)
+			}
+		})
+	}
+}
+
+func Test_Apply(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		title        string
+		iface        string
+		disruption   Disruption
+		filter       Filter
+		tcError      error
+		expectedCmds []string
+		expectError  bool
+	}{
+		{
+			title:      "delay and loss",
+			iface:      "eth0",
+			disruption: Disruption{AverageDelay: 100 * time.Millisecond, DelayVariation: 10 * time.Millisecond, LossRate: 0.1},
+			expectedCmds: []string{
+				"tc qdisc add dev eth0 root netem delay 100ms 10ms loss 10%",
+				"tc qdisc del dev eth0 root",
+			},
+		},
+		{
+			title:      "default interface",
+			iface:      "",
+			disruption: Disruption{LossRate: 0.25},
+			expectedCmds: []string{
+				"ip route show default",
+				"tc qdisc add dev ens5 root netem loss 25%",
+				"tc qdisc del dev ens5 root",
+			},
+		},
+		{
+			title:      "error adding queue discipline",
+			iface:      "eth0",
+			disruption: Disruption{AverageDelay: 100 * time.Millisecond},
+			tcError:    fmt.Errorf("fake error"),
+			expectedCmds: []string{
+				"tc qdisc add dev eth0 root netem delay 100ms",
+				"tc qdisc del dev eth0 root",
+			},
+			expectError: true,
+		},
+		{
+			title: "all faults",
+			iface: "eth0",
+			disruption: Disruption{
+				AverageDelay:     100 * time.Millisecond,
+				DelayVariation:   10 * time.Millisecond,
+				DelayCorrelation: 0.25,
+				LossRate:         0.1,
+				DuplicationRate:  0.01,
+				CorruptionRate:   0.001,
+				ReorderRate:      0.5,
+			},
+			expectedCmds: []string{
+				"tc qdisc add dev eth0 root netem delay 100ms 10ms 25% loss 10% duplicate 1% corrupt 0.1% reorder 50%",
+				"tc qdisc del dev eth0 root",
+			},
+		},
+		{
+			title:      "filter by port",
+			iface:      "eth0",
+			disruption: Disruption{LossRate: 0.1},
+			filter:     Filter{Port: 8080},
+			expectedCmds: []string{
+				"tc qdisc add dev eth0 root handle 1: prio bands 4 priomap 1 2 2 2 1 2 0 0 1 1 1 1 1 1 1 1",
+				"tc qdisc add dev eth0 parent 1:4 handle 40: netem loss 10%",
+				"tc filter add dev eth0 parent 1:0 protocol ip prio 1 u32 match ip dport 8080 0xffff flowid 1:4",
+				"tc filter add dev eth0 parent 1:0 protocol ip prio 1 u32 match ip sport 8080 0xffff flowid 1:4",
+				"tc qdisc del dev eth0 root",
+			},
+		},
+		{
+			title:      "filter by peer",
+			iface:      "eth0",
+			disruption: Disruption{LossRate: 0.1},
+			filter:     Filter{Peer: "192.0.2.10"},
+			expectedCmds: []string{
+				"tc qdisc add dev eth0 root handle 1: prio bands 4 priomap 1 2 2 2 1 2 0 0 1 1 1 1 1 1 1 1",
+				"tc qdisc add dev eth0 parent 1:4 handle 40: netem loss 10%",
+				"tc filter add dev eth0 parent 1:0 protocol ip prio 1 u32 match ip dst 192.0.2.10/32 flowid 1:4",
+				"tc qdisc del dev eth0 root",
+			},
+		},
+		{
+			title:      "filter by port and peer",
+			iface:      "eth0",
+			disruption: Disruption{AverageDelay: 100 * time.Millisecond},
+			filter:     Filter{Port: 5432, Peer: "10.0.0.0/8"},
+			expectedCmds: []string{
+				"tc qdisc add dev eth0 root handle 1: prio bands 4 priomap 1 2 2 2 1 2 0 0 1 1 1 1 1 1 1 1",
+				"tc qdisc add dev eth0 parent 1:4 handle 40: netem delay 100ms",
+				//nolint:lll
+				"tc filter add dev eth0 parent 1:0 protocol ip prio 1 u32 match ip dst 10.0.0.0/8 match ip dport 5432 0xffff flowid 1:4",
+				//nolint:lll
+				"tc filter add dev eth0 parent 1:0 protocol ip prio 1 u32 match ip dst 10.0.0.0/8 match ip sport 5432 0xffff flowid 1:4",
+				"tc qdisc del dev eth0 root",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			executor := runtime.NewCallbackExecutor(func(cmd string, args ...string) ([]byte, error) {
+				if cmd == "ip" {
+					return []byte("default via 10.0.0.1 dev ens5 proto dhcp src 10.0.0.10 metric 100\n"), nil
+				}
+				return nil, tc.tcError
+			})
+
+			disruptor, err := NewDisruptor(executor, tc.iface, tc.disruption, tc.filter)
+			if err != nil {
+				t.Fatalf("failed creating disruptor: %v", err)
+			}
+
+			err = disruptor.Apply(context.TODO(), time.Second)
+			if tc.expectError && err == nil {
+				t.Fatalf("should had failed")
+			}
+
+			if !tc.expectError && err != nil {
+				t.Fatalf("failed: %v", err)
+			}
+
+			if diff := cmp.Diff(tc.expectedCmds, executor.CmdHistory()); diff != "" {
+				t.Errorf("expected commands do not match executed:\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_PrioBands(t *testing.T) {
+	t.Parallel()
+
+	d := &disruptor{
+		disruption: Disruption{LossRate: 0.1},
+		filter:     Filter{Port: 8080},
+	}
+
+	cmds := d.qdiscCommands("eth0")
+
+	prio := strings.Join(cmds[0], " ")
+	args := strings.Fields(strings.SplitN(prio, " prio ", 2)[1])
+	if len(args) != 19 || args[0] != "bands" || args[2] != "priomap" {
+		t.Fatalf("unexpected prio queue discipline: %q", prio)
+	}
+
+	bands, err := strconv.Atoi(args[1])
+	if err != nil {
+		t.Fatalf("invalid number of bands: %v", err)
+	}
+
+	// the priomap must not send any packet to the last band, which is used by netem
+	for _, value := range args[3:] {
+		band, convErr := strconv.Atoi(value)
+		if convErr != nil {
+			t.Fatalf("invalid priomap value %q: %v", value, convErr)
+		}
+		if band >= bands-1 {
+			t.Errorf("priomap maps packets to band %d in %q", band, prio)
+		}
+	}
+
+	// classes are numbered from 1, so the last band is the class with the number of bands
+	netemBand := fmt.Sprintf("1:%d", bands)
+	if netem := strings.Join(cmds[1], " "); !strings.Contains(netem, "parent "+netemBand+" ") {
+		t.Errorf("netem is not attached to band %s: %q", netemBand, netem)
+	}
+
+	for _, cmd := range cmds[2:] {
+		if filter := strings.Join(cmd, " "); !strings.HasSuffix(filter, "flowid "+netemBand) {
+			t.Errorf("filter does not classify packets in band %s: %q", netemBand, filter)
+		}
+	}
+}
@@ -0,0 +1,164 @@
+// Package partition implements a disruptor that isolates the target from a set of peers by dropping the traffic
+// exchanged with them.
+// The set of peers can be updated while the disruption is applied by writing it to the peers file.
+// Requires the iptables command to be installed.
+// Requires 'NET_ADMIN' capabilities for manipulating the iptables.
+package partition
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/grafana/xk6-disruptor/pkg/agent"
+	"github.com/grafana/xk6-disruptor/pkg/iptables"
+	"github.com/grafana/xk6-disruptor/pkg/runtime"
+)
+
+// DefaultRefreshInterval is the default interval for checking updates in the peers file
+const DefaultRefreshInterval = time.Second
+
+// Disruption specifies a network partition
+type Disruption struct {
+	// Direction of the connections blocked
+	Direction iptables.PartitionDirection
+	// IP addresses of the peers the target is isolated from
+	Peers []string
+}
+
+// disruptor is an instance of a Disruptor that isolates the target from a set of peers
+type disruptor struct {
+	executor   runtime.Executor
+	disruption Disruption
+	peersFile  string
+	refresh    time.Duration
+}
+
+// DefaultPeersFile returns the path of the file used for updating the set of peers
+func DefaultPeersFile() string {
+	return filepath.Join(os.TempDir(), "xk6-disruptor-partition-peers")
+}
+
+// WritePeers writes the set of peers to the peers file. The file is replaced atomically to prevent
+// a running disruptor from reading a partial update.
+func WritePeers(path string, peers []string) error {
+	temp := path + ".tmp"
+	err := os.WriteFile(temp, []byte(strings.Join(peers, "\n")), 0o600)
+	if err != nil {
+		return fmt.Errorf("writing peers file: %w", err)
+	}
+
+	err = os.Rename(temp, path)
+	if err != nil {
+		return fmt.Errorf("writing peers file: %w", err)
+	}
+
+	return nil
+}
+
+// readPeers returns the sorted set of peers in the peers file
+func readPeers(path string) ([]string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	peers := strings.Fields(string(content))
+	sort.Strings(peers)
+
+	return peers, nil
+}
+
+// NewDisruptor returns a Disruptor that isolates the target from the given peers. Updates to the set of peers
+// written to the peers file are checked at the given refresh interval.
+func NewDisruptor(
+	executor runtime.Executor,
+	disruption Disruption,
+	peersFile string,
+	refresh time.Duration,
+) (agent.Disruptor, error) {
+	if len(disruption.Peers) == 0 {
+		return nil, fmt.Errorf("at least one peer must be specified")
+	}
+
+	if disruption.Direction == "" {
+		disruption.Direction = iptables.PartitionBoth
+	}
+
+	if peersFile == "" {
+		return nil, fmt.Errorf("peers file must be specified")
+	}
+
+	if refresh <= 0 {
+		refresh = DefaultRefreshInterval
+	}
+
+	return &disruptor{
+		executor:   executor,
+		disruption: disruption,
+		peersFile:  peersFile,
+		refresh:    refresh,
+	}, nil
+}
+
+// Apply isolates the target from the peers for the given duration or until the context is cancelled.
+// The connectivity is restored even if applying the partition fails.
+func (d *disruptor) Apply(ctx context.Context, duration time.Duration) error {
+	if duration < time.Second {
+		return fmt.Errorf("duration must be at least one second")
+	}
+
+	partition, err := iptables.NewPartition(d.executor, d.disruption.Direction)
+	if err != nil {
+		return err
+	}
+
+	// start from the given peers, discarding any update left by a previous execution
+	err = WritePeers(d.peersFile, d.disruption.Peers)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		_ = partition.Stop()
+		_ = os.Remove(d.peersFile)
+	}()
+
+	err = partition.Update(d.disruption.Peers)
+	if err != nil {
+		return err
+	}
+
+	ticker := time.NewTicker(d.refresh)
+	defer ticker.Stop()
+
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-timer.C:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			peers, err := readPeers(d.peersFile)
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			if err != nil {
+				return err
+			}
+
+			err = partition.Update(peers)
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
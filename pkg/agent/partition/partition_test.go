@@ -0,0 +1,136 @@
+package partition
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/grafana/xk6-disruptor/pkg/iptables"
+	"github.com/grafana/xk6-disruptor/pkg/runtime"
+)
+
+func Test_Validation(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		title       string
+		disruption  Disruption
+		peersFile   string
+		expectError bool
+	}{
+		{
+			title:       "valid partition",
+			disruption:  Disruption{Peers: []string{"192.0.2.10"}},
+			peersFile:   "peers",
+			expectError: false,
+		},
+		{
+			title:       "no peers",
+			disruption:  Disruption{},
+			peersFile:   "peers",
+			expectError: true,
+		},
+		{
+			title:       "no peers file",
+			disruption:  Disruption{Peers: []string{"192.0.2.10"}},
+			peersFile:   "",
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := NewDisruptor(runtime.NewFakeExecutor(nil, nil), tc.disruption, tc.peersFile, 0)
+			if tc.expectError && err == nil {
+				t.Errorf("should had failed")
+			}
+
+			if !tc.expectError && err != nil {
+				t.Errorf("failed: %v", err)
+			}
+		})
+	}
+}
+
+func Test_Apply(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		title        string
+		disruption   Disruption
+		update       []string
+		expectedCmds []string
+	}{
+		{
+			title: "without updates",
+			disruption: Disruption{
+				Direction: iptables.PartitionOutgoing,
+				Peers:     []string{"192.0.2.10"},
+			},
+			expectedCmds: []string{
+				//nolint:lll
+				"iptables -A OUTPUT -d 192.0.2.10 -m state --state NEW -m comment --comment xk6-disruptor-partition -j DROP",
+				//nolint:lll
+				"iptables -D OUTPUT -d 192.0.2.10 -m state --state NEW -m comment --comment xk6-disruptor-partition -j DROP",
+			},
+		},
+		{
+			title: "peers updated",
+			disruption: Disruption{
+				Direction: iptables.PartitionOutgoing,
+				Peers:     []string{"192.0.2.10"},
+			},
+			update: []string{"192.0.2.11"},
+			expectedCmds: []string{
+				//nolint:lll
+				"iptables -A OUTPUT -d 192.0.2.10 -m state --state NEW -m comment --comment xk6-disruptor-partition -j DROP",
+				//nolint:lll
+				"iptables -D OUTPUT -d 192.0.2.10 -m state --state NEW -m comment --comment xk6-disruptor-partition -j DROP",
+				//nolint:lll
+				"iptables -A OUTPUT -d 192.0.2.11 -m state --state NEW -m comment --comment xk6-disruptor-partition -j DROP",
+				//nolint:lll
+				"iptables -D OUTPUT -d 192.0.2.11 -m state --state NEW -m comment --comment xk6-disruptor-partition -j DROP",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			peersFile := filepath.Join(t.TempDir(), "peers")
+			executor := runtime.NewFakeExecutor(nil, nil)
+
+			disruptor, err := NewDisruptor(executor, tc.disruption, peersFile, 50*time.Millisecond)
+			if err != nil {
+				t.Fatalf("failed creating disruptor: %v", err)
+			}
+
+			if tc.update != nil {
+				go func() {
+					time.Sleep(200 * time.Millisecond)
+					if werr := WritePeers(peersFile, tc.update); werr != nil {
+						t.Errorf("failed updating peers: %v", werr)
+					}
+				}()
+			}
+
+			err = disruptor.Apply(context.TODO(), time.Second)
+			if err != nil {
+				t.Fatalf("failed: %v", err)
+			}
+
+			if diff := cmp.Diff(tc.expectedCmds, executor.CmdHistory()); diff != "" {
+				t.Errorf("expected commands do not match executed:\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,140 @@
+package process
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// CgroupJoiner defines the interface for moving a process to the cgroup of a container
+type CgroupJoiner interface {
+	// Join moves the process with the given PID to the cgroups of the container with the given ID
+	Join(containerID string, pid int) error
+}
+
+// procCgroupJoiner is a CgroupJoiner that finds the cgroups of the container in the proc filesystem and moves the
+// process by writing its PID to the cgroup filesystem
+type procCgroupJoiner struct {
+	finder     Finder
+	procRoot   string
+	cgroupRoot string
+}
+
+// NewCgroupJoiner returns a CgroupJoiner that inspects the proc filesystem mounted at procRoot and modifies the
+// cgroup filesystem mounted at cgroupRoot
+func NewCgroupJoiner(finder Finder, procRoot string, cgroupRoot string) CgroupJoiner {
+	return &procCgroupJoiner{
+		finder:     finder,
+		procRoot:   procRoot,
+		cgroupRoot: cgroupRoot,
+	}
+}
+
+// DefaultCgroupJoiner returns a CgroupJoiner that inspects the proc filesystem mounted at /proc and modifies the
+// cgroup filesystem mounted at /sys/fs/cgroup
+func DefaultCgroupJoiner() CgroupJoiner {
+	return NewCgroupJoiner(DefaultFinder(), "/proc", "/sys/fs/cgroup")
+}
+
+// Join moves the process to the cgroups of the main process of the container. The process must run in the same
+// cgroup namespace as the container runtime and have write access to the cgroup filesystem, for example because
+// it runs in a privileged container.
+func (j *procCgroupJoiner) Join(containerID string, pid int) error {
+	processes, err := j.finder.Find(containerID)
+	if err != nil {
+		return err
+	}
+
+	main, err := MainProcess(processes)
+	if err != nil {
+		return fmt.Errorf("finding main process of container %q: %w", containerID, err)
+	}
+
+	content, err := os.ReadFile(filepath.Join(j.procRoot, fmt.Sprint(main.PID), "cgroup"))
+	if err != nil {
+		return fmt.Errorf("reading cgroups of process %d: %w", main.PID, err)
+	}
+
+	dirs, err := cgroupDirs(string(content))
+	if err != nil {
+		return fmt.Errorf("parsing cgroups of process %d: %w", main.PID, err)
+	}
+
+	for _, dir := range dirs {
+		procs := filepath.Join(j.cgroupRoot, dir, "cgroup.procs")
+		if _, err = os.Stat(procs); err != nil {
+			return fmt.Errorf("cgroup %q of container %q is not accessible: %w", dir, containerID, err)
+		}
+
+		// the file must exist, so it is opened without creating it
+		err = writeExisting(procs, fmt.Sprint(pid))
+		if err != nil {
+			return fmt.Errorf("moving process %d to cgroup %q: %w", pid, dir, err)
+		}
+	}
+
+	return nil
+}
+
+// writeExisting writes the content to an existing file
+func writeExisting(path string, content string) error {
+	file, err := os.OpenFile(path, os.O_WRONLY, 0)
+	if err != nil {
+		return err
+	}
+
+	_, err = file.WriteString(content)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+
+	return err
+}
+
+// cgroupDirs returns the directories, relative to the root of the cgroup filesystem, of the cgroups listed in the
+// content of the /proc/<pid>/cgroup file. Each line has the form "id:controllers:path". In cgroup v2 there is a single
+// line with an empty list of controllers. In cgroup v1, each hierarchy is mounted in a directory named after its
+// controllers (e.g. "cpu,cpuacct") or its name (e.g. "name=systemd" is mounted in "systemd").
+func cgroupDirs(content string) ([]string, error) {
+	type cgroup struct {
+		controllers string
+		path        string
+	}
+
+	cgroups := []cgroup{}
+	for _, line := range strings.Split(strings.TrimSpace(content), "\n") {
+		fields := strings.SplitN(line, ":", 3)
+		if len(fields) != 3 {
+			return nil, fmt.Errorf("malformed cgroup line %q", line)
+		}
+
+		// paths outside the root of the cgroup namespace of the reader are shown relative to it
+		if strings.HasPrefix(fields[2], "/..") {
+			return nil, fmt.Errorf("cgroup %q is outside of the cgroup namespace of the agent", fields[2])
+		}
+
+		cgroups = append(cgroups, cgroup{controllers: fields[1], path: fields[2]})
+	}
+
+	// cgroup v2
+	if len(cgroups) == 1 && cgroups[0].controllers == "" {
+		return []string{cgroups[0].path}, nil
+	}
+
+	dirs := []string{}
+	for _, c := range cgroups {
+		// the unified hierarchy of an hybrid setup does not have controllers
+		if c.controllers == "" {
+			continue
+		}
+
+		dirs = append(dirs, filepath.Join(strings.TrimPrefix(c.controllers, "name="), c.path))
+	}
+
+	if len(dirs) == 0 {
+		return nil, fmt.Errorf("no cgroups found")
+	}
+
+	return dirs, nil
+}
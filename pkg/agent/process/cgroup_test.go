@@ -0,0 +1,91 @@
+package process
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func Test_CgroupJoin(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		title       string
+		cgroup      string
+		dirs        []string
+		expectError bool
+	}{
+		{
+			title:  "cgroup v2",
+			cgroup: "0::/kubepods/burstable/pod1234/cri-containerd-abcdef.scope\n",
+			dirs:   []string{"kubepods/burstable/pod1234/cri-containerd-abcdef.scope"},
+		},
+		{
+			title: "cgroup v1",
+			cgroup: "4:memory:/kubepods/burstable/pod1234/abcdef\n" +
+				"3:cpu,cpuacct:/kubepods/burstable/pod1234/abcdef\n" +
+				"1:name=systemd:/kubepods/burstable/pod1234/abcdef\n" +
+				"0::/\n",
+			dirs: []string{
+				"memory/kubepods/burstable/pod1234/abcdef",
+				"cpu,cpuacct/kubepods/burstable/pod1234/abcdef",
+				"systemd/kubepods/burstable/pod1234/abcdef",
+			},
+		},
+		{
+			title:       "cgroup not accessible",
+			cgroup:      "0::/kubepods/burstable/pod1234/cri-containerd-abcdef.scope\n",
+			dirs:        []string{},
+			expectError: true,
+		},
+		{
+			title:       "cgroup outside of the namespace",
+			cgroup:      "0::/../cri-containerd-abcdef.scope\n",
+			dirs:        []string{},
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			procRoot := buildProcFS(t, []fakeProc{
+				{
+					pid:    "7",
+					cgroup: tc.cgroup,
+					stat:   "7 (app) S 0 7 7 0 -1",
+				},
+			})
+
+			cgroupRoot := t.TempDir()
+			for _, dir := range tc.dirs {
+				if err := os.MkdirAll(filepath.Join(cgroupRoot, dir), 0o755); err != nil {
+					t.Fatalf("creating cgroup dir: %v", err)
+				}
+				if err := os.WriteFile(filepath.Join(cgroupRoot, dir, "cgroup.procs"), []byte{}, 0o600); err != nil {
+					t.Fatalf("creating cgroup.procs file: %v", err)
+				}
+			}
+
+			joiner := NewCgroupJoiner(NewProcFinder(procRoot), procRoot, cgroupRoot)
+			err := joiner.Join("abcdef", 42)
+			if tc.expectError && err == nil {
+				t.Fatalf("should had failed")
+			}
+
+			if !tc.expectError && err != nil {
+				t.Fatalf("failed: %v", err)
+			}
+
+			for _, dir := range tc.dirs {
+				procs, _ := os.ReadFile(filepath.Join(cgroupRoot, dir, "cgroup.procs"))
+				if string(procs) != "42" {
+					t.Errorf("expected process in cgroup %q got %q", dir, string(procs))
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package process
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/grafana/xk6-disruptor/internal/multierr"
+	"github.com/grafana/xk6-disruptor/pkg/agent"
+	"github.com/grafana/xk6-disruptor/pkg/runtime"
+)
+
+// signalAll sends a signal to all the given processes. It attempts to signal every process even if signaling
+// one of them fails.
+func signalAll(executor runtime.Executor, signal string, processes []Process) error {
+	errs := []error{}
+	for _, p := range processes {
+		errs = append(errs, sendSignal(executor, signal, p.PID))
+	}
+
+	return multierr.Join(errs...)
+}
+
+// Resume resumes all the processes of the given container by sending them the SIGCONT signal.
+// Resuming processes that are not stopped has no effect.
+func Resume(executor runtime.Executor, finder Finder, containerID string) error {
+	processes, err := finder.Find(containerID)
+	if err != nil {
+		return fmt.Errorf("finding processes of container %q: %w", containerID, err)
+	}
+
+	return signalAll(executor, "SIGCONT", processes)
+}
+
+// freezeDisruptor is an instance of a Disruptor that stops all the processes of a container
+type freezeDisruptor struct {
+	executor    runtime.Executor
+	finder      Finder
+	containerID string
+}
+
+// NewFreezeDisruptor returns a Disruptor that stops all the processes of the given container for the duration
+// of the disruption and resumes them afterwards
+func NewFreezeDisruptor(
+	executor runtime.Executor,
+	finder Finder,
+	containerID string,
+) (agent.Disruptor, error) {
+	if containerID == "" {
+		return nil, fmt.Errorf("container ID must be specified")
+	}
+
+	return &freezeDisruptor{
+		executor:    executor,
+		finder:      finder,
+		containerID: containerID,
+	}, nil
+}
+
+// Apply stops the processes of the container and resumes them when the duration expires or the context is
+// cancelled. Processes are resumed even if stopping them fails.
+func (d *freezeDisruptor) Apply(ctx context.Context, duration time.Duration) error {
+	if duration < time.Second {
+		return fmt.Errorf("duration must be at least one second")
+	}
+
+	processes, err := d.finder.Find(d.containerID)
+	if err != nil {
+		return fmt.Errorf("finding processes of container %q: %w", d.containerID, err)
+	}
+
+	if len(processes) == 0 {
+		return fmt.Errorf("container %q does not have any process", d.containerID)
+	}
+
+	err = signalAll(d.executor, "SIGSTOP", processes)
+	if err == nil {
+		select {
+		case <-time.After(duration):
+		case <-ctx.Done():
+			err = ctx.Err()
+		}
+	}
+
+	// processes are searched again for resuming any process forked while the others were being stopped
+	if resumeErr := Resume(d.executor, d.finder, d.containerID); resumeErr != nil && err == nil {
+		err = resumeErr
+	}
+
+	return err
+}
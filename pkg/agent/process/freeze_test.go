@@ -0,0 +1,124 @@
+package process
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/grafana/xk6-disruptor/pkg/runtime"
+)
+
+func Test_FreezeDisruptor(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		title        string
+		finder       Finder
+		duration     time.Duration
+		cancel       bool
+		execError    error
+		expectedCmds []string
+		expectedErr  error
+		expectError  bool
+	}{
+		{
+			title: "freeze container",
+			finder: fakeFinder{
+				processes: []Process{{PID: 7, PPID: 0}, {PID: 12, PPID: 7}},
+			},
+			duration: time.Second,
+			expectedCmds: []string{
+				"kill -s STOP 7",
+				"kill -s STOP 12",
+				"kill -s CONT 7",
+				"kill -s CONT 12",
+			},
+		},
+		{
+			title: "processes are resumed when cancelled",
+			finder: fakeFinder{
+				processes: []Process{{PID: 7, PPID: 0}},
+			},
+			duration: 10 * time.Second,
+			cancel:   true,
+			expectedCmds: []string{
+				"kill -s STOP 7",
+				"kill -s CONT 7",
+			},
+			expectedErr: context.Canceled,
+			expectError: true,
+		},
+		{
+			title: "processes are resumed if stopping fails",
+			finder: fakeFinder{
+				processes: []Process{{PID: 7, PPID: 0}},
+			},
+			duration:  time.Second,
+			execError: fmt.Errorf("fake error"),
+			expectedCmds: []string{
+				"kill -s STOP 7",
+				"kill -s CONT 7",
+			},
+			expectError: true,
+		},
+		{
+			title:        "container without processes",
+			finder:       fakeFinder{processes: []Process{}},
+			duration:     time.Second,
+			expectedCmds: nil,
+			expectError:  true,
+		},
+		{
+			title: "invalid duration",
+			finder: fakeFinder{
+				processes: []Process{{PID: 7, PPID: 0}},
+			},
+			duration:     0,
+			expectedCmds: nil,
+			expectError:  true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			executor := runtime.NewFakeExecutor(nil, tc.execError)
+			disruptor, err := NewFreezeDisruptor(executor, tc.finder, "abcdef")
+			if err != nil {
+				t.Fatalf("failed creating disruptor: %v", err)
+			}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			if tc.cancel {
+				go func() {
+					time.Sleep(time.Second)
+					cancel()
+				}()
+			}
+
+			err = disruptor.Apply(ctx, tc.duration)
+			if tc.expectError && err == nil {
+				t.Fatalf("should had failed")
+			}
+
+			if !tc.expectError && err != nil {
+				t.Fatalf("failed: %v", err)
+			}
+
+			if tc.expectedErr != nil && !errors.Is(err, tc.expectedErr) {
+				t.Fatalf("expected %v got %v", tc.expectedErr, err)
+			}
+
+			if diff := cmp.Diff(tc.expectedCmds, executor.CmdHistory()); diff != "" {
+				t.Errorf("expected commands do not match executed:\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,127 @@
+// Package process implements disruptors that act on the processes of a target container.
+// The agent must share the process namespace with the target container, for example because it runs in an
+// ephemeral container that targets it.
+package process
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Process describes a process running in a container
+type Process struct {
+	// PID is the ID of the process
+	PID int
+	// PPID is the ID of the parent of the process
+	PPID int
+}
+
+// Finder defines the interface for finding the processes that run in a container
+type Finder interface {
+	// Find returns the processes that belong to the container with the given ID
+	Find(containerID string) ([]Process, error)
+}
+
+// procFinder is a Finder that inspects the proc filesystem
+type procFinder struct {
+	root string
+}
+
+// NewProcFinder returns a Finder that inspects the proc filesystem mounted at the given root
+func NewProcFinder(root string) Finder {
+	return &procFinder{
+		root: root,
+	}
+}
+
+// DefaultFinder returns a Finder that inspects the proc filesystem mounted at /proc
+func DefaultFinder() Finder {
+	return NewProcFinder("/proc")
+}
+
+// Find returns the processes whose cgroup contains the given container ID.
+func (f *procFinder) Find(containerID string) ([]Process, error) {
+	if containerID == "" {
+		return nil, fmt.Errorf("container ID cannot be empty")
+	}
+
+	entries, err := os.ReadDir(f.root)
+	if err != nil {
+		return nil, fmt.Errorf("reading %q: %w", f.root, err)
+	}
+
+	processes := []Process{}
+	for _, entry := range entries {
+		pid, err := strconv.Atoi(entry.Name())
+		if err != nil || !entry.IsDir() {
+			continue
+		}
+
+		// processes may terminate while we are inspecting them, so errors are ignored
+		cgroup, err := os.ReadFile(filepath.Join(f.root, entry.Name(), "cgroup"))
+		if err != nil || !strings.Contains(string(cgroup), containerID) {
+			continue
+		}
+
+		stat, err := os.ReadFile(filepath.Join(f.root, entry.Name(), "stat"))
+		if err != nil {
+			continue
+		}
+
+		ppid, err := parsePPID(string(stat))
+		if err != nil {
+			return nil, fmt.Errorf("parsing stat for process %d: %w", pid, err)
+		}
+
+		processes = append(processes, Process{PID: pid, PPID: ppid})
+	}
+
+	return processes, nil
+}
+
+// parsePPID returns the parent process id from the content of the /proc/<pid>/stat file.
+// The file has the form "pid (comm) state ppid ...". As comm may contain spaces and parenthesis,
+// fields are parsed after the last closing parenthesis.
+func parsePPID(stat string) (int, error) {
+	end := strings.LastIndex(stat, ")")
+	if end < 0 {
+		return 0, fmt.Errorf("malformed stat %q", stat)
+	}
+
+	fields := strings.Fields(stat[end+1:])
+	if len(fields) < 2 {
+		return 0, fmt.Errorf("malformed stat %q", stat)
+	}
+
+	return strconv.Atoi(fields[1])
+}
+
+// MainProcess returns the main process from a list of processes of a container, that is, the process whose parent
+// does not belong to the container. If more than one process satisfies this condition, the one with the lowest PID
+// is returned.
+func MainProcess(processes []Process) (Process, error) {
+	pids := map[int]bool{}
+	for _, p := range processes {
+		pids[p.PID] = true
+	}
+
+	var main *Process
+	for i, p := range processes {
+		if pids[p.PPID] {
+			continue
+		}
+
+		if main == nil || p.PID < main.PID {
+			main = &processes[i]
+		}
+	}
+
+	if main == nil {
+		return Process{}, fmt.Errorf("main process not found")
+	}
+
+	return *main, nil
+}
@@ -0,0 +1,176 @@
+package process
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// fakeProc describes a process to be created in a fake proc filesystem
+type fakeProc struct {
+	pid    string
+	cgroup string
+	stat   string
+}
+
+// buildProcFS creates a fake proc filesystem in a temporary directory
+func buildProcFS(t *testing.T, procs []fakeProc) string {
+	t.Helper()
+
+	root := t.TempDir()
+	for _, p := range procs {
+		dir := filepath.Join(root, p.pid)
+		if err := os.Mkdir(dir, 0o755); err != nil {
+			t.Fatalf("creating proc dir: %v", err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "cgroup"), []byte(p.cgroup), 0o600); err != nil {
+			t.Fatalf("creating cgroup file: %v", err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "stat"), []byte(p.stat), 0o600); err != nil {
+			t.Fatalf("creating stat file: %v", err)
+		}
+	}
+
+	// files that are not process directories must be ignored
+	if err := os.WriteFile(filepath.Join(root, "uptime"), []byte("100.0 200.0"), 0o600); err != nil {
+		t.Fatalf("creating uptime file: %v", err)
+	}
+
+	return root
+}
+
+func Test_Find(t *testing.T) {
+	t.Parallel()
+
+	procs := []fakeProc{
+		{
+			pid:    "1",
+			cgroup: "0::/kubepods/besteffort/pod1234/pause\n",
+			stat:   "1 (pause) S 0 1 1 0 -1",
+		},
+		{
+			pid:    "7",
+			cgroup: "0::/kubepods/besteffort/pod1234/cri-containerd-abcdef.scope\n",
+			stat:   "7 (my app) S 0 7 7 0 -1",
+		},
+		{
+			pid:    "12",
+			cgroup: "0::/kubepods/besteffort/pod1234/cri-containerd-abcdef.scope\n",
+			stat:   "12 (worker (1)) S 7 7 7 0 -1",
+		},
+		{
+			pid:    "20",
+			cgroup: "0::/kubepods/besteffort/pod1234/cri-containerd-012345.scope\n",
+			stat:   "20 (xk6-disruptor-agent) S 0 20 20 0 -1",
+		},
+	}
+
+	testCases := []struct {
+		title       string
+		containerID string
+		expected    []Process
+		expectError bool
+	}{
+		{
+			title:       "container with multiple processes",
+			containerID: "abcdef",
+			expected:    []Process{{PID: 7, PPID: 0}, {PID: 12, PPID: 7}},
+		},
+		{
+			title:       "container without processes",
+			containerID: "fedcba",
+			expected:    []Process{},
+		},
+		{
+			title:       "empty container id",
+			containerID: "",
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			finder := NewProcFinder(buildProcFS(t, procs))
+			processes, err := finder.Find(tc.containerID)
+			if tc.expectError && err == nil {
+				t.Fatalf("should had failed")
+			}
+
+			if !tc.expectError && err != nil {
+				t.Fatalf("failed: %v", err)
+			}
+
+			if tc.expectError {
+				return
+			}
+
+			sort.Slice(processes, func(i, j int) bool {
+				return processes[i].PID < processes[j].PID
+			})
+
+			if diff := cmp.Diff(tc.expected, processes); diff != "" {
+				t.Errorf("expected processes do not match returned:\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_MainProcess(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		title       string
+		processes   []Process
+		expected    Process
+		expectError bool
+	}{
+		{
+			title:     "single process",
+			processes: []Process{{PID: 7, PPID: 0}},
+			expected:  Process{PID: 7, PPID: 0},
+		},
+		{
+			title:     "process with children",
+			processes: []Process{{PID: 12, PPID: 7}, {PID: 7, PPID: 1}, {PID: 13, PPID: 12}},
+			expected:  Process{PID: 7, PPID: 1},
+		},
+		{
+			title:     "multiple root processes",
+			processes: []Process{{PID: 30, PPID: 1}, {PID: 9, PPID: 1}},
+			expected:  Process{PID: 9, PPID: 1},
+		},
+		{
+			title:       "no processes",
+			processes:   []Process{},
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			main, err := MainProcess(tc.processes)
+			if tc.expectError && err == nil {
+				t.Fatalf("should had failed")
+			}
+
+			if !tc.expectError && err != nil {
+				t.Fatalf("failed: %v", err)
+			}
+
+			if main != tc.expected {
+				t.Errorf("expected %v got %v", tc.expected, main)
+			}
+		})
+	}
+}
@@ -0,0 +1,109 @@
+package process
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/grafana/xk6-disruptor/pkg/agent"
+	"github.com/grafana/xk6-disruptor/pkg/runtime"
+)
+
+// DefaultSignal is the signal sent to the target process if none is specified
+const DefaultSignal = "SIGKILL"
+
+// supportedSignals lists the signals that can be sent to a target process
+var supportedSignals = map[string]bool{
+	"SIGKILL": true,
+	"SIGTERM": true,
+	"SIGINT":  true,
+	"SIGHUP":  true,
+	"SIGQUIT": true,
+	"SIGSTOP": true,
+	"SIGCONT": true,
+	"SIGUSR1": true,
+	"SIGUSR2": true,
+}
+
+// ParseSignal returns the canonical name of a signal (e.g. "SIGKILL"). The name is case-insensitive
+// and the "SIG" prefix is optional.
+func ParseSignal(name string) (string, error) {
+	signal := strings.ToUpper(name)
+	if !strings.HasPrefix(signal, "SIG") {
+		signal = "SIG" + signal
+	}
+
+	if !supportedSignals[signal] {
+		return "", fmt.Errorf("unsupported signal %q", name)
+	}
+
+	return signal, nil
+}
+
+// initPID is the PID of the init process of the pid namespace shared by the agent and the target container
+const initPID = 1
+
+// sendSignal sends a signal to a process using the kill command. When the agent shares the pid namespace of the
+// target container, the main process of the container is the init process of the namespace, which ignores the
+// SIGKILL and SIGSTOP signals sent from it, so these signals are injected in the process instead.
+func sendSignal(executor runtime.Executor, signal string, pid int) error {
+	if sig, found := injectedSignals[signal]; found && pid == initPID {
+		return injectSignal(pid, sig)
+	}
+
+	out, err := executor.Exec("kill", "-s", strings.TrimPrefix(signal, "SIG"), fmt.Sprint(pid))
+	if err != nil {
+		return fmt.Errorf("sending %s to process %d: %w %s", signal, pid, err, string(out))
+	}
+
+	return nil
+}
+
+// signalDisruptor is an instance of a Disruptor that sends a signal to the main process of a container
+type signalDisruptor struct {
+	executor    runtime.Executor
+	finder      Finder
+	containerID string
+	signal      string
+}
+
+// NewSignalDisruptor returns a Disruptor that sends a signal to the main process of the given container
+func NewSignalDisruptor(
+	executor runtime.Executor,
+	finder Finder,
+	containerID string,
+	signal string,
+) (agent.Disruptor, error) {
+	if containerID == "" {
+		return nil, fmt.Errorf("container ID must be specified")
+	}
+
+	signal, err := ParseSignal(signal)
+	if err != nil {
+		return nil, err
+	}
+
+	return &signalDisruptor{
+		executor:    executor,
+		finder:      finder,
+		containerID: containerID,
+		signal:      signal,
+	}, nil
+}
+
+// Apply sends the signal to the main process of the container. The duration is ignored because
+// the signal is delivered once.
+func (d *signalDisruptor) Apply(_ context.Context, _ time.Duration) error {
+	processes, err := d.finder.Find(d.containerID)
+	if err != nil {
+		return fmt.Errorf("finding processes of container %q: %w", d.containerID, err)
+	}
+
+	main, err := MainProcess(processes)
+	if err != nil {
+		return fmt.Errorf("container %q: %w", d.containerID, err)
+	}
+
+	return sendSignal(d.executor, d.signal, main.PID)
+}
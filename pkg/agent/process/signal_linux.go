@@ -0,0 +1,62 @@
+package process
+
+import (
+	"fmt"
+	goruntime "runtime"
+	"syscall"
+)
+
+// injectedSignals are the signals that must be injected in the init process of a pid namespace
+var injectedSignals = map[string]syscall.Signal{
+	"SIGKILL": syscall.SIGKILL,
+	"SIGSTOP": syscall.SIGSTOP,
+}
+
+// injectSignal delivers a signal to a process by attaching to it with ptrace and injecting the signal when
+// detaching from it. Signals injected this way are not ignored by the init process of a pid namespace, which
+// otherwise ignores the SIGKILL and SIGSTOP signals sent from its own namespace.
+func injectSignal(pid int, signal syscall.Signal) error {
+	// all the ptrace requests must be issued from the thread attached to the process
+	goruntime.LockOSThread()
+	defer goruntime.UnlockOSThread()
+
+	if err := syscall.PtraceAttach(pid); err != nil {
+		return fmt.Errorf("attaching to process %d: %w", pid, err)
+	}
+
+	// wait for the process to stop on the SIGSTOP sent when attaching, delivering any other signal it receives
+	for {
+		var status syscall.WaitStatus
+		if _, err := syscall.Wait4(pid, &status, syscall.WALL, nil); err != nil {
+			return fmt.Errorf("waiting for process %d: %w", pid, err)
+		}
+
+		if status.Exited() || status.Signaled() {
+			return nil
+		}
+
+		if status.StopSignal() == syscall.SIGSTOP {
+			break
+		}
+
+		// the SIGTRAP received when the process executes a program while being traced is not delivered
+		received := status.StopSignal()
+		if received == syscall.SIGTRAP {
+			received = 0
+		}
+
+		if err := syscall.PtraceCont(pid, int(received)); err != nil {
+			return fmt.Errorf("resuming process %d: %w", pid, err)
+		}
+	}
+
+	// the signal replaces the SIGSTOP sent when attaching
+	_, _, errno := syscall.Syscall6(
+		syscall.SYS_PTRACE, syscall.PTRACE_DETACH, uintptr(pid), 0, uintptr(signal), 0, 0,
+	)
+	if errno != 0 {
+		return fmt.Errorf("injecting %s in process %d: %w", signal, pid, errno)
+	}
+
+	return nil
+}
@@ -0,0 +1,56 @@
+package process
+
+import (
+	"errors"
+	"os/exec"
+	"syscall"
+	"testing"
+)
+
+func Test_InjectSignal(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		title  string
+		signal syscall.Signal
+	}{
+		{
+			title:  "kill process",
+			signal: syscall.SIGKILL,
+		},
+		{
+			title:  "terminate process",
+			signal: syscall.SIGTERM,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			cmd := exec.Command("sleep", "60")
+			if err := cmd.Start(); err != nil {
+				t.Fatalf("starting process: %v", err)
+			}
+
+			err := injectSignal(cmd.Process.Pid, tc.signal)
+			if err != nil {
+				_ = cmd.Process.Kill()
+				_ = cmd.Wait()
+				t.Fatalf("failed: %v", err)
+			}
+
+			var exitErr *exec.ExitError
+			if err = cmd.Wait(); !errors.As(err, &exitErr) {
+				t.Fatalf("expected process to be signaled got %v", err)
+			}
+
+			status, _ := exitErr.Sys().(syscall.WaitStatus)
+			if !status.Signaled() || status.Signal() != tc.signal {
+				t.Errorf("expected process terminated by %s got %s", tc.signal, exitErr)
+			}
+		})
+	}
+}
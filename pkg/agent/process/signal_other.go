@@ -0,0 +1,14 @@
+//go:build !linux
+
+package process
+
+import (
+	"errors"
+	"syscall"
+)
+
+var injectedSignals = map[string]syscall.Signal{}
+
+func injectSignal(_ int, _ syscall.Signal) error {
+	return errors.New("injecting signals is only supported in linux")
+}
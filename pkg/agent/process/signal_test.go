@@ -0,0 +1,147 @@
+package process
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/grafana/xk6-disruptor/pkg/runtime"
+)
+
+// fakeFinder is a Finder that returns a predefined list of processes
+type fakeFinder struct {
+	processes []Process
+	err       error
+}
+
+func (f fakeFinder) Find(_ string) ([]Process, error) {
+	return f.processes, f.err
+}
+
+func Test_ParseSignal(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name        string
+		expected    string
+		expectError bool
+	}{
+		{name: "SIGKILL", expected: "SIGKILL"},
+		{name: "sigterm", expected: "SIGTERM"},
+		{name: "STOP", expected: "SIGSTOP"},
+		{name: "cont", expected: "SIGCONT"},
+		{name: "SIGSEGV", expectError: true},
+		{name: "", expectError: true},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			signal, err := ParseSignal(tc.name)
+			if tc.expectError && err == nil {
+				t.Fatalf("should had failed")
+			}
+
+			if !tc.expectError && err != nil {
+				t.Fatalf("failed: %v", err)
+			}
+
+			if signal != tc.expected {
+				t.Errorf("expected %q got %q", tc.expected, signal)
+			}
+		})
+	}
+}
+
+func Test_SignalDisruptor(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		title        string
+		signal       string
+		finder       Finder
+		execError    error
+		expectedCmds []string
+		expectError  bool
+	}{
+		{
+			title:  "kill main process",
+			signal: "SIGKILL",
+			finder: fakeFinder{
+				processes: []Process{{PID: 12, PPID: 7}, {PID: 7, PPID: 0}},
+			},
+			expectedCmds: []string{"kill -s KILL 7"},
+		},
+		{
+			title:  "stop main process",
+			signal: "stop",
+			finder: fakeFinder{
+				processes: []Process{{PID: 7, PPID: 0}},
+			},
+			expectedCmds: []string{"kill -s STOP 7"},
+		},
+		{
+			title:  "terminate init process",
+			signal: "SIGTERM",
+			finder: fakeFinder{
+				processes: []Process{{PID: 1, PPID: 0}},
+			},
+			expectedCmds: []string{"kill -s TERM 1"},
+		},
+		{
+			title:        "container without processes",
+			signal:       "SIGTERM",
+			finder:       fakeFinder{processes: []Process{}},
+			expectedCmds: nil,
+			expectError:  true,
+		},
+		{
+			title:        "error finding processes",
+			signal:       "SIGTERM",
+			finder:       fakeFinder{err: fmt.Errorf("fake error")},
+			expectedCmds: nil,
+			expectError:  true,
+		},
+		{
+			title:  "error sending signal",
+			signal: "SIGTERM",
+			finder: fakeFinder{
+				processes: []Process{{PID: 7, PPID: 0}},
+			},
+			execError:    fmt.Errorf("fake error"),
+			expectedCmds: []string{"kill -s TERM 7"},
+			expectError:  true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			executor := runtime.NewFakeExecutor(nil, tc.execError)
+			disruptor, err := NewSignalDisruptor(executor, tc.finder, "abcdef", tc.signal)
+			if err != nil {
+				t.Fatalf("failed creating disruptor: %v", err)
+			}
+
+			err = disruptor.Apply(context.TODO(), 0)
+			if tc.expectError && err == nil {
+				t.Fatalf("should had failed")
+			}
+
+			if !tc.expectError && err != nil {
+				t.Fatalf("failed: %v", err)
+			}
+
+			if diff := cmp.Diff(tc.expectedCmds, executor.CmdHistory()); diff != "" {
+				t.Errorf("expected commands do not match executed:\n%s", diff)
+			}
+		})
+	}
+}
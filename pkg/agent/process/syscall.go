@@ -0,0 +1,36 @@
+package process
+
+// SyscallFunc executes a system call with the given number and arguments on behalf of a traced process and returns
+// its result. Errors returned by the system call are returned as a syscall.Errno.
+type SyscallFunc func(nr uintptr, args ...uintptr) (uintptr, error)
+
+// SyscallInjector defines the interface for executing system calls on behalf of a process
+type SyscallInjector interface {
+	// Inject stops the process with the given PID and passes to the inject function a SyscallFunc that executes
+	// system calls on behalf of the process, therefore subject to its limits. The process is resumed when the inject
+	// function returns.
+	Inject(pid int, inject func(call SyscallFunc) error) error
+}
+
+// ptraceInjector is a SyscallInjector that uses ptrace for executing the system calls in a thread of the process
+type ptraceInjector struct {
+	procRoot string
+}
+
+// NewSyscallInjector returns a SyscallInjector that inspects the threads of the process in the proc filesystem mounted
+// at procRoot
+func NewSyscallInjector(procRoot string) SyscallInjector {
+	return &ptraceInjector{
+		procRoot: procRoot,
+	}
+}
+
+// DefaultSyscallInjector returns a SyscallInjector that inspects the proc filesystem mounted at /proc
+func DefaultSyscallInjector() SyscallInjector {
+	return NewSyscallInjector("/proc")
+}
+
+// Inject executes the system calls in a thread of the process
+func (i *ptraceInjector) Inject(pid int, inject func(call SyscallFunc) error) error {
+	return injectSyscalls(i.procRoot, pid, inject)
+}
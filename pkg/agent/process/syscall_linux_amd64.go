@@ -0,0 +1,254 @@
+package process
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	goruntime "runtime"
+	"strconv"
+	"syscall"
+
+	"github.com/grafana/xk6-disruptor/internal/multierr"
+)
+
+const (
+	// ptrace requests not defined in the syscall package
+	ptraceSeize     = 0x4206
+	ptraceInterrupt = 0x4207
+	// ptraceEventStop is the event reported when a seized thread stops because of a ptraceInterrupt request
+	ptraceEventStop = 128
+	// maxErrno is the largest error number returned by a system call as a negative value
+	maxErrno = 4095
+)
+
+// syscallInstruction is the encoding of the syscall instruction
+var syscallInstruction = []byte{0x0f, 0x05}
+
+// tracedThread is a thread of a process stopped with ptrace
+type tracedThread struct {
+	tid int
+	// registers of the thread when it was stopped
+	regs syscall.PtraceRegs
+	// signals received by the thread while it was traced, which are delivered when it is detached
+	pending []syscall.Signal
+}
+
+// injectSyscalls stops a thread of the process that is blocked in a system call and passes to the inject function
+// a SyscallFunc that executes system calls in that thread. Once the inject function returns, the registers of the
+// thread are restored and the interrupted system call is restarted. The thread is stopped until the inject function
+// returns, so it should not execute a large number of system calls.
+func injectSyscalls(procRoot string, pid int, inject func(call SyscallFunc) error) error {
+	// all the ptrace requests must be issued from the thread attached to the process
+	goruntime.LockOSThread()
+	defer goruntime.UnlockOSThread()
+
+	thread, err := stopInSyscall(procRoot, pid)
+	if err != nil {
+		return err
+	}
+
+	err = inject(thread.syscall)
+
+	if detachErr := thread.detach(pid); err == nil {
+		err = detachErr
+	}
+
+	return err
+}
+
+// stopInSyscall stops a thread of the process that is blocked in a system call. The system call instruction executed
+// by the thread is reused for injecting system calls, as the code of the process cannot be modified safely.
+func stopInSyscall(procRoot string, pid int) (*tracedThread, error) {
+	tasks, err := os.ReadDir(filepath.Join(procRoot, strconv.Itoa(pid), "task"))
+	if err != nil {
+		return nil, fmt.Errorf("reading threads of process %d: %w", pid, err)
+	}
+
+	for _, task := range tasks {
+		tid, convErr := strconv.Atoi(task.Name())
+		if convErr != nil {
+			continue
+		}
+
+		thread, seizeErr := seize(tid)
+		if seizeErr != nil {
+			// the thread may have exited
+			err = seizeErr
+			continue
+		}
+
+		if thread.inSyscall() {
+			return thread, nil
+		}
+
+		if err = thread.detach(pid); err != nil {
+			return nil, err
+		}
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("stopping process %d: %w", pid, err)
+	}
+
+	return nil, fmt.Errorf("no thread of process %d is blocked in a system call", pid)
+}
+
+// seize attaches to the thread and stops it
+func seize(tid int) (*tracedThread, error) {
+	if err := ptrace(ptraceSeize, tid, 0); err != nil {
+		return nil, fmt.Errorf("attaching to thread %d: %w", tid, err)
+	}
+
+	thread := &tracedThread{tid: tid}
+	err := ptrace(ptraceInterrupt, tid, 0)
+	if err == nil {
+		err = thread.waitInterrupt()
+	}
+	if err == nil {
+		err = syscall.PtraceGetRegs(tid, &thread.regs)
+	}
+	if err != nil {
+		_ = syscall.PtraceDetach(tid)
+		return nil, fmt.Errorf("stopping thread %d: %w", tid, err)
+	}
+
+	return thread, nil
+}
+
+// waitInterrupt waits for the thread to stop because of the interrupt request, delivering any signal it receives
+func (t *tracedThread) waitInterrupt() error {
+	for {
+		status, err := t.wait()
+		if err != nil {
+			return err
+		}
+
+		if int(status>>16) == ptraceEventStop {
+			if status.StopSignal() != syscall.SIGTRAP {
+				return fmt.Errorf("thread %d is stopped by %s", t.tid, status.StopSignal())
+			}
+
+			return nil
+		}
+
+		if err = syscall.PtraceCont(t.tid, int(status.StopSignal())); err != nil {
+			return err
+		}
+	}
+}
+
+// wait waits for the thread to stop
+func (t *tracedThread) wait() (syscall.WaitStatus, error) {
+	for {
+		var status syscall.WaitStatus
+		if _, err := syscall.Wait4(t.tid, &status, syscall.WALL, nil); err != nil {
+			return 0, err
+		}
+
+		if status.Exited() || status.Signaled() {
+			return 0, fmt.Errorf("thread %d terminated", t.tid)
+		}
+
+		if status.Stopped() {
+			return status, nil
+		}
+	}
+}
+
+// inSyscall returns true if the thread was stopped while executing a system call
+func (t *tracedThread) inSyscall() bool {
+	// the number of the system call is -1 if the thread was not executing one
+	if int64(t.regs.Orig_rax) < 0 {
+		return false
+	}
+
+	instruction := make([]byte, len(syscallInstruction))
+	_, err := syscall.PtracePeekText(t.tid, t.syscallAddress(), instruction)
+
+	return err == nil && string(instruction) == string(syscallInstruction)
+}
+
+// syscallAddress returns the address of the system call instruction executed by the thread, which precedes the
+// instruction the thread will execute when resumed
+func (t *tracedThread) syscallAddress() uintptr {
+	return uintptr(t.regs.Rip) - uintptr(len(syscallInstruction))
+}
+
+// syscall executes a system call in the thread by single-stepping its system call instruction
+func (t *tracedThread) syscall(nr uintptr, args ...uintptr) (uintptr, error) {
+	if len(args) > 6 {
+		return 0, fmt.Errorf("system calls have at most 6 arguments")
+	}
+
+	argv := make([]uint64, 6)
+	for i, arg := range args {
+		argv[i] = uint64(arg)
+	}
+
+	regs := t.regs
+	regs.Rip = uint64(t.syscallAddress())
+	regs.Rax = uint64(nr)
+	// prevents the kernel from restarting the interrupted system call when resuming the thread
+	regs.Orig_rax = ^uint64(0)
+	regs.Rdi, regs.Rsi, regs.Rdx, regs.R10, regs.R8, regs.R9 = argv[0], argv[1], argv[2], argv[3], argv[4], argv[5]
+
+	if err := syscall.PtraceSetRegs(t.tid, &regs); err != nil {
+		return 0, fmt.Errorf("setting registers of thread %d: %w", t.tid, err)
+	}
+
+	for {
+		if err := syscall.PtraceSingleStep(t.tid); err != nil {
+			return 0, fmt.Errorf("resuming thread %d: %w", t.tid, err)
+		}
+
+		status, err := t.wait()
+		if err != nil {
+			return 0, err
+		}
+
+		if status.StopSignal() == syscall.SIGTRAP {
+			break
+		}
+
+		// signals received before executing the system call are delivered when detaching
+		t.pending = append(t.pending, status.StopSignal())
+	}
+
+	if err := syscall.PtraceGetRegs(t.tid, &regs); err != nil {
+		return 0, fmt.Errorf("getting registers of thread %d: %w", t.tid, err)
+	}
+
+	if result := int64(regs.Rax); result < 0 && result >= -maxErrno {
+		return 0, syscall.Errno(-result)
+	}
+
+	return uintptr(regs.Rax), nil
+}
+
+// detach restores the registers of the thread and resumes it
+func (t *tracedThread) detach(pid int) error {
+	err := syscall.PtraceSetRegs(t.tid, &t.regs)
+	if err != nil {
+		err = fmt.Errorf("restoring registers of thread %d: %w", t.tid, err)
+	}
+
+	if detachErr := syscall.PtraceDetach(t.tid); detachErr != nil {
+		return multierr.Join(err, fmt.Errorf("detaching from thread %d: %w", t.tid, detachErr))
+	}
+
+	for _, signal := range t.pending {
+		_ = syscall.Tgkill(pid, t.tid, signal)
+	}
+
+	return err
+}
+
+// ptrace issues a ptrace request with the given data
+func ptrace(request int, tid int, data uintptr) error {
+	_, _, errno := syscall.Syscall6(syscall.SYS_PTRACE, uintptr(request), uintptr(tid), 0, data, 0, 0)
+	if errno != 0 {
+		return errno
+	}
+
+	return nil
+}
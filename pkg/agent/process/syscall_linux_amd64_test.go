@@ -0,0 +1,81 @@
+package process
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// waitSleeping waits for the process to be blocked in a system call
+func waitSleeping(t *testing.T, pid int) {
+	t.Helper()
+
+	for i := 0; i < 100; i++ {
+		stat, err := os.ReadFile(filepath.Join("/proc", strconv.Itoa(pid), "stat"))
+		if err != nil {
+			t.Fatalf("reading process status: %v", err)
+		}
+
+		if strings.Contains(string(stat), ") S ") {
+			return
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	t.Fatalf("process %d is not sleeping", pid)
+}
+
+func Test_SyscallInjector(t *testing.T) {
+	t.Parallel()
+
+	// the process must complete its sleep after the system calls are injected
+	cmd := exec.Command("sleep", "2")
+	if err := cmd.Start(); err != nil {
+		t.Fatalf("starting process: %v", err)
+	}
+
+	pid := cmd.Process.Pid
+	waitSleeping(t, pid)
+
+	var fd uintptr
+	err := DefaultSyscallInjector().Inject(pid, func(call SyscallFunc) error {
+		result, err := call(syscall.SYS_GETPID)
+		if err != nil {
+			return err
+		}
+		if int(result) != pid {
+			t.Errorf("expected pid %d got %d", pid, result)
+		}
+
+		// a system call that fails returns its error
+		_, err = call(syscall.SYS_CLOSE, 1000)
+		if !errors.Is(err, syscall.EBADF) {
+			t.Errorf("expected %v got %v", syscall.EBADF, err)
+		}
+
+		fd, err = call(syscall.SYS_EVENTFD2, 0, syscall.O_CLOEXEC)
+		return err
+	})
+	if err != nil {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+		t.Fatalf("failed: %v", err)
+	}
+
+	// the descriptor is opened by the process
+	link, err := os.Readlink(filepath.Join("/proc", strconv.Itoa(pid), "fd", strconv.Itoa(int(fd))))
+	if err != nil || link != "anon_inode:[eventfd]" {
+		t.Errorf("expected descriptor %d to be an eventfd got %q (%v)", fd, link, err)
+	}
+
+	if err = cmd.Wait(); err != nil {
+		t.Errorf("expected process to complete got %v", err)
+	}
+}
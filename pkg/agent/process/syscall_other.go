@@ -0,0 +1,11 @@
+//go:build !linux || !amd64
+
+package process
+
+import (
+	"errors"
+)
+
+func injectSyscalls(_ string, _ int, _ func(call SyscallFunc) error) error {
+	return errors.New("injecting system calls is only supported in linux on amd64")
+}
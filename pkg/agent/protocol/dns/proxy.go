@@ -0,0 +1,464 @@
+// Package dns implements a proxy that applies disruptions to the DNS queries sent by the target.
+// Queries are forwarded to the upstream resolver unless a fault is injected.
+package dns
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/grafana/xk6-disruptor/internal/multierr"
+	"github.com/grafana/xk6-disruptor/pkg/agent/protocol"
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// Errors that can be returned for the queries selected in the error rate
+const (
+	// ErrorServFail answers the query with a SERVFAIL response
+	ErrorServFail = "servfail"
+	// ErrorNXDomain answers the query with a NXDOMAIN response
+	ErrorNXDomain = "nxdomain"
+	// ErrorEmpty answers the query with a successful response without any answer
+	ErrorEmpty = "empty"
+)
+
+// upstreamTimeout is the maximum time waiting for the response of the upstream resolver
+const upstreamTimeout = 5 * time.Second
+
+// maxMessageSize is the maximum size of a DNS message
+const maxMessageSize = 65535
+
+// Disruption specifies disruptions in DNS queries
+type Disruption struct {
+	// Average delay introduced to queries
+	AverageDelay time.Duration
+	// Variation in the delay (with respect of the average delay)
+	DelayVariation time.Duration
+	// Fraction (in the range 0.0 to 1.0) of queries that will return an error
+	ErrorRate float32
+	// Error returned by the queries selected in the error rate: "servfail", "nxdomain" or "empty"
+	Error string
+	// Names to be disrupted. A name also matches its subdomains. If empty, all the queries are disrupted.
+	Names []string
+}
+
+// proxy defines the parameters used by the proxy for processing DNS queries and its execution state
+type proxy struct {
+	udp        net.PacketConn
+	tcp        net.Listener
+	upstream   string
+	dialer     *net.Dialer
+	disruption Disruption
+	metrics    *protocol.MetricMap
+	mutex      sync.Mutex
+	stopped    bool
+	conns      map[net.Conn]struct{}
+}
+
+// validateDisruption checks the disruption is valid
+func validateDisruption(d Disruption) error {
+	if d.DelayVariation > d.AverageDelay {
+		return fmt.Errorf("variation must be less that average delay")
+	}
+
+	if d.ErrorRate < 0.0 || d.ErrorRate > 1.0 {
+		return fmt.Errorf("error rate must be in the range [0.0, 1.0]")
+	}
+
+	switch d.Error {
+	case ErrorServFail, ErrorNXDomain, ErrorEmpty:
+	case "":
+		if d.ErrorRate > 0 {
+			return fmt.Errorf("error must be specified when error rate is greater than 0")
+		}
+	default:
+		return fmt.Errorf("invalid error %q", d.Error)
+	}
+
+	return nil
+}
+
+func newProxy(
+	udp net.PacketConn,
+	tcp net.Listener,
+	upstream string,
+	dialer *net.Dialer,
+	d Disruption,
+) *proxy {
+	return &proxy{
+		udp:        udp,
+		tcp:        tcp,
+		upstream:   upstream,
+		dialer:     dialer,
+		disruption: d,
+		metrics: protocol.NewMetricMap(
+			protocol.MetricRequests,
+			protocol.MetricRequestsExcluded,
+			protocol.MetricRequestsDisrupted,
+		),
+		conns: map[net.Conn]struct{}{},
+	}
+}
+
+// NewProxy returns a new Proxy for the DNS queries received on the given UDP connection and TCP listener.
+// Queries are forwarded to the upstream resolver from a connection marked with the protocol.EgressMark,
+// to prevent them from being redirected back to the proxy.
+func NewProxy(udp net.PacketConn, tcp net.Listener, upstream string, d Disruption) (protocol.Proxy, error) {
+	if udp == nil || tcp == nil {
+		return nil, fmt.Errorf("UDP connection and TCP listener must be provided")
+	}
+
+	if upstream == "" {
+		return nil, fmt.Errorf("upstream resolver must be provided")
+	}
+
+	if err := validateDisruption(d); err != nil {
+		return nil, err
+	}
+
+	return newProxy(udp, tcp, upstream, protocol.EgressDialer(), d), nil
+}
+
+// Resolver returns the address of the first nameserver in the given resolv.conf file
+func Resolver(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("reading resolver configuration: %w", err)
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) >= 2 && fields[0] == "nameserver" {
+			return net.JoinHostPort(fields[1], "53"), nil
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return "", fmt.Errorf("reading resolver configuration: %w", err)
+	}
+
+	return "", fmt.Errorf("no nameserver found in %q", path)
+}
+
+// matches returns true if the name must be disrupted
+func (p *proxy) matches(name string) bool {
+	if len(p.disruption.Names) == 0 {
+		return true
+	}
+
+	name = strings.ToLower(strings.TrimSuffix(name, "."))
+	for _, pattern := range p.disruption.Names {
+		pattern = strings.ToLower(strings.TrimSuffix(pattern, "."))
+		if name == pattern || strings.HasSuffix(name, "."+pattern) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// delay returns the delay to be introduced to a query
+func (p *proxy) delay() time.Duration {
+	delay := p.disruption.AverageDelay
+	if p.disruption.DelayVariation > 0 {
+		variation := int64(p.disruption.DelayVariation)
+		delay += time.Duration(variation - 2*rand.Int63n(variation))
+	}
+
+	return delay
+}
+
+// errorResponse builds the response to the query for the error specified in the disruption
+func (p *proxy) errorResponse(header dnsmessage.Header, question dnsmessage.Question) ([]byte, error) {
+	rcode := dnsmessage.RCodeSuccess
+	switch p.disruption.Error {
+	case ErrorServFail:
+		rcode = dnsmessage.RCodeServerFailure
+	case ErrorNXDomain:
+		rcode = dnsmessage.RCodeNameError
+	}
+
+	builder := dnsmessage.NewBuilder(nil, dnsmessage.Header{
+		ID:                 header.ID,
+		Response:           true,
+		OpCode:             header.OpCode,
+		RecursionDesired:   header.RecursionDesired,
+		RecursionAvailable: true,
+		RCode:              rcode,
+	})
+
+	if err := builder.StartQuestions(); err != nil {
+		return nil, err
+	}
+
+	if err := builder.Question(question); err != nil {
+		return nil, err
+	}
+
+	return builder.Finish()
+}
+
+// handle applies the disruption to the query and returns the response, either injected or received from the upstream
+func (p *proxy) handle(network string, query []byte) ([]byte, error) {
+	p.metrics.Inc(protocol.MetricRequests)
+
+	var parser dnsmessage.Parser
+	header, err := parser.Start(query)
+	if err != nil {
+		return nil, fmt.Errorf("parsing query: %w", err)
+	}
+
+	question, err := parser.Question()
+	if err != nil {
+		return nil, fmt.Errorf("parsing query: %w", err)
+	}
+
+	if !p.matches(question.Name.String()) {
+		p.metrics.Inc(protocol.MetricRequestsExcluded)
+		return p.forward(network, query)
+	}
+
+	if p.disruption.AverageDelay > 0 {
+		p.metrics.Inc(protocol.MetricRequestsDisrupted)
+		time.Sleep(p.delay())
+	}
+
+	if p.disruption.ErrorRate > 0 && rand.Float32() <= p.disruption.ErrorRate {
+		// count the query only once if it was already delayed
+		if p.disruption.AverageDelay == 0 {
+			p.metrics.Inc(protocol.MetricRequestsDisrupted)
+		}
+		return p.errorResponse(header, question)
+	}
+
+	return p.forward(network, query)
+}
+
+// forward sends the query to the upstream resolver using the given network ("udp" or "tcp") and returns its response
+func (p *proxy) forward(network string, query []byte) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), upstreamTimeout)
+	defer cancel()
+
+	conn, err := p.dialer.DialContext(ctx, network, p.upstream)
+	if err != nil {
+		return nil, fmt.Errorf("connecting to upstream resolver: %w", err)
+	}
+	defer func() {
+		_ = conn.Close()
+	}()
+
+	_ = conn.SetDeadline(time.Now().Add(upstreamTimeout))
+
+	if network == "tcp" {
+		if err = writeTCPMessage(conn, query); err != nil {
+			return nil, err
+		}
+
+		return readTCPMessage(conn)
+	}
+
+	if _, err = conn.Write(query); err != nil {
+		return nil, err
+	}
+
+	response := make([]byte, maxMessageSize)
+	n, err := conn.Read(response)
+	if err != nil {
+		return nil, err
+	}
+
+	return response[:n], nil
+}
+
+// readTCPMessage reads a DNS message prefixed by its length, as sent over TCP
+func readTCPMessage(conn net.Conn) ([]byte, error) {
+	var length uint16
+	if err := binary.Read(conn, binary.BigEndian, &length); err != nil {
+		return nil, err
+	}
+
+	message := make([]byte, length)
+	if _, err := io.ReadFull(conn, message); err != nil {
+		return nil, err
+	}
+
+	return message, nil
+}
+
+// writeTCPMessage writes a DNS message prefixed by its length, as sent over TCP
+func writeTCPMessage(conn net.Conn, message []byte) error {
+	buffer := make([]byte, 2+len(message))
+	binary.BigEndian.PutUint16(buffer, uint16(len(message)))
+	copy(buffer[2:], message)
+
+	_, err := conn.Write(buffer)
+	return err
+}
+
+// isStopped returns true if the proxy was stopped
+func (p *proxy) isStopped() bool {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	return p.stopped
+}
+
+// track adds a connection to the set of open connections. Returns false if the proxy is stopped.
+func (p *proxy) track(conn net.Conn) bool {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.stopped {
+		return false
+	}
+
+	p.conns[conn] = struct{}{}
+	return true
+}
+
+func (p *proxy) untrack(conn net.Conn) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	delete(p.conns, conn)
+}
+
+// serveUDP answers the queries received on the UDP connection until it is closed
+func (p *proxy) serveUDP() error {
+	for {
+		buffer := make([]byte, maxMessageSize)
+		n, addr, err := p.udp.ReadFrom(buffer)
+		if err != nil {
+			if p.isStopped() || errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+
+			return fmt.Errorf("proxy terminated with error: %w", err)
+		}
+
+		go func() {
+			// queries that cannot be answered are dropped, and the client will retry them
+			response, herr := p.handle("udp", buffer[:n])
+			if herr != nil {
+				return
+			}
+
+			_, _ = p.udp.WriteTo(response, addr)
+		}()
+	}
+}
+
+// serveTCPConn answers the queries received on a TCP connection until any side closes it
+func (p *proxy) serveTCPConn(conn net.Conn) {
+	defer func() {
+		p.untrack(conn)
+		_ = conn.Close()
+	}()
+
+	for {
+		query, err := readTCPMessage(conn)
+		if err != nil {
+			return
+		}
+
+		response, err := p.handle("tcp", query)
+		if err != nil {
+			return
+		}
+
+		if err = writeTCPMessage(conn, response); err != nil {
+			return
+		}
+	}
+}
+
+// serveTCP accepts connections on the TCP listener until it is closed
+func (p *proxy) serveTCP() error {
+	for {
+		conn, err := p.tcp.Accept()
+		if err != nil {
+			if p.isStopped() || errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+
+			return fmt.Errorf("proxy terminated with error: %w", err)
+		}
+
+		if !p.track(conn) {
+			_ = conn.Close()
+			return nil
+		}
+
+		go p.serveTCPConn(conn)
+	}
+}
+
+// Start starts the execution of the proxy. Returns when the proxy is stopped or any of its listeners fails.
+func (p *proxy) Start() error {
+	errs := make(chan error, 2)
+	go func() {
+		errs <- p.serveUDP()
+	}()
+	go func() {
+		errs <- p.serveTCP()
+	}()
+
+	// stop serving the other protocol if one of them fails
+	err := <-errs
+	if err != nil {
+		_ = p.Stop()
+	}
+
+	if otherErr := <-errs; err == nil {
+		err = otherErr
+	}
+
+	return err
+}
+
+// Stop stops the execution of the proxy and closes the open connections
+func (p *proxy) Stop() error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.stopped = true
+	for conn := range p.conns {
+		_ = conn.Close()
+	}
+
+	var errs []error
+
+	if err := p.udp.Close(); err != nil && !errors.Is(err, net.ErrClosed) {
+		errs = append(errs, err)
+	}
+
+	if err := p.tcp.Close(); err != nil && !errors.Is(err, net.ErrClosed) {
+		errs = append(errs, err)
+	}
+
+	return multierr.Join(errs...)
+}
+
+// Metrics returns runtime metrics for the proxy.
+func (p *proxy) Metrics() map[string]uint {
+	return p.metrics.Map()
+}
+
+// Force stops the proxy without waiting for connections to drain.
+// In dns this is equivalent to Stop
+func (p *proxy) Force() error {
+	return p.Stop()
+}
@@ -0,0 +1,458 @@
+package dns
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/grafana/xk6-disruptor/pkg/agent/protocol"
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+func Test_Validations(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		title       string
+		disruption  Disruption
+		upstream    string
+		expectError bool
+	}{
+		{
+			title:       "valid defaults",
+			disruption:  Disruption{},
+			upstream:    "127.0.0.1:53",
+			expectError: false,
+		},
+		{
+			title: "valid error",
+			disruption: Disruption{
+				ErrorRate: 0.1,
+				Error:     ErrorNXDomain,
+			},
+			upstream:    "127.0.0.1:53",
+			expectError: false,
+		},
+		{
+			title:       "invalid upstream address",
+			disruption:  Disruption{},
+			upstream:    "",
+			expectError: true,
+		},
+		{
+			title: "variation larger than average delay",
+			disruption: Disruption{
+				AverageDelay:   100,
+				DelayVariation: 200,
+			},
+			upstream:    "127.0.0.1:53",
+			expectError: true,
+		},
+		{
+			title: "invalid error rate",
+			disruption: Disruption{
+				ErrorRate: 1.1,
+				Error:     ErrorServFail,
+			},
+			upstream:    "127.0.0.1:53",
+			expectError: true,
+		},
+		{
+			title: "error rate without error",
+			disruption: Disruption{
+				ErrorRate: 0.1,
+			},
+			upstream:    "127.0.0.1:53",
+			expectError: true,
+		},
+		{
+			title: "invalid error",
+			disruption: Disruption{
+				ErrorRate: 0.1,
+				Error:     "refused",
+			},
+			upstream:    "127.0.0.1:53",
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			udp, err := net.ListenPacket("udp", "127.0.0.1:0")
+			if err != nil {
+				t.Fatalf("failed to create udp listener: %v", err)
+			}
+			defer udp.Close() //nolint:errcheck
+
+			tcp, err := net.Listen("tcp", "127.0.0.1:0")
+			if err != nil {
+				t.Fatalf("failed to create tcp listener: %v", err)
+			}
+			defer tcp.Close() //nolint:errcheck
+
+			_, err = NewProxy(udp, tcp, tc.upstream, tc.disruption)
+			if !tc.expectError && err != nil {
+				t.Errorf("failed: %v", err)
+				return
+			}
+
+			if tc.expectError && err == nil {
+				t.Errorf("should had failed")
+				return
+			}
+		})
+	}
+}
+
+func Test_Resolver(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		title       string
+		config      string
+		expected    string
+		expectError bool
+	}{
+		{
+			title:       "first nameserver",
+			config:      "search default.svc.cluster.local\nnameserver 10.96.0.10\nnameserver 10.96.0.11\n",
+			expected:    "10.96.0.10:53",
+			expectError: false,
+		},
+		{
+			title:       "no nameserver",
+			config:      "search default.svc.cluster.local\n",
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			path := filepath.Join(t.TempDir(), "resolv.conf")
+			if err := os.WriteFile(path, []byte(tc.config), 0o600); err != nil {
+				t.Fatalf("failed writing config: %v", err)
+			}
+
+			resolver, err := Resolver(path)
+			if tc.expectError && err == nil {
+				t.Errorf("should had failed")
+				return
+			}
+
+			if !tc.expectError && err != nil {
+				t.Errorf("failed: %v", err)
+				return
+			}
+
+			if resolver != tc.expected {
+				t.Errorf("expected %q got %q", tc.expected, resolver)
+			}
+		})
+	}
+}
+
+// upstreamAddress is the address returned by the fake upstream resolver for any query
+var upstreamAddress = [4]byte{192, 0, 2, 1}
+
+// answer returns the response of the fake upstream resolver to a query
+func answer(t *testing.T, query []byte) []byte {
+	t.Helper()
+
+	var parser dnsmessage.Parser
+	header, err := parser.Start(query)
+	if err != nil {
+		t.Errorf("upstream failed parsing query: %v", err)
+		return nil
+	}
+
+	question, err := parser.Question()
+	if err != nil {
+		t.Errorf("upstream failed parsing query: %v", err)
+		return nil
+	}
+
+	builder := dnsmessage.NewBuilder(nil, dnsmessage.Header{ID: header.ID, Response: true})
+	_ = builder.StartQuestions()
+	_ = builder.Question(question)
+	_ = builder.StartAnswers()
+	_ = builder.AResource(
+		dnsmessage.ResourceHeader{Name: question.Name, Class: dnsmessage.ClassINET, TTL: 60},
+		dnsmessage.AResource{A: upstreamAddress},
+	)
+
+	response, err := builder.Finish()
+	if err != nil {
+		t.Errorf("upstream failed building response: %v", err)
+	}
+
+	return response
+}
+
+// startUpstream starts a fake resolver listening on UDP and TCP on the same port and returns its address
+func startUpstream(t *testing.T) string {
+	t.Helper()
+
+	udp, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to create upstream udp listener: %v", err)
+	}
+	t.Cleanup(func() { _ = udp.Close() })
+
+	tcp, err := net.Listen("tcp", udp.LocalAddr().String())
+	if err != nil {
+		t.Fatalf("failed to create upstream tcp listener: %v", err)
+	}
+	t.Cleanup(func() { _ = tcp.Close() })
+
+	go func() {
+		buffer := make([]byte, maxMessageSize)
+		for {
+			n, addr, rerr := udp.ReadFrom(buffer)
+			if rerr != nil {
+				return
+			}
+			_, _ = udp.WriteTo(answer(t, buffer[:n]), addr)
+		}
+	}()
+
+	go func() {
+		for {
+			conn, aerr := tcp.Accept()
+			if aerr != nil {
+				return
+			}
+
+			query, rerr := readTCPMessage(conn)
+			if rerr == nil {
+				_ = writeTCPMessage(conn, answer(t, query))
+			}
+			_ = conn.Close()
+		}
+	}()
+
+	return udp.LocalAddr().String()
+}
+
+// query sends a query for an A record of the name to the server and returns the response
+func query(t *testing.T, network string, server string, name string) dnsmessage.Message {
+	t.Helper()
+
+	builder := dnsmessage.NewBuilder(nil, dnsmessage.Header{ID: 1234, RecursionDesired: true})
+	_ = builder.StartQuestions()
+	_ = builder.Question(dnsmessage.Question{
+		Name:  dnsmessage.MustNewName(name),
+		Type:  dnsmessage.TypeA,
+		Class: dnsmessage.ClassINET,
+	})
+	request, err := builder.Finish()
+	if err != nil {
+		t.Fatalf("failed building query: %v", err)
+	}
+
+	conn, err := net.Dial(network, server)
+	if err != nil {
+		t.Fatalf("failed connecting to proxy: %v", err)
+	}
+	defer conn.Close() //nolint:errcheck
+
+	_ = conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+	var response []byte
+	if network == "tcp" {
+		if err = writeTCPMessage(conn, request); err != nil {
+			t.Fatalf("failed sending query: %v", err)
+		}
+
+		response, err = readTCPMessage(conn)
+		if err != nil {
+			t.Fatalf("failed reading response: %v", err)
+		}
+	} else {
+		if _, err = conn.Write(request); err != nil {
+			t.Fatalf("failed sending query: %v", err)
+		}
+
+		response = make([]byte, maxMessageSize)
+		n, rerr := conn.Read(response)
+		if rerr != nil {
+			t.Fatalf("failed reading response: %v", rerr)
+		}
+		response = response[:n]
+	}
+
+	var message dnsmessage.Message
+	if err = message.Unpack(response); err != nil {
+		t.Fatalf("failed parsing response: %v", err)
+	}
+
+	return message
+}
+
+func Test_ProxyHandler(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		title           string
+		disruption      Disruption
+		network         string
+		name            string
+		expectedRCode   dnsmessage.RCode
+		expectedAnswers int
+		expectedMetrics map[string]uint
+	}{
+		{
+			title:           "no disruption",
+			disruption:      Disruption{},
+			network:         "udp",
+			name:            "api.example.com.",
+			expectedRCode:   dnsmessage.RCodeSuccess,
+			expectedAnswers: 1,
+			expectedMetrics: map[string]uint{
+				protocol.MetricRequests:          1,
+				protocol.MetricRequestsExcluded:  0,
+				protocol.MetricRequestsDisrupted: 0,
+			},
+		},
+		{
+			title: "nxdomain",
+			disruption: Disruption{
+				ErrorRate: 1.0,
+				Error:     ErrorNXDomain,
+			},
+			network:         "udp",
+			name:            "api.example.com.",
+			expectedRCode:   dnsmessage.RCodeNameError,
+			expectedAnswers: 0,
+			expectedMetrics: map[string]uint{
+				protocol.MetricRequests:          1,
+				protocol.MetricRequestsExcluded:  0,
+				protocol.MetricRequestsDisrupted: 1,
+			},
+		},
+		{
+			title: "servfail over tcp",
+			disruption: Disruption{
+				ErrorRate: 1.0,
+				Error:     ErrorServFail,
+			},
+			network:         "tcp",
+			name:            "api.example.com.",
+			expectedRCode:   dnsmessage.RCodeServerFailure,
+			expectedAnswers: 0,
+			expectedMetrics: map[string]uint{
+				protocol.MetricRequests:          1,
+				protocol.MetricRequestsExcluded:  0,
+				protocol.MetricRequestsDisrupted: 1,
+			},
+		},
+		{
+			title: "empty answer for matching subdomain",
+			disruption: Disruption{
+				ErrorRate: 1.0,
+				Error:     ErrorEmpty,
+				Names:     []string{"example.com"},
+			},
+			network:         "udp",
+			name:            "api.example.com.",
+			expectedRCode:   dnsmessage.RCodeSuccess,
+			expectedAnswers: 0,
+			expectedMetrics: map[string]uint{
+				protocol.MetricRequests:          1,
+				protocol.MetricRequestsExcluded:  0,
+				protocol.MetricRequestsDisrupted: 1,
+			},
+		},
+		{
+			title: "name not matching",
+			disruption: Disruption{
+				ErrorRate: 1.0,
+				Error:     ErrorNXDomain,
+				Names:     []string{"example.com"},
+			},
+			network:         "tcp",
+			name:            "api.example.org.",
+			expectedRCode:   dnsmessage.RCodeSuccess,
+			expectedAnswers: 1,
+			expectedMetrics: map[string]uint{
+				protocol.MetricRequests:          1,
+				protocol.MetricRequestsExcluded:  1,
+				protocol.MetricRequestsDisrupted: 0,
+			},
+		},
+		{
+			title: "delay",
+			disruption: Disruption{
+				AverageDelay: 10 * time.Millisecond,
+			},
+			network:         "udp",
+			name:            "api.example.com.",
+			expectedRCode:   dnsmessage.RCodeSuccess,
+			expectedAnswers: 1,
+			expectedMetrics: map[string]uint{
+				protocol.MetricRequests:          1,
+				protocol.MetricRequestsExcluded:  0,
+				protocol.MetricRequestsDisrupted: 1,
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			upstream := startUpstream(t)
+
+			udp, err := net.ListenPacket("udp", "127.0.0.1:0")
+			if err != nil {
+				t.Fatalf("failed to create udp listener: %v", err)
+			}
+
+			tcp, err := net.Listen("tcp", udp.LocalAddr().String())
+			if err != nil {
+				t.Fatalf("failed to create tcp listener: %v", err)
+			}
+
+			proxy := newProxy(udp, tcp, upstream, &net.Dialer{}, tc.disruption)
+
+			go func() {
+				if serr := proxy.Start(); serr != nil {
+					t.Errorf("proxy failed: %v", serr)
+				}
+			}()
+			defer func() {
+				_ = proxy.Stop()
+			}()
+
+			response := query(t, tc.network, udp.LocalAddr().String(), tc.name)
+
+			if response.Header.ID != 1234 {
+				t.Errorf("expected response id 1234 got %d", response.Header.ID)
+			}
+
+			if response.Header.RCode != tc.expectedRCode {
+				t.Errorf("expected rcode %s got %s", tc.expectedRCode, response.Header.RCode)
+			}
+
+			if len(response.Answers) != tc.expectedAnswers {
+				t.Errorf("expected %d answers got %d", tc.expectedAnswers, len(response.Answers))
+			}
+
+			if diff := cmp.Diff(tc.expectedMetrics, proxy.Metrics()); diff != "" {
+				t.Errorf("expected metrics do not match returned:\n%s", diff)
+			}
+		})
+	}
+}
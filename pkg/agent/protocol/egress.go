@@ -0,0 +1,102 @@
+package protocol
+
+import (
+	"net"
+	"sync"
+	"syscall"
+)
+
+// EgressMark is the mark set on the connections the proxies open to the original destination of egress traffic.
+// Connections with this mark are not redirected to the proxy, preventing redirection loops.
+const EgressMark = 0xd15
+
+// EgressDialer returns a dialer that sets the EgressMark on the connections it opens
+func EgressDialer() *net.Dialer {
+	return &net.Dialer{
+		Control: func(_, _ string, c syscall.RawConn) error {
+			var markErr error
+			err := c.Control(func(fd uintptr) {
+				markErr = markSocket(fd, EgressMark)
+			})
+			if err != nil {
+				return err
+			}
+
+			return markErr
+		},
+	}
+}
+
+// EgressListener is a net.Listener for connections redirected to the proxy from the OUTPUT chain. It keeps track of
+// the original destination of the accepted connections, so the proxies can forward the requests to it.
+type EgressListener struct {
+	net.Listener
+	mutex        sync.RWMutex
+	destinations map[string]string
+}
+
+// NewEgressListener returns an EgressListener that accepts connections from the given listener
+func NewEgressListener(listener net.Listener) *EgressListener {
+	return &EgressListener{
+		Listener:     listener,
+		destinations: map[string]string{},
+	}
+}
+
+// egressConn is a connection accepted by the EgressListener. Its original destination is forgotten when closed.
+type egressConn struct {
+	*net.TCPConn
+	once    sync.Once
+	release func()
+}
+
+func (c *egressConn) Close() error {
+	c.once.Do(c.release)
+	return c.TCPConn.Close()
+}
+
+// Accept waits for and returns the next connection to the listener. Connections whose original destination cannot
+// be determined are closed.
+func (l *EgressListener) Accept() (net.Conn, error) {
+	for {
+		conn, err := l.Listener.Accept()
+		if err != nil {
+			return nil, err
+		}
+
+		tcpConn, isTCP := conn.(*net.TCPConn)
+		if !isTCP {
+			_ = conn.Close()
+			continue
+		}
+
+		destination, err := originalDestination(tcpConn)
+		if err != nil {
+			_ = conn.Close()
+			continue
+		}
+
+		key := conn.RemoteAddr().String()
+		l.mutex.Lock()
+		l.destinations[key] = destination
+		l.mutex.Unlock()
+
+		return &egressConn{
+			TCPConn: tcpConn,
+			release: func() {
+				l.mutex.Lock()
+				delete(l.destinations, key)
+				l.mutex.Unlock()
+			},
+		}, nil
+	}
+}
+
+// OriginalDestination returns the address the connection from the given remote address was originally sent to
+func (l *EgressListener) OriginalDestination(remoteAddr string) (string, bool) {
+	l.mutex.RLock()
+	defer l.mutex.RUnlock()
+
+	destination, found := l.destinations[remoteAddr]
+	return destination, found
+}
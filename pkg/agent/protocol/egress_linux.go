@@ -0,0 +1,45 @@
+package protocol
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"syscall"
+)
+
+// soOriginalDst is the socket option for retrieving the destination of a connection before it was redirected
+// by netfilter. Defined in linux/netfilter_ipv4.h
+const soOriginalDst = 80
+
+// originalDestination returns the address a redirected connection was originally sent to.
+// Only IPv4 connections are supported.
+func originalDestination(conn *net.TCPConn) (string, error) {
+	raw, err := conn.SyscallConn()
+	if err != nil {
+		return "", err
+	}
+
+	// SO_ORIGINAL_DST returns a sockaddr_in structure, which has the same size than the IPv6Mreq structure
+	var addr *syscall.IPv6Mreq
+	var sockErr error
+	err = raw.Control(func(fd uintptr) {
+		addr, sockErr = syscall.GetsockoptIPv6Mreq(int(fd), syscall.IPPROTO_IP, soOriginalDst)
+	})
+	if err != nil {
+		return "", err
+	}
+	if sockErr != nil {
+		return "", fmt.Errorf("getting original destination: %w", sockErr)
+	}
+
+	// sockaddr_in has the family (2 bytes), the port (2 bytes in network order) and the address (4 bytes)
+	port := int(addr.Multiaddr[2])<<8 | int(addr.Multiaddr[3])
+	ip := net.IPv4(addr.Multiaddr[4], addr.Multiaddr[5], addr.Multiaddr[6], addr.Multiaddr[7])
+
+	return net.JoinHostPort(ip.String(), strconv.Itoa(port)), nil
+}
+
+// markSocket sets the mark of the packets sent through the socket
+func markSocket(fd uintptr, mark int) error {
+	return syscall.SetsockoptInt(int(fd), syscall.SOL_SOCKET, syscall.SO_MARK, mark)
+}
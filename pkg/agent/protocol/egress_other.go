@@ -0,0 +1,19 @@
+//go:build !linux
+
+package protocol
+
+import (
+	"errors"
+	"net"
+)
+
+// errEgressNotSupported is returned when intercepting egress traffic in a platform other than linux
+var errEgressNotSupported = errors.New("egress traffic interception is only supported in linux")
+
+func originalDestination(_ *net.TCPConn) (string, error) {
+	return "", errEgressNotSupported
+}
+
+func markSocket(_ uintptr, _ int) error {
+	return errEgressNotSupported
+}
@@ -6,13 +6,16 @@ import (
 	"fmt"
 	"io"
 	"math/rand"
+	"net"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/grafana/xk6-disruptor/pkg/agent/protocol"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/emptypb"
 )
@@ -40,6 +43,78 @@ type handler struct {
 	disruption  Disruption
 	forwardConn *grpc.ClientConn
 	metrics     *protocol.MetricMap
+	// if not nil, requests are forwarded to the original destination of their connection
+	egress *egressConns
+}
+
+// egressConns maintains the connections to the original destinations of egress requests
+type egressConns struct {
+	listener *protocol.EgressListener
+	mutex    sync.Mutex
+	conns    map[string]*grpc.ClientConn
+}
+
+func newEgressConns(listener *protocol.EgressListener) *egressConns {
+	return &egressConns{
+		listener: listener,
+		conns:    map[string]*grpc.ClientConn{},
+	}
+}
+
+// get returns a connection to the original destination of the stream's connection
+func (e *egressConns) get(ctx context.Context) (*grpc.ClientConn, error) {
+	p, found := peer.FromContext(ctx)
+	if !found {
+		return nil, status.Errorf(codes.Internal, "peer not found in context")
+	}
+
+	destination, found := e.listener.OriginalDestination(p.Addr.String())
+	if !found {
+		return nil, status.Errorf(codes.Unavailable, "original destination not found")
+	}
+
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	if conn, found := e.conns[destination]; found {
+		return conn, nil
+	}
+
+	dialer := protocol.EgressDialer()
+	conn, err := grpc.Dial(
+		destination,
+		grpc.WithInsecure(),
+		grpc.WithContextDialer(func(ctx context.Context, addr string) (net.Conn, error) {
+			return dialer.DialContext(ctx, "tcp", addr)
+		}),
+	)
+	if err != nil {
+		return nil, status.Errorf(codes.Unavailable, "dialing %s: %v", destination, err)
+	}
+
+	e.conns[destination] = conn
+
+	return conn, nil
+}
+
+// close closes all the connections to the original destinations
+func (e *egressConns) close() {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	for destination, conn := range e.conns {
+		_ = conn.Close()
+		delete(e.conns, destination)
+	}
+}
+
+// upstream returns the connection the stream must be forwarded to
+func (h *handler) upstream(ctx context.Context) (*grpc.ClientConn, error) {
+	if h.egress != nil {
+		return h.egress.get(ctx)
+	}
+
+	return h.forwardConn, nil
 }
 
 // contains verifies if a list of strings contains the given string
@@ -101,10 +176,15 @@ func (h *handler) transparentForward(serverStream grpc.ServerStream) error {
 		return status.Errorf(codes.Internal, "ServerTransportStream not exists in context")
 	}
 
+	upstreamConn, err := h.upstream(ctx)
+	if err != nil {
+		return err
+	}
+
 	clientStream, err := grpc.NewClientStream(
 		clientCtx,
 		clientStreamDescForProxy(),
-		h.forwardConn,
+		upstreamConn,
 		fullMethodName,
 	)
 	if err != nil {
@@ -37,22 +37,31 @@ type proxy struct {
 	metrics  *protocol.MetricMap
 }
 
-// NewProxy return a new Proxy
-func NewProxy(listener net.Listener, upstreamAddress string, d Disruption) (protocol.Proxy, error) {
-	if upstreamAddress == "" {
-		return nil, fmt.Errorf("proxy's forwarding address must be provided")
-	}
-
+// validateDisruption checks the disruption is valid
+func validateDisruption(d Disruption) error {
 	if d.DelayVariation > d.AverageDelay {
-		return nil, fmt.Errorf("variation must be less that average delay")
+		return fmt.Errorf("variation must be less that average delay")
 	}
 
 	if d.ErrorRate < 0.0 || d.ErrorRate > 1.0 {
-		return nil, fmt.Errorf("error rate must be in the range [0.0, 1.0]")
+		return fmt.Errorf("error rate must be in the range [0.0, 1.0]")
 	}
 
 	if d.ErrorRate > 0.0 && d.StatusCode == 0 {
-		return nil, fmt.Errorf("status code cannot be 0 (OK)")
+		return fmt.Errorf("status code cannot be 0 (OK)")
+	}
+
+	return nil
+}
+
+// NewProxy return a new Proxy
+func NewProxy(listener net.Listener, upstreamAddress string, d Disruption) (protocol.Proxy, error) {
+	if upstreamAddress == "" {
+		return nil, fmt.Errorf("proxy's forwarding address must be provided")
+	}
+
+	if err := validateDisruption(d); err != nil {
+		return nil, err
 	}
 
 	ctx, cancel := context.WithCancel(context.Background())
@@ -86,6 +95,38 @@ func NewProxy(listener net.Listener, upstreamAddress string, d Disruption) (prot
 	}, nil
 }
 
+// NewEgressProxy returns a new Proxy for the grpc requests sent by the target to external services.
+// Requests are forwarded to their original destination.
+func NewEgressProxy(listener *protocol.EgressListener, d Disruption) (protocol.Proxy, error) {
+	if err := validateDisruption(d); err != nil {
+		return nil, err
+	}
+
+	metrics := protocol.NewMetricMap(
+		protocol.MetricRequests,
+		protocol.MetricRequestsExcluded,
+		protocol.MetricRequestsDisrupted,
+	)
+
+	conns := newEgressConns(listener)
+	handler := &handler{
+		disruption: d,
+		metrics:    metrics,
+		egress:     conns,
+	}
+
+	srv := grpc.NewServer(
+		grpc.UnknownServiceHandler(handler.streamHandler),
+	)
+
+	return &proxy{
+		listener: listener,
+		srv:      srv,
+		cancel:   conns.close,
+		metrics:  metrics,
+	}, nil
+}
+
 // Start starts the execution of the proxy
 func (p *proxy) Start() error {
 	err := p.srv.Serve(p.listener)
@@ -98,8 +139,9 @@ func (p *proxy) Start() error {
 
 // Stop stops the execution of the proxy
 func (p *proxy) Stop() error {
-	p.cancel()
+	// wait for ongoing requests to complete before closing the connections to the upstream
 	p.srv.GracefulStop()
+	p.cancel()
 
 	return nil
 }
@@ -46,16 +46,8 @@ func NewProxy(listener net.Listener, upstreamAddress string, d Disruption) (prot
 		return nil, fmt.Errorf("proxy's forwarding address must be provided")
 	}
 
-	if d.DelayVariation > d.AverageDelay {
-		return nil, fmt.Errorf("variation must be less that average delay")
-	}
-
-	if d.ErrorRate < 0.0 || d.ErrorRate > 1.0 {
-		return nil, fmt.Errorf("error rate must be in the range [0.0, 1.0]")
-	}
-
-	if d.ErrorRate > 0.0 && d.ErrorCode == 0 {
-		return nil, fmt.Errorf("error code must be a valid http error code")
+	if err := validateDisruption(d); err != nil {
+		return nil, err
 	}
 
 	upstreamURL, err := url.Parse(upstreamAddress)
@@ -63,22 +55,63 @@ func NewProxy(listener net.Listener, upstreamAddress string, d Disruption) (prot
 		return nil, err
 	}
 
-	metrics := protocol.NewMetricMap(supportedMetrics()...)
-
 	handler := &httpHandler{
 		upstreamURL: *upstreamURL,
 		disruption:  d,
-		metrics:     metrics,
+		metrics:     protocol.NewMetricMap(supportedMetrics()...),
+		client:      http.DefaultClient,
+	}
+
+	return newProxy(listener, handler), nil
+}
+
+// NewEgressProxy returns a new Proxy for the HTTP requests sent by the target to external services.
+// Requests are forwarded to their original destination, preserving their Host header.
+func NewEgressProxy(listener *protocol.EgressListener, d Disruption) (protocol.Proxy, error) {
+	if err := validateDisruption(d); err != nil {
+		return nil, err
+	}
+
+	handler := &httpHandler{
+		disruption: d,
+		metrics:    protocol.NewMetricMap(supportedMetrics()...),
+		egress:     listener,
+		client: &http.Client{
+			Transport: &http.Transport{
+				DialContext: protocol.EgressDialer().DialContext,
+			},
+		},
+	}
+
+	return newProxy(listener, handler), nil
+}
+
+// validateDisruption checks the disruption is valid
+func validateDisruption(d Disruption) error {
+	if d.DelayVariation > d.AverageDelay {
+		return fmt.Errorf("variation must be less that average delay")
+	}
+
+	if d.ErrorRate < 0.0 || d.ErrorRate > 1.0 {
+		return fmt.Errorf("error rate must be in the range [0.0, 1.0]")
 	}
 
+	if d.ErrorRate > 0.0 && d.ErrorCode == 0 {
+		return fmt.Errorf("error code must be a valid http error code")
+	}
+
+	return nil
+}
+
+func newProxy(listener net.Listener, handler *httpHandler) *proxy {
 	return &proxy{
 		listener:   listener,
-		disruption: d,
-		metrics:    metrics,
+		disruption: handler.disruption,
+		metrics:    handler.metrics,
 		srv: &http.Server{
 			Handler: handler,
 		},
-	}, nil
+	}
 }
 
 // httpHandler implements a http.Handler for disrupting request to a upstream server
@@ -86,6 +119,9 @@ type httpHandler struct {
 	upstreamURL url.URL
 	disruption  Disruption
 	metrics     *protocol.MetricMap
+	client      *http.Client
+	// if not nil, requests are forwarded to the original destination of their connection
+	egress *protocol.EgressListener
 }
 
 // isExcluded checks whether a request should be proxied through without any kind of modification whatsoever.
@@ -105,12 +141,24 @@ func (h *httpHandler) forward(rw http.ResponseWriter, req *http.Request, delay t
 	timer := time.After(delay)
 
 	upstreamReq := req.Clone(context.Background())
-	upstreamReq.Host = h.upstreamURL.Host
-	upstreamReq.URL.Host = h.upstreamURL.Host
-	upstreamReq.URL.Scheme = h.upstreamURL.Scheme
+	if h.egress != nil {
+		destination, found := h.egress.OriginalDestination(req.RemoteAddr)
+		if !found {
+			<-timer
+			rw.WriteHeader(http.StatusBadGateway)
+			_, _ = fmt.Fprint(rw, "original destination not found")
+			return
+		}
+		upstreamReq.URL.Host = destination
+		upstreamReq.URL.Scheme = "http"
+	} else {
+		upstreamReq.Host = h.upstreamURL.Host
+		upstreamReq.URL.Host = h.upstreamURL.Host
+		upstreamReq.URL.Scheme = h.upstreamURL.Scheme
+	}
 	upstreamReq.RequestURI = "" // It is an error to set this field in an HTTP client request.
 
-	response, err := http.DefaultClient.Do(upstreamReq)
+	response, err := h.client.Do(upstreamReq)
 	<-timer
 	if err != nil {
 		rw.WriteHeader(http.StatusBadGateway)
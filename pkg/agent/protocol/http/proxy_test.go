@@ -307,6 +307,7 @@ func Test_ProxyHandler(t *testing.T) {
 				upstreamURL: *upstreamURL,
 				disruption:  tc.disruption,
 				metrics:     protocol.NewMetricMap(supportedMetrics()...),
+				client:      http.DefaultClient,
 			}
 
 			proxyServer := httptest.NewServer(handler)
@@ -400,6 +401,7 @@ func Test_Metrics(t *testing.T) {
 				upstreamURL: *upstreamURL,
 				disruption:  tc.config,
 				metrics:     metrics,
+				client:      http.DefaultClient,
 			}
 
 			proxyServer := httptest.NewServer(handler)
@@ -5,8 +5,10 @@ package protocol
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"time"
 
 	"github.com/grafana/xk6-disruptor/pkg/runtime"
@@ -49,34 +51,59 @@ const (
 	MetricRequestsDisrupted = "requests_disrupted"
 )
 
+// Summary summarizes the requests handled by the proxy during a disruption
+type Summary struct {
+	// Requests is the total number of requests received by the proxy
+	Requests uint `json:"requests"`
+	// Excluded is the number of requests passed through due to exclusion rules
+	Excluded uint `json:"excluded"`
+	// Disrupted is the number of requests that the proxy altered in any way
+	Disrupted uint `json:"disrupted"`
+}
+
+// summaryLine is the line of JSON printed at the end of a disruption. The disruptor looks for it in the output of
+// the agent.
+type summaryLine struct {
+	Summary Summary `json:"summary"`
+}
+
 // disruptor is an instance of a Disruptor that applies a disruption
 // to a target
 type disruptor struct {
 	proxy      Proxy
 	redirector TrafficRedirector
 	executor   runtime.Executor
+	output     io.Writer
 }
 
 // NewDisruptor creates a new instance of a Disruptor that applies a disruptions to a target
-// The configuration controls how the disruptor operates.
+// The configuration controls how the disruptor operates. When the disruption ends, either because its duration
+// elapsed, it was cancelled or the proxy failed, a summary of the requests handled by the proxy is printed to the
+// output as a line of JSON.
 func NewDisruptor(
 	executor runtime.Executor,
 	proxy Proxy,
 	redirector TrafficRedirector,
+	output io.Writer,
 ) (Disruptor, error) {
 	if proxy == nil {
 		return nil, fmt.Errorf("proxy cannot be null")
 	}
 
+	if output == nil {
+		output = io.Discard
+	}
+
 	return &disruptor{
 		proxy:      proxy,
 		executor:   executor,
 		redirector: redirector,
+		output:     output,
 	}, nil
 }
 
 // Apply applies the Disruption to the target system
-func (d *disruptor) Apply(ctx context.Context, duration time.Duration) error {
+func (d *disruptor) Apply(ctx context.Context, duration time.Duration) (err error) {
 	if duration < time.Second {
 		return fmt.Errorf("duration must be at least one second")
 	}
@@ -91,6 +118,13 @@ func (d *disruptor) Apply(ctx context.Context, duration time.Duration) error {
 		_ = d.proxy.Stop()
 	}()
 
+	// print the summary on every exit, so the requests handled until the disruption is cancelled are reported too
+	defer func() {
+		if summaryErr := d.printSummary(d.proxy.Metrics()); summaryErr != nil && err == nil {
+			err = summaryErr
+		}
+	}()
+
 	if err := d.redirector.Start(); err != nil {
 		return fmt.Errorf(" failed traffic redirection: %w", err)
 	}
@@ -119,6 +153,23 @@ func (d *disruptor) Apply(ctx context.Context, duration time.Duration) error {
 	}
 }
 
+// printSummary prints the summary of the requests handled by the proxy as a line of JSON
+func (d *disruptor) printSummary(metrics map[string]uint) error {
+	summary := summaryLine{
+		Summary: Summary{
+			Requests:  metrics[MetricRequests],
+			Excluded:  metrics[MetricRequestsExcluded],
+			Disrupted: metrics[MetricRequestsDisrupted],
+		},
+	}
+
+	if err := json.NewEncoder(d.output).Encode(summary); err != nil {
+		return fmt.Errorf("printing summary: %w", err)
+	}
+
+	return nil
+}
+
 // noop is a no-op traffic redirector
 type noop struct{}
 
@@ -0,0 +1,131 @@
+package protocol_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/grafana/xk6-disruptor/pkg/agent/protocol"
+	"github.com/grafana/xk6-disruptor/pkg/runtime"
+)
+
+// errProxy is returned by a fakeProxy that fails
+var errProxy = errors.New("proxy failed")
+
+// fakeProxy is a Proxy that returns the given metrics, and the given error when started
+type fakeProxy struct {
+	metrics map[string]uint
+	err     error
+}
+
+func (p *fakeProxy) Start() error {
+	return p.err
+}
+
+func (p *fakeProxy) Stop() error {
+	return nil
+}
+
+func (p *fakeProxy) Metrics() map[string]uint {
+	return p.metrics
+}
+
+func (p *fakeProxy) Force() error {
+	return nil
+}
+
+func Test_DisruptorSummary(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		title       string
+		metrics     map[string]uint
+		proxyErr    error
+		cancel      bool
+		expectedErr error
+		expected    protocol.Summary
+	}{
+		{
+			title: "requests received",
+			metrics: map[string]uint{
+				protocol.MetricRequests:          10,
+				protocol.MetricRequestsExcluded:  2,
+				protocol.MetricRequestsDisrupted: 5,
+			},
+			expected: protocol.Summary{Requests: 10, Excluded: 2, Disrupted: 5},
+		},
+		{
+			title: "no requests",
+			metrics: map[string]uint{
+				protocol.MetricRequests: 0,
+			},
+			expectedErr: protocol.ErrNoRequests,
+			expected:    protocol.Summary{},
+		},
+		{
+			title: "disruption cancelled",
+			metrics: map[string]uint{
+				protocol.MetricRequests:          4,
+				protocol.MetricRequestsDisrupted: 1,
+			},
+			cancel:      true,
+			expectedErr: context.Canceled,
+			expected:    protocol.Summary{Requests: 4, Disrupted: 1},
+		},
+		{
+			title: "proxy failed",
+			metrics: map[string]uint{
+				protocol.MetricRequests: 3,
+			},
+			proxyErr:    errProxy,
+			expectedErr: errProxy,
+			expected:    protocol.Summary{Requests: 3},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			output := &bytes.Buffer{}
+			disruptor, err := protocol.NewDisruptor(
+				runtime.NewFakeExecutor(nil, nil),
+				&fakeProxy{metrics: tc.metrics, err: tc.proxyErr},
+				protocol.NoopTrafficRedirector(),
+				output,
+			)
+			if err != nil {
+				t.Fatalf("unexpected error : %v", err)
+			}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			if tc.cancel {
+				cancel()
+			}
+
+			err = disruptor.Apply(ctx, time.Second)
+			if !errors.Is(err, tc.expectedErr) {
+				t.Fatalf("expected error %v got %v", tc.expectedErr, err)
+			}
+
+			line := struct {
+				Summary protocol.Summary `json:"summary"`
+			}{}
+			err = json.Unmarshal(output.Bytes(), &line)
+			if err != nil {
+				t.Fatalf("summary is not valid JSON %q: %v", output.String(), err)
+			}
+
+			if diff := cmp.Diff(tc.expected, line.Summary); diff != "" {
+				t.Errorf("summary does not match expected:\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,243 @@
+// Package tcp implements a proxy that applies disruptions to TCP connections
+package tcp
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/grafana/xk6-disruptor/pkg/agent/protocol"
+)
+
+// Disruption specifies disruptions in TCP connections
+type Disruption struct {
+	// Average delay introduced when establishing connections
+	AverageDelay time.Duration
+	// Variation in the delay (with respect of the average delay)
+	DelayVariation time.Duration
+	// Fraction (in the range 0.0 to 1.0) of connections that will be reset
+	ErrorRate float32
+}
+
+// proxy defines the parameters used by the proxy for processing TCP connections and its execution state
+type proxy struct {
+	listener   net.Listener
+	disruption Disruption
+	dialer     *net.Dialer
+	// upstream returns the address a connection must be forwarded to
+	upstream func(conn net.Conn) (string, error)
+	metrics  *protocol.MetricMap
+	mutex    sync.Mutex
+	stopped  bool
+	conns    map[net.Conn]struct{}
+}
+
+// validateDisruption checks the disruption is valid
+func validateDisruption(d Disruption) error {
+	if d.DelayVariation > d.AverageDelay {
+		return fmt.Errorf("variation must be less that average delay")
+	}
+
+	if d.ErrorRate < 0.0 || d.ErrorRate > 1.0 {
+		return fmt.Errorf("error rate must be in the range [0.0, 1.0]")
+	}
+
+	return nil
+}
+
+func newProxy(
+	listener net.Listener,
+	dialer *net.Dialer,
+	upstream func(net.Conn) (string, error),
+	d Disruption,
+) *proxy {
+	return &proxy{
+		listener:   listener,
+		disruption: d,
+		dialer:     dialer,
+		upstream:   upstream,
+		metrics: protocol.NewMetricMap(
+			protocol.MetricRequests,
+			protocol.MetricRequestsDisrupted,
+		),
+		conns: map[net.Conn]struct{}{},
+	}
+}
+
+// NewProxy returns a new Proxy for TCP connections
+func NewProxy(listener net.Listener, upstreamAddress string, d Disruption) (protocol.Proxy, error) {
+	if upstreamAddress == "" {
+		return nil, fmt.Errorf("proxy's forwarding address must be provided")
+	}
+
+	if err := validateDisruption(d); err != nil {
+		return nil, err
+	}
+
+	upstream := func(_ net.Conn) (string, error) {
+		return upstreamAddress, nil
+	}
+
+	return newProxy(listener, &net.Dialer{}, upstream, d), nil
+}
+
+// NewEgressProxy returns a new Proxy for the TCP connections opened by the target to external services.
+// Connections are forwarded to their original destination.
+func NewEgressProxy(listener *protocol.EgressListener, d Disruption) (protocol.Proxy, error) {
+	if err := validateDisruption(d); err != nil {
+		return nil, err
+	}
+
+	upstream := func(conn net.Conn) (string, error) {
+		destination, found := listener.OriginalDestination(conn.RemoteAddr().String())
+		if !found {
+			return "", fmt.Errorf("original destination not found")
+		}
+
+		return destination, nil
+	}
+
+	return newProxy(listener, protocol.EgressDialer(), upstream, d), nil
+}
+
+// track adds a connection to the set of open connections. Returns false if the proxy is stopped.
+func (p *proxy) track(conn net.Conn) bool {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.stopped {
+		return false
+	}
+
+	p.conns[conn] = struct{}{}
+	return true
+}
+
+func (p *proxy) untrack(conn net.Conn) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	delete(p.conns, conn)
+}
+
+// reset closes the connection sending a RST instead of a FIN, if supported by the connection
+func reset(conn net.Conn) {
+	if lingerer, ok := conn.(interface{ SetLinger(int) error }); ok {
+		_ = lingerer.SetLinger(0)
+	}
+
+	_ = conn.Close()
+}
+
+// handle applies the disruption to the connection and then forwards it to the upstream
+func (p *proxy) handle(conn net.Conn) {
+	defer func() {
+		p.untrack(conn)
+		_ = conn.Close()
+	}()
+
+	p.metrics.Inc(protocol.MetricRequests)
+
+	if p.disruption.ErrorRate > 0 && rand.Float32() <= p.disruption.ErrorRate {
+		p.metrics.Inc(protocol.MetricRequestsDisrupted)
+		reset(conn)
+		return
+	}
+
+	if p.disruption.AverageDelay > 0 {
+		p.metrics.Inc(protocol.MetricRequestsDisrupted)
+
+		delay := p.disruption.AverageDelay
+		if p.disruption.DelayVariation > 0 {
+			variation := int64(p.disruption.DelayVariation)
+			delay += time.Duration(variation - 2*rand.Int63n(variation))
+		}
+		time.Sleep(delay)
+	}
+
+	address, err := p.upstream(conn)
+	if err != nil {
+		reset(conn)
+		return
+	}
+
+	upstream, err := p.dialer.Dial("tcp", address)
+	if err != nil {
+		reset(conn)
+		return
+	}
+	defer func() {
+		_ = upstream.Close()
+	}()
+
+	// copy data in both directions until any of the sides closes the connection
+	done := make(chan struct{}, 2)
+	go func() {
+		_, _ = io.Copy(upstream, conn)
+		done <- struct{}{}
+	}()
+	go func() {
+		_, _ = io.Copy(conn, upstream)
+		done <- struct{}{}
+	}()
+
+	<-done
+}
+
+// Start starts the execution of the proxy
+func (p *proxy) Start() error {
+	for {
+		conn, err := p.listener.Accept()
+		if err != nil {
+			p.mutex.Lock()
+			stopped := p.stopped
+			p.mutex.Unlock()
+
+			if stopped || errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+
+			return fmt.Errorf("proxy terminated with error: %w", err)
+		}
+
+		if !p.track(conn) {
+			_ = conn.Close()
+			return nil
+		}
+
+		go p.handle(conn)
+	}
+}
+
+// Stop stops the execution of the proxy and closes the open connections
+func (p *proxy) Stop() error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.stopped = true
+	for conn := range p.conns {
+		_ = conn.Close()
+	}
+
+	err := p.listener.Close()
+	if err != nil && !errors.Is(err, net.ErrClosed) {
+		return err
+	}
+
+	return nil
+}
+
+// Metrics returns runtime metrics for the proxy.
+func (p *proxy) Metrics() map[string]uint {
+	return p.metrics.Map()
+}
+
+// Force stops the proxy without waiting for connections to drain.
+// In tcp this is equivalent to Stop
+func (p *proxy) Force() error {
+	return p.Stop()
+}
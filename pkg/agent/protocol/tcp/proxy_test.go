@@ -0,0 +1,224 @@
+package tcp
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/grafana/xk6-disruptor/pkg/agent/protocol"
+)
+
+func Test_Validations(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		title       string
+		disruption  Disruption
+		upstream    string
+		expectError bool
+	}{
+		{
+			title:       "valid defaults",
+			disruption:  Disruption{},
+			upstream:    ":8080",
+			expectError: false,
+		},
+		{
+			title:       "invalid upstream address",
+			disruption:  Disruption{},
+			upstream:    "",
+			expectError: true,
+		},
+		{
+			title: "variation larger than average delay",
+			disruption: Disruption{
+				AverageDelay:   100,
+				DelayVariation: 200,
+			},
+			upstream:    ":8080",
+			expectError: true,
+		},
+		{
+			title: "invalid error rate",
+			disruption: Disruption{
+				ErrorRate: 1.1,
+			},
+			upstream:    ":8080",
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			listener, err := net.Listen("tcp", "127.0.0.1:0")
+			if err != nil {
+				t.Fatalf("failed to create listener: %v", err)
+			}
+			defer listener.Close()
+
+			_, err = NewProxy(listener, tc.upstream, tc.disruption)
+			if !tc.expectError && err != nil {
+				t.Errorf("unexpected error creating proxy: %v", err)
+			}
+
+			if tc.expectError && err == nil {
+				t.Errorf("should had failed creating proxy")
+			}
+		})
+	}
+}
+
+// startEchoServer starts a server that echoes the data it receives and returns its address
+func startEchoServer(t *testing.T) string {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to create listener: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = listener.Close()
+	})
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				_, _ = io.Copy(conn, conn)
+			}()
+		}
+	}()
+
+	return listener.Addr().String()
+}
+
+// ping sends a message to the echo server and checks the response
+func ping(address string) error {
+	conn, err := net.Dial("tcp", address)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	message := []byte("ping")
+	_, err = conn.Write(message)
+	if err != nil {
+		return err
+	}
+
+	buffer := make([]byte, len(message))
+	_, err = io.ReadFull(conn, buffer)
+	if err != nil {
+		return err
+	}
+
+	if string(buffer) != string(message) {
+		return fmt.Errorf("expected %q got %q", message, buffer)
+	}
+
+	return nil
+}
+
+func Test_ProxyTCP(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		title           string
+		disruption      Disruption
+		expectError     bool
+		minDuration     time.Duration
+		expectedMetrics map[string]uint
+	}{
+		{
+			title:       "no disruption",
+			disruption:  Disruption{},
+			expectError: false,
+			expectedMetrics: map[string]uint{
+				protocol.MetricRequests:          1,
+				protocol.MetricRequestsDisrupted: 0,
+			},
+		},
+		{
+			title: "delay",
+			disruption: Disruption{
+				AverageDelay: 100 * time.Millisecond,
+			},
+			expectError: false,
+			minDuration: 100 * time.Millisecond,
+			expectedMetrics: map[string]uint{
+				protocol.MetricRequests:          1,
+				protocol.MetricRequestsDisrupted: 1,
+			},
+		},
+		{
+			title: "reset",
+			disruption: Disruption{
+				ErrorRate: 1.0,
+			},
+			expectError: true,
+			expectedMetrics: map[string]uint{
+				protocol.MetricRequests:          1,
+				protocol.MetricRequestsDisrupted: 1,
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			upstream := startEchoServer(t)
+
+			listener, err := net.Listen("tcp", "127.0.0.1:0")
+			if err != nil {
+				t.Fatalf("failed to create listener: %v", err)
+			}
+
+			proxy, err := NewProxy(listener, upstream, tc.disruption)
+			if err != nil {
+				t.Fatalf("failed to create proxy: %v", err)
+			}
+
+			go func() {
+				if serr := proxy.Start(); serr != nil {
+					t.Logf("failed to start proxy: %v", serr)
+				}
+			}()
+			defer func() {
+				_ = proxy.Stop()
+			}()
+
+			start := time.Now()
+			err = ping(listener.Addr().String())
+
+			if !tc.expectError && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if tc.expectError && err == nil {
+				t.Fatalf("should had failed")
+			}
+
+			if elapsed := time.Since(start); elapsed < tc.minDuration {
+				t.Errorf("expected a delay of at least %s got %s", tc.minDuration, elapsed)
+			}
+
+			if diff := cmp.Diff(tc.expectedMetrics, proxy.Metrics()); diff != "" {
+				t.Errorf("expected metrics do not match returned:\n%s", diff)
+			}
+		})
+	}
+}
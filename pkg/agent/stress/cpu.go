@@ -0,0 +1,104 @@
+// Package stress implements disruptors that consume resources such as CPU and memory in the target
+// where the agent runs.
+package stress
+
+import (
+	"context"
+	"fmt"
+	goruntime "runtime"
+	"sync"
+	"time"
+
+	"github.com/grafana/xk6-disruptor/pkg/agent"
+)
+
+// loadPeriod is the period in which each core is kept busy for the fraction of time given by the load
+const loadPeriod = 100 * time.Millisecond
+
+// CPUStress specifies the CPU load to be generated
+type CPUStress struct {
+	// Number of cores to keep busy
+	Cores uint
+	// Percentage (in the range 1 to 100) of the time each core is kept busy
+	Load uint
+}
+
+// cpuStressor is an instance of a Disruptor that keeps a number of cores busy
+type cpuStressor struct {
+	stress CPUStress
+}
+
+// NewCPUStressor returns a Disruptor that generates the given CPU load
+func NewCPUStressor(stress CPUStress) (agent.Disruptor, error) {
+	if stress.Cores == 0 {
+		return nil, fmt.Errorf("number of cores must be greater than zero")
+	}
+
+	if stress.Load == 0 || stress.Load > 100 {
+		return nil, fmt.Errorf("load must be in the range [1, 100]")
+	}
+
+	return &cpuStressor{
+		stress: stress,
+	}, nil
+}
+
+// burn keeps a core busy for the given percentage of the time until the context is done
+func burn(ctx context.Context, load uint) {
+	// ensure each worker is scheduled in its own thread
+	goruntime.LockOSThread()
+	defer goruntime.UnlockOSThread()
+
+	busy := loadPeriod * time.Duration(load) / 100
+	for {
+		start := time.Now()
+		for time.Since(start) < busy {
+			select {
+			case <-ctx.Done():
+				return
+			default:
+			}
+		}
+
+		if busy == loadPeriod {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(loadPeriod - busy):
+		}
+	}
+}
+
+// Apply keeps the cores busy until the duration expires or the context is cancelled
+func (s *cpuStressor) Apply(ctx context.Context, duration time.Duration) error {
+	if duration < time.Second {
+		return fmt.Errorf("duration must be at least one second")
+	}
+
+	burnCtx, cancel := context.WithCancel(ctx)
+
+	var wg sync.WaitGroup
+	for i := uint(0); i < s.stress.Cores; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			burn(burnCtx, s.stress.Load)
+		}()
+	}
+
+	// stop workers on termination
+	defer func() {
+		cancel()
+		wg.Wait()
+	}()
+
+	select {
+	case <-time.After(duration):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
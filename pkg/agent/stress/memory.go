@@ -0,0 +1,133 @@
+package stress
+
+import (
+	"context"
+	"fmt"
+	"os"
+	goruntime "runtime"
+	"runtime/debug"
+	"time"
+
+	"github.com/grafana/xk6-disruptor/pkg/agent"
+)
+
+// rampStep is the interval between the allocations made while ramping up the memory
+const rampStep = 100 * time.Millisecond
+
+// MemoryStress specifies the memory to be allocated
+type MemoryStress struct {
+	// Number of bytes to allocate
+	Bytes uint64
+	// Time taken to reach the number of bytes. If zero, the memory is allocated at once.
+	Ramp time.Duration
+	// Keep allocating memory after reaching the number of bytes, until the process is killed by the OOM killer
+	// or the duration expires
+	OOM bool
+}
+
+// memoryStressor is an instance of a Disruptor that allocates memory
+type memoryStressor struct {
+	stress MemoryStress
+}
+
+// NewMemoryStressor returns a Disruptor that allocates the given amount of memory
+func NewMemoryStressor(stress MemoryStress) (agent.Disruptor, error) {
+	if stress.Bytes == 0 {
+		return nil, fmt.Errorf("amount of memory must be greater than zero")
+	}
+
+	if stress.Ramp < 0 {
+		return nil, fmt.Errorf("ramp must be a positive duration")
+	}
+
+	return &memoryStressor{
+		stress: stress,
+	}, nil
+}
+
+// allocate returns a buffer of the given size whose pages have been touched, so they are backed by physical memory
+func allocate(size uint64) []byte {
+	buffer := make([]byte, size)
+	page := os.Getpagesize()
+	for i := 0; i < len(buffer); i += page {
+		buffer[i] = 1
+	}
+
+	return buffer
+}
+
+// hold allocates the memory, ramping it up if requested, and holds it until the duration expires or the context
+// is cancelled
+func (s *memoryStressor) hold(ctx context.Context, duration time.Duration) error {
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+
+	ticker := time.NewTicker(rampStep)
+	defer ticker.Stop()
+
+	// allocate the memory in chunks at each step of the ramp
+	steps := uint64(s.stress.Ramp / rampStep)
+	if steps == 0 {
+		steps = 1
+	}
+	chunk := s.stress.Bytes / steps
+	if chunk == 0 {
+		chunk = s.stress.Bytes
+	}
+
+	// after reaching the number of bytes, the OOM mode keeps allocating a fraction of it at each step
+	oomChunk := s.stress.Bytes / 10
+	if oomChunk < uint64(os.Getpagesize()) {
+		oomChunk = uint64(os.Getpagesize())
+	}
+
+	buffers := [][]byte{}
+	allocated := uint64(0)
+
+	// ensure the buffers are not collected before the stress ends
+	defer func() {
+		goruntime.KeepAlive(buffers)
+	}()
+
+	for {
+		size := uint64(0)
+		switch {
+		case allocated < s.stress.Bytes:
+			size = chunk
+			if remaining := s.stress.Bytes - allocated; size > remaining {
+				size = remaining
+			}
+		case s.stress.OOM:
+			size = oomChunk
+		}
+
+		if size > 0 {
+			buffers = append(buffers, allocate(size))
+			allocated += size
+		}
+
+		select {
+		case <-timer.C:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Apply allocates the memory for the given duration and returns it to the OS afterwards
+func (s *memoryStressor) Apply(ctx context.Context, duration time.Duration) error {
+	if duration < time.Second {
+		return fmt.Errorf("duration must be at least one second")
+	}
+
+	if s.stress.Ramp >= duration {
+		return fmt.Errorf("ramp must be shorter than the duration")
+	}
+
+	err := s.hold(ctx, duration)
+	debug.FreeOSMemory()
+
+	return err
+}
@@ -0,0 +1,160 @@
+package stress
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/grafana/xk6-disruptor/pkg/agent"
+)
+
+func Test_Stressors(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		title       string
+		build       func() (agent.Disruptor, error)
+		duration    time.Duration
+		cancel      bool
+		expectError bool
+		expectedErr error
+	}{
+		{
+			title: "cpu stress",
+			build: func() (agent.Disruptor, error) {
+				return NewCPUStressor(CPUStress{Cores: 1, Load: 100})
+			},
+			duration: time.Second,
+		},
+		{
+			title: "cpu stress cancelled",
+			build: func() (agent.Disruptor, error) {
+				return NewCPUStressor(CPUStress{Cores: 1, Load: 100})
+			},
+			duration:    10 * time.Second,
+			cancel:      true,
+			expectError: true,
+			expectedErr: context.Canceled,
+		},
+		{
+			title: "cpu stress with invalid duration",
+			build: func() (agent.Disruptor, error) {
+				return NewCPUStressor(CPUStress{Cores: 1, Load: 100})
+			},
+			duration:    0,
+			expectError: true,
+		},
+		{
+			title: "cpu stress with partial load",
+			build: func() (agent.Disruptor, error) {
+				return NewCPUStressor(CPUStress{Cores: 2, Load: 50})
+			},
+			duration: time.Second,
+		},
+		{
+			title: "cpu stress with partial load cancelled",
+			build: func() (agent.Disruptor, error) {
+				return NewCPUStressor(CPUStress{Cores: 1, Load: 20})
+			},
+			duration:    10 * time.Second,
+			cancel:      true,
+			expectError: true,
+			expectedErr: context.Canceled,
+		},
+		{
+			title: "memory stress",
+			build: func() (agent.Disruptor, error) {
+				return NewMemoryStressor(MemoryStress{Bytes: 1024 * 1024})
+			},
+			duration: time.Second,
+		},
+		{
+			title: "memory stress with ramp",
+			build: func() (agent.Disruptor, error) {
+				return NewMemoryStressor(MemoryStress{Bytes: 1024 * 1024, Ramp: 500 * time.Millisecond})
+			},
+			duration: time.Second,
+		},
+		{
+			title: "memory stress with ramp longer than duration",
+			build: func() (agent.Disruptor, error) {
+				return NewMemoryStressor(MemoryStress{Bytes: 1024 * 1024, Ramp: 2 * time.Second})
+			},
+			duration:    time.Second,
+			expectError: true,
+		},
+		{
+			title: "memory stress past the amount",
+			build: func() (agent.Disruptor, error) {
+				return NewMemoryStressor(MemoryStress{Bytes: 1024 * 1024, OOM: true})
+			},
+			duration: time.Second,
+		},
+		{
+			title: "memory stress cancelled",
+			build: func() (agent.Disruptor, error) {
+				return NewMemoryStressor(MemoryStress{Bytes: 1024 * 1024})
+			},
+			duration:    10 * time.Second,
+			cancel:      true,
+			expectError: true,
+			expectedErr: context.Canceled,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			stressor, err := tc.build()
+			if err != nil {
+				t.Fatalf("failed creating stressor: %v", err)
+			}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			if tc.cancel {
+				go func() {
+					time.Sleep(time.Second)
+					cancel()
+				}()
+			}
+
+			err = stressor.Apply(ctx, tc.duration)
+			if tc.expectError && err == nil {
+				t.Fatalf("should had failed")
+			}
+
+			if !tc.expectError && err != nil {
+				t.Fatalf("failed: %v", err)
+			}
+
+			if tc.expectedErr != nil && !errors.Is(err, tc.expectedErr) {
+				t.Fatalf("expected %v got %v", tc.expectedErr, err)
+			}
+		})
+	}
+}
+
+func Test_Validation(t *testing.T) {
+	t.Parallel()
+
+	if _, err := NewCPUStressor(CPUStress{}); err == nil {
+		t.Errorf("cpu stressor without cores should had failed")
+	}
+
+	if _, err := NewCPUStressor(CPUStress{Cores: 1, Load: 101}); err == nil {
+		t.Errorf("cpu stressor with load over 100 should had failed")
+	}
+
+	if _, err := NewMemoryStressor(MemoryStress{}); err == nil {
+		t.Errorf("memory stressor without bytes should had failed")
+	}
+
+	if _, err := NewMemoryStressor(MemoryStress{Bytes: 1024, Ramp: -time.Second}); err == nil {
+		t.Errorf("memory stressor with negative ramp should had failed")
+	}
+}
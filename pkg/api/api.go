@@ -15,6 +15,7 @@ import (
 	"github.com/grafana/xk6-disruptor/pkg/disruptors"
 	"github.com/grafana/xk6-disruptor/pkg/kubernetes"
 	"go.k6.io/k6/js/common"
+	"go.k6.io/k6/js/modules"
 )
 
 // TODO: call directly Convert from API methods
@@ -40,230 +41,1425 @@ func buildObject(rt *goja.Runtime, value interface{}) (*goja.Object, error) {
 	return obj, nil
 }
 
-// jsDisruptor implements the JS interface for Disruptor
-type jsDisruptor struct {
+// jsCaller calls the methods of a disruptor on behalf of its JS object
+type jsCaller struct {
 	ctx context.Context // this context controls the object's lifecycle
 	rt  *goja.Runtime
+	// registerCallback registers callbacks in the event loop of the VU for resolving the promises returned by the
+	// methods. If it is nil, the methods are called synchronously.
+	registerCallback func() func(func() error)
+}
+
+// call calls the function with the caller's context. If the caller is synchronous, it returns the result of the
+// function and throws its error as an exception, prefixed by the given message. Otherwise, it calls the function
+// asynchronously and returns a promise that is settled with its result in the event loop.
+func (c jsCaller) call(message string, fn func(ctx context.Context) (interface{}, error)) goja.Value {
+	if c.registerCallback == nil {
+		result, err := fn(c.ctx)
+		if err != nil {
+			common.Throw(c.rt, fmt.Errorf("%s: %w", message, err))
+		}
+
+		return c.toValue(result)
+	}
+
+	promise, resolve, reject := c.rt.NewPromise()
+	callback := c.registerCallback()
+	go func() {
+		result, err := fn(c.ctx)
+		callback(func() error {
+			if err != nil {
+				reject(fmt.Errorf("%s: %w", message, err))
+				return nil
+			}
+
+			resolve(c.toValue(result))
+			return nil
+		})
+	}()
+
+	return c.rt.ToValue(promise)
+}
+
+// fail reports an invalid argument of a method. If the caller is synchronous, it throws the error as an exception.
+// Otherwise, it returns a promise rejected with the error, so it can be handled as the errors of the method.
+func (c jsCaller) fail(err error) goja.Value {
+	if c.registerCallback == nil {
+		common.Throw(c.rt, err)
+	}
+
+	promise, _, reject := c.rt.NewPromise()
+	reject(err)
+
+	return c.rt.ToValue(promise)
+}
+
+// toValue returns the result of a method as a JS value. A nil result is returned as undefined.
+func (c jsCaller) toValue(result interface{}) goja.Value {
+	if result == nil {
+		return goja.Undefined()
+	}
+
+	if value, ok := result.(goja.Value); ok {
+		return value
+	}
+
+	return c.rt.ToValue(result)
+}
+
+// jsDisruptor implements the JS interface for Disruptor
+type jsDisruptor struct {
+	jsCaller
 	disruptors.Disruptor
 }
 
-// Targets is a proxy method. Validates parameters and delegates to the PodDisruptor method
-func (p *jsDisruptor) Targets() goja.Value {
-	targets, err := p.Disruptor.Targets(p.ctx)
+// Targets is a proxy method. Validates parameters and delegates to the PodDisruptor method
+func (p *jsDisruptor) Targets() goja.Value {
+	return p.call("error getting kubernetes config path", func(ctx context.Context) (interface{}, error) {
+		return p.Disruptor.Targets(ctx)
+	})
+}
+
+// jsProtocolFaultInjector implements the JS interface for jsProtocolFaultInjector
+type jsProtocolFaultInjector struct {
+	jsCaller
+	disruptors.ProtocolFaultInjector
+}
+
+// httpFaultArgs validates and converts the arguments of the methods that inject HTTP faults
+func (p *jsProtocolFaultInjector) httpFaultArgs(
+	args []goja.Value,
+) (fault disruptors.HTTPFault, duration time.Duration, opts disruptors.HTTPDisruptionOptions, err error) {
+	if len(args) < 2 {
+		return fault, duration, opts, fmt.Errorf("HTTPFault and duration are required")
+	}
+
+	err = convertValue(p.rt, args[0], &fault)
+	if err != nil {
+		return fault, duration, opts, fmt.Errorf("invalid fault argument: %w", err)
+	}
+
+	err = convertValue(p.rt, args[1], &duration)
+	if err != nil {
+		return fault, duration, opts, fmt.Errorf("invalid duration argument: %w", err)
+	}
+
+	if len(args) > 2 {
+		err = convertValue(p.rt, args[2], &opts)
+		if err != nil {
+			return fault, duration, opts, fmt.Errorf("invalid options argument: %w", err)
+		}
+	}
+
+	return fault, duration, opts, nil
+}
+
+// injectHTTPFaults is a proxy method. Validates parameters and delegates to the Protocol Disruptor method.
+// Returns the report of the injection in each target.
+func (p *jsProtocolFaultInjector) InjectHTTPFaults(args ...goja.Value) goja.Value {
+	fault, duration, opts, err := p.httpFaultArgs(args)
+	if err != nil {
+		return p.fail(err)
+	}
+
+	return p.call("error injecting fault", func(ctx context.Context) (interface{}, error) {
+		return p.ProtocolFaultInjector.InjectHTTPFaultsWithReport(ctx, fault, duration, opts)
+	})
+}
+
+// StartHTTPFaults is a proxy method. Validates parameters and injects the HTTP faults asynchronously, returning a
+// handle for controlling them
+func (p *jsProtocolFaultInjector) StartHTTPFaults(args ...goja.Value) goja.Value {
+	fault, duration, opts, err := p.httpFaultArgs(args)
+	if err != nil {
+		common.Throw(p.rt, err)
+	}
+
+	handle := disruptors.StartFault(p.ctx, func(ctx context.Context) (disruptors.FaultReport, error) {
+		return p.ProtocolFaultInjector.InjectHTTPFaultsWithReport(ctx, fault, duration, opts)
+	})
+
+	return buildJsFaultHandle(p.jsCaller, handle)
+}
+
+// grpcFaultArgs validates and converts the arguments of the methods that inject grpc faults
+func (p *jsProtocolFaultInjector) grpcFaultArgs(
+	args []goja.Value,
+) (fault disruptors.GrpcFault, duration time.Duration, opts disruptors.GrpcDisruptionOptions, err error) {
+	if len(args) < 2 {
+		return fault, duration, opts, fmt.Errorf("GrpcFault and duration are required")
+	}
+
+	err = convertValue(p.rt, args[0], &fault)
+	if err != nil {
+		return fault, duration, opts, fmt.Errorf("invalid fault argument: %w", err)
+	}
+
+	err = convertValue(p.rt, args[1], &duration)
+	if err != nil {
+		return fault, duration, opts, fmt.Errorf("invalid duration argument: %w", err)
+	}
+
+	if len(args) > 2 {
+		err = convertValue(p.rt, args[2], &opts)
+		if err != nil {
+			return fault, duration, opts, fmt.Errorf("invalid options argument: %w", err)
+		}
+	}
+
+	return fault, duration, opts, nil
+}
+
+// InjectGrpcFaults is a proxy method. Validates parameters and delegates to the PodDisruptor method.
+// Returns the report of the injection in each target.
+func (p *jsProtocolFaultInjector) InjectGrpcFaults(args ...goja.Value) goja.Value {
+	fault, duration, opts, err := p.grpcFaultArgs(args)
+	if err != nil {
+		return p.fail(err)
+	}
+
+	return p.call("error injecting fault", func(ctx context.Context) (interface{}, error) {
+		return p.ProtocolFaultInjector.InjectGrpcFaultsWithReport(ctx, fault, duration, opts)
+	})
+}
+
+// StartGrpcFaults is a proxy method. Validates parameters and injects the grpc faults asynchronously, returning a
+// handle for controlling them
+func (p *jsProtocolFaultInjector) StartGrpcFaults(args ...goja.Value) goja.Value {
+	fault, duration, opts, err := p.grpcFaultArgs(args)
+	if err != nil {
+		common.Throw(p.rt, err)
+	}
+
+	handle := disruptors.StartFault(p.ctx, func(ctx context.Context) (disruptors.FaultReport, error) {
+		return p.ProtocolFaultInjector.InjectGrpcFaultsWithReport(ctx, fault, duration, opts)
+	})
+
+	return buildJsFaultHandle(p.jsCaller, handle)
+}
+
+// jsFaultHandle implements the JS interface for FaultHandle
+type jsFaultHandle struct {
+	jsCaller
+	handle disruptors.FaultHandle
+}
+
+// Status is a proxy method. Returns the status of the fault
+func (h *jsFaultHandle) Status() string {
+	return h.handle.Status()
+}
+
+// Wait is a proxy method. Waits for the fault to end and returns its report
+func (h *jsFaultHandle) Wait() goja.Value {
+	return h.call("error injecting fault", func(_ context.Context) (interface{}, error) {
+		return h.handle.Wait()
+	})
+}
+
+// Stop is a proxy method. Ends the fault, waits for the targets to be restored and returns the report of the fault
+func (h *jsFaultHandle) Stop() goja.Value {
+	return h.call("error stopping fault", func(_ context.Context) (interface{}, error) {
+		return h.handle.Stop()
+	})
+}
+
+// buildJsFaultHandle returns the JS object for a FaultHandle
+func buildJsFaultHandle(caller jsCaller, handle disruptors.FaultHandle) goja.Value {
+	obj, err := buildObject(caller.rt, &jsFaultHandle{jsCaller: caller, handle: handle})
+	if err != nil {
+		common.Throw(caller.rt, fmt.Errorf("error creating fault handle: %w", err))
+	}
+
+	return obj
+}
+
+// jsTCPFaultInjector implements the JS interface for TCPFaultInjector
+type jsTCPFaultInjector struct {
+	jsCaller
+	disruptors.TCPFaultInjector
+}
+
+// InjectTCPFaults is a proxy method. Validates parameters and delegates to the TCPFaultInjector method
+func (p *jsTCPFaultInjector) InjectTCPFaults(args ...goja.Value) goja.Value {
+	if len(args) < 2 {
+		return p.fail(fmt.Errorf("TCPFault and duration are required"))
+	}
+
+	fault := disruptors.TCPFault{}
+	err := convertValue(p.rt, args[0], &fault)
+	if err != nil {
+		return p.fail(fmt.Errorf("invalid fault argument: %w", err))
+	}
+
+	var duration time.Duration
+	err = convertValue(p.rt, args[1], &duration)
+	if err != nil {
+		return p.fail(fmt.Errorf("invalid duration argument: %w", err))
+	}
+
+	opts := disruptors.TCPDisruptionOptions{}
+	if len(args) > 2 {
+		err = convertValue(p.rt, args[2], &opts)
+		if err != nil {
+			return p.fail(fmt.Errorf("invalid options argument: %w", err))
+		}
+	}
+
+	return p.call("error injecting fault", func(ctx context.Context) (interface{}, error) {
+		return nil, p.TCPFaultInjector.InjectTCPFaults(ctx, fault, duration, opts)
+	})
+}
+
+// jsProcessFaultInjector implements the JS interface for ProcessFaultInjector
+type jsProcessFaultInjector struct {
+	jsCaller
+	disruptors.ProcessFaultInjector
+}
+
+// InjectSignalFault is a proxy method. Validates parameters and delegates to the PodDisruptor method
+func (p *jsProcessFaultInjector) InjectSignalFault(args ...goja.Value) goja.Value {
+	if len(args) < 1 {
+		return p.fail(fmt.Errorf("SignalFault is required"))
+	}
+
+	fault := disruptors.SignalFault{}
+	err := convertValue(p.rt, args[0], &fault)
+	if err != nil {
+		return p.fail(fmt.Errorf("invalid fault argument: %w", err))
+	}
+
+	return p.call("error injecting fault", func(ctx context.Context) (interface{}, error) {
+		return nil, p.ProcessFaultInjector.InjectSignalFault(ctx, fault)
+	})
+}
+
+// InjectFreezeFault is a proxy method. Validates parameters and delegates to the PodDisruptor method
+func (p *jsProcessFaultInjector) InjectFreezeFault(args ...goja.Value) goja.Value {
+	if len(args) < 2 {
+		return p.fail(fmt.Errorf("FreezeFault and duration are required"))
+	}
+
+	fault := disruptors.FreezeFault{}
+	err := convertValue(p.rt, args[0], &fault)
+	if err != nil {
+		return p.fail(fmt.Errorf("invalid fault argument: %w", err))
+	}
+
+	var duration time.Duration
+	err = convertValue(p.rt, args[1], &duration)
+	if err != nil {
+		return p.fail(fmt.Errorf("invalid duration argument: %w", err))
+	}
+
+	return p.call("error injecting fault", func(ctx context.Context) (interface{}, error) {
+		return nil, p.ProcessFaultInjector.InjectFreezeFault(ctx, fault, duration)
+	})
+}
+
+// jsNetworkFaultInjector implements the JS interface for NetworkFaultInjector
+type jsNetworkFaultInjector struct {
+	jsCaller
+	disruptors.NetworkFaultInjector
+}
+
+// InjectNetworkFaults is a proxy method. Validates parameters and delegates to the NetworkFaultInjector method
+func (p *jsNetworkFaultInjector) InjectNetworkFaults(args ...goja.Value) goja.Value {
+	if len(args) < 2 {
+		return p.fail(fmt.Errorf("NetworkFault and duration are required"))
+	}
+
+	fault := disruptors.NetworkFault{}
+	err := convertValue(p.rt, args[0], &fault)
+	if err != nil {
+		return p.fail(fmt.Errorf("invalid fault argument: %w", err))
+	}
+
+	var duration time.Duration
+	err = convertValue(p.rt, args[1], &duration)
+	if err != nil {
+		return p.fail(fmt.Errorf("invalid duration argument: %w", err))
+	}
+
+	return p.call("error injecting fault", func(ctx context.Context) (interface{}, error) {
+		return nil, p.NetworkFaultInjector.InjectNetworkFaults(ctx, fault, duration)
+	})
+}
+
+// jsPartitionInjector implements the JS interface for PartitionInjector
+type jsPartitionInjector struct {
+	jsCaller
+	disruptors.PartitionInjector
+}
+
+// InjectPartitionFault is a proxy method. Validates parameters and delegates to the PartitionInjector method
+func (p *jsPartitionInjector) InjectPartitionFault(args ...goja.Value) goja.Value {
+	if len(args) < 2 {
+		return p.fail(fmt.Errorf("PartitionFault and duration are required"))
+	}
+
+	fault := disruptors.PartitionFault{}
+	err := convertValue(p.rt, args[0], &fault)
+	if err != nil {
+		return p.fail(fmt.Errorf("invalid fault argument: %w", err))
+	}
+
+	var duration time.Duration
+	err = convertValue(p.rt, args[1], &duration)
+	if err != nil {
+		return p.fail(fmt.Errorf("invalid duration argument: %w", err))
+	}
+
+	return p.call("error injecting fault", func(ctx context.Context) (interface{}, error) {
+		return nil, p.PartitionInjector.InjectPartitionFault(ctx, fault, duration)
+	})
+}
+
+// jsDNSFaultInjector implements the JS interface for DNSFaultInjector
+type jsDNSFaultInjector struct {
+	jsCaller
+	disruptors.DNSFaultInjector
+}
+
+// InjectDNSFaults is a proxy method. Validates parameters and delegates to the DNSFaultInjector method
+func (p *jsDNSFaultInjector) InjectDNSFaults(args ...goja.Value) goja.Value {
+	if len(args) < 2 {
+		return p.fail(fmt.Errorf("DNSFault and duration are required"))
+	}
+
+	fault := disruptors.DNSFault{}
+	err := convertValue(p.rt, args[0], &fault)
+	if err != nil {
+		return p.fail(fmt.Errorf("invalid fault argument: %w", err))
+	}
+
+	var duration time.Duration
+	err = convertValue(p.rt, args[1], &duration)
+	if err != nil {
+		return p.fail(fmt.Errorf("invalid duration argument: %w", err))
+	}
+
+	opts := disruptors.DNSDisruptionOptions{}
+	if len(args) > 2 {
+		err = convertValue(p.rt, args[2], &opts)
+		if err != nil {
+			return p.fail(fmt.Errorf("invalid options argument: %w", err))
+		}
+	}
+
+	return p.call("error injecting fault", func(ctx context.Context) (interface{}, error) {
+		return nil, p.DNSFaultInjector.InjectDNSFaults(ctx, fault, duration, opts)
+	})
+}
+
+// jsCPUStressInjector implements the JS interface for CPUStressInjector
+type jsCPUStressInjector struct {
+	jsCaller
+	disruptors.CPUStressInjector
+}
+
+// StressCPU is a proxy method. Validates parameters and delegates to the CPUStressInjector method
+func (p *jsCPUStressInjector) StressCPU(args ...goja.Value) goja.Value {
+	if len(args) < 2 {
+		return p.fail(fmt.Errorf("CPUStress and duration are required"))
+	}
+
+	stress := disruptors.CPUStress{}
+	err := convertValue(p.rt, args[0], &stress)
+	if err != nil {
+		return p.fail(fmt.Errorf("invalid stress argument: %w", err))
+	}
+
+	var duration time.Duration
+	err = convertValue(p.rt, args[1], &duration)
+	if err != nil {
+		return p.fail(fmt.Errorf("invalid duration argument: %w", err))
+	}
+
+	return p.call("error stressing CPU", func(ctx context.Context) (interface{}, error) {
+		return nil, p.CPUStressInjector.StressCPU(ctx, stress, duration)
+	})
+}
+
+// jsMemoryStressInjector implements the JS interface for MemoryStressInjector
+type jsMemoryStressInjector struct {
+	jsCaller
+	disruptors.MemoryStressInjector
+}
+
+// StressMemory is a proxy method. Validates parameters and delegates to the MemoryStressInjector method
+func (p *jsMemoryStressInjector) StressMemory(args ...goja.Value) goja.Value {
+	if len(args) < 2 {
+		return p.fail(fmt.Errorf("MemoryStress and duration are required"))
+	}
+
+	stress := disruptors.MemoryStress{}
+	err := convertValue(p.rt, args[0], &stress)
+	if err != nil {
+		return p.fail(fmt.Errorf("invalid stress argument: %w", err))
+	}
+
+	var duration time.Duration
+	err = convertValue(p.rt, args[1], &duration)
+	if err != nil {
+		return p.fail(fmt.Errorf("invalid duration argument: %w", err))
+	}
+
+	return p.call("error stressing memory", func(ctx context.Context) (interface{}, error) {
+		return nil, p.MemoryStressInjector.StressMemory(ctx, stress, duration)
+	})
+}
+
+// jsResourceExhaustionInjector implements the JS interface for ResourceExhaustionInjector
+type jsResourceExhaustionInjector struct {
+	jsCaller
+	disruptors.ResourceExhaustionInjector
+}
+
+// InjectDiskFillFault is a proxy method. Validates parameters and delegates to the ResourceExhaustionInjector method
+func (p *jsResourceExhaustionInjector) InjectDiskFillFault(args ...goja.Value) goja.Value {
+	if len(args) < 2 {
+		return p.fail(fmt.Errorf("DiskFillFault and duration are required"))
+	}
+
+	fault := disruptors.DiskFillFault{}
+	err := convertValue(p.rt, args[0], &fault)
+	if err != nil {
+		return p.fail(fmt.Errorf("invalid fault argument: %w", err))
+	}
+
+	var duration time.Duration
+	err = convertValue(p.rt, args[1], &duration)
+	if err != nil {
+		return p.fail(fmt.Errorf("invalid duration argument: %w", err))
+	}
+
+	return p.call("error injecting fault", func(ctx context.Context) (interface{}, error) {
+		return nil, p.ResourceExhaustionInjector.InjectDiskFillFault(ctx, fault, duration)
+	})
+}
+
+// InjectFDExhaustionFault is a proxy method. Validates parameters and delegates to the ResourceExhaustionInjector
+// method
+func (p *jsResourceExhaustionInjector) InjectFDExhaustionFault(args ...goja.Value) goja.Value {
+	if len(args) < 2 {
+		return p.fail(fmt.Errorf("FDExhaustionFault and duration are required"))
+	}
+
+	fault := disruptors.FDExhaustionFault{}
+	err := convertValue(p.rt, args[0], &fault)
+	if err != nil {
+		return p.fail(fmt.Errorf("invalid fault argument: %w", err))
+	}
+
+	var duration time.Duration
+	err = convertValue(p.rt, args[1], &duration)
+	if err != nil {
+		return p.fail(fmt.Errorf("invalid duration argument: %w", err))
+	}
+
+	return p.call("error injecting fault", func(ctx context.Context) (interface{}, error) {
+		return nil, p.ResourceExhaustionInjector.InjectFDExhaustionFault(ctx, fault, duration)
+	})
+}
+
+// jsNodeMaintenanceInjector implements the JS interface for NodeMaintenanceInjector
+type jsNodeMaintenanceInjector struct {
+	jsCaller
+	disruptors.NodeMaintenanceInjector
+}
+
+// CordonNodes is a proxy method. Validates parameters and delegates to the NodeMaintenanceInjector method
+func (p *jsNodeMaintenanceInjector) CordonNodes(args ...goja.Value) goja.Value {
+	if len(args) < 1 {
+		return p.fail(fmt.Errorf("duration is required"))
+	}
+
+	var duration time.Duration
+	err := convertValue(p.rt, args[0], &duration)
+	if err != nil {
+		return p.fail(fmt.Errorf("invalid duration argument: %w", err))
+	}
+
+	return p.call("error cordoning nodes", func(ctx context.Context) (interface{}, error) {
+		return nil, p.NodeMaintenanceInjector.CordonNodes(ctx, duration)
+	})
+}
+
+// DrainNodes is a proxy method. Validates parameters and delegates to the NodeMaintenanceInjector method
+func (p *jsNodeMaintenanceInjector) DrainNodes(args ...goja.Value) goja.Value {
+	if len(args) < 1 {
+		return p.fail(fmt.Errorf("duration is required"))
+	}
+
+	var duration time.Duration
+	err := convertValue(p.rt, args[0], &duration)
+	if err != nil {
+		return p.fail(fmt.Errorf("invalid duration argument: %w", err))
+	}
+
+	opts := disruptors.NodeDrainOptions{}
+	if len(args) > 1 {
+		err = convertValue(p.rt, args[1], &opts)
+		if err != nil {
+			return p.fail(fmt.Errorf("invalid options argument: %w", err))
+		}
+	}
+
+	return p.call("error draining nodes", func(ctx context.Context) (interface{}, error) {
+		return nil, p.NodeMaintenanceInjector.DrainNodes(ctx, duration, opts)
+	})
+}
+
+// jsReplicaFaultInjector implements the JS interface for ReplicaFaultInjector
+type jsReplicaFaultInjector struct {
+	jsCaller
+	disruptors.ReplicaFaultInjector
+}
+
+// ScaleReplicas is a proxy method. Validates parameters and delegates to the ReplicaFaultInjector method
+func (p *jsReplicaFaultInjector) ScaleReplicas(args ...goja.Value) goja.Value {
+	if len(args) < 2 {
+		return p.fail(fmt.Errorf("ReplicaFault and duration are required"))
+	}
+
+	fault := disruptors.ReplicaFault{}
+	err := convertValue(p.rt, args[0], &fault)
+	if err != nil {
+		return p.fail(fmt.Errorf("invalid fault argument: %w", err))
+	}
+
+	var duration time.Duration
+	err = convertValue(p.rt, args[1], &duration)
+	if err != nil {
+		return p.fail(fmt.Errorf("invalid duration argument: %w", err))
+	}
+
+	return p.call("error scaling replicas", func(ctx context.Context) (interface{}, error) {
+		return nil, p.ReplicaFaultInjector.ScaleReplicas(ctx, fault, duration)
+	})
+}
+
+// jsChaosInjector implements the JS interface for ChaosInjector
+type jsChaosInjector struct {
+	jsCaller
+	disruptors.ChaosInjector
+}
+
+// InjectChaos is a proxy method. Validates parameters and delegates to the ChaosInjector method.
+// Returns the schedule of the faults injected, including the seed for replaying it.
+func (p *jsChaosInjector) InjectChaos(args ...goja.Value) goja.Value {
+	if len(args) < 1 {
+		return p.fail(fmt.Errorf("ChaosOptions are required"))
+	}
+
+	options := disruptors.ChaosOptions{}
+	err := convertValue(p.rt, args[0], &options)
+	if err != nil {
+		return p.fail(fmt.Errorf("invalid options argument: %w", err))
+	}
+
+	return p.call("error injecting chaos", func(ctx context.Context) (interface{}, error) {
+		report, err := p.ChaosInjector.InjectChaos(ctx, options)
+		if err != nil {
+			return nil, err
+		}
+
+		events := []interface{}{}
+		for _, event := range report.Events {
+			events = append(events, map[string]interface{}{
+				"offset":   event.Offset.String(),
+				"target":   event.Target,
+				"fault":    event.Fault,
+				"duration": event.Duration.String(),
+			})
+		}
+
+		return map[string]interface{}{
+			"seed":   report.Seed,
+			"events": events,
+		}, nil
+	})
+}
+
+type jsPodDisruptor struct {
+	jsDisruptor
+	jsProtocolFaultInjector
+	jsProcessFaultInjector
+	jsNetworkFaultInjector
+	jsPartitionInjector
+	jsDNSFaultInjector
+	jsCPUStressInjector
+	jsMemoryStressInjector
+	jsResourceExhaustionInjector
+	jsChaosInjector
+}
+
+// buildJsPodDisruptor builds a goja object that implements the PodDisruptor API
+func buildJsPodDisruptor(
+	caller jsCaller,
+	disruptor disruptors.PodDisruptor,
+) (*goja.Object, error) {
+	d := &jsPodDisruptor{
+		jsDisruptor: jsDisruptor{
+			jsCaller:  caller,
+			Disruptor: disruptor,
+		},
+		jsProtocolFaultInjector: jsProtocolFaultInjector{
+			jsCaller:              caller,
+			ProtocolFaultInjector: disruptor,
+		},
+		jsProcessFaultInjector: jsProcessFaultInjector{
+			jsCaller:             caller,
+			ProcessFaultInjector: disruptor,
+		},
+		jsNetworkFaultInjector: jsNetworkFaultInjector{
+			jsCaller:             caller,
+			NetworkFaultInjector: disruptor,
+		},
+		jsPartitionInjector: jsPartitionInjector{
+			jsCaller:          caller,
+			PartitionInjector: disruptor,
+		},
+		jsDNSFaultInjector: jsDNSFaultInjector{
+			jsCaller:         caller,
+			DNSFaultInjector: disruptor,
+		},
+		jsCPUStressInjector: jsCPUStressInjector{
+			jsCaller:          caller,
+			CPUStressInjector: disruptor,
+		},
+		jsMemoryStressInjector: jsMemoryStressInjector{
+			jsCaller:             caller,
+			MemoryStressInjector: disruptor,
+		},
+		jsResourceExhaustionInjector: jsResourceExhaustionInjector{
+			jsCaller:                   caller,
+			ResourceExhaustionInjector: disruptor,
+		},
+		jsChaosInjector: jsChaosInjector{
+			jsCaller:      caller,
+			ChaosInjector: disruptor,
+		},
+	}
+
+	return buildObject(caller.rt, d)
+}
+
+type jsServiceDisruptor struct {
+	jsDisruptor
+	jsProtocolFaultInjector
+	jsNetworkFaultInjector
+}
+
+// buildJsServiceDisruptor builds a goja object that implements the ServiceDisruptor API
+func buildJsServiceDisruptor(
+	caller jsCaller,
+	disruptor disruptors.ServiceDisruptor,
+) (*goja.Object, error) {
+	d := &jsServiceDisruptor{
+		jsDisruptor: jsDisruptor{
+			jsCaller:  caller,
+			Disruptor: disruptor,
+		},
+		jsProtocolFaultInjector: jsProtocolFaultInjector{
+			jsCaller:              caller,
+			ProtocolFaultInjector: disruptor,
+		},
+		jsNetworkFaultInjector: jsNetworkFaultInjector{
+			jsCaller:             caller,
+			NetworkFaultInjector: disruptor,
+		},
+	}
+
+	return buildObject(caller.rt, d)
+}
+
+type jsNodeDisruptor struct {
+	jsDisruptor
+	jsNetworkFaultInjector
+	jsCPUStressInjector
+	jsMemoryStressInjector
+	jsNodeMaintenanceInjector
+	disruptor disruptors.NodeDisruptor
+}
+
+// Cleanup is a proxy method. Delegates to the NodeDisruptor method
+func (p *jsNodeDisruptor) Cleanup() goja.Value {
+	return p.jsDisruptor.call("error cleaning up agents", func(ctx context.Context) (interface{}, error) {
+		return nil, p.disruptor.Cleanup(ctx)
+	})
+}
+
+// buildJsNodeDisruptor builds a goja object that implements the NodeDisruptor API
+func buildJsNodeDisruptor(
+	caller jsCaller,
+	disruptor disruptors.NodeDisruptor,
+) (*goja.Object, error) {
+	d := &jsNodeDisruptor{
+		jsDisruptor: jsDisruptor{
+			jsCaller:  caller,
+			Disruptor: disruptor,
+		},
+		jsNetworkFaultInjector: jsNetworkFaultInjector{
+			jsCaller:             caller,
+			NetworkFaultInjector: disruptor,
+		},
+		jsCPUStressInjector: jsCPUStressInjector{
+			jsCaller:          caller,
+			CPUStressInjector: disruptor,
+		},
+		jsMemoryStressInjector: jsMemoryStressInjector{
+			jsCaller:             caller,
+			MemoryStressInjector: disruptor,
+		},
+		jsNodeMaintenanceInjector: jsNodeMaintenanceInjector{
+			jsCaller:                caller,
+			NodeMaintenanceInjector: disruptor,
+		},
+		disruptor: disruptor,
+	}
+
+	return buildObject(caller.rt, d)
+}
+
+type jsDependencyDisruptor struct {
+	jsDisruptor
+	jsProtocolFaultInjector
+	jsTCPFaultInjector
+}
+
+// buildJsDependencyDisruptor builds a goja object that implements the DependencyDisruptor API
+func buildJsDependencyDisruptor(
+	caller jsCaller,
+	disruptor disruptors.DependencyDisruptor,
+) (*goja.Object, error) {
+	d := &jsDependencyDisruptor{
+		jsDisruptor: jsDisruptor{
+			jsCaller:  caller,
+			Disruptor: disruptor,
+		},
+		jsProtocolFaultInjector: jsProtocolFaultInjector{
+			jsCaller:              caller,
+			ProtocolFaultInjector: disruptor,
+		},
+		jsTCPFaultInjector: jsTCPFaultInjector{
+			jsCaller:         caller,
+			TCPFaultInjector: disruptor,
+		},
+	}
+
+	return buildObject(caller.rt, d)
+}
+
+type jsWorkloadDisruptor struct {
+	jsDisruptor
+	jsReplicaFaultInjector
+}
+
+// buildJsWorkloadDisruptor builds a goja object that implements the WorkloadDisruptor API
+func buildJsWorkloadDisruptor(
+	caller jsCaller,
+	disruptor disruptors.WorkloadDisruptor,
+) (*goja.Object, error) {
+	d := &jsWorkloadDisruptor{
+		jsDisruptor: jsDisruptor{
+			jsCaller:  caller,
+			Disruptor: disruptor,
+		},
+		jsReplicaFaultInjector: jsReplicaFaultInjector{
+			jsCaller:             caller,
+			ReplicaFaultInjector: disruptor,
+		},
+	}
+
+	return buildObject(caller.rt, d)
+}
+
+// disruptorSymbol is the key of the property of the JS objects of the disruptors that holds the disruptor. Being a
+// symbol, it is not visible from the scripts.
+var disruptorSymbol = goja.NewSymbol("disruptor")
+
+// disruptorOf returns the disruptor of the JS object of a disruptor
+func disruptorOf(value goja.Value) (disruptors.Disruptor, error) {
+	obj, ok := value.(*goja.Object)
+	if !ok {
+		return nil, fmt.Errorf("expected a disruptor")
+	}
+
+	symbolValue := obj.GetSymbol(disruptorSymbol)
+	if symbolValue == nil {
+		return nil, fmt.Errorf("expected a disruptor")
+	}
+
+	disruptor, ok := symbolValue.Export().(disruptors.Disruptor)
+	if !ok {
+		return nil, fmt.Errorf("expected a disruptor")
+	}
+
+	return disruptor, nil
+}
+
+// disruptorFactory creates a disruptor from the arguments of its JS constructor
+type disruptorFactory struct {
+	// name of the disruptor
+	name string
+	// create creates the disruptor. It does not use the JS runtime, so it can be called outside the event loop.
+	create func(ctx context.Context) (interface{}, error)
+	// build builds the JS object for the disruptor
+	build func(caller jsCaller, disruptor interface{}) (*goja.Object, error)
+}
+
+// new creates the disruptor and returns its JS object
+func (f *disruptorFactory) new(caller jsCaller) (*goja.Object, error) {
+	disruptor, err := f.create(caller.ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error creating %s: %w", f.name, err)
+	}
+
+	obj, err := f.build(caller, disruptor)
 	if err != nil {
-		common.Throw(p.rt, fmt.Errorf("error getting kubernetes config path: %w", err))
+		return nil, fmt.Errorf("error creating %s: %w", f.name, err)
 	}
 
-	return p.rt.ToValue(targets)
+	err = obj.SetSymbol(disruptorSymbol, caller.rt.ToValue(disruptor))
+	if err != nil {
+		return nil, fmt.Errorf("error creating %s: %w", f.name, err)
+	}
+
+	return obj, nil
 }
 
-// jsProtocolFaultInjector implements the JS interface for jsProtocolFaultInjector
-type jsProtocolFaultInjector struct {
-	ctx context.Context // this context controls the object's lifecycle
-	rt  *goja.Runtime
-	disruptors.ProtocolFaultInjector
+// newAsync creates the disruptor asynchronously and returns a promise that is resolved in the event loop with its
+// JS object, whose methods also return promises
+func (f *disruptorFactory) newAsync(caller jsCaller) *goja.Promise {
+	promise, resolve, reject := caller.rt.NewPromise()
+	callback := caller.registerCallback()
+	go func() {
+		disruptor, err := f.create(caller.ctx)
+		callback(func() error {
+			if err != nil {
+				reject(fmt.Errorf("error creating %s: %w", f.name, err))
+				return nil
+			}
+
+			obj, err := f.build(caller, disruptor)
+			if err == nil {
+				err = obj.SetSymbol(disruptorSymbol, caller.rt.ToValue(disruptor))
+			}
+			if err != nil {
+				reject(fmt.Errorf("error creating %s: %w", f.name, err))
+				return nil
+			}
+
+			resolve(obj)
+			return nil
+		})
+	}()
+
+	return promise
 }
 
-// injectHTTPFaults is a proxy method. Validates parameters and delegates to the Protocol Disruptor method
-func (p *jsProtocolFaultInjector) InjectHTTPFaults(args ...goja.Value) {
-	if len(args) < 2 {
-		common.Throw(p.rt, fmt.Errorf("HTTPFault and duration are required"))
+// argument returns the argument at the given index, or undefined if it was not passed
+func argument(args []goja.Value, index int) goja.Value {
+	if index < len(args) {
+		return args[index]
 	}
 
-	fault := disruptors.HTTPFault{}
-	err := convertValue(p.rt, args[0], &fault)
+	return goja.Undefined()
+}
+
+// NewPodDisruptor creates an instance of a PodDisruptor
+// The context passed to this constructor is expected to control the lifecycle of the PodDisruptor
+func NewPodDisruptor(
+	ctx context.Context,
+	rt *goja.Runtime,
+	c goja.ConstructorCall,
+	k8s kubernetes.Kubernetes,
+) (*goja.Object, error) {
+	factory, err := podDisruptorFactory(rt, c.Arguments, k8s)
 	if err != nil {
-		common.Throw(p.rt, fmt.Errorf("invalid fault argument: %w", err))
+		return nil, err
 	}
 
-	var duration time.Duration
-	err = convertValue(p.rt, args[1], &duration)
+	return factory.new(jsCaller{ctx: ctx, rt: rt})
+}
+
+// podDisruptorFactory returns the factory of a PodDisruptor for the arguments of its constructor
+func podDisruptorFactory(
+	rt *goja.Runtime,
+	args []goja.Value,
+	k8s kubernetes.Kubernetes,
+) (*disruptorFactory, error) {
+	if argument(args, 0).Equals(goja.Null()) {
+		return nil, fmt.Errorf("PodDisruptor constructor expects a non null PodSelector argument")
+	}
+
+	selector := disruptors.PodSelector{}
+	err := convertValue(rt, argument(args, 0), &selector)
 	if err != nil {
-		common.Throw(p.rt, fmt.Errorf("invalid duration argument: %w", err))
+		return nil, fmt.Errorf("invalid PodSelector: %w", err)
 	}
 
-	opts := disruptors.HTTPDisruptionOptions{}
-	if len(args) > 2 {
-		err = convertValue(p.rt, args[2], &opts)
+	options := disruptors.PodDisruptorOptions{}
+	// options argument is optional
+	if len(args) > 1 {
+		err = convertValue(rt, args[1], &options)
 		if err != nil {
-			common.Throw(p.rt, fmt.Errorf("invalid options argument: %w", err))
+			return nil, fmt.Errorf("invalid PodDisruptorOptions: %w", err)
 		}
 	}
 
-	err = p.ProtocolFaultInjector.InjectHTTPFaults(p.ctx, fault, duration, opts)
+	return &disruptorFactory{
+		name: "PodDisruptor",
+		create: func(ctx context.Context) (interface{}, error) {
+			return disruptors.NewPodDisruptor(ctx, k8s, selector, options)
+		},
+		build: func(caller jsCaller, disruptor interface{}) (*goja.Object, error) {
+			return buildJsPodDisruptor(caller, disruptor.(disruptors.PodDisruptor))
+		},
+	}, nil
+}
+
+// NewWorkloadPodDisruptor creates an instance of a PodDisruptor that targets the pods controlled by a workload
+// The context passed to this constructor is expected to control the lifecycle of the PodDisruptor
+func NewWorkloadPodDisruptor(
+	ctx context.Context,
+	rt *goja.Runtime,
+	c goja.ConstructorCall,
+	k8s kubernetes.Kubernetes,
+) (*goja.Object, error) {
+	factory, err := workloadPodDisruptorFactory(rt, c.Arguments, k8s)
 	if err != nil {
-		common.Throw(p.rt, fmt.Errorf("error injecting fault: %w", err))
+		return nil, err
 	}
+
+	return factory.new(jsCaller{ctx: ctx, rt: rt})
 }
 
-// InjectGrpcFaults is a proxy method. Validates parameters and delegates to the PodDisruptor method
-func (p *jsProtocolFaultInjector) InjectGrpcFaults(args ...goja.Value) {
-	if len(args) < 2 {
-		common.Throw(p.rt, fmt.Errorf("GrpcFault and duration are required"))
+// workloadPodDisruptorFactory returns the factory of a PodDisruptor for the pods of a workload for the arguments of
+// its constructor
+func workloadPodDisruptorFactory(
+	rt *goja.Runtime,
+	args []goja.Value,
+	k8s kubernetes.Kubernetes,
+) (*disruptorFactory, error) {
+	if argument(args, 0).Equals(goja.Null()) {
+		return nil, fmt.Errorf("WorkloadPodDisruptor constructor expects a non null WorkloadSelector argument")
 	}
 
-	fault := disruptors.GrpcFault{}
-	err := convertValue(p.rt, args[0], &fault)
+	workload := disruptors.WorkloadSelector{}
+	err := convertValue(rt, argument(args, 0), &workload)
 	if err != nil {
-		common.Throw(p.rt, fmt.Errorf("invalid fault argument: %w", err))
+		return nil, fmt.Errorf("invalid WorkloadSelector: %w", err)
 	}
 
-	var duration time.Duration
-	err = convertValue(p.rt, args[1], &duration)
-	if err != nil {
-		common.Throw(p.rt, fmt.Errorf("invalid duration argument: %w", err))
+	if workload.Kind == "" || workload.Name == "" {
+		return nil, fmt.Errorf("kind and name of the workload are required")
 	}
 
-	opts := disruptors.GrpcDisruptionOptions{}
-	if len(args) > 2 {
-		err = convertValue(p.rt, args[2], &opts)
+	options := disruptors.PodDisruptorOptions{}
+	// options argument is optional
+	if len(args) > 1 {
+		err = convertValue(rt, args[1], &options)
 		if err != nil {
-			common.Throw(p.rt, fmt.Errorf("invalid options argument: %w", err))
+			return nil, fmt.Errorf("invalid PodDisruptorOptions: %w", err)
 		}
 	}
 
-	err = p.ProtocolFaultInjector.InjectGrpcFaults(p.ctx, fault, duration, opts)
+	return &disruptorFactory{
+		name: "PodDisruptor",
+		create: func(ctx context.Context) (interface{}, error) {
+			return disruptors.NewPodDisruptor(ctx, k8s, workload.PodSelector(), options)
+		},
+		build: func(caller jsCaller, disruptor interface{}) (*goja.Object, error) {
+			return buildJsPodDisruptor(caller, disruptor.(disruptors.PodDisruptor))
+		},
+	}, nil
+}
+
+// NewServiceDisruptor creates an instance of a ServiceDisruptor and returns it as a goja object
+// The context passed to this constructor is expected to control the lifecycle of the ServiceDisruptor
+func NewServiceDisruptor(
+	ctx context.Context,
+	rt *goja.Runtime,
+	c goja.ConstructorCall,
+	k8s kubernetes.Kubernetes,
+) (*goja.Object, error) {
+	factory, err := serviceDisruptorFactory(rt, c.Arguments, k8s)
 	if err != nil {
-		common.Throw(p.rt, fmt.Errorf("error injecting fault: %w", err))
+		return nil, err
 	}
+
+	return factory.new(jsCaller{ctx: ctx, rt: rt})
 }
 
-type jsPodDisruptor struct {
-	jsDisruptor
-	jsProtocolFaultInjector
+// serviceDisruptorFactory returns the factory of a ServiceDisruptor for the arguments of its constructor
+func serviceDisruptorFactory(
+	rt *goja.Runtime,
+	args []goja.Value,
+	k8s kubernetes.Kubernetes,
+) (*disruptorFactory, error) {
+	if len(args) < 2 {
+		return nil, fmt.Errorf("ServiceDisruptor constructor requires service and namespace parameters")
+	}
+
+	var service string
+	err := convertValue(rt, args[0], &service)
+	if err != nil {
+		return nil, fmt.Errorf("invalid service name argument for ServiceDisruptor constructor: %w", err)
+	}
+
+	var namespace string
+	err = convertValue(rt, args[1], &namespace)
+	if err != nil {
+		return nil, fmt.Errorf("invalid namespace argument for ServiceDisruptor constructor: %w", err)
+	}
+
+	options := disruptors.ServiceDisruptorOptions{}
+	// options argument is optional
+	if len(args) > 2 {
+		err = convertValue(rt, args[2], &options)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ServiceDisruptorOptions: %w", err)
+		}
+	}
+
+	return &disruptorFactory{
+		name: "ServiceDisruptor",
+		create: func(ctx context.Context) (interface{}, error) {
+			return disruptors.NewServiceDisruptor(ctx, k8s, service, namespace, options)
+		},
+		build: func(caller jsCaller, disruptor interface{}) (*goja.Object, error) {
+			return buildJsServiceDisruptor(caller, disruptor.(disruptors.ServiceDisruptor))
+		},
+	}, nil
 }
 
-// buildJsPodDisruptor builds a goja object that implements the PodDisruptor API
-func buildJsPodDisruptor(
+// NewNodeDisruptor creates an instance of a NodeDisruptor and returns it as a goja object
+// The context passed to this constructor is expected to control the lifecycle of the NodeDisruptor
+func NewNodeDisruptor(
 	ctx context.Context,
 	rt *goja.Runtime,
-	disruptor disruptors.PodDisruptor,
+	c goja.ConstructorCall,
+	k8s kubernetes.Kubernetes,
 ) (*goja.Object, error) {
-	d := &jsPodDisruptor{
-		jsDisruptor: jsDisruptor{
-			ctx:       ctx,
-			rt:        rt,
-			Disruptor: disruptor,
-		},
-		jsProtocolFaultInjector: jsProtocolFaultInjector{
-			ctx:                   ctx,
-			rt:                    rt,
-			ProtocolFaultInjector: disruptor,
-		},
+	factory, err := nodeDisruptorFactory(rt, c.Arguments, k8s)
+	if err != nil {
+		return nil, err
 	}
 
-	return buildObject(rt, d)
+	return factory.new(jsCaller{ctx: ctx, rt: rt})
 }
 
-type jsServiceDisruptor struct {
-	jsDisruptor
-	jsProtocolFaultInjector
+// nodeDisruptorFactory returns the factory of a NodeDisruptor for the arguments of its constructor
+func nodeDisruptorFactory(
+	rt *goja.Runtime,
+	args []goja.Value,
+	k8s kubernetes.Kubernetes,
+) (*disruptorFactory, error) {
+	if argument(args, 0).Equals(goja.Null()) {
+		return nil, fmt.Errorf("NodeDisruptor constructor expects a non null NodeSelector argument")
+	}
+
+	selector := disruptors.NodeSelector{}
+	err := convertValue(rt, argument(args, 0), &selector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid NodeSelector: %w", err)
+	}
+
+	options := disruptors.NodeDisruptorOptions{}
+	// options argument is optional
+	if len(args) > 1 {
+		err = convertValue(rt, args[1], &options)
+		if err != nil {
+			return nil, fmt.Errorf("invalid NodeDisruptorOptions: %w", err)
+		}
+	}
+
+	return &disruptorFactory{
+		name: "NodeDisruptor",
+		create: func(ctx context.Context) (interface{}, error) {
+			return disruptors.NewNodeDisruptor(ctx, k8s, selector, options)
+		},
+		build: func(caller jsCaller, disruptor interface{}) (*goja.Object, error) {
+			return buildJsNodeDisruptor(caller, disruptor.(disruptors.NodeDisruptor))
+		},
+	}, nil
 }
 
-// buildJsServiceDisruptor builds a goja object that implements the ServiceDisruptor API
-func buildJsServiceDisruptor(
+// NewDependencyDisruptor creates an instance of a DependencyDisruptor and returns it as a goja object
+// The context passed to this constructor is expected to control the lifecycle of the DependencyDisruptor
+func NewDependencyDisruptor(
 	ctx context.Context,
 	rt *goja.Runtime,
-	disruptor disruptors.ServiceDisruptor,
+	c goja.ConstructorCall,
+	k8s kubernetes.Kubernetes,
 ) (*goja.Object, error) {
-	d := &jsServiceDisruptor{
-		jsDisruptor: jsDisruptor{
-			ctx:       ctx,
-			rt:        rt,
-			Disruptor: disruptor,
-		},
-		jsProtocolFaultInjector: jsProtocolFaultInjector{
-			ctx:                   ctx,
-			rt:                    rt,
-			ProtocolFaultInjector: disruptor,
-		},
+	factory, err := dependencyDisruptorFactory(rt, c.Arguments, k8s)
+	if err != nil {
+		return nil, err
 	}
 
-	return buildObject(rt, d)
+	return factory.new(jsCaller{ctx: ctx, rt: rt})
 }
 
-// NewPodDisruptor creates an instance of a PodDisruptor
-// The context passed to this constructor is expected to control the lifecycle of the PodDisruptor
-func NewPodDisruptor(
-	ctx context.Context,
+// dependencyDisruptorFactory returns the factory of a DependencyDisruptor for the arguments of its constructor
+func dependencyDisruptorFactory(
 	rt *goja.Runtime,
-	c goja.ConstructorCall,
+	args []goja.Value,
 	k8s kubernetes.Kubernetes,
-) (*goja.Object, error) {
-	if c.Argument(0).Equals(goja.Null()) {
-		return nil, fmt.Errorf("PodDisruptor constructor expects a non null PodSelector argument")
+) (*disruptorFactory, error) {
+	if len(args) < 2 {
+		return nil, fmt.Errorf("DependencyDisruptor constructor requires PodSelector and Dependency parameters")
+	}
+
+	if args[0].Equals(goja.Null()) {
+		return nil, fmt.Errorf("DependencyDisruptor constructor expects a non null PodSelector argument")
 	}
 
 	selector := disruptors.PodSelector{}
-	err := convertValue(rt, c.Argument(0), &selector)
+	err := convertValue(rt, args[0], &selector)
 	if err != nil {
 		return nil, fmt.Errorf("invalid PodSelector: %w", err)
 	}
 
-	options := disruptors.PodDisruptorOptions{}
+	dependency := disruptors.Dependency{}
+	err = convertValue(rt, args[1], &dependency)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Dependency: %w", err)
+	}
+
+	options := disruptors.DependencyDisruptorOptions{}
 	// options argument is optional
-	if len(c.Arguments) > 1 {
-		err = convertValue(rt, c.Argument(1), &options)
+	if len(args) > 2 {
+		err = convertValue(rt, args[2], &options)
 		if err != nil {
-			return nil, fmt.Errorf("invalid PodDisruptorOptions: %w", err)
+			return nil, fmt.Errorf("invalid DependencyDisruptorOptions: %w", err)
 		}
 	}
 
-	disruptor, err := disruptors.NewPodDisruptor(ctx, k8s, selector, options)
+	return &disruptorFactory{
+		name: "DependencyDisruptor",
+		create: func(ctx context.Context) (interface{}, error) {
+			return disruptors.NewDependencyDisruptor(ctx, k8s, selector, dependency, options)
+		},
+		build: func(caller jsCaller, disruptor interface{}) (*goja.Object, error) {
+			return buildJsDependencyDisruptor(caller, disruptor.(disruptors.DependencyDisruptor))
+		},
+	}, nil
+}
+
+// NewWorkloadDisruptor creates an instance of a WorkloadDisruptor and returns it as a goja object
+// The context passed to this constructor is expected to control the lifecycle of the WorkloadDisruptor
+func NewWorkloadDisruptor(
+	ctx context.Context,
+	rt *goja.Runtime,
+	c goja.ConstructorCall,
+	k8s kubernetes.Kubernetes,
+) (*goja.Object, error) {
+	factory, err := workloadDisruptorFactory(rt, c.Arguments, k8s)
+	if err != nil {
+		return nil, err
+	}
+
+	return factory.new(jsCaller{ctx: ctx, rt: rt})
+}
+
+// workloadDisruptorFactory returns the factory of a WorkloadDisruptor for the arguments of its constructor
+func workloadDisruptorFactory(
+	rt *goja.Runtime,
+	args []goja.Value,
+	k8s kubernetes.Kubernetes,
+) (*disruptorFactory, error) {
+	if argument(args, 0).Equals(goja.Null()) {
+		return nil, fmt.Errorf("WorkloadDisruptor constructor expects a non null WorkloadSelector argument")
+	}
+
+	selector := disruptors.WorkloadSelector{}
+	err := convertValue(rt, argument(args, 0), &selector)
 	if err != nil {
-		return nil, fmt.Errorf("error creating PodDisruptor: %w", err)
+		return nil, fmt.Errorf("invalid WorkloadSelector: %w", err)
+	}
+
+	// scaling the replicas of a workload cannot be planned, so options such as dryRun must not be silently ignored
+	if !goja.IsUndefined(argument(args, 1)) {
+		return nil, fmt.Errorf("WorkloadDisruptor constructor does not accept options")
+	}
+
+	return &disruptorFactory{
+		name: "WorkloadDisruptor",
+		create: func(ctx context.Context) (interface{}, error) {
+			return disruptors.NewWorkloadDisruptor(ctx, k8s, selector)
+		},
+		build: func(caller jsCaller, disruptor interface{}) (*goja.Object, error) {
+			return buildJsWorkloadDisruptor(caller, disruptor.(disruptors.WorkloadDisruptor))
+		},
+	}, nil
+}
+
+// factories maps the name of the JS constructor of each disruptor to the function that returns its factory
+var factories = map[string]func(*goja.Runtime, []goja.Value, kubernetes.Kubernetes) (*disruptorFactory, error){
+	"PodDisruptor":         podDisruptorFactory,
+	"WorkloadPodDisruptor": workloadPodDisruptorFactory,
+	"ServiceDisruptor":     serviceDisruptorFactory,
+	"NodeDisruptor":        nodeDisruptorFactory,
+	"DependencyDisruptor":  dependencyDisruptorFactory,
+	"WorkloadDisruptor":    workloadDisruptorFactory,
+}
+
+// NewDisruptorAsync creates an instance of the disruptor with the given constructor name asynchronously, and returns a
+// promise that is resolved in the event loop of the VU with its JS object. The methods of the object return promises.
+// If the arguments are not valid, the promise is rejected. The context of the VU is expected to control the lifecycle
+// of the disruptor.
+func NewDisruptorAsync(
+	vu modules.VU,
+	name string,
+	args []goja.Value,
+	k8s kubernetes.Kubernetes,
+) (*goja.Promise, error) {
+	newFactory, found := factories[name]
+	if !found {
+		return nil, fmt.Errorf("unknown disruptor %q", name)
+	}
+
+	caller := jsCaller{
+		ctx:              vu.Context(),
+		rt:               vu.Runtime(),
+		registerCallback: vu.RegisterCallback,
 	}
 
-	obj, err := buildJsPodDisruptor(ctx, rt, disruptor)
+	factory, err := newFactory(vu.Runtime(), args, k8s)
 	if err != nil {
-		return nil, fmt.Errorf("error creating PodDisruptor: %w", err)
+		promise, _, reject := caller.rt.NewPromise()
+		reject(fmt.Errorf("error creating %s: %w", name, err))
+		return promise, nil
 	}
 
-	return obj, nil
+	return factory.newAsync(caller), nil
 }
 
-// NewServiceDisruptor creates an instance of a ServiceDisruptor and returns it as a goja object
-// The context passed to this constructor is expected to control the lifecycle of the ServiceDisruptor
-func NewServiceDisruptor(
+// RunScenario runs the scenario defined in the YAML or JSON file whose path is passed as argument.
+// The context passed to this function is expected to control the lifecycle of the scenario.
+func RunScenario(
 	ctx context.Context,
 	rt *goja.Runtime,
-	c goja.ConstructorCall,
+	args []goja.Value,
 	k8s kubernetes.Kubernetes,
-) (*goja.Object, error) {
-	if len(c.Arguments) < 2 {
-		return nil, fmt.Errorf("ServiceDisruptor constructor requires service and namespace parameters")
+) error {
+	if goja.IsUndefined(argument(args, 0)) || goja.IsNull(argument(args, 0)) {
+		return fmt.Errorf("runScenario requires the path to the scenario file")
 	}
 
-	var service string
-	err := convertValue(rt, c.Argument(0), &service)
+	var path string
+	err := convertValue(rt, args[0], &path)
 	if err != nil {
-		return nil, fmt.Errorf("invalid service name argument for ServiceDisruptor constructor: %w", err)
+		return fmt.Errorf("invalid scenario path: %w", err)
 	}
 
-	var namespace string
-	err = convertValue(rt, c.Argument(1), &namespace)
+	scenario, err := disruptors.LoadScenario(path)
 	if err != nil {
-		return nil, fmt.Errorf("invalid namespace argument for ServiceDisruptor constructor: %w", err)
+		return err
 	}
 
-	options := disruptors.ServiceDisruptorOptions{}
-	// options argument is optional
-	if len(c.Arguments) > 2 {
-		err = convertValue(rt, c.Argument(2), &options)
+	return disruptors.RunScenario(ctx, k8s, scenario)
+}
+
+// jsTimeline implements the JS interface for Timeline
+type jsTimeline struct {
+	jsCaller
+	timeline *disruptors.Timeline
+}
+
+// Run is a proxy method. Runs the steps of the timeline and waits for them to end
+func (t *jsTimeline) Run() goja.Value {
+	return t.call("error running timeline", func(ctx context.Context) (interface{}, error) {
+		return nil, t.timeline.Run(ctx)
+	})
+}
+
+// Start is a proxy method. Runs the steps of the timeline asynchronously, returning a handle for controlling them
+func (t *jsTimeline) Start() goja.Value {
+	// the report of a timeline does not describe any target, as each step injects a fault in different targets
+	handle := disruptors.StartFault(t.ctx, func(ctx context.Context) (disruptors.FaultReport, error) {
+		return disruptors.FaultReport{Targets: []disruptors.TargetReport{}}, t.timeline.Run(ctx)
+	})
+
+	return buildJsFaultHandle(t.jsCaller, handle)
+}
+
+// NewTimeline creates an instance of a Timeline from a list of steps. Each step references a disruptor and defines
+// the fault injected in it as in a scenario: type, params, options, start, duration, repeats and interval.
+// The context passed to this constructor is expected to control the lifecycle of the Timeline, so the steps that
+// did not end are cancelled when it is done.
+func NewTimeline(
+	ctx context.Context,
+	rt *goja.Runtime,
+	c goja.ConstructorCall,
+) (*goja.Object, error) {
+	arg := argument(c.Arguments, 0)
+	if goja.IsUndefined(arg) || goja.IsNull(arg) {
+		return nil, fmt.Errorf("Timeline constructor expects a list of steps")
+	}
+
+	var stepValues []goja.Value
+	err := rt.ExportTo(arg, &stepValues)
+	if err != nil {
+		return nil, fmt.Errorf("invalid steps: %w", err)
+	}
+
+	steps := []disruptors.TimelineStep{}
+	for i, stepValue := range stepValues {
+		step, err := timelineStep(stepValue)
 		if err != nil {
-			return nil, fmt.Errorf("invalid ServiceDisruptorOptions: %w", err)
+			return nil, fmt.Errorf("invalid steps[%d]: %w", i, err)
+		}
+
+		field := fmt.Sprintf("steps[%d]", i)
+		run := step.Run
+		step.Run = func(ctx context.Context) error {
+			if err := run(ctx); err != nil {
+				return fmt.Errorf("%s: %w", field, err)
+			}
+			return nil
 		}
+
+		steps = append(steps, step)
+	}
+
+	timeline, err := disruptors.NewTimeline(steps)
+	if err != nil {
+		return nil, err
+	}
+
+	return buildObject(rt, &jsTimeline{
+		jsCaller: jsCaller{ctx: ctx, rt: rt},
+		timeline: timeline,
+	})
+}
+
+// timelineStep returns the TimelineStep for the JS object of a step
+func timelineStep(value goja.Value) (disruptors.TimelineStep, error) {
+	obj, ok := value.(*goja.Object)
+	if !ok {
+		return disruptors.TimelineStep{}, fmt.Errorf("expected an object")
 	}
 
-	disruptor, err := disruptors.NewServiceDisruptor(ctx, k8s, service, namespace, options)
+	disruptor, err := disruptorOf(obj.Get("disruptor"))
 	if err != nil {
-		return nil, fmt.Errorf("error creating ServiceDisruptor: %w", err)
+		return disruptors.TimelineStep{}, fmt.Errorf("disruptor: %w", err)
+	}
+
+	attributes, ok := obj.Export().(map[string]interface{})
+	if !ok {
+		return disruptors.TimelineStep{}, fmt.Errorf("expected an object")
 	}
+	delete(attributes, "disruptor")
 
-	obj, err := buildJsServiceDisruptor(ctx, rt, disruptor)
+	fault := disruptors.ScenarioFault{}
+	err = Convert(attributes, &fault)
 	if err != nil {
-		return nil, fmt.Errorf("error creating ServiceDisruptor: %w", err)
+		return disruptors.TimelineStep{}, err
 	}
 
-	return obj, nil
+	return disruptors.FaultStep(disruptor, fault)
 }
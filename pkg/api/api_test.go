@@ -3,12 +3,17 @@ package api
 import (
 	"context"
 	"fmt"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/dop251/goja"
 	"github.com/grafana/xk6-disruptor/pkg/kubernetes"
 	"github.com/grafana/xk6-disruptor/pkg/testutils/kubernetes/builders"
 	"go.k6.io/k6/js/common"
+	"go.k6.io/k6/js/modulestest"
+	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
@@ -44,6 +49,8 @@ func testSetup() (*testEnv, error) {
 	rt.SetFieldNameMapper(common.FieldNameMapper{})
 
 	client := fake.NewSimpleClientset()
+	// WorkloadDisruptor scales workloads through their scale subresource
+	builders.AddScaleReactors(client)
 	k8s, err := kubernetes.NewFakeKubernetes(client)
 	if err != nil {
 		return nil, err
@@ -69,20 +76,29 @@ func testSetup() (*testEnv, error) {
 			Build(),
 		).
 		WithIP("192.0.2.6").
+		WithShareProcessNamespace(true).
+		WithOwnerReference("ReplicaSet", "some-deployment-5d8f").
+		WithContainerStatus(corev1.ContainerStatus{
+			Name:        "main",
+			ContainerID: "containerd://0123456789abcdef",
+		}).
 		Build()
 
 	// Constructors for ServiceDisruptor and PodDisruptor will also attempt to inject the disruptor agent into a target
 	// pod once it's discovered, and then wait for that container to be Running. Flagging this pod as ready is hard to
 	// do with the k8s fake client, so we take advantage of the fact that both injection and check are skipped if the
 	// agent container already exists by creating the fake pod with the sidecar already added.
-	agentContainer := corev1.EphemeralContainer{
-		EphemeralContainerCommon: corev1.EphemeralContainerCommon{
-			Name:  "xk6-agent",
-			Image: "fake.registry.local/xk6-agent",
-		},
-	}
+	// The same applies to the agents attached to the main container for injecting process and resource faults.
+	for _, name := range []string{"xk6-agent", "xk6-agent-main", "xk6-agent-cgroup-main"} {
+		agentContainer := corev1.EphemeralContainer{
+			EphemeralContainerCommon: corev1.EphemeralContainerCommon{
+				Name:  name,
+				Image: "fake.registry.local/xk6-agent",
+			},
+		}
 
-	pod.Spec.EphemeralContainers = append(pod.Spec.EphemeralContainers, agentContainer)
+		pod.Spec.EphemeralContainers = append(pod.Spec.EphemeralContainers, agentContainer)
+	}
 
 	_, err = k8s.Client().CoreV1().Pods(ns.Name).Create(context.TODO(), &pod, metav1.CreateOptions{})
 	if err != nil {
@@ -100,6 +116,57 @@ func testSetup() (*testEnv, error) {
 		return nil, fmt.Errorf("creating namespace: %w", err)
 	}
 
+	// Partition faults require peers with an IP address
+	peer := builders.NewPodBuilder("some-peer").
+		WithNamespace(ns.Name).
+		WithLabel("app", "peer").
+		WithIP("192.0.2.7").
+		Build()
+
+	_, err = k8s.Client().CoreV1().Pods(ns.Name).Create(context.TODO(), &peer, metav1.CreateOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("creating peer pod: %w", err)
+	}
+
+	// NodeDisruptor's constructor will error if it cannot find any node matching the selector
+	node := builders.NewNodeBuilder("some-node").
+		WithLabel("pool", "workers").
+		Build()
+
+	_, err = k8s.Client().CoreV1().Nodes().Create(context.TODO(), &node, metav1.CreateOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("creating node: %w", err)
+	}
+
+	// WorkloadDisruptor's constructor will error if the workload does not exist
+	replicas := int32(3)
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "some-deployment", Namespace: ns.Name},
+		Spec:       appsv1.DeploymentSpec{Replicas: &replicas},
+	}
+
+	_, err = k8s.Client().AppsV1().Deployments(ns.Name).Create(context.TODO(), deployment, metav1.CreateOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("creating deployment: %w", err)
+	}
+
+	// the pod is owned by the deployment through a replicaset
+	controller := true
+	replicaSet := &appsv1.ReplicaSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "some-deployment-5d8f",
+			Namespace: ns.Name,
+			OwnerReferences: []metav1.OwnerReference{
+				{APIVersion: "apps/v1", Kind: "Deployment", Name: deployment.Name, Controller: &controller},
+			},
+		},
+	}
+
+	_, err = k8s.Client().AppsV1().ReplicaSets(ns.Name).Create(context.TODO(), replicaSet, metav1.CreateOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("creating replicaset: %w", err)
+	}
+
 	return &testEnv{
 		rt:     rt,
 		client: client,
@@ -148,6 +215,212 @@ func Test_PodDisruptorConstructor(t *testing.T) {
 			`,
 			expectError: false,
 		},
+		{
+			description: "valid constructor with blast radius",
+			script: `
+			const selector = {
+				namespace: "namespace",
+				select: {
+					labels: {
+						app: "app"
+					}
+				}
+			}
+			const opts = {
+				blastRadius: {
+					percentage: 50,
+					seed: 42,
+					spreadBy: "node"
+				}
+			}
+			new PodDisruptor(selector, opts)
+			`,
+			expectError: false,
+		},
+		{
+			description: "valid constructor watching targets",
+			script: `
+			const selector = {
+				namespace: "namespace",
+				select: {
+					labels: {
+						app: "app"
+					}
+				}
+			}
+			const opts = {
+				watchTargets: true
+			}
+			new PodDisruptor(selector, opts)
+			`,
+			expectError: false,
+		},
+		{
+			description: "dry run returns the plan of the faults",
+			script: `
+			const selector = {
+				namespace: "namespace",
+				select: {
+					labels: {
+						app: "app"
+					}
+				}
+			}
+			const d = new PodDisruptor(selector, { dryRun: true })
+			const plan = d.injectHTTPFaults({ errorRate: 1.0, errorCode: 500, port: 80 }, "1s")
+			if (!plan.dryRun || plan.targets.length !== 1) {
+				throw new Error("unexpected plan " + JSON.stringify(plan))
+			}
+
+			const target = plan.targets[0]
+			if (target.name !== "some-pod" || target.port !== 80 || target.command.join(" ").indexOf("http") < 0) {
+				throw new Error("unexpected target " + JSON.stringify(target))
+			}
+			`,
+			expectError: false,
+		},
+		{
+			description: "dry run rejects faults that cannot be planned",
+			script: `
+			const selector = {
+				namespace: "namespace",
+				select: {
+					labels: {
+						app: "app"
+					}
+				}
+			}
+			const d = new PodDisruptor(selector, { dryRun: true })
+			d.stressCPU({ load: 50 }, "1s")
+			`,
+			expectError: true,
+		},
+		{
+			description: "invalid blast radius",
+			script: `
+			const selector = {
+				namespace: "namespace",
+				select: {
+					labels: {
+						app: "app"
+					}
+				}
+			}
+			const opts = {
+				blastRadius: {
+					count: 1,
+					percentage: 50
+				}
+			}
+			new PodDisruptor(selector, opts)
+			`,
+			expectError: true,
+		},
+		{
+			description: "valid constructor with multiple namespaces",
+			script: `
+			const selector = {
+				namespaces: ["namespace", "other-namespace"],
+				select: {
+					labels: {
+						app: "app"
+					}
+				}
+			}
+			new PodDisruptor(selector)
+			`,
+			expectError: false,
+		},
+		{
+			description: "valid constructor with namespace labels",
+			script: `
+			const selector = {
+				namespaceLabels: {
+					team: "payments"
+				},
+				select: {
+					labels: {
+						app: "app"
+					}
+				}
+			}
+			new PodDisruptor(selector)
+			`,
+			expectError: true, // no namespace is labeled
+		},
+		{
+			description: "valid constructor with annotations, phases and conditions",
+			script: `
+			const selector = {
+				namespace: "namespace",
+				select: {
+					labels: {
+						app: "app"
+					}
+				},
+				exclude: {
+					annotations: {
+						chaos: "skip"
+					},
+					phases: ["Pending"],
+					conditions: ["DisruptionTarget"]
+				}
+			}
+			new PodDisruptor(selector)
+			`,
+			expectError: false,
+		},
+		{
+			description: "valid constructor with label expressions",
+			script: `
+			const selector = {
+				namespace: "namespace",
+				select: {
+					expressions: [
+						{ key: "app", operator: "In", values: ["app", "api"] }
+					]
+				},
+				exclude: {
+					expressions: [
+						{ key: "canary", operator: "Exists" }
+					]
+				}
+			}
+			new PodDisruptor(selector)
+			`,
+			expectError: false,
+		},
+		{
+			description: "invalid constructor with malformed label expression",
+			script: `
+			const selector = {
+				namespace: "namespace",
+				select: {
+					expressions: [
+						{ key: "app", operator: "Equals", values: ["app"] }
+					]
+				}
+			}
+			new PodDisruptor(selector)
+			`,
+			expectError: true,
+		},
+		{
+			description: "constructor with selector that does not match any pod",
+			script: `
+			const selector = {
+				namespace: "namespace",
+				select: {
+					labels: {
+						app: "app"
+					},
+					conditions: ["Ready"]
+				}
+			}
+			new PodDisruptor(selector)
+			`,
+			expectError: true,
+		},
 		{
 			description: "invalid constructor without selector",
 			script: `
@@ -243,6 +516,8 @@ func Test_JsPodDisruptor(t *testing.T) {
 	testCases := []struct {
 		description string
 		script      string
+		// output of the commands executed in the targets
+		stdout      string
 		expectError bool
 	}{
 		{
@@ -273,6 +548,82 @@ func Test_JsPodDisruptor(t *testing.T) {
 			`,
 			expectError: false,
 		},
+		{
+			description: "inject HTTP Fault returns the requests handled",
+			script: `
+			const report = d.injectHTTPFaults({ errorRate: 1.0, errorCode: 500, port: 80 }, "1s")
+			const metrics = report.targets[0].metrics
+			if (report.dryRun || metrics.requests !== 10 || metrics.disrupted !== 5) {
+				throw new Error("unexpected report " + JSON.stringify(report))
+			}
+			`,
+			stdout:      `{"summary":{"requests":10,"excluded":0,"disrupted":5}}`,
+			expectError: false,
+		},
+		{
+			description: "start HTTP Fault and wait",
+			script: `
+			const fault = {
+				errorRate: 1.0,
+				errorCode: 500,
+				port: 80
+			}
+
+			const h = d.startHTTPFaults(fault, "1s")
+			h.wait()
+			if (h.status() !== "completed") {
+				throw new Error("unexpected status " + h.status())
+			}
+			`,
+			expectError: false,
+		},
+		{
+			description: "start HTTP Fault and stop",
+			script: `
+			const fault = {
+				errorRate: 1.0,
+				errorCode: 500,
+				port: 80
+			}
+
+			const h = d.startHTTPFaults(fault, "1s")
+			h.stop()
+			if (h.status() === "running") {
+				throw new Error("fault is still running")
+			}
+			`,
+			expectError: false,
+		},
+		{
+			description: "start HTTP Fault without duration",
+			script: `
+			const fault = {
+				errorRate: 1.0,
+				errorCode: 500,
+				port: 80
+			}
+
+			d.startHTTPFaults(fault)
+			`,
+			expectError: true,
+		},
+		{
+			description: "start Grpc Fault and wait",
+			script: `
+			const fault = {
+				errorRate: 1.0,
+				statusCode: 500,
+				port: 80
+			}
+
+			const h = d.startGrpcFaults(fault, "1s")
+			h.wait()
+			if (h.status() !== "completed") {
+				throw new Error("unexpected status " + h.status())
+			}
+			`,
+			expectError: false,
+		},
 		{
 			description: "inject HTTP Fault without options",
 			script: `
@@ -428,33 +779,288 @@ func Test_JsPodDisruptor(t *testing.T) {
 			`,
 			expectError: true,
 		},
-	}
-
-	for _, tc := range testCases {
-		tc := tc
-		t.Run(tc.description, func(t *testing.T) {
-			t.Parallel()
-
-			env, err := testSetup()
-			if err != nil {
-				t.Errorf("error in test setup %v", err)
-				return
-			}
-
-			err = env.registerConstructor("PodDisruptor", func(e *testEnv, c goja.ConstructorCall) (*goja.Object, error) {
-				return NewPodDisruptor(context.TODO(), e.rt, c, e.k8s)
-			})
-			if err != nil {
-				t.Errorf("error in test setup %v", err)
-				return
+		{
+			description: "inject Signal Fault",
+			script: `
+			const fault = {
+				container: "main",
+				signal: "SIGKILL"
 			}
 
-			_, err = env.rt.RunString(setupPodDisruptor)
-			if err != nil {
+			d.injectSignalFault(fault)
+			`,
+			expectError: false,
+		},
+		{
+			description: "inject Signal Fault without fault",
+			script: `
+			d.injectSignalFault()
+			`,
+			expectError: true,
+		},
+		{
+			description: "inject Signal Fault with malformed fault (misspelled field)",
+			script: `
+			const fault = {
+				sig: "SIGKILL",   // this should be 'signal'
+			}
+
+			d.injectSignalFault(fault)
+			`,
+			expectError: true,
+		},
+		{
+			description: "inject chaos",
+			script: `
+			const options = {
+				duration: "50ms",
+				interval: "20ms",
+				seed: 1,
+				faults: [
+					{ type: "kill", weight: 2 },
+					{ type: "error", errorCode: 503, port: 80 }
+				]
+			}
+
+			const report = d.injectChaos(options)
+			if (report.seed !== 1 || report.events.length !== 3) {
+				throw new Error("unexpected report " + JSON.stringify(report))
+			}
+			`,
+			expectError: false,
+		},
+		{
+			description: "inject chaos without faults",
+			script: `
+			d.injectChaos({ duration: "50ms", interval: "20ms" })
+			`,
+			expectError: true,
+		},
+		{
+			description: "inject chaos with malformed fault (misspelled field)",
+			script: `
+			d.injectChaos({ duration: "50ms", interval: "20ms", faults: [{ kind: "kill" }] })
+			`,
+			expectError: true,
+		},
+		{
+			description: "inject Freeze Fault",
+			script: `
+			const fault = {
+				container: "main"
+			}
+
+			d.injectFreezeFault(fault, "1s")
+			`,
+			expectError: false,
+		},
+		{
+			description: "inject Freeze Fault without duration",
+			script: `
+			const fault = {
+				container: "main"
+			}
+
+			d.injectFreezeFault(fault)
+			`,
+			expectError: true,
+		},
+		{
+			description: "inject Network Faults",
+			script: `
+			const fault = {
+				averageDelay: "100ms",
+				delayVariation: "10ms",
+				delayCorrelation: 0.25,
+				lossRate: 0.1,
+				duplicationRate: 0.01,
+				corruptionRate: 0.001,
+				reorderRate: 0.25,
+				port: 80,
+				peer: "10.0.0.0/8"
+			}
+
+			d.injectNetworkFaults(fault, "1s")
+			`,
+			expectError: false,
+		},
+		{
+			description: "inject Network Faults with invalid fault",
+			script: `
+			d.injectNetworkFaults({ jitter: "10ms" }, "1s")
+			`,
+			expectError: true,
+		},
+		{
+			description: "inject Partition Fault",
+			script: `
+			const fault = {
+				peers: {
+					namespace: "namespace",
+					select: {
+						labels: {
+							app: "peer"
+						}
+					}
+				},
+				direction: "outgoing"
+			}
+
+			d.injectPartitionFault(fault, "1s")
+			`,
+			expectError: false,
+		},
+		{
+			description: "inject Partition Fault without matching peers",
+			script: `
+			const fault = {
+				peers: {
+					namespace: "namespace",
+					select: {
+						labels: {
+							app: "other"
+						}
+					}
+				}
+			}
+
+			d.injectPartitionFault(fault, "1s")
+			`,
+			expectError: true,
+		},
+		{
+			description: "inject Partition Fault with invalid direction",
+			script: `
+			const fault = {
+				peers: {
+					namespace: "namespace",
+					select: {
+						labels: {
+							app: "peer"
+						}
+					}
+				},
+				direction: "sideways"
+			}
+
+			d.injectPartitionFault(fault, "1s")
+			`,
+			expectError: true,
+		},
+		{
+			description: "inject DNS Faults",
+			script: `
+			const fault = {
+				averageDelay: "100ms",
+				errorRate: 0.1,
+				error: "nxdomain",
+				names: "api.example.com"
+			}
+
+			d.injectDNSFaults(fault, "1s", { proxyPort: 9053 })
+			`,
+			expectError: false,
+		},
+		{
+			description: "inject DNS Faults with invalid fault",
+			script: `
+			d.injectDNSFaults({ rcode: "nxdomain" }, "1s")
+			`,
+			expectError: true,
+		},
+		{
+			description: "inject DNS Faults without duration",
+			script: `
+			d.injectDNSFaults({ errorRate: 0.1, error: "servfail" })
+			`,
+			expectError: true,
+		},
+		{
+			description: "stress CPU",
+			script: `
+			d.stressCPU({ cores: 2, load: 50 }, "1s")
+			`,
+			expectError: false,
+		},
+		{
+			description: "stress CPU with invalid load",
+			script: `
+			d.stressCPU({ cores: 2, load: 150 }, "1s")
+			`,
+			expectError: true,
+		},
+		{
+			description: "stress memory",
+			script: `
+			d.stressMemory({ amount: "256Mi", ramp: "10s", triggerOOM: true }, "1m")
+			`,
+			expectError: false,
+		},
+		{
+			description: "stress memory with amount and percentage",
+			script: `
+			d.stressMemory({ amount: "256Mi", percentage: 50 }, "1s")
+			`,
+			expectError: true,
+		},
+		{
+			description: "inject disk fill fault",
+			script: `
+			d.injectDiskFillFault({ path: "/data", percentage: 90 }, "1s")
+			`,
+			expectError: false,
+		},
+		{
+			description: "inject disk fill fault without path",
+			script: `
+			d.injectDiskFillFault({ percentage: 90 }, "1s")
+			`,
+			expectError: true,
+		},
+		{
+			description: "inject FD exhaustion fault",
+			script: `
+			d.injectFDExhaustionFault({ remaining: 10 }, "1s")
+			`,
+			expectError: false,
+		},
+		{
+			description: "inject FD exhaustion fault without duration",
+			script: `
+			d.injectFDExhaustionFault({ remaining: 10 })
+			`,
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.description, func(t *testing.T) {
+			t.Parallel()
+
+			env, err := testSetup()
+			if err != nil {
+				t.Errorf("error in test setup %v", err)
+				return
+			}
+
+			err = env.registerConstructor("PodDisruptor", func(e *testEnv, c goja.ConstructorCall) (*goja.Object, error) {
+				return NewPodDisruptor(context.TODO(), e.rt, c, e.k8s)
+			})
+			if err != nil {
+				t.Errorf("error in test setup %v", err)
+				return
+			}
+
+			_, err = env.rt.RunString(setupPodDisruptor)
+			if err != nil {
 				t.Errorf("error in test setup %v", err)
 				return
 			}
 
+			executor := env.k8s.(*kubernetes.FakeKubernetes).GetFakeProcessExecutor()
+			executor.SetResult([]byte(tc.stdout), []byte{}, nil)
+
 			_, err = env.rt.RunString(tc.script)
 
 			if !tc.expectError && err != nil {
@@ -495,6 +1101,17 @@ func Test_ServiceDisruptorConstructor(t *testing.T) {
 			`,
 			expectError: false,
 		},
+		{
+			description: "dry run returns the plan of the faults",
+			script: `
+			const d = new ServiceDisruptor("some-service", "namespace", { dryRun: true })
+			const plan = d.injectGrpcFaults({ statusCode: 14, port: 80 }, "1s")
+			if (!plan.dryRun || plan.targets.length !== 1 || plan.targets[0].port !== 80) {
+				throw new Error("unexpected plan " + JSON.stringify(plan))
+			}
+			`,
+			expectError: false,
+		},
 		{
 			description: "invalid constructor without namespace",
 			script: `
@@ -574,3 +1191,1058 @@ func Test_ServiceDisruptorConstructor(t *testing.T) {
 		})
 	}
 }
+
+func Test_JsNodeDisruptor(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		description string
+		script      string
+		expectError bool
+	}{
+		{
+			description: "valid constructor",
+			script: `
+			const selector = {
+				select: {
+					labels: {
+						pool: "workers"
+					}
+				}
+			}
+			const opts = {
+				injectTimeout: "-1s",
+				agentNamespace: "namespace"
+			}
+			new NodeDisruptor(selector, opts)
+			`,
+			expectError: false,
+		},
+		{
+			description: "invalid constructor without agent namespace",
+			script: `
+			new NodeDisruptor({ select: { labels: { pool: "workers" } } }, { injectTimeout: "-1s" })
+			`,
+			expectError: true,
+		},
+		{
+			description: "invalid constructor without selector",
+			script: `
+			new NodeDisruptor()
+			`,
+			expectError: true,
+		},
+		{
+			description: "invalid constructor with empty selector",
+			script: `
+			new NodeDisruptor({}, { injectTimeout: "-1s" })
+			`,
+			expectError: true,
+		},
+		{
+			description: "invalid selector",
+			script: `
+			new NodeDisruptor({ select: { nodeName: "some-node" } }, { injectTimeout: "-1s" })
+			`,
+			expectError: true,
+		},
+		{
+			description: "inject network faults",
+			script: `
+			const d = new NodeDisruptor(
+				{ select: { labels: { pool: "workers" } } },
+				{ injectTimeout: "-1s", agentNamespace: "xk6-agents" },
+			)
+			d.injectNetworkFaults({ averageDelay: "100ms", lossRate: 0.1 }, "1s")
+			`,
+			expectError: false,
+		},
+		{
+			description: "inject network faults without duration",
+			script: `
+			const d = new NodeDisruptor(
+				{ select: { labels: { pool: "workers" } } },
+				{ injectTimeout: "-1s", agentNamespace: "xk6-agents" },
+			)
+			d.injectNetworkFaults({ averageDelay: "100ms" })
+			`,
+			expectError: true,
+		},
+		{
+			description: "stress cpu",
+			script: `
+			const d = new NodeDisruptor(
+				{ select: { labels: { pool: "workers" } } },
+				{ injectTimeout: "-1s", agentNamespace: "xk6-agents" },
+			)
+			d.stressCPU({ cores: 2 }, "1s")
+			`,
+			expectError: false,
+		},
+		{
+			description: "stress memory",
+			script: `
+			const d = new NodeDisruptor(
+				{ select: { labels: { pool: "workers" } } },
+				{ injectTimeout: "-1s", agentNamespace: "xk6-agents" },
+			)
+			d.stressMemory({ amount: "256Mi" }, "1s")
+			`,
+			expectError: false,
+		},
+		{
+			description: "stress memory with invalid amount",
+			script: `
+			const d = new NodeDisruptor(
+				{ select: { labels: { pool: "workers" } } },
+				{ injectTimeout: "-1s", agentNamespace: "xk6-agents" },
+			)
+			d.stressMemory({ amount: "lots" }, "1s")
+			`,
+			expectError: true,
+		},
+		{
+			description: "targets and cleanup",
+			script: `
+			const d = new NodeDisruptor(
+				{ select: { labels: { pool: "workers" } } },
+				{ injectTimeout: "-1s", agentNamespace: "xk6-agents" },
+			)
+			const targets = d.targets()
+			if (targets.length != 1 || targets[0] != "some-node") {
+				throw new Error("unexpected targets " + targets)
+			}
+			d.cleanup()
+			`,
+			expectError: false,
+		},
+		{
+			description: "cordon nodes",
+			script: `
+			const d = new NodeDisruptor(
+				{ select: { labels: { pool: "workers" } } },
+				{ injectTimeout: "-1s", agentNamespace: "xk6-agents" },
+			)
+			d.cordonNodes("1s")
+			`,
+			expectError: false,
+		},
+		{
+			description: "drain nodes",
+			script: `
+			const d = new NodeDisruptor(
+				{ select: { labels: { pool: "workers" } } },
+				{ injectTimeout: "-1s", agentNamespace: "xk6-agents" },
+			)
+			d.drainNodes("1s", { ignorePDB: true, timeout: "5s" })
+			`,
+			expectError: false,
+		},
+		{
+			description: "drain nodes with invalid options",
+			script: `
+			const d = new NodeDisruptor(
+				{ select: { labels: { pool: "workers" } } },
+				{ injectTimeout: "-1s", agentNamespace: "xk6-agents" },
+			)
+			d.drainNodes("1s", { force: true })
+			`,
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.description, func(t *testing.T) {
+			t.Parallel()
+			env, err := testSetup()
+			if err != nil {
+				t.Errorf("error in test setup %v", err)
+				return
+			}
+
+			err = env.registerConstructor("NodeDisruptor", func(e *testEnv, c goja.ConstructorCall) (*goja.Object, error) {
+				return NewNodeDisruptor(context.TODO(), e.rt, c, e.k8s)
+			})
+			if err != nil {
+				t.Errorf("error in test setup %v", err)
+				return
+			}
+
+			_, err = env.rt.RunString(tc.script)
+
+			if !tc.expectError && err != nil {
+				t.Errorf("failed %v", err)
+				return
+			}
+
+			if tc.expectError && err == nil {
+				t.Errorf("should had failed")
+				return
+			}
+		})
+	}
+}
+
+func Test_DependencyDisruptorConstructor(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		description string
+		script      string
+		expectError bool
+	}{
+		{
+			description: "valid constructor",
+			script: `
+			const selector = { namespace: "namespace", select: { labels: { app: "app" } } }
+			new DependencyDisruptor(selector, { host: "api.example.com", port: 443 })
+			`,
+			expectError: false,
+		},
+		{
+			description: "valid constructor with options",
+			script: `
+			const selector = { namespace: "namespace", select: { labels: { app: "app" } } }
+			new DependencyDisruptor(selector, { host: "10.0.0.0/8", port: 5432 }, { injectTimeout: "10s" })
+			`,
+			expectError: false,
+		},
+		{
+			description: "invalid constructor without dependency",
+			script: `
+			const selector = { namespace: "namespace", select: { labels: { app: "app" } } }
+			new DependencyDisruptor(selector)
+			`,
+			expectError: true,
+		},
+		{
+			description: "invalid constructor with malformed dependency",
+			script: `
+			const selector = { namespace: "namespace", select: { labels: { app: "app" } } }
+			new DependencyDisruptor(selector, { address: "api.example.com" })
+			`,
+			expectError: true,
+		},
+		{
+			description: "invalid constructor without dependency port",
+			script: `
+			const selector = { namespace: "namespace", select: { labels: { app: "app" } } }
+			new DependencyDisruptor(selector, { host: "api.example.com" })
+			`,
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.description, func(t *testing.T) {
+			t.Parallel()
+
+			env, err := testSetup()
+			if err != nil {
+				t.Errorf("error in test setup %v", err)
+				return
+			}
+
+			err = env.registerConstructor("DependencyDisruptor", func(e *testEnv, c goja.ConstructorCall) (*goja.Object, error) {
+				return NewDependencyDisruptor(context.TODO(), e.rt, c, e.k8s)
+			})
+			if err != nil {
+				t.Errorf("error in test setup %v", err)
+				return
+			}
+
+			_, err = env.rt.RunString(tc.script)
+
+			if !tc.expectError && err != nil {
+				t.Errorf("failed %v", err)
+				return
+			}
+
+			if tc.expectError && err == nil {
+				t.Errorf("should had failed")
+				return
+			}
+		})
+	}
+}
+
+const setupDependencyDisruptor = `
+const selector = {
+	namespace: "namespace",
+	select: {
+		labels: {
+			app: "app"
+		}
+	}
+}
+
+const d = new DependencyDisruptor(selector, { host: "api.example.com", port: 443 })
+`
+
+func Test_JsDependencyDisruptor(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		description string
+		script      string
+		expectError bool
+	}{
+		{
+			description: "inject http faults",
+			script: `
+			d.injectHTTPFaults({ errorRate: 0.1, errorCode: 503 }, "1s")
+			`,
+			expectError: false,
+		},
+		{
+			description: "inject grpc faults",
+			script: `
+			d.injectGrpcFaults({ averageDelay: "100ms", port: 443 }, "1s", { proxyPort: 4000 })
+			`,
+			expectError: false,
+		},
+		{
+			description: "inject http faults in a port other than the dependency's",
+			script: `
+			d.injectHTTPFaults({ errorRate: 0.1, errorCode: 503, port: 80 }, "1s")
+			`,
+			expectError: true,
+		},
+		{
+			description: "inject tcp faults",
+			script: `
+			d.injectTCPFaults({ averageDelay: "100ms", errorRate: 0.1 }, "1s")
+			`,
+			expectError: false,
+		},
+		{
+			description: "inject tcp faults with invalid fault",
+			script: `
+			d.injectTCPFaults({ lossRate: 0.1 }, "1s")
+			`,
+			expectError: true,
+		},
+		{
+			description: "inject tcp faults without duration",
+			script: `
+			d.injectTCPFaults({ errorRate: 0.1 })
+			`,
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.description, func(t *testing.T) {
+			t.Parallel()
+
+			env, err := testSetup()
+			if err != nil {
+				t.Errorf("error in test setup %v", err)
+				return
+			}
+
+			err = env.registerConstructor("DependencyDisruptor", func(e *testEnv, c goja.ConstructorCall) (*goja.Object, error) {
+				return NewDependencyDisruptor(context.TODO(), e.rt, c, e.k8s)
+			})
+			if err != nil {
+				t.Errorf("error in test setup %v", err)
+				return
+			}
+
+			_, err = env.rt.RunString(setupDependencyDisruptor)
+			if err != nil {
+				t.Errorf("error in test setup %v", err)
+				return
+			}
+
+			_, err = env.rt.RunString(tc.script)
+
+			if !tc.expectError && err != nil {
+				t.Errorf("failed %v", err)
+				return
+			}
+
+			if tc.expectError && err == nil {
+				t.Errorf("should had failed")
+				return
+			}
+		})
+	}
+}
+
+func Test_JsWorkloadDisruptor(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		description string
+		script      string
+		expectError bool
+	}{
+		{
+			description: "valid constructor",
+			script: `
+			const d = new WorkloadDisruptor({ kind: "Deployment", name: "some-deployment", namespace: "namespace" })
+			const targets = d.targets()
+			if (targets.length != 1 || targets[0] != "some-deployment") {
+				throw new Error("unexpected targets " + targets)
+			}
+			`,
+			expectError: false,
+		},
+		{
+			description: "invalid constructor without selector",
+			script: `
+			new WorkloadDisruptor()
+			`,
+			expectError: true,
+		},
+		{
+			description: "invalid selector",
+			script: `
+			new WorkloadDisruptor({ kind: "Deployment", name: "some-deployment", replicas: 1 })
+			`,
+			expectError: true,
+		},
+		{
+			description: "workload does not exist",
+			script: `
+			new WorkloadDisruptor({ kind: "StatefulSet", name: "some-deployment", namespace: "namespace" })
+			`,
+			expectError: true,
+		},
+		{
+			description: "dry run is not supported",
+			script: `
+			new WorkloadDisruptor({ kind: "Deployment", name: "some-deployment", namespace: "namespace" }, { dryRun: true })
+			`,
+			expectError: true,
+		},
+		{
+			description: "scale replicas",
+			script: `
+			const d = new WorkloadDisruptor({ kind: "Deployment", name: "some-deployment", namespace: "namespace" })
+			d.scaleReplicas({ fraction: 0.5 }, "1s")
+			`,
+			expectError: false,
+		},
+		{
+			description: "scale replicas with invalid fault",
+			script: `
+			const d = new WorkloadDisruptor({ kind: "Deployment", name: "some-deployment", namespace: "namespace" })
+			d.scaleReplicas({ replicas: 5 }, "1s")
+			`,
+			expectError: true,
+		},
+		{
+			description: "scale replicas without duration",
+			script: `
+			const d = new WorkloadDisruptor({ kind: "Deployment", name: "some-deployment", namespace: "namespace" })
+			d.scaleReplicas({ replicas: 1 })
+			`,
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.description, func(t *testing.T) {
+			t.Parallel()
+
+			env, err := testSetup()
+			if err != nil {
+				t.Errorf("error in test setup %v", err)
+				return
+			}
+
+			err = env.registerConstructor("WorkloadDisruptor", func(e *testEnv, c goja.ConstructorCall) (*goja.Object, error) {
+				return NewWorkloadDisruptor(context.TODO(), e.rt, c, e.k8s)
+			})
+			if err != nil {
+				t.Errorf("error in test setup %v", err)
+				return
+			}
+
+			_, err = env.rt.RunString(tc.script)
+
+			if !tc.expectError && err != nil {
+				t.Errorf("failed %v", err)
+				return
+			}
+
+			if tc.expectError && err == nil {
+				t.Errorf("should had failed")
+				return
+			}
+		})
+	}
+}
+
+// Test_JsWorkloadDisruptorAbort checks the replicas of the workload are restored when the test is aborted while it is
+// scaled down, which cancels the context of the VU
+func Test_JsWorkloadDisruptorAbort(t *testing.T) {
+	t.Parallel()
+
+	env, err := testSetup()
+	if err != nil {
+		t.Fatalf("error in test setup %v", err)
+	}
+
+	ctx, abort := context.WithCancel(context.Background())
+	defer abort()
+
+	err = env.registerConstructor("WorkloadDisruptor", func(e *testEnv, c goja.ConstructorCall) (*goja.Object, error) {
+		return NewWorkloadDisruptor(ctx, e.rt, c, e.k8s)
+	})
+	if err != nil {
+		t.Fatalf("error in test setup %v", err)
+	}
+
+	deployments := env.client.AppsV1().Deployments("namespace")
+
+	// abort the test once the workload is scaled down
+	scaled := make(chan int32, 1)
+	go func() {
+		time.Sleep(500 * time.Millisecond)
+		replicas := int32(-1)
+		if deployment, gerr := deployments.Get(context.TODO(), "some-deployment", metav1.GetOptions{}); gerr == nil {
+			replicas = *deployment.Spec.Replicas
+		}
+		scaled <- replicas
+		abort()
+	}()
+
+	_, err = env.rt.RunString(`
+	const d = new WorkloadDisruptor({ kind: "Deployment", name: "some-deployment", namespace: "namespace" })
+	d.scaleReplicas({ replicas: 0 }, "10s")
+	`)
+	if err == nil {
+		t.Fatalf("should had failed")
+	}
+
+	if replicas := <-scaled; replicas != 0 {
+		t.Errorf("expected %d replicas while scaled got %d", 0, replicas)
+	}
+
+	deployment, err := deployments.Get(context.TODO(), "some-deployment", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("getting deployment: %v", err)
+	}
+
+	if *deployment.Spec.Replicas != 3 {
+		t.Errorf("expected %d replicas after the test is aborted got %d", 3, *deployment.Spec.Replicas)
+	}
+
+	if len(deployment.Annotations) != 0 {
+		t.Errorf("expected original replicas annotation to be removed")
+	}
+}
+
+func Test_WorkloadPodDisruptorConstructor(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		description string
+		script      string
+		expectError bool
+	}{
+		{
+			description: "valid constructor",
+			script: `
+			const d = new WorkloadPodDisruptor({ kind: "Deployment", name: "some-deployment", namespace: "namespace" })
+			const targets = d.targets()
+			if (targets.length != 1 || targets[0] != "some-pod") {
+				throw new Error("unexpected targets " + targets)
+			}
+			`,
+			expectError: false,
+		},
+		{
+			description: "valid constructor with options",
+			script: `
+			new WorkloadPodDisruptor(
+				{ kind: "ReplicaSet", name: "some-deployment-5d8f", namespace: "namespace" },
+				{ injectTimeout: "-1s" },
+			)
+			`,
+			expectError: false,
+		},
+		{
+			description: "workload without pods",
+			script: `
+			new WorkloadPodDisruptor({ kind: "StatefulSet", name: "some-deployment", namespace: "namespace" })
+			`,
+			expectError: true,
+		},
+		{
+			description: "invalid constructor without kind",
+			script: `
+			new WorkloadPodDisruptor({ name: "some-deployment", namespace: "namespace" })
+			`,
+			expectError: true,
+		},
+		{
+			description: "invalid constructor without selector",
+			script: `
+			new WorkloadPodDisruptor()
+			`,
+			expectError: true,
+		},
+		{
+			description: "pod selector with owner",
+			script: `
+			new PodDisruptor({ namespace: "namespace", owner: { kind: "Deployment", name: "some-deployment" } })
+			`,
+			expectError: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.description, func(t *testing.T) {
+			t.Parallel()
+
+			env, err := testSetup()
+			if err != nil {
+				t.Errorf("error in test setup %v", err)
+				return
+			}
+
+			err = env.registerConstructor(
+				"WorkloadPodDisruptor",
+				func(e *testEnv, c goja.ConstructorCall) (*goja.Object, error) {
+					return NewWorkloadPodDisruptor(context.TODO(), e.rt, c, e.k8s)
+				},
+			)
+			if err != nil {
+				t.Errorf("error in test setup %v", err)
+				return
+			}
+
+			err = env.registerConstructor("PodDisruptor", func(e *testEnv, c goja.ConstructorCall) (*goja.Object, error) {
+				return NewPodDisruptor(context.TODO(), e.rt, c, e.k8s)
+			})
+			if err != nil {
+				t.Errorf("error in test setup %v", err)
+				return
+			}
+
+			_, err = env.rt.RunString(tc.script)
+
+			if !tc.expectError && err != nil {
+				t.Errorf("failed %v", err)
+				return
+			}
+
+			if tc.expectError && err == nil {
+				t.Errorf("should had failed")
+				return
+			}
+		})
+	}
+}
+
+func Test_AsyncDisruptors(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		description string
+		script      string
+		expectError bool
+	}{
+		{
+			description: "create pod disruptor and inject faults",
+			script: `
+			(async () => {
+				const d = await createPodDisruptor({ namespace: "namespace", select: { labels: { app: "app" } } })
+				const targets = await d.targets()
+				if (targets.length !== 1 || targets[0] !== "some-pod") {
+					throw new Error("unexpected targets " + targets)
+				}
+				await d.injectHTTPFaults({ errorRate: 1.0, errorCode: 500, port: 80 }, "1s")
+			})()
+			`,
+			expectError: false,
+		},
+		{
+			description: "inject faults concurrently",
+			script: `
+			(async () => {
+				const d = await createPodDisruptor({ namespace: "namespace", select: { labels: { app: "app" } } })
+				await Promise.all([
+					d.injectHTTPFaults({ errorRate: 1.0, errorCode: 500, port: 80 }, "1s"),
+					d.injectGrpcFaults({ errorRate: 1.0, statusCode: 14, port: 80 }, "1s"),
+					d.stressCPU({ load: 50 }, "1s"),
+				])
+			})()
+			`,
+			expectError: false,
+		},
+		{
+			description: "fault handle",
+			script: `
+			(async () => {
+				const d = await createPodDisruptor({ namespace: "namespace", select: { labels: { app: "app" } } })
+				const h = d.startHTTPFaults({ errorRate: 1.0, errorCode: 500, port: 80 }, "1s")
+				const report = await h.wait()
+				if (h.status() !== "completed") {
+					throw new Error("unexpected status " + h.status())
+				}
+				if (report.targets.length !== 1) {
+					throw new Error("unexpected report " + JSON.stringify(report))
+				}
+			})()
+			`,
+			expectError: false,
+		},
+		{
+			description: "create disruptor without matching pods",
+			script: `
+			(async () => {
+				await createPodDisruptor({ namespace: "namespace", select: { labels: { app: "other" } } })
+			})()
+			`,
+			expectError: true,
+		},
+		{
+			description: "timeline with disruptor created asynchronously",
+			script: `
+			(async () => {
+				const d = await createPodDisruptor({ namespace: "namespace", select: { labels: { app: "app" } } })
+				new Timeline([{ disruptor: d, type: "http", params: { errorRate: 0.1, errorCode: 503 }, duration: "1s" }]).run()
+			})()
+			`,
+			expectError: false,
+		},
+		{
+			description: "rejected fault is caught",
+			script: `
+			(async () => {
+				const d = await createPodDisruptor({ namespace: "namespace", select: { labels: { app: "app" } } })
+				try {
+					await d.stressMemory({ amount: "lots" }, "1s")
+				} catch (e) {
+					return
+				}
+				throw new Error("should had failed")
+			})()
+			`,
+			expectError: false,
+		},
+		{
+			description: "invalid arguments reject the promise",
+			script: `
+			(async () => {
+				const d = await createPodDisruptor({ namespace: "namespace", select: { labels: { app: "app" } } })
+				const rejected = await Promise.all([
+					d.injectHTTPFaults({ errorRate: 1.0, errorCode: 500, port: 80 }).then(() => false, () => true),
+					d.injectGrpcFaults({ errorRate: "high" }, "1s").then(() => false, () => true),
+					d.stressCPU({ load: 50 }).then(() => false, () => true),
+				])
+				if (rejected.some((r) => !r)) {
+					throw new Error("should had failed " + rejected)
+				}
+			})()
+			`,
+			expectError: false,
+		},
+		{
+			description: "invalid constructor arguments reject the promise",
+			script: `
+			(async () => {
+				const rejected = await createPodDisruptor().then(() => false, () => true)
+				if (!rejected) {
+					throw new Error("should had failed")
+				}
+			})()
+			`,
+			expectError: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.description, func(t *testing.T) {
+			t.Parallel()
+
+			env, err := testSetup()
+			if err != nil {
+				t.Fatalf("error in test setup %v", err)
+			}
+
+			runtime := modulestest.NewRuntime(t)
+			rt := runtime.VU.Runtime()
+			err = rt.Set("createPodDisruptor", func(c goja.FunctionCall) goja.Value {
+				promise, err := NewDisruptorAsync(runtime.VU, "PodDisruptor", c.Arguments, env.k8s)
+				if err != nil {
+					common.Throw(rt, err)
+				}
+				return rt.ToValue(promise)
+			})
+			if err != nil {
+				t.Fatalf("error registering function %v", err)
+			}
+
+			err = rt.Set("Timeline", func(c goja.ConstructorCall) *goja.Object {
+				timeline, err := NewTimeline(runtime.VU.Context(), rt, c)
+				if err != nil {
+					common.Throw(rt, err)
+				}
+				return timeline
+			})
+			if err != nil {
+				t.Fatalf("error registering constructor %v", err)
+			}
+
+			_, err = runtime.RunOnEventLoop(tc.script)
+			if tc.expectError && err == nil {
+				t.Errorf("should had failed")
+			}
+
+			if !tc.expectError && err != nil {
+				t.Errorf("failed: %v", err)
+			}
+		})
+	}
+}
+
+func Test_RunScenario(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		description string
+		scenario    string
+		expectError bool
+	}{
+		{
+			description: "valid scenario",
+			scenario: `
+faults:
+  - target:
+      pods:
+        namespace: namespace
+        select:
+          labels:
+            app: app
+    type: http
+    params:
+      errorRate: 0.1
+      errorCode: 503
+    duration: 1s
+  - target:
+      service:
+        name: some-service
+        namespace: namespace
+    type: network
+    params:
+      lossRate: 0.1
+    start: 10ms
+    duration: 1s
+`,
+			expectError: false,
+		},
+		{
+			description: "invalid scenario",
+			scenario: `
+faults:
+  - target:
+      service:
+        name: some-service
+        namespace: namespace
+    type: cpu
+    duration: 1s
+`,
+			expectError: true,
+		},
+		{
+			description: "target does not exist",
+			scenario: `
+faults:
+  - target:
+      service:
+        name: other-service
+        namespace: namespace
+    type: http
+    duration: 1s
+`,
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.description, func(t *testing.T) {
+			t.Parallel()
+
+			env, err := testSetup()
+			if err != nil {
+				t.Errorf("error in test setup %v", err)
+				return
+			}
+
+			path := filepath.Join(t.TempDir(), "scenario.yaml")
+			err = os.WriteFile(path, []byte(tc.scenario), 0o600)
+			if err != nil {
+				t.Errorf("error in test setup %v", err)
+				return
+			}
+
+			err = env.rt.Set("runScenario", func(c goja.FunctionCall) goja.Value {
+				if err := RunScenario(context.TODO(), env.rt, c.Arguments, env.k8s); err != nil {
+					common.Throw(env.rt, err)
+				}
+				return goja.Undefined()
+			})
+			if err != nil {
+				t.Errorf("error in test setup %v", err)
+				return
+			}
+
+			_, err = env.rt.RunString(fmt.Sprintf("runScenario(%q)", path))
+
+			if !tc.expectError && err != nil {
+				t.Errorf("failed %v", err)
+				return
+			}
+
+			if tc.expectError && err == nil {
+				t.Errorf("should had failed")
+				return
+			}
+		})
+	}
+}
+
+const setupTimeline = `
+const pod = new PodDisruptor({ namespace: "namespace", select: { labels: { app: "app" } } })
+const svc = new ServiceDisruptor("some-service", "namespace")
+`
+
+func Test_JsTimeline(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		description string
+		script      string
+		expectError bool
+	}{
+		{
+			description: "run timeline",
+			script: `
+			const t = new Timeline([
+				{ disruptor: pod, type: "http", params: { errorRate: 0.1, errorCode: 503 }, duration: "1s" },
+				{ disruptor: svc, type: "network", params: { lossRate: 0.1 }, start: "10ms", duration: "1s" },
+				{ disruptor: pod, type: "signal", params: { signal: "SIGTERM" }, start: "20ms" },
+			])
+			t.run()
+			`,
+			expectError: false,
+		},
+		{
+			description: "start timeline",
+			script: `
+			const h = new Timeline([
+				{ disruptor: pod, type: "cpu", params: { load: 50 }, duration: "10ms", repeats: 2, interval: "10ms" },
+			]).start()
+			h.wait()
+			if (h.status() !== "completed") {
+				throw new Error("unexpected status " + h.status())
+			}
+			`,
+			expectError: false,
+		},
+		{
+			description: "step without disruptor",
+			script: `
+			new Timeline([{ type: "http", duration: "1s" }])
+			`,
+			expectError: true,
+		},
+		{
+			description: "step with an object that is not a disruptor",
+			script: `
+			new Timeline([{ disruptor: { targets: () => [] }, type: "http", duration: "1s" }])
+			`,
+			expectError: true,
+		},
+		{
+			description: "fault not supported by the disruptor",
+			script: `
+			new Timeline([{ disruptor: svc, type: "cpu", duration: "1s" }])
+			`,
+			expectError: true,
+		},
+		{
+			description: "invalid fault parameter",
+			script: `
+			new Timeline([{ disruptor: pod, type: "http", params: { errorRate: "high" }, duration: "1s" }])
+			`,
+			expectError: true,
+		},
+		{
+			description: "no steps",
+			script: `
+			new Timeline([])
+			`,
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.description, func(t *testing.T) {
+			t.Parallel()
+
+			env, err := testSetup()
+			if err != nil {
+				t.Errorf("error in test setup %v", err)
+				return
+			}
+
+			err = env.registerConstructor("PodDisruptor", func(e *testEnv, c goja.ConstructorCall) (*goja.Object, error) {
+				return NewPodDisruptor(context.TODO(), e.rt, c, e.k8s)
+			})
+			if err != nil {
+				t.Errorf("error in test setup %v", err)
+				return
+			}
+
+			err = env.registerConstructor("ServiceDisruptor", func(e *testEnv, c goja.ConstructorCall) (*goja.Object, error) {
+				return NewServiceDisruptor(context.TODO(), e.rt, c, e.k8s)
+			})
+			if err != nil {
+				t.Errorf("error in test setup %v", err)
+				return
+			}
+
+			err = env.registerConstructor("Timeline", func(e *testEnv, c goja.ConstructorCall) (*goja.Object, error) {
+				return NewTimeline(context.TODO(), e.rt, c)
+			})
+			if err != nil {
+				t.Errorf("error in test setup %v", err)
+				return
+			}
+
+			_, err = env.rt.RunString(setupTimeline)
+			if err != nil {
+				t.Errorf("error in test setup %v", err)
+				return
+			}
+
+			_, err = env.rt.RunString(tc.script)
+
+			if !tc.expectError && err != nil {
+				t.Errorf("failed %v", err)
+				return
+			}
+
+			if tc.expectError && err == nil {
+				t.Errorf("should had failed")
+				return
+			}
+		})
+	}
+}
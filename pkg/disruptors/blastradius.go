@@ -0,0 +1,236 @@
+package disruptors
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"time"
+
+	"github.com/grafana/xk6-disruptor/pkg/kubernetes"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Domains the targets can be spread across
+const (
+	SpreadByNode = "node"
+	SpreadByZone = "zone"
+)
+
+// BlastRadius limits the targets of a disruptor to a sample of the pods that match its selector.
+// If neither Count nor Percentage are set, all the matching pods are targeted.
+type BlastRadius struct {
+	// Maximum number of pods selected as targets
+	Count uint `js:"count"`
+	// Percentage (1 to 100) of the matching pods selected as targets, rounded up
+	Percentage uint `js:"percentage"`
+	// Seed for sampling the pods. The same seed selects the same pods from the same set of matching pods.
+	// If zero, the pods are sampled randomly.
+	Seed int64 `js:"seed"`
+	// Spread the selected pods across "node" or "zone". If empty, pods are selected regardless of their location.
+	SpreadBy string `js:"spreadBy"`
+}
+
+// validateBlastRadius validates a BlastRadius
+func validateBlastRadius(radius BlastRadius) error {
+	if radius.Count > 0 && radius.Percentage > 0 {
+		return fmt.Errorf("count and percentage cannot both be specified")
+	}
+
+	if radius.Percentage > 100 {
+		return fmt.Errorf("percentage must be in the range [1, 100]")
+	}
+
+	switch radius.SpreadBy {
+	case "", SpreadByNode, SpreadByZone:
+	default:
+		return fmt.Errorf("invalid spreadBy %q. Must be %q or %q", radius.SpreadBy, SpreadByNode, SpreadByZone)
+	}
+
+	return nil
+}
+
+// sampleSize returns the number of pods selected from the given number of matching pods
+func (r BlastRadius) sampleSize(matching int) int {
+	switch {
+	case r.Count > 0:
+		if int(r.Count) < matching {
+			return int(r.Count)
+		}
+		return matching
+	case r.Percentage > 0:
+		return int(math.Ceil(float64(matching) * float64(r.Percentage) / 100))
+	default:
+		return matching
+	}
+}
+
+// samplePods returns a sample of the pods according to the blast radius, sorted by namespace and name
+func samplePods(
+	ctx context.Context,
+	k8s kubernetes.Kubernetes,
+	pods []corev1.Pod,
+	radius BlastRadius,
+) ([]corev1.Pod, error) {
+	size := radius.sampleSize(len(pods))
+	if size == len(pods) {
+		return pods, nil
+	}
+
+	seed := radius.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	rng := rand.New(rand.NewSource(seed))
+
+	// sort the pods so the same seed produces the same sample regardless of the order they were listed
+	candidates := make([]corev1.Pod, len(pods))
+	copy(candidates, pods)
+	sortPods(candidates)
+	rng.Shuffle(len(candidates), func(i, j int) {
+		candidates[i], candidates[j] = candidates[j], candidates[i]
+	})
+
+	var sample []corev1.Pod
+	if radius.SpreadBy == "" {
+		sample = candidates[:size]
+	} else {
+		domains, err := spreadDomains(ctx, k8s, candidates, radius.SpreadBy)
+		if err != nil {
+			return nil, err
+		}
+		sample = spreadSample(candidates, domains, size, rng)
+	}
+
+	sortPods(sample)
+
+	return sample, nil
+}
+
+// resamplePods returns a sample of the pods that keeps the pods of a previous sample that are still in the pods, and
+// samples the rest of the pods only if they are needed for completing the sample
+func resamplePods(
+	ctx context.Context,
+	k8s kubernetes.Kubernetes,
+	sampled []corev1.Pod,
+	pods []corev1.Pod,
+	radius BlastRadius,
+) ([]corev1.Pod, error) {
+	size := radius.sampleSize(len(pods))
+	if size == len(pods) {
+		return pods, nil
+	}
+
+	previous := map[string]bool{}
+	for _, pod := range sampled {
+		previous[podKey(pod)] = true
+	}
+
+	kept := []corev1.Pod{}
+	remaining := []corev1.Pod{}
+	for _, pod := range pods {
+		if previous[podKey(pod)] {
+			kept = append(kept, pod)
+			continue
+		}
+		remaining = append(remaining, pod)
+	}
+
+	if len(kept) >= size {
+		return kept[:size], nil
+	}
+
+	added, err := samplePods(
+		ctx,
+		k8s,
+		remaining,
+		BlastRadius{Count: uint(size - len(kept)), Seed: radius.Seed, SpreadBy: radius.SpreadBy},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	sample := append(kept, added...)
+	sortPods(sample)
+
+	return sample, nil
+}
+
+// spreadDomains returns the domain (node or zone) of each pod
+func spreadDomains(
+	ctx context.Context,
+	k8s kubernetes.Kubernetes,
+	pods []corev1.Pod,
+	spreadBy string,
+) ([]string, error) {
+	zones := map[string]string{}
+
+	domains := make([]string, len(pods))
+	for i, pod := range pods {
+		node := pod.Spec.NodeName
+		if spreadBy == SpreadByNode || node == "" {
+			domains[i] = node
+			continue
+		}
+
+		zone, found := zones[node]
+		if !found {
+			n, err := k8s.Client().CoreV1().Nodes().Get(ctx, node, metav1.GetOptions{})
+			if err != nil {
+				return nil, fmt.Errorf("getting node %q of pod %q: %w", node, pod.Name, err)
+			}
+			zone = n.Labels[corev1.LabelTopologyZone]
+			zones[node] = zone
+		}
+		domains[i] = zone
+	}
+
+	return domains, nil
+}
+
+// spreadSample selects size pods taking one pod from each domain in turn. The order of the domains is random, and
+// so is the order of the pods, which are expected to be shuffled.
+func spreadSample(pods []corev1.Pod, domains []string, size int, rng *rand.Rand) []corev1.Pod {
+	byDomain := map[string][]corev1.Pod{}
+	names := []string{}
+	for i, pod := range pods {
+		if _, found := byDomain[domains[i]]; !found {
+			names = append(names, domains[i])
+		}
+		byDomain[domains[i]] = append(byDomain[domains[i]], pod)
+	}
+
+	sort.Strings(names)
+	rng.Shuffle(len(names), func(i, j int) {
+		names[i], names[j] = names[j], names[i]
+	})
+
+	sample := []corev1.Pod{}
+	for len(sample) < size {
+		for _, name := range names {
+			if len(sample) == size {
+				break
+			}
+			if len(byDomain[name]) == 0 {
+				continue
+			}
+			sample = append(sample, byDomain[name][0])
+			byDomain[name] = byDomain[name][1:]
+		}
+	}
+
+	return sample
+}
+
+// sortPods sorts pods by namespace and name
+func sortPods(pods []corev1.Pod) {
+	sort.Slice(pods, func(i, j int) bool {
+		if pods[i].Namespace != pods[j].Namespace {
+			return pods[i].Namespace < pods[j].Namespace
+		}
+		return pods[i].Name < pods[j].Name
+	})
+}
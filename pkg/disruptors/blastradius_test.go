@@ -0,0 +1,298 @@
+package disruptors
+
+import (
+	"context"
+	"sort"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/grafana/xk6-disruptor/pkg/kubernetes"
+	"github.com/grafana/xk6-disruptor/pkg/testutils/kubernetes/builders"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func Test_ValidateBlastRadius(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		title       string
+		radius      BlastRadius
+		expectError bool
+	}{
+		{
+			title:  "empty",
+			radius: BlastRadius{},
+		},
+		{
+			title:  "count",
+			radius: BlastRadius{Count: 1, SpreadBy: SpreadByNode},
+		},
+		{
+			title:  "percentage",
+			radius: BlastRadius{Percentage: 20, SpreadBy: SpreadByZone},
+		},
+		{
+			title:       "count and percentage",
+			radius:      BlastRadius{Count: 1, Percentage: 20},
+			expectError: true,
+		},
+		{
+			title:       "percentage out of range",
+			radius:      BlastRadius{Percentage: 120},
+			expectError: true,
+		},
+		{
+			title:       "invalid spread",
+			radius:      BlastRadius{Count: 1, SpreadBy: "region"},
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			err := validateBlastRadius(tc.radius)
+			if tc.expectError && err == nil {
+				t.Errorf("should had failed")
+			}
+
+			if !tc.expectError && err != nil {
+				t.Errorf("unexpected error : %v", err)
+			}
+		})
+	}
+}
+
+func Test_SamplePods(t *testing.T) {
+	t.Parallel()
+
+	nodes := []corev1.Node{
+		builders.NewNodeBuilder("node-a").WithLabel(corev1.LabelTopologyZone, "zone-1").Build(),
+		builders.NewNodeBuilder("node-b").WithLabel(corev1.LabelTopologyZone, "zone-1").Build(),
+		builders.NewNodeBuilder("node-c").WithLabel(corev1.LabelTopologyZone, "zone-2").Build(),
+	}
+
+	pods := []corev1.Pod{
+		builders.NewPodBuilder("pod-1").WithNodeName("node-a").Build(),
+		builders.NewPodBuilder("pod-2").WithNodeName("node-a").Build(),
+		builders.NewPodBuilder("pod-3").WithNodeName("node-b").Build(),
+		builders.NewPodBuilder("pod-4").WithNodeName("node-b").Build(),
+		builders.NewPodBuilder("pod-5").WithNodeName("node-c").Build(),
+	}
+
+	testCases := []struct {
+		title  string
+		radius BlastRadius
+		// expected number of pods in the sample
+		expectedSize int
+		// expected number of distinct domains (nodes or zones) in the sample, if spread
+		expectedDomains int
+	}{
+		{
+			title:        "all pods",
+			radius:       BlastRadius{},
+			expectedSize: 5,
+		},
+		{
+			title:        "count",
+			radius:       BlastRadius{Count: 2},
+			expectedSize: 2,
+		},
+		{
+			title:        "count larger than matching pods",
+			radius:       BlastRadius{Count: 10},
+			expectedSize: 5,
+		},
+		{
+			title:        "percentage is rounded up",
+			radius:       BlastRadius{Percentage: 30},
+			expectedSize: 2,
+		},
+		{
+			title:           "spread by node",
+			radius:          BlastRadius{Count: 3, SpreadBy: SpreadByNode},
+			expectedSize:    3,
+			expectedDomains: 3,
+		},
+		{
+			title:           "spread by zone",
+			radius:          BlastRadius{Count: 2, SpreadBy: SpreadByZone},
+			expectedSize:    2,
+			expectedDomains: 2,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			objs := []runtime.Object{}
+			for n := range nodes {
+				objs = append(objs, &nodes[n])
+			}
+			client := fake.NewSimpleClientset(objs...)
+			k, _ := kubernetes.NewFakeKubernetes(client)
+
+			sample, err := samplePods(context.TODO(), k, pods, tc.radius)
+			if err != nil {
+				t.Fatalf("unexpected error : %v", err)
+			}
+
+			if len(sample) != tc.expectedSize {
+				t.Fatalf("expected %d pods got %d", tc.expectedSize, len(sample))
+			}
+
+			if tc.radius.SpreadBy == "" {
+				return
+			}
+
+			zones := map[string]string{}
+			for _, node := range nodes {
+				zones[node.Name] = node.Labels[corev1.LabelTopologyZone]
+			}
+
+			domains := map[string]bool{}
+			for _, pod := range sample {
+				if tc.radius.SpreadBy == SpreadByNode {
+					domains[pod.Spec.NodeName] = true
+				} else {
+					domains[zones[pod.Spec.NodeName]] = true
+				}
+			}
+
+			if len(domains) != tc.expectedDomains {
+				t.Errorf("expected pods in %d domains got %d", tc.expectedDomains, len(domains))
+			}
+		})
+	}
+}
+
+func Test_SamplePodsSeed(t *testing.T) {
+	t.Parallel()
+
+	pods := []corev1.Pod{}
+	for _, name := range []string{"pod-1", "pod-2", "pod-3", "pod-4", "pod-5", "pod-6", "pod-7", "pod-8"} {
+		pods = append(pods, builders.NewPodBuilder(name).Build())
+	}
+
+	// the same pods listed in a different order
+	reversed := []corev1.Pod{}
+	for i := len(pods) - 1; i >= 0; i-- {
+		reversed = append(reversed, pods[i])
+	}
+
+	k, _ := kubernetes.NewFakeKubernetes(fake.NewSimpleClientset())
+	radius := BlastRadius{Count: 3, Seed: 42}
+
+	names := func(pods []corev1.Pod) []string {
+		result := []string{}
+		for _, pod := range pods {
+			result = append(result, pod.Name)
+		}
+		sort.Strings(result)
+		return result
+	}
+
+	first, err := samplePods(context.TODO(), k, pods, radius)
+	if err != nil {
+		t.Fatalf("unexpected error : %v", err)
+	}
+
+	second, err := samplePods(context.TODO(), k, reversed, radius)
+	if err != nil {
+		t.Fatalf("unexpected error : %v", err)
+	}
+
+	if diff := cmp.Diff(names(first), names(second)); diff != "" {
+		t.Errorf("samples with the same seed do not match\n%s", diff)
+	}
+}
+
+func Test_ResamplePods(t *testing.T) {
+	t.Parallel()
+
+	pods := []corev1.Pod{}
+	for _, name := range []string{"pod-1", "pod-2", "pod-3", "pod-4"} {
+		pods = append(pods, builders.NewPodBuilder(name).Build())
+	}
+
+	testCases := []struct {
+		title   string
+		sampled []corev1.Pod
+		pods    []corev1.Pod
+		radius  BlastRadius
+		// pods expected in the sample
+		expectedKept []string
+		expectedSize int
+	}{
+		{
+			title:        "sampled pods are kept",
+			sampled:      []corev1.Pod{pods[0], pods[1]},
+			pods:         pods,
+			radius:       BlastRadius{Count: 2},
+			expectedKept: []string{"pod-1", "pod-2"},
+			expectedSize: 2,
+		},
+		{
+			title:        "gone pods are replaced",
+			sampled:      []corev1.Pod{pods[0], pods[1]},
+			pods:         pods[1:],
+			radius:       BlastRadius{Count: 2},
+			expectedKept: []string{"pod-2"},
+			expectedSize: 2,
+		},
+		{
+			title:        "sample shrinks with percentage",
+			sampled:      []corev1.Pod{pods[0], pods[1]},
+			pods:         pods[:2],
+			radius:       BlastRadius{Percentage: 50},
+			expectedKept: []string{"pod-1"},
+			expectedSize: 1,
+		},
+		{
+			title:        "all pods",
+			sampled:      []corev1.Pod{pods[0]},
+			pods:         pods,
+			radius:       BlastRadius{},
+			expectedKept: []string{"pod-1", "pod-2", "pod-3", "pod-4"},
+			expectedSize: 4,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			k, _ := kubernetes.NewFakeKubernetes(fake.NewSimpleClientset())
+
+			sample, err := resamplePods(context.TODO(), k, tc.sampled, tc.pods, tc.radius)
+			if err != nil {
+				t.Fatalf("unexpected error : %v", err)
+			}
+
+			if len(sample) != tc.expectedSize {
+				t.Fatalf("expected %d pods got %d", tc.expectedSize, len(sample))
+			}
+
+			names := map[string]bool{}
+			for _, pod := range sample {
+				names[pod.Name] = true
+			}
+			for _, name := range tc.expectedKept {
+				if !names[name] {
+					t.Errorf("expected pod %q to be kept", name)
+				}
+			}
+		})
+	}
+}
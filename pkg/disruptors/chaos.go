@@ -0,0 +1,296 @@
+package disruptors
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sort"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Types of faults of a chaos monkey
+const (
+	// ChaosDelay delays the HTTP requests to the target
+	ChaosDelay = "delay"
+	// ChaosError returns errors to the HTTP requests to the target
+	ChaosError = "error"
+	// ChaosKill kills the main process of a container of the target
+	ChaosKill = "kill"
+)
+
+// ChaosInjector defines the methods for injecting random faults in random targets
+type ChaosInjector interface {
+	// InjectChaos periodically injects a random fault in a random target of the disruptor for the duration of the
+	// chaos, and returns the schedule of the faults injected
+	InjectChaos(ctx context.Context, options ChaosOptions) (ChaosReport, error)
+}
+
+// ChaosFault defines a fault of the catalog of a chaos monkey
+type ChaosFault struct {
+	// Type of fault: "delay", "error" or "kill"
+	Type string `js:"type"`
+	// Relative weight of the fault when picking one at random. Defaults to 1.
+	Weight uint `js:"weight"`
+	// Port of the HTTP requests affected by "delay" and "error" faults. Defaults to DefaultTargetPort.
+	Port uint `js:"port"`
+	// Delay added to the HTTP requests by "delay" faults
+	Delay time.Duration `js:"delay"`
+	// Rate of the HTTP requests that return an error in "error" faults. Defaults to 1.
+	ErrorRate float32 `js:"errorRate"`
+	// Status code returned by "error" faults. Defaults to 500.
+	ErrorCode uint `js:"errorCode"`
+	// Container whose main process is killed by "kill" faults. If empty, the first container is used.
+	Container string `js:"container"`
+}
+
+// ChaosOptions defines the schedule of a chaos monkey
+type ChaosOptions struct {
+	// Duration of the chaos
+	Duration time.Duration `js:"duration"`
+	// Time between the start of consecutive faults
+	Interval time.Duration `js:"interval"`
+	// Minimum duration of a fault. Defaults to the maximum duration.
+	MinFaultDuration time.Duration `js:"minFaultDuration"`
+	// Maximum duration of a fault. Defaults to the interval.
+	MaxFaultDuration time.Duration `js:"maxFaultDuration"`
+	// Catalog of faults
+	Faults []ChaosFault `js:"faults"`
+	// Seed of the random schedule. If zero, a random seed is used. Using the seed of a previous run with the same
+	// targets replays its schedule.
+	Seed int64 `js:"seed"`
+	// Logger for the schedule. Defaults to the standard logger.
+	Logger logrus.FieldLogger
+}
+
+// ChaosEvent describes a fault injected by a chaos monkey
+type ChaosEvent struct {
+	// Offset from the start of the chaos when the fault was injected
+	Offset time.Duration
+	// Name of the target
+	Target string
+	// Type of fault
+	Fault string
+	// Duration of the fault
+	Duration time.Duration
+}
+
+// ChaosReport describes the faults injected by a chaos monkey
+type ChaosReport struct {
+	// Seed of the schedule
+	Seed int64
+	// Faults injected
+	Events []ChaosEvent
+}
+
+// validateChaosOptions validates the options of a chaos monkey and returns them with the defaults set
+func validateChaosOptions(options ChaosOptions) (ChaosOptions, error) {
+	if options.Duration <= 0 {
+		return options, fmt.Errorf("duration must be greater than zero")
+	}
+
+	if options.Interval <= 0 {
+		return options, fmt.Errorf("interval must be greater than zero")
+	}
+
+	if options.MaxFaultDuration == 0 {
+		options.MaxFaultDuration = options.Interval
+	}
+
+	if options.MinFaultDuration == 0 {
+		options.MinFaultDuration = options.MaxFaultDuration
+	}
+
+	if options.MinFaultDuration < 0 || options.MinFaultDuration > options.MaxFaultDuration {
+		return options, fmt.Errorf("minimum fault duration must be in the range [0, maximum fault duration]")
+	}
+
+	// faults do not overlap, so they do not conflict in the same target
+	if options.MaxFaultDuration > options.Interval {
+		return options, fmt.Errorf("maximum fault duration must not be longer than the interval")
+	}
+
+	if len(options.Faults) == 0 {
+		return options, fmt.Errorf("at least one fault must be specified")
+	}
+
+	faults := []ChaosFault{}
+	for i, fault := range options.Faults {
+		switch fault.Type {
+		case ChaosDelay:
+			if fault.Delay <= 0 {
+				return options, fmt.Errorf("fault %d: delay must be greater than zero", i)
+			}
+		case ChaosError:
+			if fault.ErrorRate < 0 || fault.ErrorRate > 1 {
+				return options, fmt.Errorf("fault %d: error rate must be in the range [0, 1]", i)
+			}
+			if fault.ErrorRate == 0 {
+				fault.ErrorRate = 1
+			}
+			if fault.ErrorCode == 0 {
+				fault.ErrorCode = 500
+			}
+		case ChaosKill:
+		default:
+			return options, fmt.Errorf("fault %d: invalid type %q", i, fault.Type)
+		}
+
+		if fault.Weight == 0 {
+			fault.Weight = 1
+		}
+
+		if fault.Port == 0 {
+			fault.Port = DefaultTargetPort
+		}
+
+		faults = append(faults, fault)
+	}
+	options.Faults = faults
+
+	if options.Seed == 0 {
+		options.Seed = time.Now().UnixNano()
+	}
+
+	if options.Logger == nil {
+		options.Logger = logrus.StandardLogger()
+	}
+
+	return options, nil
+}
+
+// chaosSchedule picks the faults of a chaos monkey. The random values are always drawn in the same order, so a
+// seed produces the same schedule for the same targets.
+type chaosSchedule struct {
+	options ChaosOptions
+	rand    *rand.Rand
+}
+
+func newChaosSchedule(options ChaosOptions) *chaosSchedule {
+	return &chaosSchedule{
+		options: options,
+		rand:    rand.New(rand.NewSource(options.Seed)),
+	}
+}
+
+// next picks the target, the fault and its duration. The targets are sorted so the pick does not depend on their
+// order.
+func (s *chaosSchedule) next(targets []string) (string, ChaosFault, time.Duration) {
+	sorted := make([]string, len(targets))
+	copy(sorted, targets)
+	sort.Strings(sorted)
+
+	target := ""
+	index := s.rand.Int63()
+	if len(sorted) > 0 {
+		target = sorted[index%int64(len(sorted))]
+	}
+
+	total := uint(0)
+	for _, fault := range s.options.Faults {
+		total += fault.Weight
+	}
+
+	pick := uint(s.rand.Int63n(int64(total)))
+	fault := s.options.Faults[0]
+	for _, f := range s.options.Faults {
+		if pick < f.Weight {
+			fault = f
+			break
+		}
+		pick -= f.Weight
+	}
+
+	bounds := s.options.MaxFaultDuration - s.options.MinFaultDuration
+	duration := s.options.MinFaultDuration + time.Duration(s.rand.Int63n(int64(bounds)+1))
+
+	// kill faults do not have a duration
+	if fault.Type == ChaosKill {
+		duration = 0
+	}
+
+	return target, fault, duration
+}
+
+// visitor returns the visitor for injecting the fault for the given duration
+func (f ChaosFault) visitor(duration time.Duration) PodVisitor {
+	switch f.Type {
+	case ChaosDelay:
+		return PodHTTPFaultVisitor{
+			fault:    HTTPFault{Port: f.Port, AverageDelay: f.Delay},
+			duration: duration,
+		}
+	case ChaosError:
+		return PodHTTPFaultVisitor{
+			fault:    HTTPFault{Port: f.Port, ErrorRate: f.ErrorRate, ErrorCode: f.ErrorCode},
+			duration: duration,
+		}
+	default:
+		return PodSignalFaultVisitor{
+			fault: SignalFault{Container: f.Container},
+		}
+	}
+}
+
+// injectChaos injects the faults of a chaos monkey in the targets of the controller
+func injectChaos(ctx context.Context, controller AgentController, options ChaosOptions) (ChaosReport, error) {
+	options, err := validateChaosOptions(options)
+	if err != nil {
+		return ChaosReport{}, err
+	}
+
+	logger := options.Logger.WithField("seed", options.Seed)
+	logger.Infof("chaos monkey started for %s with seed %d", options.Duration, options.Seed)
+
+	report := ChaosReport{Seed: options.Seed}
+	schedule := newChaosSchedule(options)
+	start := time.Now()
+	for offset := time.Duration(0); offset < options.Duration; offset += options.Interval {
+		select {
+		case <-time.After(time.Until(start.Add(offset))):
+		case <-ctx.Done():
+			return report, ctx.Err()
+		}
+
+		targets, err := controller.Targets(ctx)
+		if err != nil {
+			return report, err
+		}
+
+		target, fault, duration := schedule.next(targets)
+		if target == "" {
+			logger.Warnf("chaos monkey has no targets at %s", offset)
+			continue
+		}
+
+		// faults do not last beyond the end of the chaos
+		if remaining := options.Duration - offset; duration > remaining {
+			duration = remaining
+		}
+
+		event := ChaosEvent{
+			Offset:   offset,
+			Target:   target,
+			Fault:    fault.Type,
+			Duration: duration,
+		}
+		report.Events = append(report.Events, event)
+
+		logger.WithFields(logrus.Fields{
+			"offset":   offset,
+			"target":   target,
+			"fault":    fault.Type,
+			"duration": duration,
+		}).Info("chaos monkey injecting fault")
+
+		err = controller.VisitTargets(ctx, []string{target}, fault.visitor(duration))
+		if err != nil {
+			return report, fmt.Errorf("injecting %s fault in %q at %s: %w", fault.Type, target, offset, err)
+		}
+	}
+
+	logger.Infof("chaos monkey ended after injecting %d faults", len(report.Events))
+
+	return report, nil
+}
@@ -0,0 +1,269 @@
+package disruptors
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/grafana/xk6-disruptor/pkg/kubernetes/helpers"
+	"github.com/grafana/xk6-disruptor/pkg/testutils/kubernetes/builders"
+	logtest "github.com/sirupsen/logrus/hooks/test"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func Test_ValidateChaosOptions(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		title       string
+		options     ChaosOptions
+		expectError bool
+	}{
+		{
+			title: "valid options",
+			options: ChaosOptions{
+				Duration:         time.Minute,
+				Interval:         10 * time.Second,
+				MinFaultDuration: time.Second,
+				MaxFaultDuration: 5 * time.Second,
+				Faults: []ChaosFault{
+					{Type: ChaosDelay, Delay: 100 * time.Millisecond, Weight: 2},
+					{Type: ChaosError, ErrorRate: 0.5},
+					{Type: ChaosKill},
+				},
+			},
+		},
+		{
+			title: "missing duration",
+			options: ChaosOptions{
+				Interval: 10 * time.Second,
+				Faults:   []ChaosFault{{Type: ChaosKill}},
+			},
+			expectError: true,
+		},
+		{
+			title: "missing interval",
+			options: ChaosOptions{
+				Duration: time.Minute,
+				Faults:   []ChaosFault{{Type: ChaosKill}},
+			},
+			expectError: true,
+		},
+		{
+			title: "fault duration longer than interval",
+			options: ChaosOptions{
+				Duration:         time.Minute,
+				Interval:         10 * time.Second,
+				MaxFaultDuration: 20 * time.Second,
+				Faults:           []ChaosFault{{Type: ChaosKill}},
+			},
+			expectError: true,
+		},
+		{
+			title: "minimum fault duration longer than maximum",
+			options: ChaosOptions{
+				Duration:         time.Minute,
+				Interval:         10 * time.Second,
+				MinFaultDuration: 8 * time.Second,
+				MaxFaultDuration: 5 * time.Second,
+				Faults:           []ChaosFault{{Type: ChaosKill}},
+			},
+			expectError: true,
+		},
+		{
+			title: "no faults",
+			options: ChaosOptions{
+				Duration: time.Minute,
+				Interval: 10 * time.Second,
+			},
+			expectError: true,
+		},
+		{
+			title: "invalid fault type",
+			options: ChaosOptions{
+				Duration: time.Minute,
+				Interval: 10 * time.Second,
+				Faults:   []ChaosFault{{Type: "explode"}},
+			},
+			expectError: true,
+		},
+		{
+			title: "delay fault without delay",
+			options: ChaosOptions{
+				Duration: time.Minute,
+				Interval: 10 * time.Second,
+				Faults:   []ChaosFault{{Type: ChaosDelay}},
+			},
+			expectError: true,
+		},
+		{
+			title: "invalid error rate",
+			options: ChaosOptions{
+				Duration: time.Minute,
+				Interval: 10 * time.Second,
+				Faults:   []ChaosFault{{Type: ChaosError, ErrorRate: 2}},
+			},
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := validateChaosOptions(tc.options)
+			if tc.expectError && err == nil {
+				t.Errorf("should had failed")
+			}
+
+			if !tc.expectError && err != nil {
+				t.Errorf("unexpected error : %v", err)
+			}
+		})
+	}
+}
+
+func Test_ChaosSchedule(t *testing.T) {
+	t.Parallel()
+
+	options, err := validateChaosOptions(ChaosOptions{
+		Duration:         time.Minute,
+		Interval:         10 * time.Second,
+		MinFaultDuration: time.Second,
+		MaxFaultDuration: 5 * time.Second,
+		Seed:             42,
+		Faults: []ChaosFault{
+			{Type: ChaosDelay, Delay: 100 * time.Millisecond, Weight: 98},
+			{Type: ChaosError, Weight: 1},
+			{Type: ChaosKill, Weight: 1},
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error : %v", err)
+	}
+
+	targets := []string{"pod-1", "pod-2", "pod-3"}
+	// the same targets listed in a different order
+	reversed := []string{"pod-3", "pod-2", "pod-1"}
+
+	first := newChaosSchedule(options)
+	second := newChaosSchedule(options)
+
+	delays := 0
+	for i := 0; i < 100; i++ {
+		target, fault, duration := first.next(targets)
+		replayedTarget, replayedFault, replayedDuration := second.next(reversed)
+
+		if target != replayedTarget || fault.Type != replayedFault.Type || duration != replayedDuration {
+			t.Fatalf("schedules with the same seed do not match at pick %d", i)
+		}
+
+		if fault.Type == ChaosDelay {
+			delays++
+		}
+
+		if fault.Type != ChaosKill && (duration < time.Second || duration > 5*time.Second) {
+			t.Errorf("duration %s out of bounds", duration)
+		}
+	}
+
+	// the fault with most of the weight is picked most of the times
+	if delays < 80 {
+		t.Errorf("expected most of the faults to be delays got %d", delays)
+	}
+}
+
+func Test_InjectChaos(t *testing.T) {
+	t.Parallel()
+
+	pods := []corev1.Pod{}
+	for _, name := range []string{"pod-1", "pod-2", "pod-3"} {
+		pod := builders.NewPodBuilder(name).
+			WithNamespace("test-ns").
+			WithIP("192.0.2.6").
+			WithContainer(builders.NewContainerBuilder("main").WithPort("http", 80).Build()).
+			WithShareProcessNamespace(true).
+			WithContainerStatus(corev1.ContainerStatus{
+				Name:        "main",
+				ContainerID: "containerd://0123456789abcdef",
+			}).
+			Build()
+		pods = append(pods, pod)
+	}
+
+	options := ChaosOptions{
+		Duration: 300 * time.Millisecond,
+		Interval: 100 * time.Millisecond,
+		Seed:     7,
+		Faults: []ChaosFault{
+			{Type: ChaosDelay, Delay: 100 * time.Millisecond},
+			{Type: ChaosError},
+			{Type: ChaosKill},
+		},
+	}
+
+	// runs the chaos monkey and returns its report and the pods visited
+	run := func() (ChaosReport, []string) {
+		objs := []runtime.Object{}
+		for p := range pods {
+			objs = append(objs, &pods[p])
+		}
+
+		client := fake.NewSimpleClientset(objs...)
+		executor := helpers.NewFakePodCommandExecutor()
+		helperFor := func(namespace string) helpers.PodHelper {
+			return helpers.NewPodHelper(client, executor, namespace)
+		}
+		controller := newAgentController(helperFor, pods, -1)
+
+		logger, hook := logtest.NewNullLogger()
+		options := options
+		options.Logger = logger
+
+		report, err := injectChaos(context.TODO(), controller, options)
+		if err != nil {
+			t.Fatalf("unexpected error : %v", err)
+		}
+
+		for _, entry := range hook.AllEntries() {
+			if entry.Data["seed"] != options.Seed {
+				t.Errorf("log entry %q does not include the seed", entry.Message)
+			}
+		}
+
+		visited := []string{}
+		for _, cmd := range executor.GetHistory() {
+			visited = append(visited, cmd.Pod)
+		}
+
+		return report, visited
+	}
+
+	report, visited := run()
+
+	if len(report.Events) != 3 {
+		t.Fatalf("expected 3 faults got %d", len(report.Events))
+	}
+
+	// each fault is injected in one target
+	targets := []string{}
+	for _, event := range report.Events {
+		targets = append(targets, event.Target)
+	}
+
+	if diff := cmp.Diff(targets, visited); diff != "" {
+		t.Errorf("visited pods do not match the schedule:\n%s", diff)
+	}
+
+	// the same seed replays the schedule
+	replayed, _ := run()
+	if diff := cmp.Diff(report, replayed); diff != "" {
+		t.Errorf("replayed schedule does not match:\n%s", diff)
+	}
+}
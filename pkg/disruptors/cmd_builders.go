@@ -2,6 +2,7 @@ package disruptors
 
 import (
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/grafana/xk6-disruptor/pkg/utils"
@@ -117,3 +118,361 @@ func buildHTTPFaultCmd(
 func buildCleanupCmd() []string {
 	return []string{"xk6-disruptor-agent", "cleanup"}
 }
+
+func buildSignalFaultCmd(containerID string, fault SignalFault) []string {
+	cmd := []string{
+		"xk6-disruptor-agent",
+		"signal",
+		"--container-id", containerID,
+	}
+
+	if fault.Signal != "" {
+		cmd = append(cmd, "-s", fault.Signal)
+	}
+
+	return cmd
+}
+
+func buildFreezeFaultCmd(containerID string, duration time.Duration) []string {
+	return []string{
+		"xk6-disruptor-agent",
+		"freeze",
+		"-d", utils.DurationSeconds(duration),
+		"--container-id", containerID,
+	}
+}
+
+// buildFreezeCleanupCmd returns a cleanup command that also resumes the processes of the frozen container
+func buildFreezeCleanupCmd(containerID string) []string {
+	return append(buildCleanupCmd(), "--resume-container-id", containerID)
+}
+
+func buildNetworkFaultCmd(fault NetworkFault, duration time.Duration) []string {
+	cmd := []string{
+		"xk6-disruptor-agent",
+		"network",
+		"-d", utils.DurationSeconds(duration),
+	}
+
+	if fault.Interface != "" {
+		cmd = append(cmd, "-i", fault.Interface)
+	}
+
+	if fault.AverageDelay > 0 {
+		cmd = append(
+			cmd,
+			"-a",
+			utils.DurationMillSeconds(fault.AverageDelay),
+			"-v",
+			utils.DurationMillSeconds(fault.DelayVariation),
+		)
+	}
+
+	if fault.DelayCorrelation > 0 {
+		cmd = append(cmd, "--delay-correlation", fmt.Sprint(fault.DelayCorrelation))
+	}
+
+	if fault.LossRate > 0 {
+		cmd = append(cmd, "-l", fmt.Sprint(fault.LossRate))
+	}
+
+	if fault.DuplicationRate > 0 {
+		cmd = append(cmd, "--duplication-rate", fmt.Sprint(fault.DuplicationRate))
+	}
+
+	if fault.CorruptionRate > 0 {
+		cmd = append(cmd, "--corruption-rate", fmt.Sprint(fault.CorruptionRate))
+	}
+
+	if fault.ReorderRate > 0 {
+		cmd = append(cmd, "--reorder-rate", fmt.Sprint(fault.ReorderRate))
+	}
+
+	if fault.Port != 0 {
+		cmd = append(cmd, "--port", fmt.Sprint(fault.Port))
+	}
+
+	if fault.Peer != "" {
+		cmd = append(cmd, "--peer", fault.Peer)
+	}
+
+	return cmd
+}
+
+// buildNetworkCleanupCmd returns a cleanup command that also removes the network faults from the interface
+func buildNetworkCleanupCmd(fault NetworkFault) []string {
+	cmd := append(buildCleanupCmd(), "--reset-network")
+	if fault.Interface != "" {
+		cmd = append(cmd, "-i", fault.Interface)
+	}
+
+	return cmd
+}
+
+func buildCPUStressCmd(stress CPUStress, duration time.Duration, containerID string) []string {
+	cmd := []string{
+		"xk6-disruptor-agent",
+		"stress",
+		"cpu",
+		"-d", utils.DurationSeconds(duration),
+		"-c", fmt.Sprint(stress.Cores),
+	}
+
+	if stress.Load > 0 {
+		cmd = append(cmd, "-l", fmt.Sprint(stress.Load))
+	}
+
+	if containerID != "" {
+		cmd = append(cmd, "--container-id", containerID)
+	}
+
+	return cmd
+}
+
+func buildMemoryStressCmd(bytes uint64, stress MemoryStress, duration time.Duration, containerID string) []string {
+	cmd := []string{
+		"xk6-disruptor-agent",
+		"stress",
+		"memory",
+		"-d", utils.DurationSeconds(duration),
+		"-b", fmt.Sprint(bytes),
+	}
+
+	if stress.Ramp > 0 {
+		cmd = append(cmd, "--ramp", utils.DurationSeconds(stress.Ramp))
+	}
+
+	if stress.TriggerOOM {
+		cmd = append(cmd, "--oom")
+	}
+
+	if containerID != "" {
+		cmd = append(cmd, "--container-id", containerID)
+	}
+
+	return cmd
+}
+
+func buildEgressCmd(protocol string, dependency Dependency, duration time.Duration) []string {
+	return []string{
+		"xk6-disruptor-agent",
+		"egress",
+		protocol,
+		"-d", utils.DurationSeconds(duration),
+		"--destination", dependency.Host,
+		"--destination-port", fmt.Sprint(dependency.Port),
+	}
+}
+
+func buildEgressHTTPFaultCmd(
+	dependency Dependency,
+	fault HTTPFault,
+	duration time.Duration,
+	options HTTPDisruptionOptions,
+) []string {
+	cmd := buildEgressCmd("http", dependency, duration)
+
+	if fault.AverageDelay > 0 {
+		cmd = append(
+			cmd,
+			"-a",
+			utils.DurationMillSeconds(fault.AverageDelay),
+			"-v",
+			utils.DurationMillSeconds(fault.DelayVariation),
+		)
+	}
+
+	if fault.ErrorRate > 0 {
+		cmd = append(
+			cmd,
+			"-e",
+			fmt.Sprint(fault.ErrorCode),
+			"-r",
+			fmt.Sprint(fault.ErrorRate),
+		)
+		if fault.ErrorBody != "" {
+			cmd = append(cmd, "-b", fault.ErrorBody)
+		}
+	}
+
+	if len(fault.Exclude) > 0 {
+		cmd = append(cmd, "-x", fault.Exclude)
+	}
+
+	if options.ProxyPort != 0 {
+		cmd = append(cmd, "-p", fmt.Sprint(options.ProxyPort))
+	}
+
+	return cmd
+}
+
+func buildEgressGrpcFaultCmd(
+	dependency Dependency,
+	fault GrpcFault,
+	duration time.Duration,
+	options GrpcDisruptionOptions,
+) []string {
+	cmd := buildEgressCmd("grpc", dependency, duration)
+
+	if fault.AverageDelay > 0 {
+		cmd = append(
+			cmd,
+			"-a",
+			utils.DurationMillSeconds(fault.AverageDelay),
+			"-v",
+			utils.DurationMillSeconds(fault.DelayVariation),
+		)
+	}
+
+	if fault.ErrorRate > 0 {
+		cmd = append(
+			cmd,
+			"-s",
+			fmt.Sprint(fault.StatusCode),
+			"-r",
+			fmt.Sprint(fault.ErrorRate),
+		)
+		if fault.StatusMessage != "" {
+			cmd = append(cmd, "-m", fault.StatusMessage)
+		}
+	}
+
+	if len(fault.Exclude) > 0 {
+		cmd = append(cmd, "-x", fault.Exclude)
+	}
+
+	if options.ProxyPort != 0 {
+		cmd = append(cmd, "-p", fmt.Sprint(options.ProxyPort))
+	}
+
+	return cmd
+}
+
+func buildEgressTCPFaultCmd(
+	dependency Dependency,
+	fault TCPFault,
+	duration time.Duration,
+	options TCPDisruptionOptions,
+) []string {
+	cmd := buildEgressCmd("tcp", dependency, duration)
+
+	if fault.AverageDelay > 0 {
+		cmd = append(
+			cmd,
+			"-a",
+			utils.DurationMillSeconds(fault.AverageDelay),
+			"-v",
+			utils.DurationMillSeconds(fault.DelayVariation),
+		)
+	}
+
+	if fault.ErrorRate > 0 {
+		cmd = append(cmd, "-r", fmt.Sprint(fault.ErrorRate))
+	}
+
+	if options.ProxyPort != 0 {
+		cmd = append(cmd, "-p", fmt.Sprint(options.ProxyPort))
+	}
+
+	return cmd
+}
+
+func buildDNSFaultCmd(fault DNSFault, duration time.Duration, options DNSDisruptionOptions) []string {
+	cmd := []string{
+		"xk6-disruptor-agent",
+		"dns",
+		"-d", utils.DurationSeconds(duration),
+	}
+
+	if fault.AverageDelay > 0 {
+		cmd = append(
+			cmd,
+			"-a",
+			utils.DurationMillSeconds(fault.AverageDelay),
+			"-v",
+			utils.DurationMillSeconds(fault.DelayVariation),
+		)
+	}
+
+	if fault.ErrorRate > 0 {
+		cmd = append(
+			cmd,
+			"-e",
+			fault.Error,
+			"-r",
+			fmt.Sprint(fault.ErrorRate),
+		)
+	}
+
+	if len(fault.Names) > 0 {
+		cmd = append(cmd, "--names", fault.Names)
+	}
+
+	if options.ProxyPort != 0 {
+		cmd = append(cmd, "-p", fmt.Sprint(options.ProxyPort))
+	}
+
+	return cmd
+}
+
+func buildPartitionFaultCmd(fault PartitionFault, peers []string, duration time.Duration) []string {
+	cmd := []string{
+		"xk6-disruptor-agent",
+		"partition",
+		"-d", utils.DurationSeconds(duration),
+		"--peers", strings.Join(peers, ","),
+	}
+
+	if fault.Direction != "" {
+		cmd = append(cmd, "--direction", fault.Direction)
+	}
+
+	return cmd
+}
+
+func buildPartitionUpdateCmd(peers []string) []string {
+	return []string{
+		"xk6-disruptor-agent",
+		"partition",
+		"update",
+		"--peers", strings.Join(peers, ","),
+	}
+}
+
+// buildPartitionCleanupCmd returns a cleanup command that also restores the connectivity with the peers
+func buildPartitionCleanupCmd() []string {
+	return append(buildCleanupCmd(), "--reset-partition")
+}
+
+func buildDiskFillFaultCmd(containerID string, fault DiskFillFault, duration time.Duration) []string {
+	return []string{
+		"xk6-disruptor-agent",
+		"exhaust",
+		"disk",
+		"-d", utils.DurationSeconds(duration),
+		"--container-id", containerID,
+		"--path", fault.Path,
+		"--percentage", fmt.Sprint(fault.Percentage),
+	}
+}
+
+// buildDiskFillCleanupCmd returns a cleanup command that also removes the fill from the container's filesystem
+func buildDiskFillCleanupCmd(containerID string, fault DiskFillFault) []string {
+	return append(buildCleanupCmd(), "--disk-fill-container-id", containerID, "--disk-fill-path", fault.Path)
+}
+
+func buildFDExhaustionFaultCmd(containerID string, fault FDExhaustionFault, duration time.Duration) []string {
+	return []string{
+		"xk6-disruptor-agent",
+		"exhaust",
+		"fd",
+		"-d", utils.DurationSeconds(duration),
+		"--container-id", containerID,
+		"--remaining", fmt.Sprint(fault.Remaining),
+	}
+}
+
+// buildFDExhaustionCleanupCmd returns a cleanup command that also closes the descriptors opened in the process
+func buildFDExhaustionCleanupCmd() []string {
+	return append(buildCleanupCmd(), "--release-fds")
+}
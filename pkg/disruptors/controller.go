@@ -2,8 +2,12 @@ package disruptors
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 
@@ -12,20 +16,127 @@ import (
 	"github.com/grafana/xk6-disruptor/pkg/kubernetes/helpers"
 
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
 )
 
+// agentName is the name of the ephemeral container of the agent injected in the targets
+const agentName = "xk6-agent"
+
+// ErrDryRunNotSupported is returned when a fault that cannot be planned is injected in dry-run mode
+var ErrDryRunNotSupported = errors.New("fault is not supported in dry-run mode")
+
+// agentStopTimeout is the time the agent is given for ending after being stopped by the cleanup command
+const agentStopTimeout = 10 * time.Second
+
+// cgroupAgentName is the prefix of the name of the privileged agents that run commands in the cgroup of a container
+const cgroupAgentName = "xk6-agent-cgroup"
+
 // PodVisitor defines the interface for visiting Pods
 type PodVisitor interface {
 	// Visit returns the VisitComands for visiting the Pod
 	Visit(pod corev1.Pod) (VisitCommands, error)
 }
 
+// minRemainingDuration is the minimum time remaining of a fault for visiting a target added while it is in progress
+const minRemainingDuration = time.Second
+
+// remainingVisitor is implemented by the PodVisitors whose commands run for the duration of a fault, so the targets
+// added while the fault is in progress are visited only for the time that remains of it
+type remainingVisitor interface {
+	// remaining returns a visitor whose commands run for the duration of the fault minus the elapsed time, and
+	// false if the time remaining is below minRemainingDuration
+	remaining(elapsed time.Duration) (PodVisitor, bool)
+}
+
+// timedVisitor is a PodVisitor whose commands run for the duration of a fault. It builds the visitor of the fault for
+// the duration, so it can build it for the time remaining of the fault when visiting the targets added while it is in
+// progress.
+type timedVisitor struct {
+	duration time.Duration
+	build    func(duration time.Duration) PodVisitor
+}
+
+// Visit returns the VisitCommands of the visitor of the fault for its duration
+func (v timedVisitor) Visit(pod corev1.Pod) (VisitCommands, error) {
+	return v.build(v.duration).Visit(pod)
+}
+
+// remaining returns the visitor for the time remaining of the fault
+func (v timedVisitor) remaining(elapsed time.Duration) (PodVisitor, bool) {
+	v.duration -= elapsed
+	return v, v.duration >= minRemainingDuration
+}
+
 // VisitCommands define the commands used for visiting a Pod
 type VisitCommands struct {
 	// Exec defines the command to be executed
 	Exec []string
 	// Cleanup defines the command to execute for cleaning up if command execution fails
 	Cleanup []string
+	// Port of the target affected by the commands. Zero if the commands do not affect a port.
+	Port uint
+	// Container whose processes are affected by the commands. If set, the commands are executed by an agent that
+	// shares the process namespace of the container. Empty if the commands do not affect processes.
+	Container string
+	// Cgroup is true if the commands consume resources in the cgroup of the Container. The agent that executes them is
+	// privileged, so it can move its process to the cgroup of the container.
+	Cgroup bool
+}
+
+// FaultReport describes the injection of a fault in the targets of a disruptor
+type FaultReport struct {
+	// DryRun is true if the fault was only planned and the commands were not executed
+	DryRun bool `js:"dryRun"`
+	// Targets describes the injection in each target, sorted by namespace and name
+	Targets []TargetReport `js:"targets"`
+}
+
+// TargetReport describes the injection of a fault in a target
+type TargetReport struct {
+	// Namespace of the target
+	Namespace string `js:"namespace"`
+	// Name of the target
+	Name string `js:"name"`
+	// Port of the target affected by the fault. Zero if the fault does not affect a port.
+	Port uint `js:"port"`
+	// Command line of the agent
+	Command []string `js:"command"`
+	// Requests handled by the agent, if it reports them. Nil in dry-run mode.
+	Metrics *RequestMetrics `js:"metrics"`
+}
+
+// RequestMetrics counts the requests handled by the agent in a target
+type RequestMetrics struct {
+	// Total number of requests received
+	Requests uint `json:"requests" js:"requests"`
+	// Requests passed through due to exclusion rules
+	Excluded uint `json:"excluded" js:"excluded"`
+	// Requests altered by the fault in any way
+	Disrupted uint `json:"disrupted" js:"disrupted"`
+}
+
+// parseRequestMetrics returns the metrics in the summary printed by the agent at the end of a disruption as a line
+// of JSON in the form {"summary": {"requests": 10, ...}}. Returns nil if the output does not include a summary.
+func parseRequestMetrics(output []byte) *RequestMetrics {
+	lines := strings.Split(string(output), "\n")
+	for i := len(lines) - 1; i >= 0; i-- {
+		line := struct {
+			Summary *RequestMetrics `json:"summary"`
+		}{}
+
+		err := json.Unmarshal([]byte(strings.TrimSpace(lines[i])), &line)
+		if err == nil && line.Summary != nil {
+			return line.Summary
+		}
+	}
+
+	return nil
 }
 
 // AgentController defines the interface for controlling agents in a set of targets
@@ -36,135 +147,313 @@ type AgentController interface {
 	Targets(ctx context.Context) ([]string, error)
 	// Visit allows executing a different command on each target returned by a visiting function
 	Visit(ctx context.Context, visitor PodVisitor) error
+	// VisitTargets is like Visit, but only visits the targets with the given names, as returned by Targets
+	VisitTargets(ctx context.Context, targets []string, visitor PodVisitor) error
+	// VisitWithReport is like Visit, but returns a report of the commands executed in each target
+	VisitWithReport(ctx context.Context, visitor PodVisitor) (FaultReport, error)
 }
 
+// targetsFunc returns the targets of a controller, given its current targets
+type targetsFunc func(ctx context.Context, current []corev1.Pod) ([]corev1.Pod, error)
+
 // AgentController controls de agents in a set of target pods
 type agentController struct {
-	helper    helpers.PodHelper
-	namespace string
-	targets   []corev1.Pod
+	helperFor func(namespace string) helpers.PodHelper
 	timeout   time.Duration
+	// client used for checking if the targets still exist. Only set if the targets are watched.
+	client kubernetes.Interface
+	// if true, the agent is not injected in the targets and the commands of the visits are not executed
+	dryRun bool
+
+	mu sync.Mutex
+	// helpers for the namespaces of the targets
+	helpers map[string]helpers.PodHelper
+	// locks for attaching the agents that share the process namespace of a container
+	attaching map[string]*sync.Mutex
+	targets   []corev1.Pod
+	// visits in progress
+	visits map[*activeVisit]bool
 }
 
-// InjectDisruptorAgent injects the Disruptor agent in the target pods
-func (c *agentController) InjectDisruptorAgent(ctx context.Context) error {
+// activeVisit keeps track of a visit in progress, so it can be extended to the targets added while it runs
+type activeVisit struct {
+	visitor PodVisitor
+	// time the visit started
+	started time.Time
+	// context of the commands executed in the targets
+	execContext context.Context
+
+	mu   sync.Mutex
+	done bool
+	// error of the first failed command in an added target
+	err error
+	// reports of the added targets
+	reports []TargetReport
+	late    sync.WaitGroup
+	// notifies the visit of a failure in an added target
+	failed chan struct{}
+}
+
+// helper returns the PodHelper for the namespace of the pod
+func (c *agentController) helper(pod corev1.Pod) helpers.PodHelper {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.podHelper(pod.Namespace)
+}
+
+// podHelper returns the PodHelper for a namespace, creating it if needed. Must be called with the lock held.
+func (c *agentController) podHelper(namespace string) helpers.PodHelper {
+	helper, found := c.helpers[namespace]
+	if !found {
+		helper = c.helperFor(namespace)
+		c.helpers[namespace] = helper
+	}
+
+	return helper
+}
+
+// currentTargets returns a copy of the targets
+func (c *agentController) currentTargets() []corev1.Pod {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	targets := make([]corev1.Pod, len(c.targets))
+	copy(targets, c.targets)
+
+	return targets
+}
+
+// agentContainer returns the specification of the ephemeral container of the agent with the given name. If target is
+// not empty, the agent shares the process namespace of the target container and can trace its processes. If privileged
+// is true, the agent runs in a privileged container, which has access to the cgroups of the other containers.
+func agentContainer(name string, target string, privileged bool) corev1.EphemeralContainer {
 	var (
 		rootUser     = int64(0)
 		rootGroup    = int64(0)
 		runAsNonRoot = false
 	)
 
-	agentContainer := corev1.EphemeralContainer{
+	capabilities := []corev1.Capability{"NET_ADMIN"}
+	if target != "" {
+		capabilities = append(capabilities, "SYS_PTRACE")
+	}
+
+	securityContext := &corev1.SecurityContext{
+		Capabilities: &corev1.Capabilities{
+			Add: capabilities,
+		},
+		RunAsUser:    &rootUser,
+		RunAsGroup:   &rootGroup,
+		RunAsNonRoot: &runAsNonRoot,
+	}
+	if privileged {
+		securityContext.Privileged = &privileged
+	}
+
+	return corev1.EphemeralContainer{
 		EphemeralContainerCommon: corev1.EphemeralContainerCommon{
-			Name:            "xk6-agent",
+			Name:            name,
 			Image:           version.AgentImage(),
 			ImagePullPolicy: corev1.PullIfNotPresent,
-			SecurityContext: &corev1.SecurityContext{
-				Capabilities: &corev1.Capabilities{
-					Add: []corev1.Capability{"NET_ADMIN"},
-				},
-				RunAsUser:    &rootUser,
-				RunAsGroup:   &rootGroup,
-				RunAsNonRoot: &runAsNonRoot,
-			},
-			TTY:   true,
-			Stdin: true,
+			SecurityContext: securityContext,
+			TTY:             true,
+			Stdin:           true,
 		},
+		TargetContainerName: target,
+	}
+}
+
+// processAgentName returns the name of the agent that shares the process namespace of a container. Agents that run
+// commands in the cgroup of the container have a different name, as they are privileged.
+func processAgentName(container string, cgroup bool) string {
+	prefix := agentName
+	if cgroup {
+		prefix = cgroupAgentName
+	}
+
+	name := prefix + "-" + container
+	if len(name) <= validation.DNS1123LabelMaxLength {
+		return name
+	}
+
+	// container names can be as long as agent names, so long names are replaced by their hash
+	name = fmt.Sprintf("%s-%x", prefix, sha256.Sum256([]byte(container)))
+	return name[:validation.DNS1123LabelMaxLength]
+}
+
+// InjectDisruptorAgent injects the Disruptor agent in the target pods
+func (c *agentController) InjectDisruptorAgent(ctx context.Context) error {
+	errs := c.injectAgent(ctx, c.currentTargets())
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// injectAgent injects the Disruptor agent in the given pods and returns the error for each pod, if any
+func (c *agentController) injectAgent(ctx context.Context, pods []corev1.Pod) []error {
+	errs := make([]error, len(pods))
+	if c.dryRun {
+		return errs
 	}
 
+	container := agentContainer(agentName, "", false)
+
 	var wg sync.WaitGroup
-	// ensure errors channel has enough space to avoid blocking gorutines
-	errors := make(chan error, len(c.targets))
-	for _, pod := range c.targets {
+	for i, pod := range pods {
 		wg.Add(1)
 		// attach each container asynchronously
-		go func(podName string) {
+		go func(i int, pod corev1.Pod) {
 			defer wg.Done()
 
-			err := c.helper.AttachEphemeralContainer(
+			errs[i] = c.helper(pod).AttachEphemeralContainer(
 				ctx,
-				podName,
-				agentContainer,
+				pod.Name,
+				container,
 				helpers.AttachOptions{
 					Timeout:        c.timeout,
 					IgnoreIfExists: true,
 				},
 			)
-			if err != nil {
-				errors <- err
-			}
-		}(pod.Name)
+		}(i, pod)
 	}
 
 	wg.Wait()
 
-	select {
-	case err := <-errors:
-		return err
-	default:
-		return nil
-	}
+	return errs
 }
 
-// Visit allows executing a different command on each target returned by a visiting function
+// Visit allows executing a different command on each target returned by a visiting function.
+// If the targets are watched, the visit is extended to the targets added while it runs, until the commands in the
+// original targets complete. In dry-run mode, it fails with ErrDryRunNotSupported, as only the visits that return a
+// report can describe the plan of the fault.
 func (c *agentController) Visit(ctx context.Context, visitor PodVisitor) error {
-	// if there are no targets, nothing to do
-	if len(c.targets) == 0 {
-		return nil
+	if c.dryRun {
+		return ErrDryRunNotSupported
+	}
+
+	_, err := c.visit(ctx, visitor, nil)
+	return err
+}
+
+// VisitWithReport is like Visit, but returns a report of the commands executed in each target. In dry-run mode, the
+// commands are not executed.
+func (c *agentController) VisitWithReport(ctx context.Context, visitor PodVisitor) (FaultReport, error) {
+	return c.visit(ctx, visitor, nil)
+}
+
+// VisitTargets is like Visit, but only visits the targets with the given names, as returned by Targets. Names that
+// do not match any target are ignored. The visit is not extended to the targets added while it runs.
+// In dry-run mode, it fails with ErrDryRunNotSupported.
+func (c *agentController) VisitTargets(ctx context.Context, targets []string, visitor PodVisitor) error {
+	if c.dryRun {
+		return ErrDryRunNotSupported
+	}
+
+	selected := map[string]bool{}
+	for _, name := range targets {
+		selected[name] = true
 	}
 
+	_, err := c.visit(ctx, visitor, selected)
+	return err
+}
+
+// visitResult is the result of visiting a target
+type visitResult struct {
+	report TargetReport
+	err    error
+}
+
+// visit visits the targets whose names are selected, or all the targets if selected is nil
+func (c *agentController) visit(
+	ctx context.Context,
+	visitor PodVisitor,
+	selected map[string]bool,
+) (FaultReport, error) {
 	execContext, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	// ensure errCh channel has enough space to avoid blocking gorutines
-	errCh := make(chan error, len(c.targets))
-	for _, pod := range c.targets {
+	visit := &activeVisit{
+		visitor:     visitor,
+		started:     time.Now(),
+		execContext: execContext,
+		failed:      make(chan struct{}, 1),
+	}
+
+	// register the visit with the targets it starts with, so any target added later extends it.
+	// The commands for all the targets are obtained before executing any, so the visit fails without disrupting any
+	// target if the visitor rejects one of them.
+	c.mu.Lock()
+	targets := []corev1.Pod{}
+	commands := []VisitCommands{}
+	for i, name := range targetNames(c.targets) {
+		if selected != nil && !selected[name] {
+			continue
+		}
+
+		visitCommands, err := visitor.Visit(c.targets[i])
+		if err != nil {
+			c.mu.Unlock()
+			return FaultReport{}, fmt.Errorf("unable to get command for pod %q: %w", c.targets[i].Name, err)
+		}
+
+		targets = append(targets, c.targets[i])
+		commands = append(commands, visitCommands)
+	}
+	if len(targets) > 0 && selected == nil {
+		c.visits[visit] = true
+	}
+	c.mu.Unlock()
+
+	report := FaultReport{DryRun: c.dryRun, Targets: []TargetReport{}}
+
+	// if there are no targets, nothing to do
+	if len(targets) == 0 {
+		return report, nil
+	}
+
+	// ensure resultCh channel has enough space to avoid blocking gorutines
+	resultCh := make(chan visitResult, len(targets))
+	for i, pod := range targets {
 		pod := pod
+		visitCommands := commands[i]
 		// visit each target asynchronously
 		go func() {
-			errCh <- func(pod corev1.Pod) error {
-				// get the command to execute in the target
-				visitCommands, err := visitor.Visit(pod)
-				if err != nil {
-					return fmt.Errorf("unable to get command for pod %q: %w", pod.Name, err)
-				}
-
-				_, stderr, err := c.helper.Exec(execContext, pod.Name, "xk6-agent", visitCommands.Exec, []byte{})
-
-				// if command failed, ensure the agent execution is terminated
-				if err != nil && visitCommands.Cleanup != nil {
-					// we ignore errors because k6 was cancelled, so there's no point in reporting
-					// use a fresh context because the exec context may have been cancelled or expired
-					//nolint:contextcheck
-					_, _, _ = c.helper.Exec(context.TODO(), pod.Name, "xk6-agent", visitCommands.Cleanup, []byte{})
-				}
-
-				// if the context is cancelled, it is reported in the main loop
-				if err != nil && !errors.Is(err, context.Canceled) {
-					return fmt.Errorf("failed command execution for pod %q: %w \n%s", pod.Name, err, string(stderr))
-				}
-
-				return nil
-			}(pod)
+			targetReport, err := c.visitPod(execContext, pod, visitCommands)
+			resultCh <- visitResult{report: targetReport, err: err}
 		}()
 	}
 
 	var err error
-	pending := len(c.targets)
+	pending := len(targets)
 	for {
 		select {
-		case e := <-errCh:
+		case result := <-resultCh:
 			pending--
-			if e != nil {
+			if result.err != nil {
 				// cancel ongoing commands
 				cancel()
 				// Save first received error as reason for ending execution
-				err = e
+				err = result.err
+			} else {
+				report.Targets = append(report.Targets, result.report)
 			}
 
 			if pending == 0 {
-				return err
+				err = c.endVisit(ctx, visit, cancel, err)
+				report.Targets = append(report.Targets, visit.lateReports()...)
+				sortTargetReports(report.Targets)
+				return report, err
 			}
+		case <-visit.failed:
+			// cancel ongoing commands
+			cancel()
+			err = visit.lateError()
 		case <-ctx.Done():
 			// cancel ongoing commands
 			cancel()
@@ -174,33 +463,571 @@ func (c *agentController) Visit(ctx context.Context, visitor PodVisitor) error {
 	}
 }
 
-// Targets retrieves the list of names of the target pods
+// endVisit waits for the commands executed in the targets added during the visit and returns the error of the visit.
+// The commands are stopped if the visit failed, any of them fails or the context is cancelled.
+func (c *agentController) endVisit(
+	ctx context.Context,
+	visit *activeVisit,
+	cancel context.CancelFunc,
+	err error,
+) error {
+	c.mu.Lock()
+	delete(c.visits, visit)
+	c.mu.Unlock()
+
+	visit.mu.Lock()
+	visit.done = true
+	visit.mu.Unlock()
+
+	if err != nil {
+		cancel()
+	}
+
+	// the commands in the added targets run for the time remaining of the fault
+	lateDone := make(chan struct{})
+	go func() {
+		visit.late.Wait()
+		close(lateDone)
+	}()
+
+	select {
+	case <-lateDone:
+	case <-visit.failed:
+		cancel()
+		<-lateDone
+	case <-ctx.Done():
+		cancel()
+		<-lateDone
+		if err == nil {
+			err = ctx.Err()
+		}
+	}
+
+	if err == nil {
+		err = visit.lateError()
+	}
+
+	return err
+}
+
+// extend visits a target added while the visit is in progress. If the commands of the visit run for the duration of
+// a fault, they run in the target for the time remaining of the fault, so they end with the commands in the original
+// targets.
+func (c *agentController) extend(visit *activeVisit, pod corev1.Pod) {
+	visit.mu.Lock()
+	defer visit.mu.Unlock()
+
+	if visit.done {
+		return
+	}
+
+	visitor := visit.visitor
+	if timed, ok := visitor.(remainingVisitor); ok {
+		remaining, ok := timed.remaining(time.Since(visit.started))
+		// targets added when the fault is about to end are not visited
+		if !ok {
+			return
+		}
+		visitor = remaining
+	}
+
+	visit.late.Add(1)
+	go func() {
+		defer visit.late.Done()
+
+		var report TargetReport
+		visitCommands, err := visitor.Visit(pod)
+		if err != nil {
+			err = fmt.Errorf("unable to get command for pod %q: %w", pod.Name, err)
+		} else {
+			report, err = c.visitPod(visit.execContext, pod, visitCommands)
+		}
+		if err == nil {
+			visit.mu.Lock()
+			visit.reports = append(visit.reports, report)
+			visit.mu.Unlock()
+			return
+		}
+
+		visit.mu.Lock()
+		if visit.err == nil {
+			visit.err = err
+		}
+		visit.mu.Unlock()
+
+		select {
+		case visit.failed <- struct{}{}:
+		default:
+		}
+	}()
+}
+
+// lateError returns the error of the first failed command in a target added during the visit
+func (v *activeVisit) lateError() error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	return v.err
+}
+
+// lateReports returns the reports of the targets added during the visit
+func (v *activeVisit) lateReports() []TargetReport {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	return v.reports
+}
+
+// sortTargetReports sorts the reports by the namespace and name of their targets
+func sortTargetReports(reports []TargetReport) {
+	sort.Slice(reports, func(i, j int) bool {
+		if reports[i].Namespace != reports[j].Namespace {
+			return reports[i].Namespace < reports[j].Namespace
+		}
+		return reports[i].Name < reports[j].Name
+	})
+}
+
+// visitPod executes the commands of the visit in the pod and returns the report of their execution.
+// In dry-run mode, the commands are not executed.
+func (c *agentController) visitPod(
+	execContext context.Context,
+	pod corev1.Pod,
+	visitCommands VisitCommands,
+) (TargetReport, error) {
+	report := TargetReport{
+		Namespace: pod.Namespace,
+		Name:      pod.Name,
+		Port:      visitCommands.Port,
+		Command:   visitCommands.Exec,
+	}
+
+	if c.dryRun {
+		return report, nil
+	}
+
+	agent, err := c.attachProcessAgent(execContext, pod, visitCommands)
+	if err != nil {
+		return TargetReport{}, err
+	}
+
+	stdout, stderr, err := c.execAgent(execContext, c.helper(pod), pod.Name, agent, visitCommands)
+
+	// if the context is cancelled, it is reported in the main loop
+	if err != nil && !errors.Is(err, context.Canceled) {
+		// the command fails if the pod was deleted while being visited
+		//nolint:contextcheck
+		if c.isGone(context.TODO(), pod) {
+			return report, nil
+		}
+
+		return TargetReport{}, fmt.Errorf(
+			"failed command execution for pod %q: %w \n%s", pod.Name, err, string(stderr),
+		)
+	}
+
+	report.Metrics = parseRequestMetrics(stdout)
+
+	return report, nil
+}
+
+// execAgent executes the commands of a visit in the agent of a pod. If the context is cancelled, the agent is stopped
+// with the cleanup command instead of cutting off its execution, so it can print the summary of the fault, and it is
+// only cut off if it does not end within agentStopTimeout. The cleanup command is also executed if the agent fails.
+// If the context is cancelled, its error is returned.
+func (c *agentController) execAgent(
+	ctx context.Context,
+	helper helpers.PodHelper,
+	pod string,
+	agent string,
+	cmds VisitCommands,
+) ([]byte, []byte, error) {
+	if cmds.Cleanup == nil {
+		return helper.Exec(ctx, pod, agent, cmds.Exec, []byte{})
+	}
+
+	var stopOnce sync.Once
+	stop := func() {
+		stopOnce.Do(func() {
+			// we ignore errors because k6 was cancelled, so there's no point in reporting
+			// use a fresh context because the exec context may have been cancelled or expired
+			//nolint:contextcheck
+			_, _, _ = helper.Exec(context.TODO(), pod, agent, cmds.Cleanup, []byte{})
+		})
+	}
+
+	execContext, cutOff := context.WithCancel(context.Background())
+	defer cutOff()
+
+	done := make(chan struct{})
+	defer close(done)
+
+	go func() {
+		select {
+		case <-done:
+			return
+		case <-ctx.Done():
+		}
+
+		stop()
+
+		select {
+		case <-done:
+		case <-time.After(agentStopTimeout):
+			cutOff()
+		}
+	}()
+
+	//nolint:contextcheck // the execution is cut off when the context is cancelled and the agent does not stop
+	stdout, stderr, err := helper.Exec(execContext, pod, agent, cmds.Exec, []byte{})
+	if err == nil {
+		// the agent ended by itself, so it must not be stopped if the context is cancelled from now on
+		stopOnce.Do(func() {})
+		return stdout, stderr, nil
+	}
+
+	// ensure the agent execution is terminated
+	stop()
+
+	if ctx.Err() != nil {
+		return stdout, stderr, ctx.Err()
+	}
+
+	return stdout, stderr, err
+}
+
+// attachProcessAgent returns the name of the agent that executes the commands affecting the processes of a container
+// of the pod. The agent injected in the pod does not share the process namespace of any container, so an agent that
+// shares it is attached the first time the container is visited. If the commands do not affect a container, the
+// injected agent is used.
+func (c *agentController) attachProcessAgent(ctx context.Context, pod corev1.Pod, cmds VisitCommands) (string, error) {
+	container := cmds.Container
+	if container == "" {
+		return agentName, nil
+	}
+
+	name := processAgentName(container, cmds.Cgroup)
+
+	// prevent concurrent visits from attaching the same agent
+	key := podKey(pod) + "/" + name
+	c.mu.Lock()
+	lock, found := c.attaching[key]
+	if !found {
+		lock = &sync.Mutex{}
+		c.attaching[key] = lock
+	}
+	c.mu.Unlock()
+
+	lock.Lock()
+	defer lock.Unlock()
+
+	err := c.helper(pod).AttachEphemeralContainer(
+		ctx,
+		pod.Name,
+		agentContainer(name, container, cmds.Cgroup),
+		helpers.AttachOptions{
+			Timeout:        c.timeout,
+			IgnoreIfExists: true,
+		},
+	)
+	if err != nil {
+		return "", fmt.Errorf("attaching agent to container %q of pod %q: %w", container, pod.Name, err)
+	}
+
+	return name, nil
+}
+
+// isGone returns true if the targets are watched and the pod no longer exists or is terminating
+func (c *agentController) isGone(ctx context.Context, pod corev1.Pod) bool {
+	if c.client == nil {
+		return false
+	}
+
+	current, err := c.client.CoreV1().Pods(pod.Namespace).Get(ctx, pod.Name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return true
+	}
+	if err != nil {
+		return false
+	}
+
+	return current.UID != pod.UID || current.DeletionTimestamp != nil
+}
+
+// Targets retrieves the list of names of the target pods. If the targets are in more than one namespace, the names
+// are qualified with their namespace (e.g. "namespace/name").
 func (c *agentController) Targets(_ context.Context) ([]string, error) {
+	return targetNames(c.currentTargets()), nil
+}
+
+// targetNames returns the names of the targets, qualified with their namespace if they are in more than one
+func targetNames(targets []corev1.Pod) []string {
+	namespaces := map[string]bool{}
+	for _, p := range targets {
+		namespaces[p.Namespace] = true
+	}
+	qualify := len(namespaces) > 1
+
 	names := []string{}
-	for _, p := range c.targets {
+	for _, p := range targets {
+		if qualify {
+			names = append(names, p.Namespace+"/"+p.Name)
+			continue
+		}
 		names = append(names, p.Name)
 	}
-	return names, nil
+	return names
 }
 
 // NewAgentController creates a new controller for a list of target pods
 func NewAgentController(
-	_ context.Context,
+	ctx context.Context,
 	helper helpers.PodHelper,
-	namespace string,
 	targets []corev1.Pod,
 	timeout time.Duration,
 ) AgentController {
+	helperFor := func(string) helpers.PodHelper {
+		return helper
+	}
+
+	return NewMultiNamespaceAgentController(ctx, helperFor, targets, timeout)
+}
+
+// NewMultiNamespaceAgentController creates a new controller for a list of target pods that can be in different
+// namespaces. The helperFor function returns the PodHelper for each namespace.
+func NewMultiNamespaceAgentController(
+	_ context.Context,
+	helperFor func(namespace string) helpers.PodHelper,
+	targets []corev1.Pod,
+	timeout time.Duration,
+) AgentController {
+	return newAgentController(helperFor, targets, timeout)
+}
+
+// newAgentController returns a controller for a list of target pods
+func newAgentController(
+	helperFor func(namespace string) helpers.PodHelper,
+	targets []corev1.Pod,
+	timeout time.Duration,
+) *agentController {
+	c := &agentController{
+		helperFor: helperFor,
+		timeout:   agentTimeout(timeout),
+		helpers:   map[string]helpers.PodHelper{},
+		attaching: map[string]*sync.Mutex{},
+		targets:   targets,
+		visits:    map[*activeVisit]bool{},
+	}
+
+	for _, pod := range targets {
+		c.podHelper(pod.Namespace)
+	}
+
+	return c
+}
+
+// watchTargets watches the pods in the given namespaces and refreshes the targets when they change, until the
+// context is cancelled. If namespaceLabels is not empty, the namespaces are also watched and the pods of the namespaces
+// that start matching these labels are watched as well. The targets function returns the targets given the current
+// ones, and must resolve the namespaces that match the labels on each call.
+func (c *agentController) watchTargets(
+	ctx context.Context,
+	client kubernetes.Interface,
+	namespaces []string,
+	namespaceLabels map[string]string,
+	targets targetsFunc,
+) error {
+	c.client = client
+
+	// changes are coalesced, as each refresh considers all the changes since the previous one
+	changes := make(chan struct{}, 1)
+	notify := func() {
+		select {
+		case changes <- struct{}{}:
+		default:
+		}
+	}
+
+	handler := cache.ResourceEventHandlerFuncs{
+		AddFunc:    func(interface{}) { notify() },
+		UpdateFunc: func(interface{}, interface{}) { notify() },
+		DeleteFunc: func(interface{}) { notify() },
+	}
+
+	// the pods of a namespace that no longer matches the labels are still watched, but they are not selected
+	var watchedMu sync.Mutex
+	watched := map[string]bool{}
+	watchPods := func(namespace string) (informers.SharedInformerFactory, error) {
+		watchedMu.Lock()
+		defer watchedMu.Unlock()
+
+		if watched[namespace] {
+			return nil, nil
+		}
+
+		factory := informers.NewSharedInformerFactoryWithOptions(client, 0, informers.WithNamespace(namespace))
+		_, err := factory.Core().V1().Pods().Informer().AddEventHandler(handler)
+		if err != nil {
+			return nil, fmt.Errorf("watching pods in namespace %q: %w", namespace, err)
+		}
+
+		factory.Start(ctx.Done())
+		watched[namespace] = true
+
+		return factory, nil
+	}
+
+	for _, namespace := range namespaces {
+		factory, err := watchPods(namespace)
+		if err != nil {
+			return err
+		}
+
+		for _, synced := range factory.WaitForCacheSync(ctx.Done()) {
+			if !synced {
+				return fmt.Errorf("watching pods in namespace %q: cache not synced", namespace)
+			}
+		}
+	}
+
+	if len(namespaceLabels) > 0 {
+		err := watchNamespaces(ctx, client, namespaceLabels, func(namespace string) {
+			// the pods already in the namespace are notified when its informer lists them
+			_, _ = watchPods(namespace)
+			notify()
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-changes:
+				// if the refresh fails, it will be retried in the next change
+				_ = c.refresh(ctx, targets)
+			}
+		}
+	}()
+
+	return nil
+}
+
+// watchNamespaces watches the namespaces that match the given labels until the context is cancelled, calling the
+// changed function with the name of any namespace that starts matching the labels, changes or stops matching them
+func watchNamespaces(
+	ctx context.Context,
+	client kubernetes.Interface,
+	namespaceLabels map[string]string,
+	changed func(namespace string),
+) error {
+	selector := labels.SelectorFromSet(namespaceLabels).String()
+	factory := informers.NewSharedInformerFactoryWithOptions(
+		client,
+		0,
+		informers.WithTweakListOptions(func(options *metav1.ListOptions) {
+			options.LabelSelector = selector
+		}),
+	)
+
+	notify := func(obj interface{}) {
+		// deleted namespaces may be notified with their last known state
+		if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+			obj = tombstone.Obj
+		}
+
+		if namespace, ok := obj.(*corev1.Namespace); ok {
+			changed(namespace.Name)
+		}
+	}
+
+	_, err := factory.Core().V1().Namespaces().Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    notify,
+		UpdateFunc: func(_ interface{}, obj interface{}) { notify(obj) },
+		DeleteFunc: notify,
+	})
+	if err != nil {
+		return fmt.Errorf("watching namespaces labeled %s: %w", selector, err)
+	}
+
+	factory.Start(ctx.Done())
+	for _, synced := range factory.WaitForCacheSync(ctx.Done()) {
+		if !synced {
+			return fmt.Errorf("watching namespaces labeled %s: cache not synced", selector)
+		}
+	}
+
+	return nil
+}
+
+// refresh updates the targets. The agent is injected in the new targets and the visits in progress are extended to
+// them. New targets are only added once they are running. Targets that are no longer selected are dropped.
+func (c *agentController) refresh(ctx context.Context, targets targetsFunc) error {
+	current := c.currentTargets()
+	selected, err := targets(ctx, current)
+	if err != nil {
+		return err
+	}
+
+	known := map[string]bool{}
+	for _, pod := range current {
+		known[podKey(pod)] = true
+	}
+
+	kept := []corev1.Pod{}
+	candidates := []corev1.Pod{}
+	for _, pod := range selected {
+		switch {
+		case known[podKey(pod)]:
+			kept = append(kept, pod)
+		case pod.Status.Phase == corev1.PodRunning:
+			candidates = append(candidates, pod)
+		}
+	}
+
+	// pods where the agent cannot be injected are added in a later refresh
+	added := []corev1.Pod{}
+	for i, err := range c.injectAgent(ctx, candidates) {
+		if err == nil {
+			added = append(added, candidates[i])
+		}
+	}
+
+	c.mu.Lock()
+	c.targets = append(kept, added...)
+	visits := []*activeVisit{}
+	for visit := range c.visits {
+		visits = append(visits, visit)
+	}
+	c.mu.Unlock()
+
+	for _, visit := range visits {
+		for _, pod := range added {
+			c.extend(visit, pod)
+		}
+	}
+
+	return nil
+}
+
+// podKey returns a key that identifies a pod. Pods recreated with the same name, for example the pods of a
+// StatefulSet, have different keys.
+func podKey(pod corev1.Pod) string {
+	return pod.Namespace + "/" + pod.Name + "/" + string(pod.UID)
+}
+
+// agentTimeout returns the timeout for waiting the agent to be ready. A zero value forces the default timeout.
+// A negative value forces no waiting.
+func agentTimeout(timeout time.Duration) time.Duration {
 	if timeout == 0 {
-		timeout = 30 * time.Second
+		return 30 * time.Second
 	}
 	if timeout < 0 {
-		timeout = 0
-	}
-	return &agentController{
-		helper:    helper,
-		namespace: namespace,
-		targets:   targets,
-		timeout:   timeout,
+		return 0
 	}
+
+	return timeout
 }
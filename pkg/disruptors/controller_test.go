@@ -2,9 +2,11 @@ package disruptors
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"sort"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -14,6 +16,7 @@ import (
 	"k8s.io/client-go/kubernetes/fake"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/grafana/xk6-disruptor/pkg/kubernetes"
 	"github.com/grafana/xk6-disruptor/pkg/kubernetes/helpers"
 	"github.com/grafana/xk6-disruptor/pkg/testutils/kubernetes/builders"
 )
@@ -79,7 +82,6 @@ func Test_InjectAgent(t *testing.T) {
 			controller := NewAgentController(
 				context.TODO(),
 				helper,
-				tc.namespace,
 				tc.pods,
 				tc.timeout,
 			)
@@ -126,6 +128,20 @@ func (v fakeVisitor) Visit(_ corev1.Pod) (VisitCommands, error) {
 	return v.cmds, v.err
 }
 
+// fakeTimedVisitor is a visitor whose command runs for the duration of a fault
+type fakeTimedVisitor struct {
+	duration time.Duration
+}
+
+func (v fakeTimedVisitor) Visit(_ corev1.Pod) (VisitCommands, error) {
+	return VisitCommands{Exec: []string{"command", "-d", v.duration.String()}, Cleanup: []string{"cleanup"}}, nil
+}
+
+func (v fakeTimedVisitor) remaining(elapsed time.Duration) (PodVisitor, bool) {
+	v.duration -= elapsed
+	return v, v.duration >= minRemainingDuration
+}
+
 func Test_VisitPod(t *testing.T) {
 	t.Parallel()
 
@@ -209,7 +225,6 @@ func Test_VisitPod(t *testing.T) {
 			controller := NewAgentController(
 				context.TODO(),
 				helper,
-				tc.namespace,
 				targets,
 				tc.timeout,
 			)
@@ -252,3 +267,626 @@ func Test_VisitPod(t *testing.T) {
 		})
 	}
 }
+
+func Test_VisitProcess(t *testing.T) {
+	t.Parallel()
+
+	longName := strings.Repeat("c", 63)
+
+	testCases := []struct {
+		title         string
+		container     string
+		cgroup        bool
+		err           error
+		expectedAgent string
+		expectedCmds  int
+	}{
+		{
+			title:         "container",
+			container:     "main",
+			expectedAgent: "xk6-agent-main",
+			expectedCmds:  2,
+		},
+		{
+			title:         "cgroup of container",
+			container:     "main",
+			cgroup:        true,
+			expectedAgent: "xk6-agent-cgroup-main",
+			expectedCmds:  2,
+		},
+		{
+			title:         "cleanup after failure",
+			container:     "main",
+			err:           fmt.Errorf("fake error"),
+			expectedAgent: "xk6-agent-main",
+			expectedCmds:  4,
+		},
+		{
+			title:         "long container name",
+			container:     longName,
+			expectedAgent: processAgentName(longName, false),
+			expectedCmds:  2,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			pod := builders.NewPodBuilder("pod1").
+				WithNamespace("test-ns").
+				Build()
+
+			client := fake.NewSimpleClientset(&pod)
+			executor := helpers.NewFakePodCommandExecutor()
+			helper := helpers.NewPodHelper(client, executor, "test-ns")
+			controller := NewAgentController(context.TODO(), helper, []corev1.Pod{pod}, -1)
+
+			executor.SetResult(nil, nil, tc.err)
+			visitor := fakeVisitor{
+				cmds: VisitCommands{
+					Exec:      []string{"command"},
+					Cleanup:   []string{"cleanup"},
+					Container: tc.container,
+					Cgroup:    tc.cgroup,
+				},
+			}
+
+			// visit twice to check the agent is attached only once
+			for i := 0; i < 2; i++ {
+				err := controller.Visit(context.TODO(), visitor)
+				if tc.err == nil && err != nil {
+					t.Fatalf("failed unexpectedly: %v", err)
+				}
+				if tc.err != nil && err == nil {
+					t.Fatalf("should had failed")
+				}
+			}
+
+			if len(tc.expectedAgent) > 63 {
+				t.Errorf("agent name %q is too long", tc.expectedAgent)
+			}
+
+			current, err := client.CoreV1().Pods("test-ns").Get(context.TODO(), "pod1", metav1.GetOptions{})
+			if err != nil {
+				t.Fatalf("failed: %v", err)
+			}
+
+			expected := []corev1.EphemeralContainer{agentContainer(tc.expectedAgent, tc.container, tc.cgroup)}
+			if diff := cmp.Diff(expected, current.Spec.EphemeralContainers); diff != "" {
+				t.Errorf("attached agents do not match expected:\n%s", diff)
+			}
+
+			history := executor.GetHistory()
+			if len(history) != tc.expectedCmds {
+				t.Errorf("expected %d commands got %d", tc.expectedCmds, len(history))
+			}
+
+			// the cleanup command is executed by the same agent
+			for _, cmd := range history {
+				if cmd.Container != tc.expectedAgent {
+					t.Errorf("expected command executed in %q got %q", tc.expectedAgent, cmd.Container)
+				}
+			}
+		})
+	}
+}
+
+func Test_VisitWithReport(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		title    string
+		stdout   string
+		expected *RequestMetrics
+	}{
+		{
+			title:    "agent prints summary",
+			stdout:   "starting proxy\r\n{\"summary\":{\"requests\":10,\"excluded\":2,\"disrupted\":5}}\r\n",
+			expected: &RequestMetrics{Requests: 10, Excluded: 2, Disrupted: 5},
+		},
+		{
+			title:    "agent does not print summary",
+			stdout:   "",
+			expected: nil,
+		},
+		{
+			title:    "output is not a summary",
+			stdout:   "{\"requests\":10}\n",
+			expected: nil,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			pods := []corev1.Pod{
+				builders.NewPodBuilder("pod2").WithNamespace("test-ns").Build(),
+				builders.NewPodBuilder("pod1").WithNamespace("test-ns").Build(),
+			}
+
+			objs := []runtime.Object{}
+			for p := range pods {
+				objs = append(objs, &pods[p])
+			}
+
+			client := fake.NewSimpleClientset(objs...)
+			executor := helpers.NewFakePodCommandExecutor()
+			executor.SetResult([]byte(tc.stdout), []byte{}, nil)
+			helperFor := func(namespace string) helpers.PodHelper {
+				return helpers.NewPodHelper(client, executor, namespace)
+			}
+			controller := newAgentController(helperFor, pods, -1)
+
+			visitor := fakeVisitor{cmds: VisitCommands{Exec: []string{"command"}, Port: 80}}
+			report, err := controller.VisitWithReport(context.TODO(), visitor)
+			if err != nil {
+				t.Fatalf("unexpected error : %v", err)
+			}
+
+			expected := FaultReport{
+				Targets: []TargetReport{
+					{Namespace: "test-ns", Name: "pod1", Port: 80, Command: []string{"command"}, Metrics: tc.expected},
+					{Namespace: "test-ns", Name: "pod2", Port: 80, Command: []string{"command"}, Metrics: tc.expected},
+				},
+			}
+
+			if diff := cmp.Diff(expected, report); diff != "" {
+				t.Errorf("report does not match expected:\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_StopVisitWithReport(t *testing.T) {
+	t.Parallel()
+
+	pod := builders.NewPodBuilder("pod1").WithNamespace("test-ns").Build()
+
+	client := fake.NewSimpleClientset(&pod)
+	executor := &blockingExecutor{
+		blocking: "command",
+		stdout:   []byte("{\"summary\":{\"requests\":3,\"excluded\":0,\"disrupted\":1}}\n"),
+	}
+	helperFor := func(namespace string) helpers.PodHelper {
+		return helpers.NewPodHelper(client, executor, namespace)
+	}
+	controller := newAgentController(helperFor, []corev1.Pod{pod}, -1)
+
+	ctx, stop := context.WithCancel(context.Background())
+	defer stop()
+
+	type result struct {
+		report FaultReport
+		err    error
+	}
+	resultCh := make(chan result, 1)
+	go func() {
+		visitor := fakeVisitor{cmds: VisitCommands{Exec: []string{"command"}, Cleanup: []string{"cleanup"}, Port: 80}}
+		report, err := controller.VisitWithReport(ctx, visitor)
+		resultCh <- result{report: report, err: err}
+	}()
+
+	waitFor(t, "command in pod1", func() bool { return executor.executed("pod1", "command") })
+
+	// the agent is stopped with the cleanup command, so it reports the requests handled until then
+	stop()
+
+	var visit result
+	select {
+	case visit = <-resultCh:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("visit did not end")
+	}
+
+	if !errors.Is(visit.err, context.Canceled) {
+		t.Errorf("expected %v got %v", context.Canceled, visit.err)
+	}
+
+	expected := FaultReport{
+		Targets: []TargetReport{
+			{
+				Namespace: "test-ns",
+				Name:      "pod1",
+				Port:      80,
+				Command:   []string{"command"},
+				Metrics:   &RequestMetrics{Requests: 3, Disrupted: 1},
+			},
+		},
+	}
+
+	if diff := cmp.Diff(expected, visit.report); diff != "" {
+		t.Errorf("report does not match expected:\n%s", diff)
+	}
+}
+
+func Test_VisitTargets(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		title    string
+		pods     []corev1.Pod
+		targets  []string
+		expected []string
+	}{
+		{
+			title: "visit one target",
+			pods: []corev1.Pod{
+				builders.NewPodBuilder("pod1").WithNamespace("test-ns").Build(),
+				builders.NewPodBuilder("pod2").WithNamespace("test-ns").Build(),
+			},
+			targets:  []string{"pod2"},
+			expected: []string{"pod2"},
+		},
+		{
+			title: "targets in multiple namespaces",
+			pods: []corev1.Pod{
+				builders.NewPodBuilder("pod1").WithNamespace("ns-1").Build(),
+				builders.NewPodBuilder("pod1").WithNamespace("ns-2").Build(),
+			},
+			targets:  []string{"ns-2/pod1"},
+			expected: []string{"ns-2/pod1"},
+		},
+		{
+			title: "unknown targets are ignored",
+			pods: []corev1.Pod{
+				builders.NewPodBuilder("pod1").WithNamespace("test-ns").Build(),
+			},
+			targets:  []string{"pod3"},
+			expected: []string{},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			objs := []runtime.Object{}
+			for p := range tc.pods {
+				objs = append(objs, &tc.pods[p])
+			}
+
+			client := fake.NewSimpleClientset(objs...)
+			executor := helpers.NewFakePodCommandExecutor()
+			helperFor := func(namespace string) helpers.PodHelper {
+				return helpers.NewPodHelper(client, executor, namespace)
+			}
+			controller := newAgentController(helperFor, tc.pods, -1)
+
+			visitor := fakeVisitor{
+				cmds: VisitCommands{Exec: []string{"command"}},
+			}
+			err := controller.VisitTargets(context.TODO(), tc.targets, visitor)
+			if err != nil {
+				t.Fatalf("failed unexpectedly: %v", err)
+			}
+
+			visited := []string{}
+			for _, cmd := range executor.GetHistory() {
+				visited = append(visited, cmd.Namespace+"/"+cmd.Pod)
+			}
+			sort.Strings(visited)
+
+			expected := []string{}
+			for _, name := range tc.expected {
+				if !strings.Contains(name, "/") {
+					name = "test-ns/" + name
+				}
+				expected = append(expected, name)
+			}
+
+			if diff := cmp.Diff(expected, visited); diff != "" {
+				t.Errorf("visited targets did not match expected:\n%s", diff)
+			}
+		})
+	}
+}
+
+// blockingExecutor records the commands executed in the pods and blocks the execution of the blocking command
+// until its context is cancelled or, as the agent does, until the cleanup command is executed in the pod. Then, it
+// returns the given output.
+type blockingExecutor struct {
+	blocking string
+	stdout   []byte
+	mutex    sync.Mutex
+	history  []helpers.Command
+	// closed when the cleanup command is executed in each pod
+	stopped map[string]chan struct{}
+}
+
+// stoppedChannel returns the channel closed when the cleanup command is executed in the pod
+func (e *blockingExecutor) stoppedChannel(pod string) chan struct{} {
+	if e.stopped == nil {
+		e.stopped = map[string]chan struct{}{}
+	}
+
+	stopped, found := e.stopped[pod]
+	if !found {
+		stopped = make(chan struct{})
+		e.stopped[pod] = stopped
+	}
+
+	return stopped
+}
+
+func (e *blockingExecutor) Exec(
+	ctx context.Context,
+	pod string,
+	namespace string,
+	container string,
+	cmd []string,
+	stdin []byte,
+) ([]byte, []byte, error) {
+	e.mutex.Lock()
+	e.history = append(e.history, helpers.Command{
+		Pod:       pod,
+		Namespace: namespace,
+		Container: container,
+		Command:   cmd,
+		Stdin:     stdin,
+	})
+	stopped := e.stoppedChannel(pod)
+	if strings.Join(cmd, " ") == "cleanup" {
+		select {
+		case <-stopped:
+		default:
+			close(stopped)
+		}
+	}
+	e.mutex.Unlock()
+
+	if strings.Join(cmd, " ") != e.blocking {
+		return nil, nil, nil
+	}
+
+	select {
+	case <-stopped:
+		return e.stdout, nil, fmt.Errorf("received signal %q", "terminated")
+	case <-ctx.Done():
+		return nil, nil, ctx.Err()
+	}
+}
+
+// executed returns true if the command was executed in the pod
+func (e *blockingExecutor) executed(pod string, cmd string) bool {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	for _, c := range e.history {
+		if c.Pod == pod && strings.Join(c.Command, " ") == cmd {
+			return true
+		}
+	}
+
+	return false
+}
+
+// waitFor waits until the condition is true or fails the test after a timeout
+func waitFor(t *testing.T, description string, condition func() bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timeout waiting for %s", description)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func Test_WatchTargets(t *testing.T) {
+	t.Parallel()
+
+	namespace := "test-ns"
+	pod := builders.NewPodBuilder("pod1").
+		WithNamespace(namespace).
+		WithPhase(corev1.PodRunning).
+		Build()
+
+	client := fake.NewSimpleClientset(&pod)
+	executor := &blockingExecutor{blocking: "command"}
+	helperFor := func(namespace string) helpers.PodHelper {
+		return helpers.NewPodHelper(client, executor, namespace)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	controller := newAgentController(helperFor, []corev1.Pod{pod}, -1)
+	list := func(ctx context.Context, _ []corev1.Pod) ([]corev1.Pod, error) {
+		return helpers.NewPodHelper(client, executor, namespace).List(ctx, helpers.PodFilter{})
+	}
+	err := controller.watchTargets(ctx, client, []string{namespace}, nil, list)
+	if err != nil {
+		t.Fatalf("failed watching targets: %v", err)
+	}
+
+	visitCtx, stopVisit := context.WithCancel(context.Background())
+	visitErr := make(chan error, 1)
+	go func() {
+		visitErr <- controller.Visit(
+			visitCtx,
+			fakeVisitor{cmds: VisitCommands{Exec: []string{"command"}, Cleanup: []string{"cleanup"}}},
+		)
+	}()
+
+	waitFor(t, "command in pod1", func() bool { return executor.executed("pod1", "command") })
+
+	// a new pod receives the fault in progress
+	added := builders.NewPodBuilder("pod2").
+		WithNamespace(namespace).
+		WithPhase(corev1.PodRunning).
+		Build()
+	_, err = client.CoreV1().Pods(namespace).Create(context.TODO(), &added, metav1.CreateOptions{})
+	if err != nil {
+		t.Fatalf("failed creating pod: %v", err)
+	}
+
+	waitFor(t, "command in pod2", func() bool { return executor.executed("pod2", "command") })
+
+	updated, err := client.CoreV1().Pods(namespace).Get(context.TODO(), "pod2", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("failed getting pod: %v", err)
+	}
+	if len(updated.Spec.EphemeralContainers) == 0 {
+		t.Errorf("agent container is not attached to added pod")
+	}
+
+	// a deleted pod is dropped
+	err = client.CoreV1().Pods(namespace).Delete(context.TODO(), "pod1", metav1.DeleteOptions{})
+	if err != nil {
+		t.Fatalf("failed deleting pod: %v", err)
+	}
+
+	waitFor(t, "pod1 to be dropped", func() bool {
+		targets, _ := controller.Targets(context.TODO())
+		return cmp.Equal(targets, []string{"pod2"})
+	})
+
+	// the commands in the added pod end with the visit
+	stopVisit()
+	select {
+	case <-visitErr:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("visit did not end")
+	}
+
+	if !executor.executed("pod2", "cleanup") {
+		t.Errorf("cleanup command was not executed in added pod")
+	}
+}
+
+func Test_WatchTargetsInLabeledNamespaces(t *testing.T) {
+	t.Parallel()
+
+	namespaceLabels := map[string]string{"team": "payments"}
+	namespace := corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "ns-1", Labels: namespaceLabels}}
+	pod := builders.NewPodBuilder("pod1").
+		WithNamespace("ns-1").
+		WithPhase(corev1.PodRunning).
+		Build()
+
+	client := fake.NewSimpleClientset(&namespace, &pod)
+	k8s, _ := kubernetes.NewFakeKubernetes(client)
+	executor := &blockingExecutor{blocking: "command"}
+	helperFor := func(namespace string) helpers.PodHelper {
+		return helpers.NewPodHelper(client, executor, namespace)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	controller := newAgentController(helperFor, []corev1.Pod{pod}, -1)
+	selector := PodSelector{NamespaceLabels: namespaceLabels}
+	list := func(ctx context.Context, _ []corev1.Pod) ([]corev1.Pod, error) {
+		return listSelectorPods(ctx, k8s, selector)
+	}
+	err := controller.watchTargets(ctx, client, []string{"ns-1"}, namespaceLabels, list)
+	if err != nil {
+		t.Fatalf("failed watching targets: %v", err)
+	}
+
+	visitCtx, stopVisit := context.WithCancel(context.Background())
+	defer stopVisit()
+
+	go func() {
+		_ = controller.Visit(
+			visitCtx,
+			fakeVisitor{cmds: VisitCommands{Exec: []string{"command"}, Cleanup: []string{"cleanup"}}},
+		)
+	}()
+
+	waitFor(t, "command in pod1", func() bool { return executor.executed("pod1", "command") })
+
+	// a pod in a namespace that starts matching the labels receives the fault in progress
+	labeled := corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "ns-2", Labels: namespaceLabels}}
+	_, err = client.CoreV1().Namespaces().Create(context.TODO(), &labeled, metav1.CreateOptions{})
+	if err != nil {
+		t.Fatalf("failed creating namespace: %v", err)
+	}
+
+	added := builders.NewPodBuilder("pod2").
+		WithNamespace("ns-2").
+		WithPhase(corev1.PodRunning).
+		Build()
+	_, err = client.CoreV1().Pods("ns-2").Create(context.TODO(), &added, metav1.CreateOptions{})
+	if err != nil {
+		t.Fatalf("failed creating pod: %v", err)
+	}
+
+	waitFor(t, "command in pod2", func() bool { return executor.executed("pod2", "command") })
+}
+
+func Test_WatchTargetsRemainingDuration(t *testing.T) {
+	t.Parallel()
+
+	namespace := "test-ns"
+	pod := builders.NewPodBuilder("pod1").
+		WithNamespace(namespace).
+		WithPhase(corev1.PodRunning).
+		Build()
+
+	client := fake.NewSimpleClientset(&pod)
+	executor := &blockingExecutor{blocking: "command -d 1m0s"}
+	helperFor := func(namespace string) helpers.PodHelper {
+		return helpers.NewPodHelper(client, executor, namespace)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	controller := newAgentController(helperFor, []corev1.Pod{pod}, -1)
+	list := func(ctx context.Context, _ []corev1.Pod) ([]corev1.Pod, error) {
+		return helpers.NewPodHelper(client, executor, namespace).List(ctx, helpers.PodFilter{})
+	}
+	err := controller.watchTargets(ctx, client, []string{namespace}, nil, list)
+	if err != nil {
+		t.Fatalf("failed watching targets: %v", err)
+	}
+
+	visitCtx, stopVisit := context.WithCancel(context.Background())
+	defer stopVisit()
+
+	go func() {
+		_ = controller.Visit(visitCtx, fakeTimedVisitor{duration: time.Minute})
+	}()
+
+	waitFor(t, "command in pod1", func() bool { return executor.executed("pod1", "command -d 1m0s") })
+	time.Sleep(100 * time.Millisecond)
+
+	added := builders.NewPodBuilder("pod2").
+		WithNamespace(namespace).
+		WithPhase(corev1.PodRunning).
+		Build()
+	_, err = client.CoreV1().Pods(namespace).Create(context.TODO(), &added, metav1.CreateOptions{})
+	if err != nil {
+		t.Fatalf("failed creating pod: %v", err)
+	}
+
+	// the added pod runs the command for the time remaining of the fault
+	var remaining time.Duration
+	waitFor(t, "command in pod2", func() bool {
+		executor.mutex.Lock()
+		defer executor.mutex.Unlock()
+
+		for _, c := range executor.history {
+			if c.Pod == "pod2" && len(c.Command) == 3 && c.Command[0] == "command" {
+				remaining, err = time.ParseDuration(c.Command[2])
+				return true
+			}
+		}
+
+		return false
+	})
+
+	if err != nil || remaining >= time.Minute || remaining < 50*time.Second {
+		t.Errorf("expected command for the remaining time of the fault got %s (%v)", remaining, err)
+	}
+}
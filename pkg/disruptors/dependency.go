@@ -0,0 +1,178 @@
+package disruptors
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/grafana/xk6-disruptor/pkg/kubernetes"
+)
+
+// DependencyDisruptor defines the types of faults that can be injected in the requests the target pods
+// send to an external dependency
+type DependencyDisruptor interface {
+	Disruptor
+	ProtocolFaultInjector
+	TCPFaultInjector
+}
+
+// Dependency defines the destination of the outgoing traffic to be disrupted
+type Dependency struct {
+	// Host name, IP address or CIDR of the dependency
+	Host string `js:"host"`
+	// Port the dependency listens to
+	Port uint `js:"port"`
+}
+
+// DependencyDisruptorOptions defines options that controls the DependencyDisruptor's behavior
+type DependencyDisruptorOptions struct {
+	// timeout when waiting agent to be injected in seconds. A zero value forces default.
+	// A Negative value forces no waiting.
+	InjectTimeout time.Duration `js:"injectTimeout"`
+}
+
+// dependencyDisruptor is an instance of a DependencyDisruptor initialized with a list of target pods
+type dependencyDisruptor struct {
+	dependency Dependency
+	controller AgentController
+}
+
+// NewDependencyDisruptor creates a new instance of a DependencyDisruptor that acts on the requests sent to the
+// dependency by the pods that match the given PodSelector
+func NewDependencyDisruptor(
+	ctx context.Context,
+	k8s kubernetes.Kubernetes,
+	selector PodSelector,
+	dependency Dependency,
+	options DependencyDisruptorOptions,
+) (DependencyDisruptor, error) {
+	if dependency.Host == "" {
+		return nil, fmt.Errorf("dependency host must be specified")
+	}
+
+	if dependency.Port == 0 {
+		return nil, fmt.Errorf("dependency port must be specified")
+	}
+
+	podOptions := PodDisruptorOptions{InjectTimeout: options.InjectTimeout}
+	controller, err := newPodAgentController(ctx, k8s, selector, podOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dependencyDisruptor{
+		dependency: dependency,
+		controller: controller,
+	}, nil
+}
+
+func (d *dependencyDisruptor) Targets(ctx context.Context) ([]string, error) {
+	return d.controller.Targets(ctx)
+}
+
+// checkPort verifies the port of a fault, if specified, is the port of the dependency
+func (d *dependencyDisruptor) checkPort(port uint) error {
+	if port != 0 && port != d.dependency.Port {
+		return fmt.Errorf("fault port %d does not match dependency port %d", port, d.dependency.Port)
+	}
+
+	return nil
+}
+
+// InjectHTTPFaults injects faults in the http requests sent to the dependency by the disruptor's targets
+func (d *dependencyDisruptor) InjectHTTPFaults(
+	ctx context.Context,
+	fault HTTPFault,
+	duration time.Duration,
+	options HTTPDisruptionOptions,
+) error {
+	_, err := d.InjectHTTPFaultsWithReport(ctx, fault, duration, options)
+	return err
+}
+
+// InjectHTTPFaultsWithReport injects faults in the http requests sent to the dependency by the disruptor's targets
+// and returns the report of the injection in each target
+func (d *dependencyDisruptor) InjectHTTPFaultsWithReport(
+	ctx context.Context,
+	fault HTTPFault,
+	duration time.Duration,
+	options HTTPDisruptionOptions,
+) (FaultReport, error) {
+	if err := d.checkPort(fault.Port); err != nil {
+		return FaultReport{}, err
+	}
+
+	visitor := timedVisitor{
+		duration: duration,
+		build: func(duration time.Duration) PodVisitor {
+			return PodEgressHTTPFaultVisitor{
+				dependency: d.dependency,
+				fault:      fault,
+				duration:   duration,
+				options:    options,
+			}
+		},
+	}
+
+	return d.controller.VisitWithReport(ctx, visitor)
+}
+
+// InjectGrpcFaults injects faults in the grpc requests sent to the dependency by the disruptor's targets
+func (d *dependencyDisruptor) InjectGrpcFaults(
+	ctx context.Context,
+	fault GrpcFault,
+	duration time.Duration,
+	options GrpcDisruptionOptions,
+) error {
+	_, err := d.InjectGrpcFaultsWithReport(ctx, fault, duration, options)
+	return err
+}
+
+// InjectGrpcFaultsWithReport injects faults in the grpc requests sent to the dependency by the disruptor's targets
+// and returns the report of the injection in each target
+func (d *dependencyDisruptor) InjectGrpcFaultsWithReport(
+	ctx context.Context,
+	fault GrpcFault,
+	duration time.Duration,
+	options GrpcDisruptionOptions,
+) (FaultReport, error) {
+	if err := d.checkPort(fault.Port); err != nil {
+		return FaultReport{}, err
+	}
+
+	visitor := timedVisitor{
+		duration: duration,
+		build: func(duration time.Duration) PodVisitor {
+			return PodEgressGrpcFaultVisitor{
+				dependency: d.dependency,
+				fault:      fault,
+				duration:   duration,
+				options:    options,
+			}
+		},
+	}
+
+	return d.controller.VisitWithReport(ctx, visitor)
+}
+
+// InjectTCPFaults injects faults in the TCP connections opened to the dependency by the disruptor's targets
+func (d *dependencyDisruptor) InjectTCPFaults(
+	ctx context.Context,
+	fault TCPFault,
+	duration time.Duration,
+	options TCPDisruptionOptions,
+) error {
+	visitor := timedVisitor{
+		duration: duration,
+		build: func(duration time.Duration) PodVisitor {
+			return PodEgressTCPFaultVisitor{
+				dependency: d.dependency,
+				fault:      fault,
+				duration:   duration,
+				options:    options,
+			}
+		},
+	}
+
+	return d.controller.Visit(ctx, visitor)
+}
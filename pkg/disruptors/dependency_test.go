@@ -0,0 +1,121 @@
+package disruptors
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/grafana/xk6-disruptor/pkg/kubernetes"
+	"github.com/grafana/xk6-disruptor/pkg/testutils/kubernetes/builders"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func Test_NewDependencyDisruptor(t *testing.T) {
+	t.Parallel()
+
+	selector := PodSelector{
+		Namespace: "test-ns",
+		Select: PodAttributes{Labels: map[string]string{
+			"app": "test",
+		}},
+	}
+
+	testCases := []struct {
+		title       string
+		pods        []corev1.Pod
+		dependency  Dependency
+		expectError bool
+	}{
+		{
+			title: "valid dependency",
+			pods: []corev1.Pod{
+				builders.NewPodBuilder("pod-1").
+					WithNamespace("test-ns").
+					WithLabel("app", "test").
+					WithIP("192.0.2.6").
+					Build(),
+			},
+			dependency:  Dependency{Host: "api.example.com", Port: 443},
+			expectError: false,
+		},
+		{
+			title: "missing host",
+			pods: []corev1.Pod{
+				builders.NewPodBuilder("pod-1").
+					WithNamespace("test-ns").
+					WithLabel("app", "test").
+					Build(),
+			},
+			dependency:  Dependency{Port: 443},
+			expectError: true,
+		},
+		{
+			title: "missing port",
+			pods: []corev1.Pod{
+				builders.NewPodBuilder("pod-1").
+					WithNamespace("test-ns").
+					WithLabel("app", "test").
+					Build(),
+			},
+			dependency:  Dependency{Host: "api.example.com"},
+			expectError: true,
+		},
+		{
+			title:       "no matching pods",
+			pods:        []corev1.Pod{},
+			dependency:  Dependency{Host: "api.example.com", Port: 443},
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			client := fake.NewSimpleClientset()
+			for p := range tc.pods {
+				_ = client.Tracker().Add(&tc.pods[p])
+			}
+			k, _ := kubernetes.NewFakeKubernetes(client)
+
+			_, err := NewDependencyDisruptor(
+				context.TODO(),
+				k,
+				selector,
+				tc.dependency,
+				DependencyDisruptorOptions{InjectTimeout: -1},
+			)
+
+			if !tc.expectError && err != nil {
+				t.Errorf("unexpected error creating dependency disruptor: %v", err)
+				return
+			}
+
+			if tc.expectError && err == nil {
+				t.Errorf("should had failed creating dependency disruptor")
+			}
+		})
+	}
+}
+
+func Test_DependencyDisruptorPortMismatch(t *testing.T) {
+	t.Parallel()
+
+	d := &dependencyDisruptor{
+		dependency: Dependency{Host: "api.example.com", Port: 443},
+	}
+
+	err := d.InjectHTTPFaults(context.TODO(), HTTPFault{Port: 80}, time.Second, HTTPDisruptionOptions{})
+	if err == nil {
+		t.Errorf("should had failed injecting http faults in a port other than the dependency's")
+	}
+
+	err = d.InjectGrpcFaults(context.TODO(), GrpcFault{Port: 80}, time.Second, GrpcDisruptionOptions{})
+	if err == nil {
+		t.Errorf("should had failed injecting grpc faults in a port other than the dependency's")
+	}
+}
@@ -0,0 +1,55 @@
+package disruptors
+
+import (
+	"context"
+	"time"
+)
+
+// DNSFaultInjector defines the methods for injecting faults in the DNS queries of the targets
+type DNSFaultInjector interface {
+	// InjectDNSFaults injects faults in the DNS queries sent by the disruptor's targets
+	// for the specified duration
+	InjectDNSFaults(ctx context.Context, fault DNSFault, duration time.Duration, options DNSDisruptionOptions) error
+}
+
+// DNSDisruptionOptions defines options for the injection of DNS faults in a target pod
+type DNSDisruptionOptions struct {
+	// Port used by the agent for listening
+	ProxyPort uint `js:"proxyPort"`
+}
+
+// DNSFault specifies a fault to be injected in DNS queries
+type DNSFault struct {
+	// Average delay introduced to queries
+	AverageDelay time.Duration `js:"averageDelay"`
+	// Variation in the delay (with respect of the average delay)
+	DelayVariation time.Duration `js:"delayVariation"`
+	// Fraction (in the range 0.0 to 1.0) of queries that will return an error
+	ErrorRate float32 `js:"errorRate"`
+	// Error returned by the queries selected in the error rate: "servfail", "nxdomain" or "empty"
+	Error string `js:"error"`
+	// Comma-separated list of names to be disrupted. A name also matches its subdomains.
+	// If empty, all the queries are disrupted.
+	Names string `js:"names"`
+}
+
+// InjectDNSFaults injects faults in the DNS queries sent by the disruptor's targets
+func (d *podDisruptor) InjectDNSFaults(
+	ctx context.Context,
+	fault DNSFault,
+	duration time.Duration,
+	options DNSDisruptionOptions,
+) error {
+	visitor := timedVisitor{
+		duration: duration,
+		build: func(duration time.Duration) PodVisitor {
+			return PodDNSFaultVisitor{
+				fault:    fault,
+				duration: duration,
+				options:  options,
+			}
+		},
+	}
+
+	return d.controller.Visit(ctx, visitor)
+}
@@ -0,0 +1,94 @@
+package disruptors
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/grafana/xk6-disruptor/pkg/testutils/command"
+	"github.com/grafana/xk6-disruptor/pkg/testutils/kubernetes/builders"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+func Test_PodDNSFaultVisitor(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		title       string
+		target      corev1.Pod
+		visitor     PodVisitor
+		expectedCmd string
+		expectError bool
+	}{
+		{
+			title:  "nxdomain for names",
+			target: buildPodWithPort("my-app-pod", "http", 80),
+			visitor: PodDNSFaultVisitor{
+				fault: DNSFault{
+					ErrorRate: 0.1,
+					Error:     "nxdomain",
+					Names:     "api.example.com,db.example.com",
+				},
+				duration: 60 * time.Second,
+			},
+			expectedCmd: "xk6-disruptor-agent dns -d 60s -e nxdomain -r 0.1 --names api.example.com,db.example.com",
+			expectError: false,
+		},
+		{
+			title:  "delay with proxy port",
+			target: buildPodWithPort("my-app-pod", "http", 80),
+			visitor: PodDNSFaultVisitor{
+				fault: DNSFault{
+					AverageDelay:   100 * time.Millisecond,
+					DelayVariation: 10 * time.Millisecond,
+				},
+				duration: 60 * time.Second,
+				options:  DNSDisruptionOptions{ProxyPort: 9053},
+			},
+			expectedCmd: "xk6-disruptor-agent dns -d 60s -a 100ms -v 10ms -p 9053",
+			expectError: false,
+		},
+		{
+			title: "pod with hostNetwork",
+			target: builders.NewPodBuilder("hostnet").
+				WithNamespace("test-ns").
+				WithHostNetwork(true).
+				Build(),
+			visitor: PodDNSFaultVisitor{
+				fault:    DNSFault{ErrorRate: 0.1, Error: "servfail"},
+				duration: 60 * time.Second,
+			},
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			cmds, err := tc.visitor.Visit(tc.target)
+
+			if tc.expectError && err == nil {
+				t.Errorf("should had failed")
+				return
+			}
+
+			if !tc.expectError && err != nil {
+				t.Errorf("unexpected error : %v", err)
+				return
+			}
+
+			exec := strings.Join(cmds.Exec, " ")
+			if !command.AssertCmdEquals(exec, tc.expectedCmd) {
+				t.Errorf("expected command: %s got: %s", tc.expectedCmd, exec)
+			}
+
+			cleanup := strings.Join(cmds.Cleanup, " ")
+			if !tc.expectError && !command.AssertCmdEquals(cleanup, "xk6-disruptor-agent cleanup") {
+				t.Errorf("expected cleanup command: xk6-disruptor-agent cleanup got: %s", cleanup)
+			}
+		})
+	}
+}
@@ -0,0 +1,114 @@
+package disruptors
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/grafana/xk6-disruptor/pkg/kubernetes"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// fdExhaustionPlatform is the only platform (os/architecture) of the nodes where the agent can inject the system
+// calls that exhaust the file descriptors of a process
+const fdExhaustionPlatform = "linux/amd64"
+
+// ResourceExhaustionInjector defines the methods for exhausting the resources of a target
+type ResourceExhaustionInjector interface {
+	// InjectDiskFillFault fills a filesystem of a container in each of the disruptor's targets for the
+	// specified duration
+	InjectDiskFillFault(ctx context.Context, fault DiskFillFault, duration time.Duration) error
+	// InjectFDExhaustionFault exhausts the file descriptors of the main process of a container in each of
+	// the disruptor's targets for the specified duration
+	InjectFDExhaustionFault(ctx context.Context, fault FDExhaustionFault, duration time.Duration) error
+}
+
+// DiskFillFault specifies a filesystem of a container that will be filled for the duration of the fault
+type DiskFillFault struct {
+	// Name of the container whose filesystem will be filled. If empty, the first container is used.
+	Container string `js:"container"`
+	// Path in the container of the filesystem to fill (e.g. the mount path of a volume)
+	Path string `js:"path"`
+	// Percentage (in the range 1 to 100) of the filesystem that will be used once filled
+	Percentage uint `js:"percentage"`
+}
+
+// FDExhaustionFault specifies a container whose main process will not be able to open file descriptors
+// for the duration of the fault
+type FDExhaustionFault struct {
+	// Name of the container whose main process will be affected. If empty, the first container is used.
+	Container string `js:"container"`
+	// Number of descriptors the process can still open. If 0, opening any descriptor will fail.
+	Remaining uint `js:"remaining"`
+}
+
+func validateDiskFillFault(fault DiskFillFault) error {
+	if fault.Path == "" {
+		return fmt.Errorf("path must be specified")
+	}
+
+	if fault.Percentage == 0 || fault.Percentage > 100 {
+		return fmt.Errorf("percentage must be in the range [1, 100]")
+	}
+
+	return nil
+}
+
+// InjectDiskFillFault fills a filesystem of a container in each of the disruptor's targets for the given duration
+func (d *podDisruptor) InjectDiskFillFault(ctx context.Context, fault DiskFillFault, duration time.Duration) error {
+	if err := validateDiskFillFault(fault); err != nil {
+		return err
+	}
+
+	visitor := timedVisitor{
+		duration: duration,
+		build: func(duration time.Duration) PodVisitor {
+			return PodDiskFillFaultVisitor{
+				fault:    fault,
+				duration: duration,
+			}
+		},
+	}
+
+	return d.controller.Visit(ctx, visitor)
+}
+
+// InjectFDExhaustionFault exhausts the file descriptors of a container in each of the disruptor's targets for
+// the given duration
+func (d *podDisruptor) InjectFDExhaustionFault(
+	ctx context.Context,
+	fault FDExhaustionFault,
+	duration time.Duration,
+) error {
+	platforms := nodePlatforms(ctx, d.k8s)
+	visitor := timedVisitor{
+		duration: duration,
+		build: func(duration time.Duration) PodVisitor {
+			return PodFDExhaustionFaultVisitor{
+				fault:     fault,
+				duration:  duration,
+				platforms: platforms,
+			}
+		},
+	}
+
+	return d.controller.Visit(ctx, visitor)
+}
+
+// nodePlatforms returns the platform (os/architecture) of each node in the cluster. If the nodes cannot be listed
+// (e.g. the user is not allowed to) the platforms are not known and an empty map is returned.
+func nodePlatforms(ctx context.Context, k8s kubernetes.Kubernetes) map[string]string {
+	platforms := map[string]string{}
+
+	nodes, err := k8s.Client().CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return platforms
+	}
+
+	for _, node := range nodes.Items {
+		platforms[node.Name] = node.Status.NodeInfo.OperatingSystem + "/" + node.Status.NodeInfo.Architecture
+	}
+
+	return platforms
+}
@@ -0,0 +1,253 @@
+package disruptors
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/grafana/xk6-disruptor/pkg/kubernetes"
+	"github.com/grafana/xk6-disruptor/pkg/kubernetes/helpers"
+	"github.com/grafana/xk6-disruptor/pkg/testutils/command"
+	"github.com/grafana/xk6-disruptor/pkg/testutils/kubernetes/builders"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func buildExhaustionPod() corev1.Pod {
+	return buildExhaustionPodInNode("my-app-pod", "")
+}
+
+func buildExhaustionPodInNode(name string, node string) corev1.Pod {
+	return builders.NewPodBuilder(name).
+		WithNamespace("test-ns").
+		WithNodeName(node).
+		WithContainer(builders.NewContainerBuilder("main").Build()).
+		WithContainer(builders.NewContainerBuilder("sidecar").Build()).
+		WithContainerStatus(corev1.ContainerStatus{Name: "main", ContainerID: "containerd://0123"}).
+		WithContainerStatus(corev1.ContainerStatus{Name: "sidecar", ContainerID: "containerd://4567"}).
+		Build()
+}
+
+func Test_PodResourceExhaustionVisitors(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		title             string
+		target            corev1.Pod
+		visitor           PodVisitor
+		expectedCmd       string
+		expectedCleanup   string
+		expectedContainer string
+		expectError       bool
+	}{
+		{
+			title:  "disk fill",
+			target: buildExhaustionPod(),
+			visitor: PodDiskFillFaultVisitor{
+				fault:    DiskFillFault{Path: "/data", Percentage: 90},
+				duration: 60 * time.Second,
+			},
+			expectedCmd:       "xk6-disruptor-agent exhaust disk -d 60s --container-id 0123 --path /data --percentage 90",
+			expectedCleanup:   "xk6-disruptor-agent cleanup --disk-fill-container-id 0123 --disk-fill-path /data",
+			expectedContainer: "main",
+		},
+		{
+			title:  "fd exhaustion",
+			target: buildExhaustionPod(),
+			visitor: PodFDExhaustionFaultVisitor{
+				fault:    FDExhaustionFault{Container: "main", Remaining: 10},
+				duration: 60 * time.Second,
+			},
+			expectedCmd:       "xk6-disruptor-agent exhaust fd -d 60s --container-id 0123 --remaining 10",
+			expectedCleanup:   "xk6-disruptor-agent cleanup --release-fds",
+			expectedContainer: "main",
+		},
+		{
+			title:  "disk fill in named container",
+			target: buildExhaustionPod(),
+			visitor: PodDiskFillFaultVisitor{
+				fault:    DiskFillFault{Container: "sidecar", Path: "/data", Percentage: 90},
+				duration: 60 * time.Second,
+			},
+			expectedCmd:       "xk6-disruptor-agent exhaust disk -d 60s --container-id 4567 --path /data --percentage 90",
+			expectedCleanup:   "xk6-disruptor-agent cleanup --disk-fill-container-id 4567 --disk-fill-path /data",
+			expectedContainer: "sidecar",
+		},
+		{
+			title:  "fd exhaustion in linux/amd64 node",
+			target: buildExhaustionPodInNode("my-app-pod", "node1"),
+			visitor: PodFDExhaustionFaultVisitor{
+				fault:     FDExhaustionFault{Container: "main"},
+				duration:  60 * time.Second,
+				platforms: map[string]string{"node1": "linux/amd64"},
+			},
+			expectedCmd:       "xk6-disruptor-agent exhaust fd -d 60s --container-id 0123 --remaining 0",
+			expectedCleanup:   "xk6-disruptor-agent cleanup --release-fds",
+			expectedContainer: "main",
+		},
+		{
+			title:  "fd exhaustion in linux/arm64 node",
+			target: buildExhaustionPodInNode("my-app-pod", "node1"),
+			visitor: PodFDExhaustionFaultVisitor{
+				fault:     FDExhaustionFault{Container: "main"},
+				duration:  60 * time.Second,
+				platforms: map[string]string{"node1": "linux/arm64"},
+			},
+			expectError: true,
+		},
+		{
+			title:  "unknown container",
+			target: buildExhaustionPod(),
+			visitor: PodFDExhaustionFaultVisitor{
+				fault:    FDExhaustionFault{Container: "other"},
+				duration: 60 * time.Second,
+			},
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			cmds, err := tc.visitor.Visit(tc.target)
+			if tc.expectError && err == nil {
+				t.Errorf("should had failed")
+				return
+			}
+
+			if !tc.expectError && err != nil {
+				t.Errorf("unexpected error : %v", err)
+				return
+			}
+
+			exec := strings.Join(cmds.Exec, " ")
+			if !command.AssertCmdEquals(exec, tc.expectedCmd) {
+				t.Errorf("expected command: %s got: %s", tc.expectedCmd, exec)
+			}
+
+			cleanup := strings.Join(cmds.Cleanup, " ")
+			if !command.AssertCmdEquals(cleanup, tc.expectedCleanup) {
+				t.Errorf("expected cleanup command: %s got: %s", tc.expectedCleanup, cleanup)
+			}
+
+			if cmds.Container != tc.expectedContainer {
+				t.Errorf("expected container: %s got: %s", tc.expectedContainer, cmds.Container)
+			}
+		})
+	}
+}
+
+func Test_InvalidDiskFillFault(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		title string
+		fault DiskFillFault
+	}{
+		{
+			title: "no path",
+			fault: DiskFillFault{Percentage: 90},
+		},
+		{
+			title: "no percentage",
+			fault: DiskFillFault{Path: "/data"},
+		},
+		{
+			title: "percentage over 100",
+			fault: DiskFillFault{Path: "/data", Percentage: 101},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			controller := &recordingController{}
+			disruptor := &podDisruptor{controller: controller}
+
+			err := disruptor.InjectDiskFillFault(context.TODO(), tc.fault, 60*time.Second)
+			if err == nil {
+				t.Errorf("should had failed")
+			}
+		})
+	}
+}
+
+func Test_FDExhaustionFaultPlatform(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		title       string
+		platforms   map[string]string
+		expectError bool
+		// number of commands executed in the targets
+		expectedCmds int
+	}{
+		{
+			title:        "linux/amd64 nodes",
+			platforms:    map[string]string{"node1": "linux/amd64", "node2": "linux/amd64"},
+			expectError:  false,
+			expectedCmds: 2,
+		},
+		{
+			title:        "one target in a linux/arm64 node",
+			platforms:    map[string]string{"node1": "linux/amd64", "node2": "linux/arm64"},
+			expectError:  true,
+			expectedCmds: 0,
+		},
+		{
+			title:        "nodes not listed",
+			platforms:    map[string]string{},
+			expectError:  false,
+			expectedCmds: 2,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			targets := []corev1.Pod{
+				buildExhaustionPodInNode("pod1", "node1"),
+				buildExhaustionPodInNode("pod2", "node2"),
+			}
+
+			objs := []runtime.Object{&targets[0], &targets[1]}
+			for name, platform := range tc.platforms {
+				node := builders.NewNodeBuilder(name).Build()
+				node.Status.NodeInfo.OperatingSystem, node.Status.NodeInfo.Architecture, _ = strings.Cut(platform, "/")
+				objs = append(objs, &node)
+			}
+
+			client := fake.NewSimpleClientset(objs...)
+			k8s, _ := kubernetes.NewFakeKubernetes(client)
+			executor := helpers.NewFakePodCommandExecutor()
+			helper := helpers.NewPodHelper(client, executor, "test-ns")
+			disruptor := &podDisruptor{
+				k8s:        k8s,
+				controller: NewAgentController(context.TODO(), helper, targets, -1),
+			}
+
+			err := disruptor.InjectFDExhaustionFault(context.TODO(), FDExhaustionFault{}, 60*time.Second)
+			if tc.expectError && err == nil {
+				t.Fatalf("should had failed")
+			}
+
+			if !tc.expectError && err != nil {
+				t.Fatalf("failed: %v", err)
+			}
+
+			// the fault is not injected in any target if one of them is rejected
+			if cmds := len(executor.GetHistory()); cmds != tc.expectedCmds {
+				t.Errorf("expected %d commands executed got %d", tc.expectedCmds, cmds)
+			}
+		})
+	}
+}
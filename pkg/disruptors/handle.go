@@ -0,0 +1,115 @@
+package disruptors
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// Status of a fault injected asynchronously
+const (
+	FaultRunning   = "running"
+	FaultCompleted = "completed"
+	FaultStopped   = "stopped"
+	FaultFailed    = "failed"
+)
+
+// FaultHandle controls a fault injected asynchronously
+type FaultHandle interface {
+	// Status returns the status of the fault: "running", "completed", "stopped" or "failed"
+	Status() string
+	// Wait waits for the fault to end and returns the report of its injection in the targets and the error
+	// injecting it, if any
+	Wait() (FaultReport, error)
+	// Stop ends the fault before its duration, waits for the agents to clean up the targets and returns the report
+	// of the injection until it was stopped
+	Stop() (FaultReport, error)
+}
+
+// faultHandle is a FaultHandle for a fault injected by a function in a goroutine
+type faultHandle struct {
+	cancel context.CancelFunc
+	done   chan struct{}
+
+	mu      sync.Mutex
+	stopped bool
+	report  FaultReport
+	err     error
+}
+
+// StartFault injects a fault asynchronously calling the inject function, and returns a handle for controlling it.
+// The fault is stopped when the given context is cancelled. The handle returns the report returned by the inject
+// function.
+func StartFault(ctx context.Context, inject func(ctx context.Context) (FaultReport, error)) FaultHandle {
+	faultCtx, cancel := context.WithCancel(ctx)
+
+	h := &faultHandle{
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}
+
+	go func() {
+		defer close(h.done)
+		defer cancel()
+
+		report, err := inject(faultCtx)
+
+		h.mu.Lock()
+		defer h.mu.Unlock()
+
+		// a fault stopped by the handle is not a failure
+		if h.stopped && errors.Is(err, context.Canceled) {
+			err = nil
+		}
+		h.report = report
+		h.err = err
+	}()
+
+	return h
+}
+
+// Status returns the status of the fault
+func (h *faultHandle) Status() string {
+	select {
+	case <-h.done:
+	default:
+		return FaultRunning
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	switch {
+	case h.err != nil:
+		return FaultFailed
+	case h.stopped:
+		return FaultStopped
+	default:
+		return FaultCompleted
+	}
+}
+
+// Wait waits for the fault to end and returns its report and the error injecting it, if any
+func (h *faultHandle) Wait() (FaultReport, error) {
+	<-h.done
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return h.report, h.err
+}
+
+// Stop ends the fault and waits for it to end. Stopping a fault that already ended has no effect.
+func (h *faultHandle) Stop() (FaultReport, error) {
+	h.mu.Lock()
+	select {
+	case <-h.done:
+	default:
+		h.stopped = true
+	}
+	h.mu.Unlock()
+
+	h.cancel()
+
+	return h.Wait()
+}
@@ -0,0 +1,146 @@
+package disruptors
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_FaultHandle(t *testing.T) {
+	t.Parallel()
+
+	report := FaultReport{Targets: []TargetReport{{Namespace: "ns", Name: "pod", Metrics: &RequestMetrics{Requests: 3}}}}
+
+	testCases := []struct {
+		title          string
+		inject         func(ctx context.Context) (FaultReport, error)
+		stop           bool
+		expectError    bool
+		expectedStatus string
+		expectedReport FaultReport
+	}{
+		{
+			title: "completed",
+			inject: func(_ context.Context) (FaultReport, error) {
+				return report, nil
+			},
+			expectedStatus: FaultCompleted,
+			expectedReport: report,
+		},
+		{
+			title: "failed",
+			inject: func(_ context.Context) (FaultReport, error) {
+				return FaultReport{}, errors.New("fake error")
+			},
+			expectError:    true,
+			expectedStatus: FaultFailed,
+		},
+		{
+			title: "stopped",
+			inject: func(ctx context.Context) (FaultReport, error) {
+				<-ctx.Done()
+				return report, ctx.Err()
+			},
+			stop:           true,
+			expectedStatus: FaultStopped,
+			expectedReport: report,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			handle := StartFault(context.TODO(), tc.inject)
+
+			var faultReport FaultReport
+			var err error
+			if tc.stop {
+				faultReport, err = handle.Stop()
+			} else {
+				faultReport, err = handle.Wait()
+			}
+
+			if tc.expectError && err == nil {
+				t.Errorf("should had failed")
+			}
+
+			if !tc.expectError && err != nil {
+				t.Errorf("unexpected error : %v", err)
+			}
+
+			if status := handle.Status(); status != tc.expectedStatus {
+				t.Errorf("expected status %q got %q", tc.expectedStatus, status)
+			}
+
+			if diff := cmp.Diff(tc.expectedReport, faultReport); diff != "" {
+				t.Errorf("expected report does not match returned:\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_FaultHandleRunning(t *testing.T) {
+	t.Parallel()
+
+	release := make(chan struct{})
+	handle := StartFault(context.TODO(), func(_ context.Context) (FaultReport, error) {
+		<-release
+		return FaultReport{}, nil
+	})
+
+	if status := handle.Status(); status != FaultRunning {
+		t.Errorf("expected status %q got %q", FaultRunning, status)
+	}
+
+	close(release)
+	if _, err := handle.Wait(); err != nil {
+		t.Fatalf("unexpected error : %v", err)
+	}
+
+	// stopping a fault that already ended has no effect
+	if _, err := handle.Stop(); err != nil {
+		t.Errorf("unexpected error : %v", err)
+	}
+
+	if status := handle.Status(); status != FaultCompleted {
+		t.Errorf("expected status %q got %q", FaultCompleted, status)
+	}
+}
+
+func Test_FaultHandleContextCancelled(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	handle := StartFault(ctx, func(ctx context.Context) (FaultReport, error) {
+		<-ctx.Done()
+		return FaultReport{}, ctx.Err()
+	})
+
+	cancel()
+
+	select {
+	case <-time.After(5 * time.Second):
+		t.Fatalf("fault did not end")
+	case err := <-waitAsync(handle):
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("expected context cancelled error got %v", err)
+		}
+	}
+}
+
+// waitAsync waits for the fault handle in a goroutine and returns a channel for receiving its error
+func waitAsync(handle FaultHandle) chan error {
+	errCh := make(chan error, 1)
+	go func() {
+		_, err := handle.Wait()
+		errCh <- err
+	}()
+
+	return errCh
+}
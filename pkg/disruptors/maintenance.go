@@ -0,0 +1,131 @@
+package disruptors
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/grafana/xk6-disruptor/internal/multierr"
+	"github.com/grafana/xk6-disruptor/pkg/kubernetes/helpers"
+)
+
+// uncordonTimeout is the time allowed for uncordoning the nodes when the maintenance ends
+const uncordonTimeout = 30 * time.Second
+
+// NodeMaintenanceInjector defines the methods for simulating the maintenance of a node
+type NodeMaintenanceInjector interface {
+	// CordonNodes marks the disruptor's target nodes as unschedulable for the specified duration
+	CordonNodes(ctx context.Context, duration time.Duration) error
+	// DrainNodes cordons the disruptor's target nodes and evicts their pods. The nodes are uncordoned
+	// after the specified duration
+	DrainNodes(ctx context.Context, duration time.Duration, options NodeDrainOptions) error
+}
+
+// NodeDrainOptions defines options that controls how nodes are drained
+type NodeDrainOptions struct {
+	// Delete pods instead of evicting them, ignoring any PodDisruptionBudget
+	IgnorePDB bool `js:"ignorePDB"`
+	// timeout when waiting pods to be evicted. A zero value forces default.
+	// A Negative value forces no waiting.
+	Timeout time.Duration `js:"timeout"`
+}
+
+// CordonNodes marks the disruptor's target nodes as unschedulable for the specified duration. The agents are not
+// deployed in the nodes for cordoning them.
+func (d *nodeDisruptor) CordonNodes(ctx context.Context, duration time.Duration) error {
+	return d.maintain(ctx, duration, nil)
+}
+
+// DrainNodes cordons the disruptor's target nodes and evicts their pods. The nodes are uncordoned
+// after the specified duration. The agents are not deployed in the nodes for draining them.
+func (d *nodeDisruptor) DrainNodes(
+	ctx context.Context,
+	duration time.Duration,
+	options NodeDrainOptions,
+) error {
+	timeout := options.Timeout
+	if timeout == 0 {
+		timeout = 60 * time.Second
+	}
+	if timeout < 0 {
+		timeout = 0
+	}
+
+	drainOptions := helpers.DrainOptions{
+		IgnorePDB: options.IgnorePDB,
+		// the agents deployed by NodeDisruptors in the same nodes must not be evicted
+		Exclude: map[string]string{nodeAgentLabel: nodeAgentName},
+		Timeout: timeout,
+	}
+
+	drain := func(node string) error {
+		return d.k8s.NodeHelper().Drain(ctx, node, drainOptions)
+	}
+
+	return d.maintain(ctx, duration, drain)
+}
+
+// maintain cordons the target nodes, applies the given action (if any) to each of them and waits for the duration.
+// The nodes cordoned by the disruptor are uncordoned even if the action fails or the context is cancelled.
+func (d *nodeDisruptor) maintain(
+	ctx context.Context,
+	duration time.Duration,
+	action func(node string) error,
+) error {
+	helper := d.k8s.NodeHelper()
+
+	cordoned := []string{}
+	err := func() error {
+		for _, node := range d.nodes {
+			changed, err := helper.Cordon(ctx, node)
+			if err != nil {
+				return err
+			}
+			// nodes already unschedulable are left as they were
+			if changed {
+				cordoned = append(cordoned, node)
+			}
+		}
+
+		if action != nil {
+			for _, node := range d.nodes {
+				if err := action(node); err != nil {
+					return fmt.Errorf("draining node %q: %w", node, err)
+				}
+			}
+		}
+
+		select {
+		case <-time.After(duration):
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}()
+
+	// use a fresh context because the context may have been cancelled
+	//nolint:contextcheck
+	uncordonErr := d.uncordon(cordoned)
+	if err == nil {
+		err = uncordonErr
+	}
+
+	return err
+}
+
+// uncordon marks the given nodes as schedulable using a fresh context bounded by the uncordonTimeout
+func (d *nodeDisruptor) uncordon(nodes []string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), uncordonTimeout)
+	defer cancel()
+
+	// all nodes are uncordoned even if some of them fail
+	var errs []error
+	helper := d.k8s.NodeHelper()
+	for _, node := range nodes {
+		if err := helper.Uncordon(ctx, node); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return multierr.Join(errs...)
+}
@@ -0,0 +1,103 @@
+package disruptors
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/grafana/xk6-disruptor/pkg/kubernetes"
+	"github.com/grafana/xk6-disruptor/pkg/testutils/kubernetes/builders"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func Test_NodeDisruptorDrain(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		title                 string
+		nodes                 []corev1.Node
+		expectedUnschedulable []string
+	}{
+		{
+			title: "nodes are uncordoned",
+			nodes: []corev1.Node{
+				builders.NewNodeBuilder("node-1").WithLabel("pool", "workers").Build(),
+				builders.NewNodeBuilder("node-2").WithLabel("pool", "workers").Build(),
+			},
+			expectedUnschedulable: []string{},
+		},
+		{
+			title: "nodes already cordoned are left unschedulable",
+			nodes: []corev1.Node{
+				builders.NewNodeBuilder("node-1").WithLabel("pool", "workers").Build(),
+				builders.NewNodeBuilder("node-2").WithLabel("pool", "workers").WithUnschedulable(true).Build(),
+			},
+			expectedUnschedulable: []string{"node-2"},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			app := builders.NewPodBuilder("app").
+				WithNamespace("test-ns").
+				WithNodeName("node-1").
+				Build()
+
+			// agent deployed in the node by another NodeDisruptor
+			agent := buildNodeAgentPod("xk6-agents", "node-1", "abcd1234")
+
+			objs := []runtime.Object{&app, &agent}
+			for n := range tc.nodes {
+				objs = append(objs, &tc.nodes[n])
+			}
+			client := fake.NewSimpleClientset(objs...)
+			k, _ := kubernetes.NewFakeKubernetes(client)
+
+			disruptor, err := NewNodeDisruptor(
+				context.TODO(),
+				k,
+				NodeSelector{Select: NodeAttributes{Labels: map[string]string{"pool": "workers"}}},
+				NodeDisruptorOptions{InjectTimeout: -1, AgentNamespace: "xk6-agents"},
+			)
+			if err != nil {
+				t.Fatalf("failed creating disruptor: %v", err)
+			}
+
+			err = disruptor.DrainNodes(context.TODO(), time.Second, NodeDrainOptions{IgnorePDB: true})
+			if err != nil {
+				t.Fatalf("failed draining nodes: %v", err)
+			}
+
+			pods, _ := client.CoreV1().Pods("test-ns").List(context.TODO(), metav1.ListOptions{})
+			if len(pods.Items) != 0 {
+				t.Errorf("expected pods to be evicted, %d found", len(pods.Items))
+			}
+
+			agents, _ := client.CoreV1().Pods("xk6-agents").List(context.TODO(), metav1.ListOptions{})
+			// the agent of the other disruptor is not evicted and the disruptor does not deploy its own agents
+			if len(agents.Items) != 1 {
+				t.Errorf("expected only the existing agent, %d found", len(agents.Items))
+			}
+
+			nodes, _ := client.CoreV1().Nodes().List(context.TODO(), metav1.ListOptions{})
+			unschedulable := []string{}
+			for _, node := range nodes.Items {
+				if node.Spec.Unschedulable {
+					unschedulable = append(unschedulable, node.Name)
+				}
+			}
+			if diff := cmp.Diff(tc.expectedUnschedulable, unschedulable); diff != "" {
+				t.Errorf("expected unschedulable nodes do not match\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,37 @@
+package disruptors
+
+import (
+	"context"
+	"time"
+)
+
+// NetworkFaultInjector defines the methods for injecting faults in the network traffic of a target
+type NetworkFaultInjector interface {
+	// InjectNetworkFaults injects faults in the network traffic of the disruptor's targets
+	// for the specified duration
+	InjectNetworkFaults(ctx context.Context, fault NetworkFault, duration time.Duration) error
+}
+
+// NetworkFault specifies a fault to be injected in the network traffic
+type NetworkFault struct {
+	// Interface the fault will be applied to. If empty, the interface of the default route is used.
+	Interface string `js:"interface"`
+	// Average delay introduced to packets
+	AverageDelay time.Duration `js:"averageDelay"`
+	// Variation in the delay (with respect of the average delay)
+	DelayVariation time.Duration `js:"delayVariation"`
+	// Correlation (in the range 0.0 to 1.0) of the delay of a packet with the delay of the previous one
+	DelayCorrelation float32 `js:"delayCorrelation"`
+	// Fraction (in the range 0.0 to 1.0) of packets that will be dropped
+	LossRate float32 `js:"lossRate"`
+	// Fraction (in the range 0.0 to 1.0) of packets that will be duplicated
+	DuplicationRate float32 `js:"duplicationRate"`
+	// Fraction (in the range 0.0 to 1.0) of packets that will be corrupted
+	CorruptionRate float32 `js:"corruptionRate"`
+	// Fraction (in the range 0.0 to 1.0) of packets that will be sent ahead of the delayed ones. Requires a delay.
+	ReorderRate float32 `js:"reorderRate"`
+	// Port the fault is restricted to. Applies to packets sent from or to this port. If zero, all ports are affected.
+	Port uint `js:"port"`
+	// IPv4 address or CIDR of the peer the fault is restricted to. If empty, all peers are affected.
+	Peer string `js:"peer"`
+}
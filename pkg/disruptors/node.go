@@ -0,0 +1,449 @@
+package disruptors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/grafana/xk6-disruptor/internal/multierr"
+	"github.com/grafana/xk6-disruptor/pkg/internal/version"
+	"github.com/grafana/xk6-disruptor/pkg/kubernetes"
+	"github.com/grafana/xk6-disruptor/pkg/kubernetes/helpers"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilrand "k8s.io/apimachinery/pkg/util/rand"
+)
+
+// ErrSelectorNoNodes is returned by NewNodeDisruptor when the selector passed to it does not match any node in the
+// cluster.
+var ErrSelectorNoNodes = errors.New("no nodes found matching selector")
+
+// nodeAgentLabel is the label that identifies the agent pods deployed by the NodeDisruptor
+const nodeAgentLabel = "app.kubernetes.io/name"
+
+// nodeAgentName is the value of the nodeAgentLabel in the agent pods deployed by the NodeDisruptor
+const nodeAgentName = "xk6-disruptor-agent"
+
+// nodeAgentOwnerLabel is the label that identifies the NodeDisruptor that deployed an agent pod. Each NodeDisruptor
+// deploys its own agent pods, so disruptors that target the same nodes do not share them.
+const nodeAgentOwnerLabel = "xk6-disruptor.grafana.com/owner"
+
+// NodeDisruptor defines the types of faults that can be injected in a Node
+type NodeDisruptor interface {
+	Disruptor
+	NetworkFaultInjector
+	CPUStressInjector
+	MemoryStressInjector
+	NodeMaintenanceInjector
+	// Cleanup removes the agent pods deployed in the target nodes
+	Cleanup(ctx context.Context) error
+}
+
+// NodeDisruptorOptions defines options that controls the NodeDisruptor's behavior
+type NodeDisruptorOptions struct {
+	// timeout when waiting agent to be running in seconds. A zero value forces default.
+	// A Negative value forces no waiting.
+	InjectTimeout time.Duration `js:"injectTimeout"`
+	// Namespace where the agent pods are deployed. Must be specified, as the agent pods are privileged and
+	// should be deployed in a namespace dedicated to them.
+	AgentNamespace string `js:"agentNamespace"`
+}
+
+// NodeSelector defines the criteria for selecting a node for disruption
+type NodeSelector struct {
+	// Select Nodes that match these NodeAttributes
+	Select NodeAttributes
+	// Exclude Nodes that match these NodeAttributes
+	Exclude NodeAttributes
+}
+
+// NodeAttributes defines the attributes a Node must match for being selected/excluded
+type NodeAttributes struct {
+	Labels      map[string]string
+	Annotations map[string]string
+	// Conditions (e.g. "Ready") that must be True in the node
+	Conditions []string
+}
+
+// String returns a human-readable explanation of the nodes matched by a NodeSelector.
+func (s NodeSelector) String() string {
+	if reflect.DeepEqual(s, NodeSelector{}) {
+		return "all nodes"
+	}
+
+	str := "nodes "
+	str += s.groupAttributes("including", s.Select)
+	str += s.groupAttributes("excluding", s.Exclude)
+
+	return strings.TrimSuffix(str, ", ")
+}
+
+// groupAttributes returns a group of attributes as a string, giving that group a name. The returned string has the
+// form of: `groupName(foo=bar, Ready), `, including the trailing space and comma.
+// An empty group of attributes produces an empty string.
+func (NodeSelector) groupAttributes(groupName string, attributes NodeAttributes) string {
+	items := []string{}
+	for k, v := range attributes.Labels {
+		items = append(items, fmt.Sprintf("%s=%s", k, v))
+	}
+	for k, v := range attributes.Annotations {
+		items = append(items, fmt.Sprintf("%s=%s", k, v))
+	}
+	items = append(items, attributes.Conditions...)
+
+	if len(items) == 0 {
+		return ""
+	}
+
+	return groupName + "(" + strings.Join(items, ", ") + "), "
+}
+
+// nodeDisruptor is an instance of a NodeDisruptor initialized with a list of target nodes
+type nodeDisruptor struct {
+	k8s       kubernetes.Kubernetes
+	namespace string
+	nodes     []string
+	// identifies the agent pods deployed by the disruptor
+	owner   string
+	timeout time.Duration
+	// protects the agents, which are deployed when a fault requires them
+	mu         sync.Mutex
+	agents     []corev1.Pod
+	controller AgentController
+	// set when the context of the disruptor is done. No more agents are deployed.
+	done bool
+}
+
+// agentCleanupTimeout is the time allowed for removing the agent pods when the context of the disruptor is done
+const agentCleanupTimeout = 30 * time.Second
+
+// NewNodeDisruptor creates a new instance of a NodeDisruptor that acts on the nodes
+// that match the given NodeSelector. An agent pod is deployed in each of the target nodes when a fault that
+// requires it is injected for the first time, so faults such as draining the nodes do not deploy them.
+// The agent pods are removed when the context is done, if they were not removed before by calling Cleanup.
+func NewNodeDisruptor(
+	ctx context.Context,
+	k8s kubernetes.Kubernetes,
+	selector NodeSelector,
+	options NodeDisruptorOptions,
+) (NodeDisruptor, error) {
+	if options.AgentNamespace == "" {
+		return nil, fmt.Errorf("the namespace of the agent pods must be specified")
+	}
+
+	nodes, err := selectNodes(ctx, k8s, selector)
+	if err != nil {
+		return nil, err
+	}
+
+	names := []string{}
+	for _, node := range nodes {
+		names = append(names, node.Name)
+	}
+
+	d := &nodeDisruptor{
+		k8s:       k8s,
+		namespace: options.AgentNamespace,
+		nodes:     names,
+		owner:     utilrand.String(8),
+		timeout:   options.InjectTimeout,
+	}
+
+	// the agents are removed when the test ends even if Cleanup is not called
+	go func() {
+		<-ctx.Done()
+
+		d.mu.Lock()
+		defer d.mu.Unlock()
+
+		d.done = true
+		//nolint:contextcheck
+		_ = removeNodeAgents(k8s, d.namespace, d.agents)
+	}()
+
+	return d, nil
+}
+
+// agentController returns the controller of the agent pods in the target nodes, deploying them if they are not
+// already deployed
+func (d *nodeDisruptor) agentController(ctx context.Context) (AgentController, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.done {
+		return nil, fmt.Errorf("the disruptor is no longer active")
+	}
+
+	if d.controller != nil {
+		return d.controller, nil
+	}
+
+	helper := d.k8s.PodHelper(d.namespace)
+
+	var err error
+	agents := []corev1.Pod{}
+	for _, node := range d.nodes {
+		agent, deployErr := deployNodeAgent(ctx, d.k8s, d.namespace, node, d.owner)
+		if deployErr != nil {
+			err = deployErr
+			break
+		}
+		agents = append(agents, agent)
+	}
+
+	if err == nil {
+		err = waitNodeAgents(ctx, helper, agents, agentTimeout(d.timeout))
+	}
+
+	// the agents deployed are removed if they cannot be used
+	if err != nil {
+		//nolint:contextcheck
+		_ = removeNodeAgents(d.k8s, d.namespace, agents)
+		return nil, err
+	}
+
+	d.agents = agents
+	d.controller = NewAgentController(ctx, helper, agents, d.timeout)
+
+	return d.controller, nil
+}
+
+// selectNodes returns the nodes that match the given NodeSelector. Returns an error if no node matches it.
+func selectNodes(ctx context.Context, k8s kubernetes.Kubernetes, selector NodeSelector) ([]corev1.Node, error) {
+	if reflect.DeepEqual(selector, NodeSelector{}) {
+		return nil, fmt.Errorf("select and exclude attributes in node selector cannot both be empty")
+	}
+
+	filter := helpers.NodeFilter{
+		Select:             selector.Select.Labels,
+		Exclude:            selector.Exclude.Labels,
+		SelectAnnotations:  selector.Select.Annotations,
+		ExcludeAnnotations: selector.Exclude.Annotations,
+		SelectConditions:   selector.Select.Conditions,
+		ExcludeConditions:  selector.Exclude.Conditions,
+	}
+
+	nodes, err := k8s.NodeHelper().List(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(nodes) == 0 {
+		return nil, fmt.Errorf("finding nodes matching '%s': %w", selector, ErrSelectorNoNodes)
+	}
+
+	return nodes, nil
+}
+
+// buildNodeAgentPod returns the definition of the privileged agent pod deployed in the given node by the disruptor
+// identified by owner
+func buildNodeAgentPod(namespace string, node string, owner string) corev1.Pod {
+	privileged := true
+
+	return corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "xk6-agent-" + node + "-" + owner,
+			Namespace: namespace,
+			Labels: map[string]string{
+				nodeAgentLabel:      nodeAgentName,
+				nodeAgentOwnerLabel: owner,
+			},
+		},
+		Spec: corev1.PodSpec{
+			NodeName:      node,
+			HostNetwork:   true,
+			RestartPolicy: corev1.RestartPolicyNever,
+			// tolerate any taint for ensuring the agent can run in the node
+			Tolerations: []corev1.Toleration{
+				{Operator: corev1.TolerationOpExists},
+			},
+			Containers: []corev1.Container{
+				{
+					Name:            "xk6-agent",
+					Image:           version.AgentImage(),
+					ImagePullPolicy: corev1.PullIfNotPresent,
+					// keep the container running for executing the agent commands
+					Command: []string{"tail", "-f", "/dev/null"},
+					SecurityContext: &corev1.SecurityContext{
+						Privileged: &privileged,
+					},
+				},
+			},
+		},
+	}
+}
+
+// deployNodeAgent creates the agent pod of the disruptor identified by owner in the given node
+func deployNodeAgent(
+	ctx context.Context,
+	k8s kubernetes.Kubernetes,
+	namespace string,
+	node string,
+	owner string,
+) (corev1.Pod, error) {
+	pod := buildNodeAgentPod(namespace, node, owner)
+
+	created, err := k8s.Client().CoreV1().Pods(namespace).Create(ctx, &pod, metav1.CreateOptions{})
+	if err != nil {
+		return corev1.Pod{}, fmt.Errorf("deploying agent in node %q: %w", node, err)
+	}
+
+	return *created, nil
+}
+
+// waitNodeAgents waits for the agent pods to be running. A zero timeout forces no waiting.
+func waitNodeAgents(ctx context.Context, helper helpers.PodHelper, agents []corev1.Pod, timeout time.Duration) error {
+	if timeout == 0 {
+		return nil
+	}
+
+	var wg sync.WaitGroup
+	// ensure errors channel has enough space to avoid blocking gorutines
+	errors := make(chan error, len(agents))
+	for _, agent := range agents {
+		wg.Add(1)
+		// wait for each agent asynchronously
+		go func(podName string) {
+			defer wg.Done()
+
+			running, err := helper.WaitPodRunning(ctx, podName, timeout)
+			if err != nil {
+				errors <- fmt.Errorf("waiting for agent pod %q: %w", podName, err)
+				return
+			}
+			if !running {
+				errors <- fmt.Errorf("agent pod %q is not running after %s", podName, timeout)
+			}
+		}(agent.Name)
+	}
+
+	wg.Wait()
+
+	select {
+	case err := <-errors:
+		return err
+	default:
+		return nil
+	}
+}
+
+// Targets returns the names of the target nodes
+func (d *nodeDisruptor) Targets(_ context.Context) ([]string, error) {
+	return d.nodes, nil
+}
+
+// InjectNetworkFaults injects faults in the network traffic of the disruptor's target nodes
+func (d *nodeDisruptor) InjectNetworkFaults(ctx context.Context, fault NetworkFault, duration time.Duration) error {
+	visitor := NodeNetworkFaultVisitor{
+		fault:    fault,
+		duration: duration,
+	}
+
+	controller, err := d.agentController(ctx)
+	if err != nil {
+		return err
+	}
+
+	return controller.Visit(ctx, visitor)
+}
+
+// StressCPU generates CPU load in the disruptor's target nodes
+func (d *nodeDisruptor) StressCPU(ctx context.Context, stress CPUStress, duration time.Duration) error {
+	stress, err := validateCPUStress(stress)
+	if err != nil {
+		return err
+	}
+
+	visitor := NodeCPUStressVisitor{
+		stress:   stress,
+		duration: duration,
+	}
+
+	controller, err := d.agentController(ctx)
+	if err != nil {
+		return err
+	}
+
+	return controller.Visit(ctx, visitor)
+}
+
+// StressMemory allocates memory in the disruptor's target nodes
+func (d *nodeDisruptor) StressMemory(ctx context.Context, stress MemoryStress, duration time.Duration) error {
+	if err := validateMemoryStress(stress); err != nil {
+		return err
+	}
+
+	// the agent pods do not have a memory limit, so allocating memory without bounds would affect the whole node
+	if stress.Percentage != 0 || stress.TriggerOOM {
+		return fmt.Errorf("node disruptors only support memory stress with an amount and without OOM")
+	}
+
+	bytes, err := memoryAmount(stress.Amount)
+	if err != nil {
+		return err
+	}
+
+	visitor := NodeMemoryStressVisitor{
+		bytes:    bytes,
+		stress:   stress,
+		duration: duration,
+	}
+
+	controller, err := d.agentController(ctx)
+	if err != nil {
+		return err
+	}
+
+	return controller.Visit(ctx, visitor)
+}
+
+// Cleanup removes the agent pods deployed in the target nodes. The agents are deployed again if another fault
+// requires them.
+func (d *nodeDisruptor) Cleanup(ctx context.Context) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if err := deleteNodeAgents(ctx, d.k8s, d.namespace, d.agents); err != nil {
+		return err
+	}
+
+	d.agents = nil
+	d.controller = nil
+
+	return nil
+}
+
+// deleteNodeAgents deletes the given agent pods. Agent pods that no longer exist are ignored.
+func deleteNodeAgents(ctx context.Context, k8s kubernetes.Kubernetes, namespace string, agents []corev1.Pod) error {
+	var errs []error
+
+	// the agent pods do not handle termination signals, so there's no point in waiting
+	gracePeriod := int64(0)
+	for _, agent := range agents {
+		err := k8s.Client().CoreV1().Pods(namespace).Delete(
+			ctx,
+			agent.Name,
+			metav1.DeleteOptions{GracePeriodSeconds: &gracePeriod},
+		)
+		if err != nil && !apierrors.IsNotFound(err) {
+			errs = append(errs, fmt.Errorf("deleting agent pod %q: %w", agent.Name, err))
+		}
+	}
+
+	return multierr.Join(errs...)
+}
+
+// removeNodeAgents deletes the given agent pods using a fresh context, for removing them when the context of the
+// disruptor may have been cancelled
+func removeNodeAgents(k8s kubernetes.Kubernetes, namespace string, agents []corev1.Pod) error {
+	ctx, cancel := context.WithTimeout(context.Background(), agentCleanupTimeout)
+	defer cancel()
+
+	return deleteNodeAgents(ctx, k8s, namespace, agents)
+}
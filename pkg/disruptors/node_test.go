@@ -0,0 +1,352 @@
+package disruptors
+
+import (
+	"context"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/grafana/xk6-disruptor/pkg/kubernetes"
+	"github.com/grafana/xk6-disruptor/pkg/testutils/kubernetes/builders"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func Test_NewNodeDisruptor(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		title       string
+		nodes       []corev1.Node
+		selector    NodeSelector
+		options     NodeDisruptorOptions
+		expectError bool
+		expected    []string
+		// nodes where agents are deployed when a fault requires them
+		expectedAgents []string
+	}{
+		{
+			title: "select nodes by label",
+			nodes: []corev1.Node{
+				builders.NewNodeBuilder("node-1").WithLabel("pool", "workers").Build(),
+				builders.NewNodeBuilder("node-2").WithLabel("pool", "workers").Build(),
+				builders.NewNodeBuilder("node-3").WithLabel("pool", "system").Build(),
+			},
+			selector: NodeSelector{
+				Select: NodeAttributes{Labels: map[string]string{"pool": "workers"}},
+			},
+			options:        NodeDisruptorOptions{InjectTimeout: -1, AgentNamespace: "xk6-agents"},
+			expected:       []string{"node-1", "node-2"},
+			expectedAgents: []string{"node-1", "node-2"},
+		},
+		{
+			title: "exclude nodes by condition",
+			nodes: []corev1.Node{
+				builders.NewNodeBuilder("node-1").WithCondition(corev1.NodeReady, corev1.ConditionTrue).Build(),
+				builders.NewNodeBuilder("node-2").WithCondition(corev1.NodeReady, corev1.ConditionFalse).Build(),
+			},
+			selector: NodeSelector{
+				Select: NodeAttributes{Conditions: []string{"Ready"}},
+			},
+			options:        NodeDisruptorOptions{InjectTimeout: -1, AgentNamespace: "xk6-agents"},
+			expected:       []string{"node-1"},
+			expectedAgents: []string{"node-1"},
+		},
+		{
+			title: "missing agent namespace",
+			nodes: []corev1.Node{
+				builders.NewNodeBuilder("node-1").WithLabel("pool", "workers").Build(),
+			},
+			selector: NodeSelector{
+				Select: NodeAttributes{Labels: map[string]string{"pool": "workers"}},
+			},
+			options:     NodeDisruptorOptions{InjectTimeout: -1},
+			expectError: true,
+		},
+		{
+			title: "empty selector",
+			nodes: []corev1.Node{
+				builders.NewNodeBuilder("node-1").Build(),
+			},
+			selector:    NodeSelector{},
+			options:     NodeDisruptorOptions{InjectTimeout: -1, AgentNamespace: "xk6-agents"},
+			expectError: true,
+		},
+		{
+			title: "no matching nodes",
+			nodes: []corev1.Node{
+				builders.NewNodeBuilder("node-1").WithLabel("pool", "system").Build(),
+			},
+			selector: NodeSelector{
+				Select: NodeAttributes{Labels: map[string]string{"pool": "workers"}},
+			},
+			options:     NodeDisruptorOptions{InjectTimeout: -1, AgentNamespace: "xk6-agents"},
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			objs := []runtime.Object{}
+			for n := range tc.nodes {
+				objs = append(objs, &tc.nodes[n])
+			}
+			client := fake.NewSimpleClientset(objs...)
+			k, _ := kubernetes.NewFakeKubernetes(client)
+
+			disruptor, err := NewNodeDisruptor(context.TODO(), k, tc.selector, tc.options)
+			if tc.expectError && err == nil {
+				t.Errorf("should had failed")
+				return
+			}
+
+			if !tc.expectError && err != nil {
+				t.Errorf("unexpected error : %v", err)
+				return
+			}
+
+			if tc.expectError {
+				return
+			}
+
+			targets, _ := disruptor.Targets(context.TODO())
+			sort.Strings(targets)
+			if diff := cmp.Diff(tc.expected, targets); diff != "" {
+				t.Errorf("expected targets dot not match returned\n%s", diff)
+				return
+			}
+
+			pods, err := client.CoreV1().Pods("xk6-agents").List(context.TODO(), metav1.ListOptions{})
+			if err != nil {
+				t.Errorf("failed listing agent pods: %v", err)
+				return
+			}
+
+			// the agents are not deployed until a fault requires them
+			if len(pods.Items) != 0 {
+				t.Errorf("expected no agent pods before injecting faults, %d found", len(pods.Items))
+				return
+			}
+
+			_, err = disruptor.(*nodeDisruptor).agentController(context.TODO()) //nolint:forcetypeassert
+			if err != nil {
+				t.Errorf("failed deploying agents: %v", err)
+				return
+			}
+
+			pods, err = client.CoreV1().Pods("xk6-agents").List(context.TODO(), metav1.ListOptions{})
+			if err != nil {
+				t.Errorf("failed listing agent pods: %v", err)
+				return
+			}
+
+			agents := []string{}
+			owners := map[string]bool{}
+			for _, pod := range pods.Items {
+				agents = append(agents, pod.Spec.NodeName)
+				owners[pod.Labels[nodeAgentOwnerLabel]] = true
+			}
+			sort.Strings(agents)
+
+			// all the agents are labeled with the disruptor that deployed them
+			if len(owners) != 1 || owners[""] {
+				t.Errorf("expected agents to be labeled with a single owner, got %v", owners)
+				return
+			}
+			if diff := cmp.Diff(tc.expectedAgents, agents); diff != "" {
+				t.Errorf("expected agents dot not match deployed\n%s", diff)
+				return
+			}
+
+			err = disruptor.Cleanup(context.TODO())
+			if err != nil {
+				t.Errorf("failed cleaning up agents: %v", err)
+				return
+			}
+
+			pods, err = client.CoreV1().Pods("xk6-agents").List(context.TODO(), metav1.ListOptions{})
+			if err != nil {
+				t.Errorf("failed listing agent pods: %v", err)
+				return
+			}
+
+			if len(pods.Items) != 0 {
+				t.Errorf("expected agent pods to be deleted, %d found", len(pods.Items))
+			}
+		})
+	}
+}
+
+func Test_NodeDisruptorsInSameNodes(t *testing.T) {
+	t.Parallel()
+
+	node := builders.NewNodeBuilder("node-1").WithLabel("pool", "workers").Build()
+	client := fake.NewSimpleClientset(&node)
+	k, _ := kubernetes.NewFakeKubernetes(client)
+
+	selector := NodeSelector{Select: NodeAttributes{Labels: map[string]string{"pool": "workers"}}}
+	options := NodeDisruptorOptions{InjectTimeout: -1, AgentNamespace: "xk6-agents"}
+
+	first, err := NewNodeDisruptor(context.TODO(), k, selector, options)
+	if err != nil {
+		t.Fatalf("failed creating disruptor: %v", err)
+	}
+
+	second, err := NewNodeDisruptor(context.TODO(), k, selector, options)
+	if err != nil {
+		t.Fatalf("failed creating disruptor: %v", err)
+	}
+
+	for _, disruptor := range []NodeDisruptor{first, second} {
+		_, err = disruptor.(*nodeDisruptor).agentController(context.TODO()) //nolint:forcetypeassert
+		if err != nil {
+			t.Fatalf("failed deploying agents: %v", err)
+		}
+	}
+
+	// each disruptor deploys its own agent in the node
+	pods, err := client.CoreV1().Pods("xk6-agents").List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		t.Fatalf("failed listing agent pods: %v", err)
+	}
+
+	if len(pods.Items) != 2 {
+		t.Fatalf("expected %d agent pods got %d", 2, len(pods.Items))
+	}
+
+	// cleaning up a disruptor does not remove the agent of the other
+	if err = first.Cleanup(context.TODO()); err != nil {
+		t.Fatalf("failed cleaning up agents: %v", err)
+	}
+
+	pods, err = client.CoreV1().Pods("xk6-agents").List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		t.Fatalf("failed listing agent pods: %v", err)
+	}
+
+	secondAgents := second.(*nodeDisruptor).agents //nolint:forcetypeassert
+	if len(pods.Items) != 1 || pods.Items[0].Name != secondAgents[0].Name {
+		t.Errorf("expected only the agent of the second disruptor to remain")
+	}
+}
+
+func Test_NodeDisruptorAgentsTeardown(t *testing.T) {
+	t.Parallel()
+
+	node := builders.NewNodeBuilder("node-1").WithLabel("pool", "workers").Build()
+	client := fake.NewSimpleClientset(&node)
+	k, _ := kubernetes.NewFakeKubernetes(client)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	disruptor, err := NewNodeDisruptor(
+		ctx,
+		k,
+		NodeSelector{Select: NodeAttributes{Labels: map[string]string{"pool": "workers"}}},
+		NodeDisruptorOptions{InjectTimeout: -1, AgentNamespace: "xk6-agents"},
+	)
+	if err != nil {
+		t.Fatalf("failed creating disruptor: %v", err)
+	}
+
+	_, err = disruptor.(*nodeDisruptor).agentController(context.TODO()) //nolint:forcetypeassert
+	if err != nil {
+		t.Fatalf("failed deploying agents: %v", err)
+	}
+
+	// the agents are removed when the context is done, without calling Cleanup
+	cancel()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		pods, listErr := client.CoreV1().Pods("xk6-agents").List(context.TODO(), metav1.ListOptions{})
+		if listErr != nil {
+			t.Fatalf("failed listing agent pods: %v", listErr)
+		}
+
+		if len(pods.Items) == 0 {
+			return
+		}
+
+		if time.Now().After(deadline) {
+			t.Fatalf("expected agent pods to be deleted, %d found", len(pods.Items))
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func Test_NodeDisruptorStressMemory(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		title       string
+		stress      MemoryStress
+		expectError bool
+	}{
+		{
+			title:  "valid amount",
+			stress: MemoryStress{Amount: "512Mi"},
+		},
+		{
+			title:       "invalid amount",
+			stress:      MemoryStress{Amount: "lots"},
+			expectError: true,
+		},
+		{
+			title:       "zero amount",
+			stress:      MemoryStress{Amount: "0"},
+			expectError: true,
+		},
+		{
+			title:       "percentage",
+			stress:      MemoryStress{Percentage: 50},
+			expectError: true,
+		},
+		{
+			title:       "oom",
+			stress:      MemoryStress{Amount: "512Mi", TriggerOOM: true},
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			node := builders.NewNodeBuilder("node-1").WithLabel("pool", "workers").Build()
+			client := fake.NewSimpleClientset(&node)
+			k, _ := kubernetes.NewFakeKubernetes(client)
+
+			disruptor, err := NewNodeDisruptor(
+				context.TODO(),
+				k,
+				NodeSelector{Select: NodeAttributes{Labels: map[string]string{"pool": "workers"}}},
+				NodeDisruptorOptions{InjectTimeout: -1, AgentNamespace: "xk6-agents"},
+			)
+			if err != nil {
+				t.Fatalf("failed creating disruptor: %v", err)
+			}
+
+			err = disruptor.StressMemory(context.TODO(), tc.stress, time.Second)
+			if tc.expectError && err == nil {
+				t.Errorf("should had failed")
+			}
+
+			if !tc.expectError && err != nil {
+				t.Errorf("unexpected error : %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,135 @@
+package disruptors
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/grafana/xk6-disruptor/pkg/kubernetes"
+)
+
+// partitionRefreshInterval is the interval for checking changes in the IP addresses of the peers of a partition
+const partitionRefreshInterval = 5 * time.Second
+
+// PartitionInjector defines the methods for isolating the targets from other pods
+type PartitionInjector interface {
+	// InjectPartitionFault blocks the traffic between the disruptor's targets and the peers for the
+	// specified duration
+	InjectPartitionFault(ctx context.Context, fault PartitionFault, duration time.Duration) error
+}
+
+// PartitionFault specifies a network partition between the targets and a set of peer pods
+type PartitionFault struct {
+	// Selects the pods the targets will be isolated from
+	Peers PodSelector `js:"peers"`
+	// Direction of the connections blocked: "both" (default), "outgoing" (from the targets to the peers)
+	// or "incoming" (from the peers to the targets)
+	Direction string `js:"direction"`
+}
+
+// validatePartitionFault checks the partition fault is valid
+func validatePartitionFault(fault PartitionFault) error {
+	switch fault.Direction {
+	case "", "both", "outgoing", "incoming":
+	default:
+		return fmt.Errorf("invalid partition direction %q", fault.Direction)
+	}
+
+	if fault.Peers.isEmpty() {
+		return fmt.Errorf("namespace, select, exclude and owner attributes in peers selector cannot all be empty")
+	}
+
+	return nil
+}
+
+// resolvePeers returns the sorted IP addresses of the pods that match the selector. Pods without an IP address,
+// for example because they are being scheduled, are ignored.
+func resolvePeers(ctx context.Context, k8s kubernetes.Kubernetes, selector PodSelector) ([]string, error) {
+	pods, err := listSelectorPods(ctx, k8s, selector)
+	if err != nil {
+		return nil, err
+	}
+
+	peers := []string{}
+	for _, pod := range pods {
+		if pod.Status.PodIP != "" {
+			peers = append(peers, pod.Status.PodIP)
+		}
+	}
+	sort.Strings(peers)
+
+	return peers, nil
+}
+
+// InjectPartitionFault blocks the traffic between the disruptor's targets and the peers for the given duration.
+// Changes in the IP addresses of the peers, for example because they are rescheduled, are propagated to the targets
+// while the fault is injected.
+func (d *podDisruptor) InjectPartitionFault(
+	ctx context.Context,
+	fault PartitionFault,
+	duration time.Duration,
+) error {
+	if err := validatePartitionFault(fault); err != nil {
+		return err
+	}
+
+	peers, err := resolvePeers(ctx, d.k8s, fault.Peers)
+	if err != nil {
+		return err
+	}
+
+	if len(peers) == 0 {
+		return fmt.Errorf("finding peers matching '%s': %w", fault.Peers, ErrSelectorNoPods)
+	}
+
+	refreshCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	go d.refreshPeers(refreshCtx, fault.Peers, peers, partitionRefreshInterval)
+
+	visitor := timedVisitor{
+		duration: duration,
+		build: func(duration time.Duration) PodVisitor {
+			return PodPartitionFaultVisitor{
+				fault:    fault,
+				peers:    peers,
+				duration: duration,
+			}
+		},
+	}
+
+	return d.controller.Visit(ctx, visitor)
+}
+
+// refreshPeers periodically resolves the peers of a partition and updates them in the targets if they change,
+// until the context is cancelled
+func (d *podDisruptor) refreshPeers(
+	ctx context.Context,
+	selector PodSelector,
+	peers []string,
+	interval time.Duration,
+) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			current, err := resolvePeers(ctx, d.k8s, selector)
+			// keep the known peers if they cannot be resolved, for example while they are rescheduled
+			if err != nil || len(current) == 0 || reflect.DeepEqual(current, peers) {
+				continue
+			}
+
+			// if the update fails, it will be retried in the next refresh
+			err = d.controller.Visit(ctx, PodPartitionUpdateVisitor{peers: current})
+			if err == nil {
+				peers = current
+			}
+		}
+	}
+}
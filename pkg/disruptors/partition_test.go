@@ -0,0 +1,190 @@
+package disruptors
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/grafana/xk6-disruptor/pkg/kubernetes"
+	"github.com/grafana/xk6-disruptor/pkg/testutils/command"
+	"github.com/grafana/xk6-disruptor/pkg/testutils/kubernetes/builders"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func Test_PodPartitionFaultVisitor(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		title           string
+		target          corev1.Pod
+		visitor         PodVisitor
+		expectedCmd     string
+		expectedCleanup string
+		expectError     bool
+	}{
+		{
+			title:  "two-way partition",
+			target: buildPodWithPort("my-app-pod", "http", 80),
+			visitor: PodPartitionFaultVisitor{
+				peers:    []string{"192.0.2.10", "192.0.2.11"},
+				duration: 60 * time.Second,
+			},
+			expectedCmd:     "xk6-disruptor-agent partition -d 60s --peers 192.0.2.10,192.0.2.11",
+			expectedCleanup: "xk6-disruptor-agent cleanup --reset-partition",
+			expectError:     false,
+		},
+		{
+			title:  "one-way partition excluding the target",
+			target: buildPodWithPort("my-app-pod", "http", 80),
+			visitor: PodPartitionFaultVisitor{
+				fault:    PartitionFault{Direction: "outgoing"},
+				peers:    []string{"192.0.2.6", "192.0.2.10"},
+				duration: 60 * time.Second,
+			},
+			expectedCmd:     "xk6-disruptor-agent partition -d 60s --peers 192.0.2.10 --direction outgoing",
+			expectedCleanup: "xk6-disruptor-agent cleanup --reset-partition",
+			expectError:     false,
+		},
+		{
+			title:  "target is the only peer",
+			target: buildPodWithPort("my-app-pod", "http", 80),
+			visitor: PodPartitionFaultVisitor{
+				peers:    []string{"192.0.2.6"},
+				duration: 60 * time.Second,
+			},
+			expectError: true,
+		},
+		{
+			title:  "update peers",
+			target: buildPodWithPort("my-app-pod", "http", 80),
+			visitor: PodPartitionUpdateVisitor{
+				peers: []string{"192.0.2.12"},
+			},
+			expectedCmd:     "xk6-disruptor-agent partition update --peers 192.0.2.12",
+			expectedCleanup: "",
+			expectError:     false,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			cmds, err := tc.visitor.Visit(tc.target)
+
+			if tc.expectError && err == nil {
+				t.Errorf("should had failed")
+				return
+			}
+
+			if !tc.expectError && err != nil {
+				t.Errorf("unexpected error : %v", err)
+				return
+			}
+
+			exec := strings.Join(cmds.Exec, " ")
+			if !command.AssertCmdEquals(exec, tc.expectedCmd) {
+				t.Errorf("expected command: %s got: %s", tc.expectedCmd, exec)
+			}
+
+			cleanup := strings.Join(cmds.Cleanup, " ")
+			if !command.AssertCmdEquals(cleanup, tc.expectedCleanup) {
+				t.Errorf("expected cleanup command: %s got: %s", tc.expectedCleanup, cleanup)
+			}
+		})
+	}
+}
+
+// recordingController is an AgentController that records the visitors it receives
+type recordingController struct {
+	AgentController
+	mutex    sync.Mutex
+	visitors []PodVisitor
+}
+
+func (c *recordingController) Visit(_ context.Context, visitor PodVisitor) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.visitors = append(c.visitors, visitor)
+	return nil
+}
+
+func Test_PartitionRefreshPeers(t *testing.T) {
+	t.Parallel()
+
+	// the peer was rescheduled and now has a different IP address
+	peer := builders.NewPodBuilder("peer-1").
+		WithNamespace("test-ns").
+		WithLabel("app", "peer").
+		WithIP("192.0.2.20").
+		Build()
+
+	client := fake.NewSimpleClientset(&peer)
+	k, _ := kubernetes.NewFakeKubernetes(client)
+
+	controller := &recordingController{}
+	d := &podDisruptor{
+		k8s:        k,
+		controller: controller,
+	}
+
+	selector := PodSelector{
+		Namespace: "test-ns",
+		Select:    PodAttributes{Labels: map[string]string{"app": "peer"}},
+	}
+
+	ctx, cancel := context.WithTimeout(context.TODO(), 200*time.Millisecond)
+	defer cancel()
+
+	d.refreshPeers(ctx, selector, []string{"192.0.2.10"}, 20*time.Millisecond)
+
+	// the update must be sent only once, as the peers do not change after the first update
+	expected := []PodVisitor{
+		PodPartitionUpdateVisitor{peers: []string{"192.0.2.20"}},
+	}
+
+	if diff := cmp.Diff(expected, controller.visitors, cmp.AllowUnexported(PodPartitionUpdateVisitor{})); diff != "" {
+		t.Errorf("expected updates do not match sent:\n%s", diff)
+	}
+}
+
+func Test_InvalidPartitionFault(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		title string
+		fault PartitionFault
+	}{
+		{
+			title: "empty peers selector",
+			fault: PartitionFault{},
+		},
+		{
+			title: "invalid direction",
+			fault: PartitionFault{
+				Peers:     PodSelector{Namespace: "test-ns"},
+				Direction: "sideways",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			d := &podDisruptor{controller: &recordingController{}}
+			err := d.InjectPartitionFault(context.TODO(), tc.fault, time.Second)
+			if err == nil {
+				t.Errorf("should had failed")
+			}
+		})
+	}
+}
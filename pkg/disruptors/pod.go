@@ -6,13 +6,16 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/grafana/xk6-disruptor/pkg/kubernetes"
 	"github.com/grafana/xk6-disruptor/pkg/kubernetes/helpers"
 
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 )
 
 // DefaultTargetPort defines default target port if not specified in Fault
@@ -26,6 +29,14 @@ var ErrSelectorNoPods = errors.New("no pods found matching selector")
 type PodDisruptor interface {
 	Disruptor
 	ProtocolFaultInjector
+	ProcessFaultInjector
+	NetworkFaultInjector
+	PartitionInjector
+	DNSFaultInjector
+	CPUStressInjector
+	MemoryStressInjector
+	ResourceExhaustionInjector
+	ChaosInjector
 }
 
 // PodDisruptorOptions defines options that controls the PodDisruptor's behavior
@@ -33,25 +44,99 @@ type PodDisruptorOptions struct {
 	// timeout when waiting agent to be injected in seconds. A zero value forces default.
 	// A Negative value forces no waiting.
 	InjectTimeout time.Duration `js:"injectTimeout"`
+	// Limits the targets to a sample of the pods that match the selector
+	BlastRadius BlastRadius `js:"blastRadius"`
+	// Watch the pods in the namespaces of the selector and refresh the targets when they change. New pods receive
+	// the faults in progress, and pods that no longer exist are dropped. Namespaces that start matching the
+	// namespace labels of the selector are watched as well.
+	WatchTargets bool `js:"watchTargets"`
+	// Plan the faults without injecting them. The agent is not injected in the targets, and the methods that
+	// inject protocol faults return the commands that would be executed in each target. Other faults fail with
+	// ErrDryRunNotSupported.
+	DryRun bool `js:"dryRun"`
 }
 
 // podDisruptor is an instance of a PodDisruptor initialized with a list of target pods
 type podDisruptor struct {
+	k8s        kubernetes.Kubernetes
 	controller AgentController
 }
 
 // PodSelector defines the criteria for selecting a pod for disruption
 type PodSelector struct {
 	Namespace string
+	// Additional namespaces where Pods are selected
+	Namespaces []string
+	// Select Pods in the namespaces that have these labels. The namespaces are resolved when the targets are
+	// selected and, if the targets are watched, each time they are refreshed.
+	NamespaceLabels map[string]string
 	// Select Pods that match these PodAttributes
 	Select PodAttributes
 	// Select Pods that match these PodAttributes
 	Exclude PodAttributes
+	// Select Pods controlled by this workload
+	Owner PodOwner
+}
+
+// PodOwner identifies the workload (e.g. a Deployment or a StatefulSet) that controls a set of Pods.
+// Pods of Deployments and CronJobs are resolved through the ReplicaSets and Jobs that own them.
+type PodOwner struct {
+	// Kind of the workload (e.g. "Deployment", "StatefulSet", "DaemonSet")
+	Kind string `js:"kind"`
+	// Name of the workload
+	Name string `js:"name"`
 }
 
 // PodAttributes defines the attributes a Pod must match for being selected/excluded
 type PodAttributes struct {
 	Labels map[string]string
+	// Set-based label expressions. When excluding, Pods matching any of these expressions are excluded.
+	Expressions []LabelExpression
+	Annotations map[string]string
+	// Phases (e.g. "Running") the Pod must be in. When excluding, Pods in any of these phases are excluded.
+	Phases []string
+	// Conditions (e.g. "Ready") that must be True in the Pod
+	Conditions []string
+}
+
+// LabelExpression defines a set-based requirement on the value of a label
+type LabelExpression struct {
+	// Key of the label
+	Key string `js:"key"`
+	// Operator applied to the label: "In", "NotIn", "Exists" or "DoesNotExist"
+	Operator string `js:"operator"`
+	// Values of the label for the "In" and "NotIn" operators
+	Values []string `js:"values"`
+}
+
+// String returns the expression using the syntax of Kubernetes label selectors, e.g. `tier in (api, worker)`
+func (e LabelExpression) String() string {
+	switch metav1.LabelSelectorOperator(e.Operator) {
+	case metav1.LabelSelectorOpIn:
+		return fmt.Sprintf("%s in (%s)", e.Key, strings.Join(e.Values, ", "))
+	case metav1.LabelSelectorOpNotIn:
+		return fmt.Sprintf("%s notin (%s)", e.Key, strings.Join(e.Values, ", "))
+	case metav1.LabelSelectorOpExists:
+		return e.Key
+	case metav1.LabelSelectorOpDoesNotExist:
+		return "!" + e.Key
+	default:
+		return fmt.Sprintf("%s %s (%s)", e.Key, e.Operator, strings.Join(e.Values, ", "))
+	}
+}
+
+// labelSelectorRequirements returns the expressions as requirements of a metav1.LabelSelector
+func labelSelectorRequirements(expressions []LabelExpression) []metav1.LabelSelectorRequirement {
+	requirements := []metav1.LabelSelectorRequirement{}
+	for _, e := range expressions {
+		requirements = append(requirements, metav1.LabelSelectorRequirement{
+			Key:      e.Key,
+			Operator: metav1.LabelSelectorOperator(e.Operator),
+			Values:   e.Values,
+		})
+	}
+
+	return requirements
 }
 
 // NamespaceOrDefault returns the configured namespace for this selector, and the name of the default namespace if it
@@ -64,40 +149,164 @@ func (p PodSelector) NamespaceOrDefault() string {
 	return metav1.NamespaceDefault
 }
 
+// hasNamespaces returns true if any namespace is configured for the selector, either explicitly or by labels
+func (p PodSelector) hasNamespaces() bool {
+	return p.Namespace != "" || len(p.Namespaces) > 0 || len(p.NamespaceLabels) > 0
+}
+
+// isEmpty returns true if the selector does not restrict the selected pods in any way
+func (p PodSelector) isEmpty() bool {
+	return !p.hasNamespaces() &&
+		reflect.DeepEqual(p.Select, PodAttributes{}) &&
+		reflect.DeepEqual(p.Exclude, PodAttributes{}) &&
+		p.Owner == (PodOwner{})
+}
+
+// namespaces returns the sorted list of namespaces where the selector looks for pods: the namespaces given
+// explicitly and those whose labels match NamespaceLabels. If none is configured, the default namespace is returned.
+func (p PodSelector) namespaces(ctx context.Context, k8s kubernetes.Kubernetes) ([]string, error) {
+	if !p.hasNamespaces() {
+		return []string{metav1.NamespaceDefault}, nil
+	}
+
+	found := map[string]bool{}
+	if p.Namespace != "" {
+		found[p.Namespace] = true
+	}
+	for _, ns := range p.Namespaces {
+		found[ns] = true
+	}
+
+	if len(p.NamespaceLabels) > 0 {
+		list, err := k8s.Client().CoreV1().Namespaces().List(
+			ctx,
+			metav1.ListOptions{LabelSelector: labels.SelectorFromSet(p.NamespaceLabels).String()},
+		)
+		if err != nil {
+			return nil, fmt.Errorf("listing namespaces: %w", err)
+		}
+
+		for _, ns := range list.Items {
+			found[ns.Name] = true
+		}
+	}
+
+	namespaces := []string{}
+	for ns := range found {
+		namespaces = append(namespaces, ns)
+	}
+	sort.Strings(namespaces)
+
+	return namespaces, nil
+}
+
+// listSelectorPods returns the pods that match the selector in all of its namespaces
+func listSelectorPods(ctx context.Context, k8s kubernetes.Kubernetes, selector PodSelector) ([]corev1.Pod, error) {
+	namespaces, err := selector.namespaces(ctx, k8s)
+	if err != nil {
+		return nil, err
+	}
+
+	pods := []corev1.Pod{}
+	for _, ns := range namespaces {
+		nsPods, err := k8s.PodHelper(ns).List(ctx, selector.filter())
+		if err != nil {
+			return nil, err
+		}
+		pods = append(pods, nsPods...)
+	}
+
+	return pods, nil
+}
+
+// filter returns the helpers.PodFilter for listing the pods that match the selector
+func (p PodSelector) filter() helpers.PodFilter {
+	return helpers.PodFilter{
+		Select:             p.Select.Labels,
+		Exclude:            p.Exclude.Labels,
+		SelectExpressions:  labelSelectorRequirements(p.Select.Expressions),
+		ExcludeExpressions: labelSelectorRequirements(p.Exclude.Expressions),
+		SelectAnnotations:  p.Select.Annotations,
+		ExcludeAnnotations: p.Exclude.Annotations,
+		SelectPhases:       p.Select.Phases,
+		ExcludePhases:      p.Exclude.Phases,
+		SelectConditions:   p.Select.Conditions,
+		ExcludeConditions:  p.Exclude.Conditions,
+		Owner:              helpers.Owner{Kind: p.Owner.Kind, Name: p.Owner.Name},
+	}
+}
+
 // String returns a human-readable explanation of the pods matched by a PodSelector.
 func (p PodSelector) String() string {
 	var str string
 
-	if len(p.Select.Labels) == 0 && len(p.Exclude.Labels) == 0 {
+	if reflect.DeepEqual(p.Select, PodAttributes{}) &&
+		reflect.DeepEqual(p.Exclude, PodAttributes{}) &&
+		p.Owner == (PodOwner{}) {
 		str = "all pods"
 	} else {
 		str = "pods "
-		str += p.groupLabels("including", p.Select.Labels)
-		str += p.groupLabels("excluding", p.Exclude.Labels)
+		if p.Owner != (PodOwner{}) {
+			str += fmt.Sprintf("owned by %s/%s, ", p.Owner.Kind, p.Owner.Name)
+		}
+		str += p.groupAttributes("including", p.Select)
+		str += p.groupAttributes("excluding", p.Exclude)
 		str = strings.TrimSuffix(str, ", ")
 	}
 
-	str += fmt.Sprintf(" in ns %q", p.NamespaceOrDefault())
+	str += p.namespacesString()
 
 	return str
 }
 
-// groupLabels returns a group of labels as a string, giving that group a name. The returned string has the form of:
-// `groupName(foo=bar, boo=baz), `, including the trailing space and comma.
-// An empty group of labels produces an empty string.
-func (PodSelector) groupLabels(groupName string, labels map[string]string) string {
-	if len(labels) == 0 {
-		return ""
+// namespacesString returns a human-readable description of the namespaces of the selector, in the form of
+// ` in ns "a", "b" and namespaces labeled team=payments`, including the leading space.
+func (p PodSelector) namespacesString() string {
+	if len(p.Namespaces) == 0 && len(p.NamespaceLabels) == 0 {
+		return fmt.Sprintf(" in ns %q", p.NamespaceOrDefault())
+	}
+
+	names := []string{}
+	if p.Namespace != "" {
+		names = append(names, fmt.Sprintf("%q", p.Namespace))
+	}
+	for _, ns := range p.Namespaces {
+		names = append(names, fmt.Sprintf("%q", ns))
+	}
+
+	groups := []string{}
+	if len(names) > 0 {
+		groups = append(groups, "ns "+strings.Join(names, ", "))
+	}
+	if len(p.NamespaceLabels) > 0 {
+		groups = append(groups, "namespaces labeled "+labels.SelectorFromSet(p.NamespaceLabels).String())
+	}
+
+	return " in " + strings.Join(groups, " and ")
+}
+
+// groupAttributes returns a group of attributes as a string, giving that group a name. The returned string has the
+// form of: `groupName(foo=bar, Running, Ready), `, including the trailing space and comma.
+// An empty group of attributes produces an empty string.
+func (PodSelector) groupAttributes(groupName string, attributes PodAttributes) string {
+	items := []string{}
+	for k, v := range attributes.Labels {
+		items = append(items, fmt.Sprintf("%s=%s", k, v))
+	}
+	for _, e := range attributes.Expressions {
+		items = append(items, e.String())
 	}
+	for k, v := range attributes.Annotations {
+		items = append(items, fmt.Sprintf("%s=%s", k, v))
+	}
+	items = append(items, attributes.Phases...)
+	items = append(items, attributes.Conditions...)
 
-	group := groupName + "("
-	for k, v := range labels {
-		group += fmt.Sprintf("%s=%s, ", k, v)
+	if len(items) == 0 {
+		return ""
 	}
-	group = strings.TrimSuffix(group, ", ")
-	group += "), "
 
-	return group
+	return groupName + "(" + strings.Join(items, ", ") + "), "
 }
 
 // NewPodDisruptor creates a new instance of a PodDisruptor that acts on the pods
@@ -108,23 +317,39 @@ func NewPodDisruptor(
 	selector PodSelector,
 	options PodDisruptorOptions,
 ) (PodDisruptor, error) {
+	controller, err := newPodAgentController(ctx, k8s, selector, options)
+	if err != nil {
+		return nil, err
+	}
+
+	return &podDisruptor{
+		k8s:        k8s,
+		controller: controller,
+	}, nil
+}
+
+// newPodAgentController returns an AgentController for a sample of the pods that match the given PodSelector,
+// with the disruptor agent already injected in them
+func newPodAgentController(
+	ctx context.Context,
+	k8s kubernetes.Kubernetes,
+	selector PodSelector,
+	options PodDisruptorOptions,
+) (AgentController, error) {
 	// validate selector
-	emptySelect := reflect.DeepEqual(selector.Select, PodAttributes{})
-	emptyExclude := reflect.DeepEqual(selector.Exclude, PodAttributes{})
-	if selector.Namespace == "" && emptySelect && emptyExclude {
-		return nil, fmt.Errorf("namespace, select and exclude attributes in pod selector cannot all be empty")
+	if selector.isEmpty() {
+		return nil, fmt.Errorf("namespace, select, exclude and owner attributes in pod selector cannot all be empty")
 	}
 
-	// ensure selector and controller use default namespace if none specified
-	namespace := selector.NamespaceOrDefault()
-	helper := k8s.PodHelper(namespace)
+	if (selector.Owner.Kind == "") != (selector.Owner.Name == "") {
+		return nil, fmt.Errorf("both kind and name of the owner must be specified")
+	}
 
-	filter := helpers.PodFilter{
-		Select:  selector.Select.Labels,
-		Exclude: selector.Exclude.Labels,
+	if err := validateBlastRadius(options.BlastRadius); err != nil {
+		return nil, err
 	}
 
-	targets, err := helper.List(ctx, filter)
+	targets, err := listSelectorPods(ctx, k8s, selector)
 	if err != nil {
 		return nil, err
 	}
@@ -133,27 +358,58 @@ func NewPodDisruptor(
 		return nil, fmt.Errorf("finding pods matching '%s': %w", selector, ErrSelectorNoPods)
 	}
 
-	controller := NewAgentController(
-		ctx,
-		helper,
-		namespace,
+	targets, err = samplePods(ctx, k8s, targets, options.BlastRadius)
+	if err != nil {
+		return nil, err
+	}
+
+	controller := newAgentController(
+		k8s.PodHelper,
 		targets,
 		options.InjectTimeout,
 	)
+	controller.dryRun = options.DryRun
+
 	err = controller.InjectDisruptorAgent(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	return &podDisruptor{
-		controller: controller,
-	}, nil
+	if !options.WatchTargets {
+		return controller, nil
+	}
+
+	namespaces, err := selector.namespaces(ctx, k8s)
+	if err != nil {
+		return nil, err
+	}
+
+	refresh := func(ctx context.Context, current []corev1.Pod) ([]corev1.Pod, error) {
+		pods, err := listSelectorPods(ctx, k8s, selector)
+		if err != nil {
+			return nil, err
+		}
+
+		return resamplePods(ctx, k8s, current, pods, options.BlastRadius)
+	}
+
+	err = controller.watchTargets(ctx, k8s.Client(), namespaces, selector.NamespaceLabels, refresh)
+	if err != nil {
+		return nil, err
+	}
+
+	return controller, nil
 }
 
 func (d *podDisruptor) Targets(ctx context.Context) ([]string, error) {
 	return d.controller.Targets(ctx)
 }
 
+// InjectChaos periodically injects a random fault in a random target of the disruptor
+func (d *podDisruptor) InjectChaos(ctx context.Context, options ChaosOptions) (ChaosReport, error) {
+	return injectChaos(ctx, d.controller, options)
+}
+
 // InjectHTTPFault injects faults in the http requests sent to the disruptor's targets
 func (d *podDisruptor) InjectHTTPFaults(
 	ctx context.Context,
@@ -161,17 +417,34 @@ func (d *podDisruptor) InjectHTTPFaults(
 	duration time.Duration,
 	options HTTPDisruptionOptions,
 ) error {
+	_, err := d.InjectHTTPFaultsWithReport(ctx, fault, duration, options)
+	return err
+}
+
+// InjectHTTPFaultsWithReport injects faults in the http requests sent to the disruptor's targets and returns the
+// report of the injection in each target
+func (d *podDisruptor) InjectHTTPFaultsWithReport(
+	ctx context.Context,
+	fault HTTPFault,
+	duration time.Duration,
+	options HTTPDisruptionOptions,
+) (FaultReport, error) {
 	// TODO: make port mandatory instead of using a default
 	if fault.Port == 0 {
 		fault.Port = DefaultTargetPort
 	}
 
-	visitor := PodHTTPFaultVisitor{
-		fault:    fault,
+	visitor := timedVisitor{
 		duration: duration,
-		options:  options,
+		build: func(duration time.Duration) PodVisitor {
+			return PodHTTPFaultVisitor{
+				fault:    fault,
+				duration: duration,
+				options:  options,
+			}
+		},
 	}
-	return d.controller.Visit(ctx, visitor)
+	return d.controller.VisitWithReport(ctx, visitor)
 }
 
 // InjectGrpcFaults injects faults in the grpc requests sent to the disruptor's targets
@@ -181,10 +454,105 @@ func (d *podDisruptor) InjectGrpcFaults(
 	duration time.Duration,
 	options GrpcDisruptionOptions,
 ) error {
-	visitor := PodGrpcFaultVisitor{
-		fault:    fault,
+	_, err := d.InjectGrpcFaultsWithReport(ctx, fault, duration, options)
+	return err
+}
+
+// InjectGrpcFaultsWithReport injects faults in the grpc requests sent to the disruptor's targets and returns the
+// report of the injection in each target
+func (d *podDisruptor) InjectGrpcFaultsWithReport(
+	ctx context.Context,
+	fault GrpcFault,
+	duration time.Duration,
+	options GrpcDisruptionOptions,
+) (FaultReport, error) {
+	visitor := timedVisitor{
+		duration: duration,
+		build: func(duration time.Duration) PodVisitor {
+			return PodGrpcFaultVisitor{
+				fault:    fault,
+				duration: duration,
+				options:  options,
+			}
+		},
+	}
+
+	return d.controller.VisitWithReport(ctx, visitor)
+}
+
+// InjectSignalFault sends a signal to the main process of a container in each of the disruptor's targets
+func (d *podDisruptor) InjectSignalFault(ctx context.Context, fault SignalFault) error {
+	visitor := PodSignalFaultVisitor{
+		fault: fault,
+	}
+
+	return d.controller.Visit(ctx, visitor)
+}
+
+// InjectFreezeFault stops the processes of a container in each of the disruptor's targets for the given duration
+func (d *podDisruptor) InjectFreezeFault(ctx context.Context, fault FreezeFault, duration time.Duration) error {
+	visitor := timedVisitor{
+		duration: duration,
+		build: func(duration time.Duration) PodVisitor {
+			return PodFreezeFaultVisitor{
+				fault:    fault,
+				duration: duration,
+			}
+		},
+	}
+
+	return d.controller.Visit(ctx, visitor)
+}
+
+// InjectNetworkFaults injects faults in the network traffic of the disruptor's targets
+func (d *podDisruptor) InjectNetworkFaults(ctx context.Context, fault NetworkFault, duration time.Duration) error {
+	visitor := timedVisitor{
+		duration: duration,
+		build: func(duration time.Duration) PodVisitor {
+			return PodNetworkFaultVisitor{
+				fault:    fault,
+				duration: duration,
+			}
+		},
+	}
+
+	return d.controller.Visit(ctx, visitor)
+}
+
+// StressCPU generates CPU load in the disruptor's targets
+func (d *podDisruptor) StressCPU(ctx context.Context, stress CPUStress, duration time.Duration) error {
+	stress, err := validateCPUStress(stress)
+	if err != nil {
+		return err
+	}
+
+	visitor := timedVisitor{
+		duration: duration,
+		build: func(duration time.Duration) PodVisitor {
+			return PodCPUStressVisitor{
+				stress:   stress,
+				duration: duration,
+			}
+		},
+	}
+
+	return d.controller.Visit(ctx, visitor)
+}
+
+// StressMemory allocates memory in the disruptor's targets
+func (d *podDisruptor) StressMemory(ctx context.Context, stress MemoryStress, duration time.Duration) error {
+	if err := validateMemoryStress(stress); err != nil {
+		return err
+	}
+
+	visitor := timedVisitor{
 		duration: duration,
-		options:  options,
+		build: func(duration time.Duration) PodVisitor {
+			return PodMemoryStressVisitor{
+				stress:   stress,
+				duration: duration,
+			}
+		},
 	}
 
 	return d.controller.Visit(ctx, visitor)
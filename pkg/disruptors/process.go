@@ -0,0 +1,29 @@
+package disruptors
+
+import (
+	"context"
+	"time"
+)
+
+// ProcessFaultInjector defines the methods for injecting faults in the processes of a target
+type ProcessFaultInjector interface {
+	// InjectSignalFault sends a signal to the main process of a container in each of the disruptor's targets
+	InjectSignalFault(ctx context.Context, fault SignalFault) error
+	// InjectFreezeFault stops all the processes of a container in each of the disruptor's targets for the
+	// specified duration
+	InjectFreezeFault(ctx context.Context, fault FreezeFault, duration time.Duration) error
+}
+
+// SignalFault specifies a signal to be sent to the main process of a container
+type SignalFault struct {
+	// Name of the container whose main process will receive the signal. If empty, the first container is used.
+	Container string `js:"container"`
+	// Signal to be sent (e.g. "SIGKILL", "SIGTERM", "SIGSTOP", "SIGCONT"). If empty, SIGKILL is sent.
+	Signal string `js:"signal"`
+}
+
+// FreezeFault specifies a container whose processes will be stopped for the duration of the fault
+type FreezeFault struct {
+	// Name of the container whose processes will be stopped. If empty, the first container is used.
+	Container string `js:"container"`
+}
@@ -10,9 +10,32 @@ type ProtocolFaultInjector interface {
 	// InjectHTTPFault injects faults in the HTTP requests sent to the disruptor's targets
 	// for the specified duration
 	InjectHTTPFaults(ctx context.Context, fault HTTPFault, duration time.Duration, options HTTPDisruptionOptions) error
+	// InjectHTTPFaultsWithReport is like InjectHTTPFaults, but returns a report of the injection in each target.
+	// In dry-run mode, the report is the plan of the injection.
+	InjectHTTPFaultsWithReport(
+		ctx context.Context,
+		fault HTTPFault,
+		duration time.Duration,
+		options HTTPDisruptionOptions,
+	) (FaultReport, error)
 	// InjectGrpcFault injects faults in the grpc requests sent to the disruptor's targets
 	// for the specified duration
 	InjectGrpcFaults(ctx context.Context, fault GrpcFault, duration time.Duration, options GrpcDisruptionOptions) error
+	// InjectGrpcFaultsWithReport is like InjectGrpcFaults, but returns a report of the injection in each target.
+	// In dry-run mode, the report is the plan of the injection.
+	InjectGrpcFaultsWithReport(
+		ctx context.Context,
+		fault GrpcFault,
+		duration time.Duration,
+		options GrpcDisruptionOptions,
+	) (FaultReport, error)
+}
+
+// TCPFaultInjector defines the methods for injecting faults in TCP connections
+type TCPFaultInjector interface {
+	// InjectTCPFaults injects faults in the TCP connections of the disruptor's targets
+	// for the specified duration
+	InjectTCPFaults(ctx context.Context, fault TCPFault, duration time.Duration, options TCPDisruptionOptions) error
 }
 
 // HTTPDisruptionOptions defines options for the injection of HTTP faults in a target pod
@@ -27,6 +50,12 @@ type GrpcDisruptionOptions struct {
 	ProxyPort uint `js:"proxyPort"`
 }
 
+// TCPDisruptionOptions defines options for the injection of TCP faults in a target pod
+type TCPDisruptionOptions struct {
+	// Port used by the agent for listening
+	ProxyPort uint `js:"proxyPort"`
+}
+
 // HTTPFault specifies a fault to be injected in http requests
 type HTTPFault struct {
 	// port the disruptions will be applied to
@@ -62,3 +91,13 @@ type GrpcFault struct {
 	// List of grpc services to be excluded from disruptions
 	Exclude string `js:"exclude"`
 }
+
+// TCPFault specifies a fault to be injected in TCP connections
+type TCPFault struct {
+	// Average delay introduced when establishing connections
+	AverageDelay time.Duration `js:"averageDelay"`
+	// Variation in the delay (with respect of the average delay)
+	DelayVariation time.Duration `js:"delayVariation"`
+	// Fraction (in the range 0.0 to 1.0) of connections that will be reset
+	ErrorRate float32 `js:"errorRate"`
+}
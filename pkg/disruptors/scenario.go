@@ -0,0 +1,783 @@
+package disruptors
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/grafana/xk6-disruptor/pkg/kubernetes"
+
+	"sigs.k8s.io/yaml"
+)
+
+// Scenario defines a plan of faults injected in a set of targets
+type Scenario struct {
+	// Faults injected by the scenario
+	Faults []ScenarioFault `js:"faults"`
+}
+
+// ScenarioFault defines a fault injected in a target at a given time of a scenario
+type ScenarioFault struct {
+	// Target of the fault
+	Target ScenarioTarget `js:"target"`
+	// Type of fault (e.g. "http", "grpc", "network", "cpu")
+	Type string `js:"type"`
+	// Parameters of the fault. They have the same attributes as the fault in the JS API (e.g. HTTPFault for "http")
+	Params map[string]interface{} `js:"params"`
+	// Options of the fault injection (e.g. HTTPDisruptionOptions for "http")
+	Options map[string]interface{} `js:"options"`
+	// Offset from the start of the scenario when the fault is injected
+	Start time.Duration `js:"start"`
+	// Duration of the fault. Required for all faults except "signal"
+	Duration time.Duration `js:"duration"`
+	// Number of times the fault is injected. Defaults to 1.
+	Repeats uint `js:"repeats"`
+	// Time between the start of consecutive repetitions. Defaults to the duration of the fault.
+	Interval time.Duration `js:"interval"`
+}
+
+// ScenarioTarget defines the target of a fault in a scenario. Either Pods or Service must be specified.
+type ScenarioTarget struct {
+	// Selects the pods targeted by the fault
+	Pods *PodSelector `js:"pods"`
+	// Service whose backing pods are targeted by the fault
+	Service *ScenarioService `js:"service"`
+	// Options of the disruptor (PodDisruptorOptions or ServiceDisruptorOptions)
+	Options map[string]interface{} `js:"options"`
+}
+
+// ScenarioService identifies the service targeted by a fault in a scenario
+type ScenarioService struct {
+	Name      string `js:"name"`
+	Namespace string `js:"namespace"`
+}
+
+// ScenarioError reports an invalid field in a scenario
+type ScenarioError struct {
+	// Path to the field (e.g. "faults[1].params.errorRate")
+	Field string
+	Err   error
+}
+
+func (e *ScenarioError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Field, e.Err)
+}
+
+func (e *ScenarioError) Unwrap() error {
+	return e.Err
+}
+
+// fieldError returns a ScenarioError for the field with the formatted message
+func fieldError(field string, format string, args ...interface{}) error {
+	return &ScenarioError{Field: field, Err: fmt.Errorf(format, args...)}
+}
+
+// LoadScenario reads a scenario from a YAML or JSON file
+func LoadScenario(path string) (Scenario, error) {
+	data, err := os.ReadFile(path) //nolint:gosec
+	if err != nil {
+		return Scenario{}, fmt.Errorf("reading scenario: %w", err)
+	}
+
+	return ParseScenario(data)
+}
+
+// ParseScenario parses a scenario in YAML or JSON format
+func ParseScenario(data []byte) (Scenario, error) {
+	var raw interface{}
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return Scenario{}, fmt.Errorf("parsing scenario: %w", err)
+	}
+
+	if _, ok := raw.(map[string]interface{}); !ok {
+		return Scenario{}, fmt.Errorf("parsing scenario: expected an object")
+	}
+
+	scenario := Scenario{}
+	if err := decodeField("", raw, reflect.ValueOf(&scenario).Elem()); err != nil {
+		return Scenario{}, err
+	}
+
+	if _, err := validateScenario(scenario); err != nil {
+		return Scenario{}, err
+	}
+
+	return scenario, nil
+}
+
+// scenarioInjection injects a fault of a scenario in the disruptor for the given duration
+type scenarioInjection func(ctx context.Context, disruptor Disruptor, duration time.Duration) error
+
+// scenarioFaultType describes a type of fault of a scenario
+type scenarioFaultType struct {
+	// interface the disruptor must implement for injecting the fault
+	injector reflect.Type
+	// the fault has a duration
+	timed bool
+	// decode decodes the parameters and options of the fault and returns the function that injects it
+	decode func(field string, fault ScenarioFault) (scenarioInjection, error)
+}
+
+// decodeParams decodes the parameters and options of a fault of a scenario
+func decodeParams[F any, O any](field string, fault ScenarioFault) (F, O, error) {
+	var params F
+	var options O
+
+	if err := decodeField(fieldPath(field, "params"), fault.Params, reflect.ValueOf(&params).Elem()); err != nil {
+		return params, options, err
+	}
+
+	if err := decodeField(fieldPath(field, "options"), fault.Options, reflect.ValueOf(&options).Elem()); err != nil {
+		return params, options, err
+	}
+
+	return params, options, nil
+}
+
+// injectorType returns the type of the injector interface T
+func injectorType[T any]() reflect.Type {
+	return reflect.TypeOf((*T)(nil)).Elem()
+}
+
+// serviceDisruptorType is the type of the ServiceDisruptor interface, which implements the injectors of the faults
+// that can be injected in services
+var serviceDisruptorType = injectorType[ServiceDisruptor]()
+
+// scenarioFaultTypes maps the types of faults of a scenario to their description
+var scenarioFaultTypes = map[string]scenarioFaultType{
+	"http": {
+		injector: injectorType[ProtocolFaultInjector](),
+		timed:    true,
+		decode: func(field string, fault ScenarioFault) (scenarioInjection, error) {
+			params, options, err := decodeParams[HTTPFault, HTTPDisruptionOptions](field, fault)
+			if err != nil {
+				return nil, err
+			}
+
+			return func(ctx context.Context, d Disruptor, duration time.Duration) error {
+				return d.(ProtocolFaultInjector).InjectHTTPFaults(ctx, params, duration, options)
+			}, nil
+		},
+	},
+	"grpc": {
+		injector: injectorType[ProtocolFaultInjector](),
+		timed:    true,
+		decode: func(field string, fault ScenarioFault) (scenarioInjection, error) {
+			params, options, err := decodeParams[GrpcFault, GrpcDisruptionOptions](field, fault)
+			if err != nil {
+				return nil, err
+			}
+
+			return func(ctx context.Context, d Disruptor, duration time.Duration) error {
+				return d.(ProtocolFaultInjector).InjectGrpcFaults(ctx, params, duration, options)
+			}, nil
+		},
+	},
+	"network": {
+		injector: injectorType[NetworkFaultInjector](),
+		timed:    true,
+		decode: func(field string, fault ScenarioFault) (scenarioInjection, error) {
+			params, _, err := decodeParams[NetworkFault, struct{}](field, fault)
+			if err != nil {
+				return nil, err
+			}
+
+			return func(ctx context.Context, d Disruptor, duration time.Duration) error {
+				return d.(NetworkFaultInjector).InjectNetworkFaults(ctx, params, duration)
+			}, nil
+		},
+	},
+	"partition": {
+		injector: injectorType[PartitionInjector](),
+		timed:    true,
+		decode: func(field string, fault ScenarioFault) (scenarioInjection, error) {
+			params, _, err := decodeParams[PartitionFault, struct{}](field, fault)
+			if err != nil {
+				return nil, err
+			}
+
+			return func(ctx context.Context, d Disruptor, duration time.Duration) error {
+				return d.(PartitionInjector).InjectPartitionFault(ctx, params, duration)
+			}, nil
+		},
+	},
+	"dns": {
+		injector: injectorType[DNSFaultInjector](),
+		timed:    true,
+		decode: func(field string, fault ScenarioFault) (scenarioInjection, error) {
+			params, options, err := decodeParams[DNSFault, DNSDisruptionOptions](field, fault)
+			if err != nil {
+				return nil, err
+			}
+
+			return func(ctx context.Context, d Disruptor, duration time.Duration) error {
+				return d.(DNSFaultInjector).InjectDNSFaults(ctx, params, duration, options)
+			}, nil
+		},
+	},
+	"signal": {
+		injector: injectorType[ProcessFaultInjector](),
+		decode: func(field string, fault ScenarioFault) (scenarioInjection, error) {
+			params, _, err := decodeParams[SignalFault, struct{}](field, fault)
+			if err != nil {
+				return nil, err
+			}
+
+			return func(ctx context.Context, d Disruptor, _ time.Duration) error {
+				return d.(ProcessFaultInjector).InjectSignalFault(ctx, params)
+			}, nil
+		},
+	},
+	"freeze": {
+		injector: injectorType[ProcessFaultInjector](),
+		timed:    true,
+		decode: func(field string, fault ScenarioFault) (scenarioInjection, error) {
+			params, _, err := decodeParams[FreezeFault, struct{}](field, fault)
+			if err != nil {
+				return nil, err
+			}
+
+			return func(ctx context.Context, d Disruptor, duration time.Duration) error {
+				return d.(ProcessFaultInjector).InjectFreezeFault(ctx, params, duration)
+			}, nil
+		},
+	},
+	"cpu": {
+		injector: injectorType[CPUStressInjector](),
+		timed:    true,
+		decode: func(field string, fault ScenarioFault) (scenarioInjection, error) {
+			params, _, err := decodeParams[CPUStress, struct{}](field, fault)
+			if err != nil {
+				return nil, err
+			}
+
+			return func(ctx context.Context, d Disruptor, duration time.Duration) error {
+				return d.(CPUStressInjector).StressCPU(ctx, params, duration)
+			}, nil
+		},
+	},
+	"memory": {
+		injector: injectorType[MemoryStressInjector](),
+		timed:    true,
+		decode: func(field string, fault ScenarioFault) (scenarioInjection, error) {
+			params, _, err := decodeParams[MemoryStress, struct{}](field, fault)
+			if err != nil {
+				return nil, err
+			}
+
+			return func(ctx context.Context, d Disruptor, duration time.Duration) error {
+				return d.(MemoryStressInjector).StressMemory(ctx, params, duration)
+			}, nil
+		},
+	},
+	"diskFill": {
+		injector: injectorType[ResourceExhaustionInjector](),
+		timed:    true,
+		decode: func(field string, fault ScenarioFault) (scenarioInjection, error) {
+			params, _, err := decodeParams[DiskFillFault, struct{}](field, fault)
+			if err != nil {
+				return nil, err
+			}
+
+			return func(ctx context.Context, d Disruptor, duration time.Duration) error {
+				return d.(ResourceExhaustionInjector).InjectDiskFillFault(ctx, params, duration)
+			}, nil
+		},
+	},
+	"fdExhaustion": {
+		injector: injectorType[ResourceExhaustionInjector](),
+		timed:    true,
+		decode: func(field string, fault ScenarioFault) (scenarioInjection, error) {
+			params, _, err := decodeParams[FDExhaustionFault, struct{}](field, fault)
+			if err != nil {
+				return nil, err
+			}
+
+			return func(ctx context.Context, d Disruptor, duration time.Duration) error {
+				return d.(ResourceExhaustionInjector).InjectFDExhaustionFault(ctx, params, duration)
+			}, nil
+		},
+	},
+}
+
+// faultRunner injects a fault of a scenario in a disruptor, repeating it at the interval of the fault
+type faultRunner func(ctx context.Context, disruptor Disruptor) error
+
+// validateFault validates a fault of a scenario, except its target, and returns the interface the disruptor must
+// implement for injecting it and the function that injects it
+func validateFault(field string, fault ScenarioFault) (reflect.Type, faultRunner, error) {
+	if fault.Type == "" {
+		return nil, nil, fieldError(fieldPath(field, "type"), "fault type must be specified")
+	}
+
+	faultType, found := scenarioFaultTypes[fault.Type]
+	if !found {
+		return nil, nil, fieldError(fieldPath(field, "type"), "unknown fault type %q", fault.Type)
+	}
+
+	inject, err := faultType.decode(field, fault)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if fault.Start < 0 {
+		return nil, nil, fieldError(fieldPath(field, "start"), "must not be negative")
+	}
+
+	if faultType.timed && fault.Duration <= 0 {
+		return nil, nil, fieldError(fieldPath(field, "duration"), "must be greater than zero")
+	}
+
+	if !faultType.timed && fault.Duration != 0 {
+		return nil, nil, fieldError(fieldPath(field, "duration"), "%q faults do not have a duration", fault.Type)
+	}
+
+	repeats := fault.Repeats
+	if repeats == 0 {
+		repeats = 1
+	}
+
+	interval := fault.Interval
+	if repeats > 1 {
+		if interval == 0 {
+			interval = fault.Duration
+		}
+
+		if interval <= 0 {
+			return nil, nil, fieldError(fieldPath(field, "interval"), "must be greater than zero when the fault is repeated")
+		}
+
+		if interval < fault.Duration {
+			return nil, nil, fieldError(fieldPath(field, "interval"), "must not be shorter than the duration of the fault")
+		}
+	}
+
+	run := func(ctx context.Context, disruptor Disruptor) error {
+		start := time.Now()
+		for i := uint(0); i < repeats; i++ {
+			select {
+			case <-time.After(time.Until(start.Add(time.Duration(i) * interval))):
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+
+			if err := inject(ctx, disruptor, fault.Duration); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	return faultType.injector, run, nil
+}
+
+// FaultStep returns a TimelineStep that injects a fault in the disruptor at the start of the fault. The fault is
+// defined as in a Scenario, except its target, which is the disruptor.
+func FaultStep(disruptor Disruptor, fault ScenarioFault) (TimelineStep, error) {
+	injector, run, err := validateFault("", fault)
+	if err != nil {
+		return TimelineStep{}, err
+	}
+
+	if !reflect.TypeOf(disruptor).Implements(injector) {
+		return TimelineStep{}, fieldError("type", "%q faults are not supported by the disruptor", fault.Type)
+	}
+
+	return TimelineStep{
+		Start: fault.Start,
+		Run: func(ctx context.Context) error {
+			return run(ctx, disruptor)
+		},
+	}, nil
+}
+
+// scenarioStep is a validated fault of a scenario
+type scenarioStep struct {
+	// path to the fault in the scenario
+	field     string
+	start     time.Duration
+	run       faultRunner
+	target    ScenarioTarget
+	newTarget func(ctx context.Context, k8s kubernetes.Kubernetes) (Disruptor, error)
+	// periods of the scenario when the fault is injected
+	windows []faultWindow
+}
+
+// faultWindow is a period of a scenario when a fault is injected
+type faultWindow struct {
+	start time.Duration
+	end   time.Duration
+}
+
+// overlaps returns whether the window overlaps with another. Faults without a duration overlap with the windows
+// that contain their start.
+func (w faultWindow) overlaps(other faultWindow) bool {
+	return w.start == other.start || (w.start < other.end && other.start < w.end)
+}
+
+// faultWindows returns the periods of the scenario when each repetition of a valid fault is injected
+func faultWindows(fault ScenarioFault) []faultWindow {
+	repeats := fault.Repeats
+	if repeats == 0 {
+		repeats = 1
+	}
+
+	interval := fault.Interval
+	if interval == 0 {
+		interval = fault.Duration
+	}
+
+	windows := []faultWindow{}
+	for i := uint(0); i < repeats; i++ {
+		start := fault.Start + time.Duration(i)*interval
+		windows = append(windows, faultWindow{start: start, end: start + fault.Duration})
+	}
+
+	return windows
+}
+
+// validateScenario validates the faults of a scenario and returns the steps for running them. As the agent injects
+// one fault at a time in each target, faults that overlap in the same target are rejected.
+func validateScenario(scenario Scenario) ([]scenarioStep, error) {
+	if len(scenario.Faults) == 0 {
+		return nil, fieldError("faults", "at least one fault must be specified")
+	}
+
+	steps := []scenarioStep{}
+	for i, fault := range scenario.Faults {
+		step, err := validateScenarioFault(fmt.Sprintf("faults[%d]", i), fault)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, other := range steps {
+			if reflect.DeepEqual(step.target, other.target) && overlappingWindows(step.windows, other.windows) {
+				return nil, fieldError(step.field, "overlaps with %s, which is injected in the same target", other.field)
+			}
+		}
+
+		steps = append(steps, step)
+	}
+
+	return steps, nil
+}
+
+// overlappingWindows returns whether any of the windows overlaps with any of the others
+func overlappingWindows(windows []faultWindow, others []faultWindow) bool {
+	for _, window := range windows {
+		for _, other := range others {
+			if window.overlaps(other) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func validateScenarioFault(field string, fault ScenarioFault) (scenarioStep, error) {
+	newTarget, err := validateScenarioTarget(field+".target", fault.Target)
+	if err != nil {
+		return scenarioStep{}, err
+	}
+
+	injector, run, err := validateFault(field, fault)
+	if err != nil {
+		return scenarioStep{}, err
+	}
+
+	if fault.Target.Service != nil && !serviceDisruptorType.Implements(injector) {
+		return scenarioStep{}, fieldError(field+".type", "%q faults cannot be injected in services", fault.Type)
+	}
+
+	return scenarioStep{
+		field:     field,
+		start:     fault.Start,
+		run:       run,
+		target:    fault.Target,
+		newTarget: newTarget,
+		windows:   faultWindows(fault),
+	}, nil
+}
+
+// validateScenarioTarget validates the target of a fault and returns a function that creates its disruptor
+func validateScenarioTarget(
+	field string,
+	target ScenarioTarget,
+) (func(context.Context, kubernetes.Kubernetes) (Disruptor, error), error) {
+	if (target.Pods == nil) == (target.Service == nil) {
+		return nil, fieldError(field, "either pods or service must be specified")
+	}
+
+	if target.Service != nil {
+		service := *target.Service
+		if service.Name == "" {
+			return nil, fieldError(field+".service.name", "service name must be specified")
+		}
+
+		options := ServiceDisruptorOptions{}
+		if err := decodeField(field+".options", target.Options, reflect.ValueOf(&options).Elem()); err != nil {
+			return nil, err
+		}
+
+		return func(ctx context.Context, k8s kubernetes.Kubernetes) (Disruptor, error) {
+			return NewServiceDisruptor(ctx, k8s, service.Name, service.Namespace, options)
+		}, nil
+	}
+
+	selector := *target.Pods
+	if selector.isEmpty() {
+		return nil, fieldError(field+".pods", "pod selector cannot be empty")
+	}
+
+	options := PodDisruptorOptions{}
+	if err := decodeField(field+".options", target.Options, reflect.ValueOf(&options).Elem()); err != nil {
+		return nil, err
+	}
+
+	if err := validateBlastRadius(options.BlastRadius); err != nil {
+		return nil, &ScenarioError{Field: field + ".options.blastRadius", Err: err}
+	}
+
+	return func(ctx context.Context, k8s kubernetes.Kubernetes) (Disruptor, error) {
+		return NewPodDisruptor(ctx, k8s, selector, options)
+	}, nil
+}
+
+// RunScenario creates the disruptors for the targets of the scenario and injects its faults. Each fault is injected
+// at its offset from the start of the scenario, and repeated at the given interval. Faults that overlap are injected
+// in parallel, unless they are injected in the same target. The faults injected in the same target share its
+// disruptor. RunScenario returns when all the faults end, or when one of them fails, stopping the others.
+func RunScenario(ctx context.Context, k8s kubernetes.Kubernetes, scenario Scenario) error {
+	steps, err := validateScenario(scenario)
+	if err != nil {
+		return err
+	}
+
+	// create the disruptors before the scenario starts, so injecting the agents does not delay the faults
+	timelineSteps := []TimelineStep{}
+	disruptors := []Disruptor{}
+	for i, step := range steps {
+		step := step
+
+		var target Disruptor
+		for j := 0; j < i; j++ {
+			if reflect.DeepEqual(step.target, steps[j].target) {
+				target = disruptors[j]
+				break
+			}
+		}
+
+		if target == nil {
+			target, err = step.newTarget(ctx, k8s)
+			if err != nil {
+				return fmt.Errorf("%s.target: %w", step.field, err)
+			}
+		}
+		disruptors = append(disruptors, target)
+
+		timelineSteps = append(timelineSteps, TimelineStep{
+			Start: step.start,
+			Run: func(ctx context.Context) error {
+				if err := step.run(ctx, target); err != nil {
+					return fmt.Errorf("%s: %w", step.field, err)
+				}
+				return nil
+			},
+		})
+	}
+
+	timeline, err := NewTimeline(timelineSteps)
+	if err != nil {
+		return err
+	}
+
+	return timeline.Run(ctx)
+}
+
+// durationType is the type of the time.Duration fields, which are decoded from strings such as "10s"
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// decodeField decodes a value parsed from a scenario into the target. Errors report the path to the field.
+// Structs are decoded from objects whose keys match the js tag of the fields, or their name in camel case.
+//
+//nolint:gocyclo,cyclop,exhaustive
+func decodeField(field string, value interface{}, target reflect.Value) error {
+	// null values leave the zero value
+	if value == nil {
+		return nil
+	}
+
+	if target.Type() == durationType {
+		str, ok := value.(string)
+		if !ok {
+			return fieldError(field, "expected a duration (e.g. \"10s\") got %v", value)
+		}
+
+		duration, err := time.ParseDuration(str)
+		if err != nil {
+			return fieldError(field, "invalid duration %q", str)
+		}
+
+		target.SetInt(int64(duration))
+		return nil
+	}
+
+	switch target.Kind() {
+	case reflect.Interface:
+		target.Set(reflect.ValueOf(value))
+	case reflect.Pointer:
+		elem := reflect.New(target.Type().Elem())
+		if err := decodeField(field, value, elem.Elem()); err != nil {
+			return err
+		}
+		target.Set(elem)
+	case reflect.Struct:
+		return decodeStruct(field, value, target)
+	case reflect.Map:
+		object, ok := value.(map[string]interface{})
+		if !ok || target.Type().Key().Kind() != reflect.String {
+			return fieldError(field, "expected an object got %v", value)
+		}
+
+		result := reflect.MakeMap(target.Type())
+		for _, key := range sortedKeys(object) {
+			elem := reflect.New(target.Type().Elem()).Elem()
+			if err := decodeField(fieldPath(field, key), object[key], elem); err != nil {
+				return err
+			}
+			result.SetMapIndex(reflect.ValueOf(key), elem)
+		}
+		target.Set(result)
+	case reflect.Slice:
+		items, ok := value.([]interface{})
+		if !ok {
+			return fieldError(field, "expected a list got %v", value)
+		}
+
+		result := reflect.MakeSlice(target.Type(), len(items), len(items))
+		for i, item := range items {
+			if err := decodeField(fmt.Sprintf("%s[%d]", field, i), item, result.Index(i)); err != nil {
+				return err
+			}
+		}
+		target.Set(result)
+	case reflect.String:
+		str, ok := value.(string)
+		if !ok {
+			return fieldError(field, "expected a string got %v", value)
+		}
+		target.SetString(str)
+	case reflect.Bool:
+		b, ok := value.(bool)
+		if !ok {
+			return fieldError(field, "expected a boolean got %v", value)
+		}
+		target.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		number, ok := toNumber(value)
+		if !ok || number != math.Trunc(number) || target.OverflowInt(int64(number)) {
+			return fieldError(field, "expected an integer got %v", value)
+		}
+		target.SetInt(int64(number))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		number, ok := toNumber(value)
+		if !ok || number < 0 || number != math.Trunc(number) || target.OverflowUint(uint64(number)) {
+			return fieldError(field, "expected a non negative integer got %v", value)
+		}
+		target.SetUint(uint64(number))
+	case reflect.Float32, reflect.Float64:
+		number, ok := toNumber(value)
+		if !ok {
+			return fieldError(field, "expected a number got %v", value)
+		}
+		target.SetFloat(number)
+	default:
+		return fieldError(field, "unsupported type %s", target.Type())
+	}
+
+	return nil
+}
+
+// toNumber returns the value of a number parsed from a scenario, or passed from JS
+func toNumber(value interface{}) (float64, bool) {
+	switch number := value.(type) {
+	case float64:
+		return number, true
+	case int64:
+		return float64(number), true
+	case int:
+		return float64(number), true
+	default:
+		return 0, false
+	}
+}
+
+// decodeStruct decodes an object parsed from a scenario into the target struct
+func decodeStruct(field string, value interface{}, target reflect.Value) error {
+	object, ok := value.(map[string]interface{})
+	if !ok {
+		return fieldError(field, "expected an object got %v", value)
+	}
+
+	for _, key := range sortedKeys(object) {
+		structField, found := findStructField(target.Type(), key)
+		if !found {
+			return fieldError(fieldPath(field, key), "unknown field")
+		}
+
+		if err := decodeField(fieldPath(field, key), object[key], target.FieldByIndex(structField.Index)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// findStructField returns the exported field of the struct that matches the key by its js tag or its name
+func findStructField(structType reflect.Type, key string) (reflect.StructField, bool) {
+	for i := 0; i < structType.NumField(); i++ {
+		structField := structType.Field(i)
+		if !structField.IsExported() {
+			continue
+		}
+
+		if tag, found := structField.Tag.Lookup("js"); found {
+			if tag == key {
+				return structField, true
+			}
+			continue
+		}
+
+		if key != "" && structField.Name == strings.ToUpper(key[:1])+key[1:] {
+			return structField, true
+		}
+	}
+
+	return reflect.StructField{}, false
+}
+
+// fieldPath returns the path to the key of an object at the given path
+func fieldPath(field string, key string) string {
+	if field == "" {
+		return key
+	}
+
+	return field + "." + key
+}
+
+func sortedKeys(object map[string]interface{}) []string {
+	keys := make([]string, 0, len(object))
+	for key := range object {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
@@ -0,0 +1,471 @@
+package disruptors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/grafana/xk6-disruptor/pkg/kubernetes"
+	"github.com/grafana/xk6-disruptor/pkg/testutils/kubernetes/builders"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func Test_ParseScenario(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		title    string
+		scenario string
+		// path to the invalid field. Empty if the scenario is valid
+		expectedField string
+		expectError   bool
+	}{
+		{
+			title: "valid yaml",
+			scenario: `
+faults:
+  - target:
+      pods:
+        namespace: shop
+        select:
+          labels:
+            app: checkout
+      options:
+        blastRadius:
+          count: 1
+    type: http
+    params:
+      port: 8080
+      errorRate: 0.1
+      errorCode: 500
+      averageDelay: 100ms
+    start: 10s
+    duration: 30s
+    repeats: 3
+    interval: 1m
+  - target:
+      service:
+        name: checkout
+        namespace: shop
+    type: network
+    params:
+      lossRate: 0.2
+    duration: 30s
+`,
+		},
+		{
+			title: "valid json",
+			//nolint:lll
+			scenario: `{"faults": [{"target": {"pods": {"namespace": "shop"}}, "type": "signal", "params": {"signal": "SIGTERM"}, "start": "5s"}]}`,
+		},
+		{
+			title:       "invalid yaml",
+			scenario:    "faults: [",
+			expectError: true,
+		},
+		{
+			title:         "no faults",
+			scenario:      "faults: []",
+			expectedField: "faults",
+		},
+		{
+			title:         "unknown attribute",
+			scenario:      "faults: []\nsteps: []",
+			expectedField: "steps",
+		},
+		{
+			title: "missing target",
+			scenario: `
+faults:
+  - type: cpu
+    duration: 10s
+`,
+			expectedField: "faults[0].target",
+		},
+		{
+			title: "pods and service",
+			scenario: `
+faults:
+  - target:
+      pods:
+        namespace: shop
+      service:
+        name: checkout
+    type: http
+    duration: 10s
+`,
+			expectedField: "faults[0].target",
+		},
+		{
+			title: "missing service name",
+			scenario: `
+faults:
+  - target:
+      service:
+        namespace: shop
+    type: http
+    duration: 10s
+`,
+			expectedField: "faults[0].target.service.name",
+		},
+		{
+			title: "invalid target options",
+			scenario: `
+faults:
+  - target:
+      pods:
+        namespace: shop
+      options:
+        blastRadius:
+          count: 1
+          percentage: 10
+    type: cpu
+    duration: 10s
+`,
+			expectedField: "faults[0].target.options.blastRadius",
+		},
+		{
+			title: "unknown fault type",
+			scenario: `
+faults:
+  - target:
+      pods:
+        namespace: shop
+    type: explode
+    duration: 10s
+`,
+			expectedField: "faults[0].type",
+		},
+		{
+			title: "fault not supported by services",
+			scenario: `
+faults:
+  - target:
+      service:
+        name: checkout
+    type: cpu
+    duration: 10s
+`,
+			expectedField: "faults[0].type",
+		},
+		{
+			title: "invalid parameter",
+			scenario: `
+faults:
+  - target:
+      pods:
+        namespace: shop
+    type: http
+    duration: 10s
+  - target:
+      pods:
+        namespace: shop
+    type: http
+    params:
+      errorRate: high
+    duration: 10s
+`,
+			expectedField: "faults[1].params.errorRate",
+		},
+		{
+			title: "unknown parameter",
+			scenario: `
+faults:
+  - target:
+      pods:
+        namespace: shop
+    type: cpu
+    params:
+      threads: 2
+    duration: 10s
+`,
+			expectedField: "faults[0].params.threads",
+		},
+		{
+			title: "invalid duration",
+			scenario: `
+faults:
+  - target:
+      pods:
+        namespace: shop
+    type: cpu
+    duration: 10
+`,
+			expectedField: "faults[0].duration",
+		},
+		{
+			title: "missing duration",
+			scenario: `
+faults:
+  - target:
+      pods:
+        namespace: shop
+    type: cpu
+`,
+			expectedField: "faults[0].duration",
+		},
+		{
+			title: "negative start",
+			scenario: `
+faults:
+  - target:
+      pods:
+        namespace: shop
+    type: cpu
+    start: -5s
+    duration: 10s
+`,
+			expectedField: "faults[0].start",
+		},
+		{
+			title: "overlapping repetitions",
+			scenario: `
+faults:
+  - target:
+      pods:
+        namespace: shop
+    type: cpu
+    duration: 10s
+    repeats: 2
+    interval: 5s
+`,
+			expectedField: "faults[0].interval",
+		},
+		{
+			title: "repeated signal without interval",
+			scenario: `
+faults:
+  - target:
+      pods:
+        namespace: shop
+    type: signal
+    repeats: 2
+`,
+			expectedField: "faults[0].interval",
+		},
+		{
+			title: "overlapping faults in the same target",
+			scenario: `
+faults:
+  - target:
+      pods:
+        namespace: shop
+    type: cpu
+    duration: 10s
+  - target:
+      pods:
+        namespace: shop
+    type: network
+    start: 5s
+    duration: 10s
+`,
+			expectedField: "faults[1]",
+		},
+		{
+			title: "signal during a fault in the same target",
+			scenario: `
+faults:
+  - target:
+      pods:
+        namespace: shop
+    type: cpu
+    duration: 10s
+    repeats: 3
+    interval: 20s
+  - target:
+      pods:
+        namespace: shop
+    type: signal
+    start: 45s
+`,
+			expectedField: "faults[1]",
+		},
+		{
+			title: "consecutive faults in the same target",
+			scenario: `
+faults:
+  - target:
+      pods:
+        namespace: shop
+    type: cpu
+    duration: 10s
+  - target:
+      pods:
+        namespace: shop
+    type: network
+    start: 10s
+    duration: 10s
+`,
+		},
+		{
+			title: "overlapping faults in different targets",
+			scenario: `
+faults:
+  - target:
+      pods:
+        namespace: shop
+    type: cpu
+    duration: 10s
+  - target:
+      pods:
+        namespace: payments
+    type: cpu
+    duration: 10s
+`,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := ParseScenario([]byte(tc.scenario))
+			if tc.expectedField == "" && !tc.expectError {
+				if err != nil {
+					t.Errorf("unexpected error : %v", err)
+				}
+				return
+			}
+
+			if err == nil {
+				t.Fatalf("should had failed")
+			}
+
+			if tc.expectedField == "" {
+				return
+			}
+
+			var scenarioErr *ScenarioError
+			if !errors.As(err, &scenarioErr) {
+				t.Fatalf("expected a ScenarioError got %v", err)
+			}
+
+			if scenarioErr.Field != tc.expectedField {
+				t.Errorf("expected error in field %q got %q", tc.expectedField, scenarioErr.Field)
+			}
+		})
+	}
+}
+
+func Test_RunScenario(t *testing.T) {
+	t.Parallel()
+
+	scenario := `
+faults:
+  - target:
+      pods:
+        namespace: test-ns
+        select:
+          labels:
+            app: test
+    type: network
+    params:
+      lossRate: 0.1
+    duration: 10ms
+    repeats: 2
+    interval: 100ms
+  - target:
+      pods:
+        namespace: test-ns
+        select:
+          labels:
+            app: test
+    type: signal
+    start: 50ms
+`
+
+	testCases := []struct {
+		title       string
+		err         error
+		expectError bool
+		// number of times each agent command is expected to be executed
+		expected map[string]int
+	}{
+		{
+			title: "faults are injected",
+			expected: map[string]int{
+				"network": 2,
+				"signal":  1,
+			},
+		},
+		{
+			title:       "injection fails",
+			err:         fmt.Errorf("fake error"),
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			pod := builders.NewPodBuilder("pod-1").
+				WithNamespace("test-ns").
+				WithLabel("app", "test").
+				WithContainer(builders.NewContainerBuilder("main").Build()).
+				WithShareProcessNamespace(true).
+				WithContainerStatus(corev1.ContainerStatus{
+					Name:        "main",
+					ContainerID: "containerd://0123456789abcdef",
+				}).
+				Build()
+
+			// the agents are already attached so they are not injected
+			pod.Spec.EphemeralContainers = append(
+				pod.Spec.EphemeralContainers,
+				corev1.EphemeralContainer{
+					EphemeralContainerCommon: corev1.EphemeralContainerCommon{Name: "xk6-agent"},
+				},
+				corev1.EphemeralContainer{
+					EphemeralContainerCommon: corev1.EphemeralContainerCommon{Name: "xk6-agent-main"},
+				},
+			)
+
+			client := fake.NewSimpleClientset(&pod)
+			k, _ := kubernetes.NewFakeKubernetes(client)
+			executor := k.GetFakeProcessExecutor()
+			executor.SetResult([]byte{}, []byte{}, tc.err)
+
+			s, err := ParseScenario([]byte(scenario))
+			if err != nil {
+				t.Fatalf("unexpected error : %v", err)
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			err = RunScenario(ctx, k, s)
+			if tc.expectError {
+				if err == nil {
+					t.Errorf("should had failed")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error : %v", err)
+			}
+
+			executed := map[string]int{}
+			for _, cmd := range executor.GetHistory() {
+				if len(cmd.Command) > 1 {
+					executed[cmd.Command[1]]++
+				}
+			}
+
+			for command, count := range tc.expected {
+				if executed[command] != count {
+					t.Errorf("expected %q executed %d times got %d", command, count, executed[command])
+				}
+			}
+		})
+	}
+}
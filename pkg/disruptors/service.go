@@ -19,6 +19,7 @@ var ErrServiceNoTargets = errors.New("service does not have any backing pods")
 type ServiceDisruptor interface {
 	Disruptor
 	ProtocolFaultInjector
+	NetworkFaultInjector
 }
 
 // ServiceDisruptorOptions defines options that controls the behavior of the ServiceDisruptor
@@ -26,6 +27,10 @@ type ServiceDisruptorOptions struct {
 	// timeout when waiting agent to be injected (default 30s). A zero value forces default.
 	// A Negative value forces no waiting.
 	InjectTimeout time.Duration `js:"injectTimeout"`
+	// Plan the faults without injecting them. The agent is not injected in the targets, and the methods that
+	// inject protocol faults return the commands that would be executed in each target. Other faults fail with
+	// ErrDryRunNotSupported.
+	DryRun bool `js:"dryRun"`
 }
 
 // serviceDisruptor is an instance of a ServiceDisruptor
@@ -62,14 +67,12 @@ func NewServiceDisruptor(
 		return nil, fmt.Errorf("creating disruptor for service %s/%s: %w", service, namespace, ErrServiceNoTargets)
 	}
 
-	ph := k8s.PodHelper(namespace)
-	controller := NewAgentController(
-		ctx,
-		ph,
-		namespace,
+	controller := newAgentController(
+		k8s.PodHelper,
 		targets,
 		options.InjectTimeout,
 	)
+	controller.dryRun = options.DryRun
 
 	err = controller.InjectDisruptorAgent(ctx)
 	if err != nil {
@@ -88,14 +91,29 @@ func (d *serviceDisruptor) InjectHTTPFaults(
 	duration time.Duration,
 	options HTTPDisruptionOptions,
 ) error {
-	visitor := ServiceHTTPFaultVisitor{
-		service:  d.service,
-		fault:    fault,
+	_, err := d.InjectHTTPFaultsWithReport(ctx, fault, duration, options)
+	return err
+}
+
+func (d *serviceDisruptor) InjectHTTPFaultsWithReport(
+	ctx context.Context,
+	fault HTTPFault,
+	duration time.Duration,
+	options HTTPDisruptionOptions,
+) (FaultReport, error) {
+	visitor := timedVisitor{
 		duration: duration,
-		options:  options,
+		build: func(duration time.Duration) PodVisitor {
+			return ServiceHTTPFaultVisitor{
+				service:  d.service,
+				fault:    fault,
+				duration: duration,
+				options:  options,
+			}
+		},
 	}
 
-	return d.controller.Visit(ctx, visitor)
+	return d.controller.VisitWithReport(ctx, visitor)
 }
 
 func (d *serviceDisruptor) InjectGrpcFaults(
@@ -104,11 +122,42 @@ func (d *serviceDisruptor) InjectGrpcFaults(
 	duration time.Duration,
 	options GrpcDisruptionOptions,
 ) error {
-	visitor := ServiceGrpcFaultVisitor{
-		service:  d.service,
-		fault:    fault,
+	_, err := d.InjectGrpcFaultsWithReport(ctx, fault, duration, options)
+	return err
+}
+
+func (d *serviceDisruptor) InjectGrpcFaultsWithReport(
+	ctx context.Context,
+	fault GrpcFault,
+	duration time.Duration,
+	options GrpcDisruptionOptions,
+) (FaultReport, error) {
+	visitor := timedVisitor{
+		duration: duration,
+		build: func(duration time.Duration) PodVisitor {
+			return ServiceGrpcFaultVisitor{
+				service:  d.service,
+				fault:    fault,
+				duration: duration,
+				options:  options,
+			}
+		},
+	}
+
+	return d.controller.VisitWithReport(ctx, visitor)
+}
+
+// InjectNetworkFaults injects faults in the network traffic of the pods that back the service
+func (d *serviceDisruptor) InjectNetworkFaults(ctx context.Context, fault NetworkFault, duration time.Duration) error {
+	visitor := timedVisitor{
 		duration: duration,
-		options:  options,
+		build: func(duration time.Duration) PodVisitor {
+			return ServiceNetworkFaultVisitor{
+				service:  d.service,
+				fault:    fault,
+				duration: duration,
+			}
+		},
 	}
 
 	return d.controller.Visit(ctx, visitor)
@@ -2,10 +2,13 @@ package disruptors
 
 import (
 	"context"
+	"errors"
 	"sort"
 	"testing"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	runtime "k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/intstr"
@@ -165,3 +168,113 @@ func Test_NewServiceDisruptor(t *testing.T) {
 		})
 	}
 }
+
+func Test_ServiceDisruptorDryRun(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		title  string
+		dryRun bool
+		// number of agents expected to be injected in the targets
+		expectedAgents int
+		// number of commands expected to be executed in the targets
+		expectedExecs int
+	}{
+		{
+			title:          "dry run",
+			dryRun:         true,
+			expectedAgents: 0,
+			expectedExecs:  0,
+		},
+		{
+			title:          "injection",
+			dryRun:         false,
+			expectedAgents: 1,
+			expectedExecs:  1,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			service := builders.NewServiceBuilder("test-svc").
+				WithNamespace("test-ns").
+				WithSelectorLabel("app", "test").
+				WithPort("http", 80, intstr.FromInt(8080)).
+				Build()
+
+			pod := builders.NewPodBuilder("pod-1").
+				WithNamespace("test-ns").
+				WithLabel("app", "test").
+				WithIP("192.0.2.6").
+				WithContainer(builders.NewContainerBuilder("main").WithPort("http", 8080).Build()).
+				Build()
+
+			client := fake.NewSimpleClientset(&service, &pod)
+			k, _ := kubernetes.NewFakeKubernetes(client)
+			executor := k.GetFakeProcessExecutor()
+
+			d, err := NewServiceDisruptor(
+				context.TODO(),
+				k,
+				"test-svc",
+				"test-ns",
+				ServiceDisruptorOptions{InjectTimeout: -1, DryRun: tc.dryRun},
+			)
+			if err != nil {
+				t.Fatalf("unexpected error creating service disruptor: %v", err)
+			}
+
+			fault := HTTPFault{Port: 80, ErrorRate: 1.0, ErrorCode: 500}
+			report, err := d.InjectHTTPFaultsWithReport(context.TODO(), fault, time.Second, HTTPDisruptionOptions{})
+			if err != nil {
+				t.Fatalf("unexpected error : %v", err)
+			}
+
+			// the fault is injected in the port of the pod that backs the service port
+			podFault := fault
+			podFault.Port = 8080
+			expected := FaultReport{
+				DryRun: tc.dryRun,
+				Targets: []TargetReport{
+					{
+						Namespace: "test-ns",
+						Name:      "pod-1",
+						Port:      8080,
+						Command:   buildHTTPFaultCmd("192.0.2.6", podFault, time.Second, HTTPDisruptionOptions{}),
+					},
+				},
+			}
+
+			if diff := cmp.Diff(expected, report); diff != "" {
+				t.Errorf("report does not match expected:\n%s", diff)
+			}
+
+			if execs := len(executor.GetHistory()); execs != tc.expectedExecs {
+				t.Errorf("expected %d commands executed got %d", tc.expectedExecs, execs)
+			}
+
+			target, err := client.CoreV1().Pods("test-ns").Get(context.TODO(), "pod-1", metav1.GetOptions{})
+			if err != nil {
+				t.Fatalf("unexpected error : %v", err)
+			}
+
+			if agents := len(target.Spec.EphemeralContainers); agents != tc.expectedAgents {
+				t.Errorf("expected %d agents injected got %d", tc.expectedAgents, agents)
+			}
+
+			// faults that cannot be planned are rejected in dry-run mode
+			if !tc.dryRun {
+				return
+			}
+
+			err = d.InjectNetworkFaults(context.TODO(), NetworkFault{}, time.Second)
+			if !errors.Is(err, ErrDryRunNotSupported) {
+				t.Errorf("expected %v injecting network faults got %v", ErrDryRunNotSupported, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,128 @@
+package disruptors
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// CPUStressInjector defines the methods for stressing the CPU of a target
+type CPUStressInjector interface {
+	// StressCPU generates CPU load in the disruptor's targets for the specified duration
+	StressCPU(ctx context.Context, stress CPUStress, duration time.Duration) error
+}
+
+// MemoryStressInjector defines the methods for stressing the memory of a target
+type MemoryStressInjector interface {
+	// StressMemory allocates memory in the disruptor's targets for the specified duration
+	StressMemory(ctx context.Context, stress MemoryStress, duration time.Duration) error
+}
+
+// CPUStress specifies the CPU load to be generated in a target
+type CPUStress struct {
+	// Number of cores to keep busy
+	Cores uint `js:"cores"`
+	// Percentage (in the range 1 to 100) of the time each core is kept busy. Defaults to 100.
+	Load uint `js:"load"`
+	// Container whose CPU the load competes for, as it is generated in its cgroup. Defaults to the first container
+	// of the pod. Ignored by node disruptors.
+	Container string `js:"container"`
+}
+
+// MemoryStress specifies the memory to be allocated in a target
+type MemoryStress struct {
+	// Amount of memory to allocate, expressed as a Kubernetes quantity (e.g. "512Mi")
+	Amount string `js:"amount"`
+	// Amount of memory to allocate, expressed as a percentage (in the range 1 to 100) of the memory limit of the
+	// container. Only one of Amount and Percentage can be specified.
+	Percentage uint `js:"percentage"`
+	// Container in whose cgroup the memory is allocated, so it is accounted to the container and subject to its
+	// memory limit. The limit is also used with Percentage. Defaults to the first container of the pod.
+	// Ignored by node disruptors.
+	Container string `js:"container"`
+	// Time taken to allocate the memory. If not specified, the memory is allocated at once.
+	Ramp time.Duration `js:"ramp"`
+	// Keep allocating memory after reaching the amount, pushing past the memory limit of the container to trigger
+	// an OOM kill. The process killed is selected by the kernel among the processes of the container.
+	TriggerOOM bool `js:"triggerOOM"`
+}
+
+// validateCPUStress checks the CPU stress is valid and sets the defaults for the attributes not specified
+func validateCPUStress(stress CPUStress) (CPUStress, error) {
+	if stress.Cores == 0 {
+		stress.Cores = 1
+	}
+
+	if stress.Load > 100 {
+		return CPUStress{}, fmt.Errorf("load must be in the range [1, 100]")
+	}
+
+	return stress, nil
+}
+
+// validateMemoryStress checks the memory stress is valid
+func validateMemoryStress(stress MemoryStress) error {
+	if stress.Amount == "" && stress.Percentage == 0 {
+		return fmt.Errorf("either memory amount or percentage must be specified")
+	}
+
+	if stress.Amount != "" && stress.Percentage != 0 {
+		return fmt.Errorf("memory amount and percentage cannot be both specified")
+	}
+
+	if stress.Percentage > 100 {
+		return fmt.Errorf("memory percentage must be in the range [1, 100]")
+	}
+
+	if stress.Amount != "" {
+		if _, err := memoryAmount(stress.Amount); err != nil {
+			return err
+		}
+	}
+
+	if stress.Ramp < 0 {
+		return fmt.Errorf("ramp must be a positive duration")
+	}
+
+	return nil
+}
+
+// memoryAmount returns the number of bytes of a memory amount expressed as a Kubernetes quantity
+func memoryAmount(amount string) (uint64, error) {
+	quantity, err := resource.ParseQuantity(amount)
+	if err != nil {
+		return 0, fmt.Errorf("invalid memory amount %q: %w", amount, err)
+	}
+
+	if quantity.Value() <= 0 {
+		return 0, fmt.Errorf("memory amount must be positive")
+	}
+
+	return uint64(quantity.Value()), nil
+}
+
+// memoryStressBytes returns the number of bytes to allocate in the container of the pod, computing the percentage of
+// its memory limit if the stress does not specify an amount
+func memoryStressBytes(stress MemoryStress, pod corev1.Pod, name string) (uint64, error) {
+	if stress.Amount != "" {
+		return memoryAmount(stress.Amount)
+	}
+
+	for _, container := range pod.Spec.Containers {
+		if container.Name != name {
+			continue
+		}
+
+		limit := container.Resources.Limits.Memory()
+		if limit.IsZero() {
+			return 0, fmt.Errorf("container %q in pod %q does not have a memory limit", container.Name, pod.Name)
+		}
+
+		return uint64(limit.Value()) * uint64(stress.Percentage) / 100, nil
+	}
+
+	return 0, fmt.Errorf("container %q not found in pod %q", name, pod.Name)
+}
@@ -0,0 +1,240 @@
+package disruptors
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/grafana/xk6-disruptor/pkg/testutils/command"
+	"github.com/grafana/xk6-disruptor/pkg/testutils/kubernetes/builders"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+func Test_PodStressCPU(t *testing.T) {
+	t.Parallel()
+
+	pod := builders.NewPodBuilder("my-app-pod").
+		WithNamespace("test-ns").
+		WithContainer(builders.NewContainerBuilder("app").Build()).
+		WithContainer(builders.NewContainerBuilder("sidecar").Build()).
+		WithContainerStatus(corev1.ContainerStatus{Name: "app", ContainerID: "containerd://0123"}).
+		WithContainerStatus(corev1.ContainerStatus{Name: "sidecar", ContainerID: "containerd://4567"}).
+		Build()
+
+	testCases := []struct {
+		title             string
+		stress            CPUStress
+		expectedCmd       string
+		expectedContainer string
+		expectError       bool
+	}{
+		{
+			title:             "default cores",
+			stress:            CPUStress{},
+			expectedCmd:       "xk6-disruptor-agent stress cpu -d 60s -c 1 --container-id 0123",
+			expectedContainer: "app",
+			expectError:       false,
+		},
+		{
+			title:             "cores with load",
+			stress:            CPUStress{Cores: 2, Load: 50},
+			expectedCmd:       "xk6-disruptor-agent stress cpu -d 60s -c 2 -l 50 --container-id 0123",
+			expectedContainer: "app",
+			expectError:       false,
+		},
+		{
+			title:             "container",
+			stress:            CPUStress{Container: "sidecar"},
+			expectedCmd:       "xk6-disruptor-agent stress cpu -d 60s -c 1 --container-id 4567",
+			expectedContainer: "sidecar",
+			expectError:       false,
+		},
+		{
+			title:       "load over 100",
+			stress:      CPUStress{Cores: 2, Load: 150},
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			controller := &recordingController{}
+			d := &podDisruptor{controller: controller}
+
+			err := d.StressCPU(context.TODO(), tc.stress, 60*time.Second)
+			if tc.expectError && err == nil {
+				t.Errorf("should had failed")
+				return
+			}
+
+			if !tc.expectError && err != nil {
+				t.Errorf("unexpected error : %v", err)
+				return
+			}
+
+			if tc.expectError {
+				return
+			}
+
+			if len(controller.visitors) != 1 {
+				t.Fatalf("expected 1 visitor got %d", len(controller.visitors))
+			}
+
+			cmds, err := controller.visitors[0].Visit(pod)
+			if err != nil {
+				t.Fatalf("unexpected error : %v", err)
+			}
+
+			// the load is generated in the cgroup of the container
+			if cmds.Container != tc.expectedContainer || !cmds.Cgroup {
+				t.Errorf("expected commands in the cgroup of container %q got %q", tc.expectedContainer, cmds.Container)
+			}
+
+			exec := strings.Join(cmds.Exec, " ")
+			if !command.AssertCmdEquals(exec, tc.expectedCmd) {
+				t.Errorf("expected command: %s got: %s", tc.expectedCmd, exec)
+			}
+
+			cleanup := strings.Join(cmds.Cleanup, " ")
+			if !command.AssertCmdEquals(cleanup, "xk6-disruptor-agent cleanup") {
+				t.Errorf("expected cleanup command: xk6-disruptor-agent cleanup got: %s", cleanup)
+			}
+		})
+	}
+}
+
+func Test_PodMemoryStressVisitor(t *testing.T) {
+	t.Parallel()
+
+	pod := builders.NewPodBuilder("my-app-pod").
+		WithNamespace("test-ns").
+		WithContainer(builders.NewContainerBuilder("app").WithMemoryLimit("512Mi").Build()).
+		WithContainer(builders.NewContainerBuilder("sidecar").WithMemoryLimit("100Mi").Build()).
+		WithContainerStatus(corev1.ContainerStatus{Name: "app", ContainerID: "containerd://0123"}).
+		WithContainerStatus(corev1.ContainerStatus{Name: "sidecar", ContainerID: "containerd://4567"}).
+		Build()
+
+	testCases := []struct {
+		title       string
+		target      corev1.Pod
+		stress      MemoryStress
+		expectedCmd string
+		expectError bool
+	}{
+		{
+			title:       "amount",
+			target:      pod,
+			stress:      MemoryStress{Amount: "1Mi"},
+			expectedCmd: "xk6-disruptor-agent stress memory -d 60s -b 1048576 --container-id 0123",
+			expectError: false,
+		},
+		{
+			title:       "percentage of the first container",
+			target:      pod,
+			stress:      MemoryStress{Percentage: 50},
+			expectedCmd: "xk6-disruptor-agent stress memory -d 60s -b 268435456 --container-id 0123",
+			expectError: false,
+		},
+		{
+			title:       "percentage of a container with ramp and oom",
+			target:      pod,
+			stress:      MemoryStress{Percentage: 100, Container: "sidecar", Ramp: 30 * time.Second, TriggerOOM: true},
+			expectedCmd: "xk6-disruptor-agent stress memory -d 60s -b 104857600 --ramp 30s --oom --container-id 4567",
+			expectError: false,
+		},
+		{
+			title: "container without memory limit",
+			target: builders.NewPodBuilder("my-app-pod").
+				WithNamespace("test-ns").
+				WithContainer(builders.NewContainerBuilder("app").Build()).
+				WithContainerStatus(corev1.ContainerStatus{Name: "app", ContainerID: "containerd://0123"}).
+				Build(),
+			stress:      MemoryStress{Percentage: 50},
+			expectError: true,
+		},
+		{
+			title:       "container not found",
+			target:      pod,
+			stress:      MemoryStress{Percentage: 50, Container: "other"},
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			visitor := PodMemoryStressVisitor{stress: tc.stress, duration: 60 * time.Second}
+			cmds, err := visitor.Visit(tc.target)
+
+			if tc.expectError && err == nil {
+				t.Errorf("should had failed")
+				return
+			}
+
+			if !tc.expectError && err != nil {
+				t.Errorf("unexpected error : %v", err)
+				return
+			}
+
+			exec := strings.Join(cmds.Exec, " ")
+			if !command.AssertCmdEquals(exec, tc.expectedCmd) {
+				t.Errorf("expected command: %s got: %s", tc.expectedCmd, exec)
+			}
+
+			// the memory is allocated in the cgroup of the container
+			if !tc.expectError && !cmds.Cgroup {
+				t.Errorf("expected commands in the cgroup of container %q", cmds.Container)
+			}
+		})
+	}
+}
+
+func Test_InvalidMemoryStress(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		title  string
+		stress MemoryStress
+	}{
+		{
+			title:  "neither amount nor percentage",
+			stress: MemoryStress{},
+		},
+		{
+			title:  "both amount and percentage",
+			stress: MemoryStress{Amount: "1Mi", Percentage: 50},
+		},
+		{
+			title:  "percentage over 100",
+			stress: MemoryStress{Percentage: 150},
+		},
+		{
+			title:  "invalid amount",
+			stress: MemoryStress{Amount: "lots"},
+		},
+		{
+			title:  "negative ramp",
+			stress: MemoryStress{Amount: "1Mi", Ramp: -time.Second},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			d := &podDisruptor{controller: &recordingController{}}
+			err := d.StressMemory(context.TODO(), tc.stress, time.Second)
+			if err == nil {
+				t.Errorf("should had failed")
+			}
+		})
+	}
+}
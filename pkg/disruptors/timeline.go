@@ -0,0 +1,72 @@
+package disruptors
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// TimelineStep defines a step of a Timeline
+type TimelineStep struct {
+	// Offset from the start of the timeline when the step starts
+	Start time.Duration
+	// Run runs the step. The context is cancelled when the timeline is stopped.
+	Run func(ctx context.Context) error
+}
+
+// Timeline runs a sequence of steps on schedule. Steps that overlap run in parallel.
+type Timeline struct {
+	steps []TimelineStep
+}
+
+// NewTimeline returns a Timeline with the given steps
+func NewTimeline(steps []TimelineStep) (*Timeline, error) {
+	if len(steps) == 0 {
+		return nil, fmt.Errorf("timeline must have at least one step")
+	}
+
+	for i, step := range steps {
+		if step.Start < 0 {
+			return nil, fmt.Errorf("start of step %d must not be negative", i)
+		}
+
+		if step.Run == nil {
+			return nil, fmt.Errorf("step %d does not define a function to run", i)
+		}
+	}
+
+	return &Timeline{steps: steps}, nil
+}
+
+// Run starts the timeline and waits for its steps to end. If a step fails or the context is cancelled (for example,
+// because the test ended) the steps that did not start are skipped and the ones running are cancelled.
+// Run returns the error of the first step that failed.
+func (t *Timeline) Run(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	start := time.Now()
+	errCh := make(chan error, len(t.steps))
+	for _, step := range t.steps {
+		go func(step TimelineStep) {
+			select {
+			case <-time.After(time.Until(start.Add(step.Start))):
+			case <-ctx.Done():
+				errCh <- ctx.Err()
+				return
+			}
+
+			errCh <- step.Run(ctx)
+		}(step)
+	}
+
+	var err error
+	for range t.steps {
+		if stepErr := <-errCh; stepErr != nil && err == nil {
+			err = stepErr
+			cancel()
+		}
+	}
+
+	return err
+}
@@ -0,0 +1,353 @@
+package disruptors
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func Test_NewTimeline(t *testing.T) {
+	t.Parallel()
+
+	run := func(_ context.Context) error {
+		return nil
+	}
+
+	testCases := []struct {
+		title       string
+		steps       []TimelineStep
+		expectError bool
+	}{
+		{
+			title: "valid steps",
+			steps: []TimelineStep{
+				{Start: 0, Run: run},
+				{Start: time.Minute, Run: run},
+			},
+		},
+		{
+			title:       "no steps",
+			steps:       []TimelineStep{},
+			expectError: true,
+		},
+		{
+			title: "negative start",
+			steps: []TimelineStep{
+				{Start: -time.Second, Run: run},
+			},
+			expectError: true,
+		},
+		{
+			title: "missing run function",
+			steps: []TimelineStep{
+				{Start: time.Second},
+			},
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := NewTimeline(tc.steps)
+			if tc.expectError && err == nil {
+				t.Errorf("should had failed")
+			}
+
+			if !tc.expectError && err != nil {
+				t.Errorf("unexpected error : %v", err)
+			}
+		})
+	}
+}
+
+// stepRecorder records the steps of a timeline that were run
+type stepRecorder struct {
+	mutex   sync.Mutex
+	started map[string]time.Duration
+	start   time.Time
+}
+
+func newStepRecorder() *stepRecorder {
+	return &stepRecorder{
+		started: map[string]time.Duration{},
+		start:   time.Now(),
+	}
+}
+
+// step returns a TimelineStep that records its start and runs for the given duration, or returns the error
+func (r *stepRecorder) step(name string, start time.Duration, duration time.Duration, err error) TimelineStep {
+	return TimelineStep{
+		Start: start,
+		Run: func(ctx context.Context) error {
+			r.mutex.Lock()
+			r.started[name] = time.Since(r.start)
+			r.mutex.Unlock()
+
+			if err != nil {
+				return err
+			}
+
+			select {
+			case <-time.After(duration):
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		},
+	}
+}
+
+func (r *stepRecorder) wasStarted(name string) (time.Duration, bool) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	offset, found := r.started[name]
+	return offset, found
+}
+
+func Test_TimelineRun(t *testing.T) {
+	t.Parallel()
+
+	recorder := newStepRecorder()
+	timeline, err := NewTimeline([]TimelineStep{
+		recorder.step("first", 0, 200*time.Millisecond, nil),
+		recorder.step("overlapping", 100*time.Millisecond, 200*time.Millisecond, nil),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error : %v", err)
+	}
+
+	err = timeline.Run(context.TODO())
+	if err != nil {
+		t.Fatalf("unexpected error : %v", err)
+	}
+
+	elapsed := time.Since(recorder.start)
+
+	offset, started := recorder.wasStarted("overlapping")
+	if !started {
+		t.Fatalf("step was not started")
+	}
+
+	if offset < 100*time.Millisecond {
+		t.Errorf("step started at %s before its start", offset)
+	}
+
+	// the overlapping step runs in parallel with the first one
+	if elapsed >= 400*time.Millisecond {
+		t.Errorf("steps did not run in parallel: timeline took %s", elapsed)
+	}
+}
+
+func Test_TimelineStops(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		title string
+		// error returned by the failing step. If nil, the timeline is stopped by cancelling the context
+		err error
+	}{
+		{
+			title: "step fails",
+			err:   errors.New("fake error"),
+		},
+		{
+			title: "context cancelled",
+			err:   nil,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			recorder := newStepRecorder()
+			steps := []TimelineStep{
+				recorder.step("running", 0, time.Minute, nil),
+				recorder.step("pending", time.Minute, time.Second, nil),
+			}
+			if tc.err != nil {
+				steps = append(steps, recorder.step("failing", 100*time.Millisecond, 0, tc.err))
+			}
+
+			timeline, err := NewTimeline(steps)
+			if err != nil {
+				t.Fatalf("unexpected error : %v", err)
+			}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			if tc.err == nil {
+				time.AfterFunc(100*time.Millisecond, cancel)
+			}
+
+			errCh := make(chan error, 1)
+			go func() {
+				errCh <- timeline.Run(ctx)
+			}()
+
+			select {
+			case <-time.After(5 * time.Second):
+				t.Fatalf("timeline was not stopped")
+			case err = <-errCh:
+			}
+
+			expected := tc.err
+			if expected == nil {
+				expected = context.Canceled
+			}
+
+			if !errors.Is(err, expected) {
+				t.Errorf("expected error %v got %v", expected, err)
+			}
+
+			if _, started := recorder.wasStarted("pending"); started {
+				t.Errorf("pending step should not have been started")
+			}
+		})
+	}
+}
+
+// fakeDisruptor is a Disruptor that does not implement any fault injector
+type fakeDisruptor struct{}
+
+func (d *fakeDisruptor) Targets(_ context.Context) ([]string, error) {
+	return []string{"pod-1"}, nil
+}
+
+// fakeProtocolDisruptor is a Disruptor that records the protocol faults injected
+type fakeProtocolDisruptor struct {
+	fakeDisruptor
+	mutex sync.Mutex
+	http  []HTTPFault
+}
+
+func (d *fakeProtocolDisruptor) InjectHTTPFaults(
+	_ context.Context,
+	fault HTTPFault,
+	_ time.Duration,
+	_ HTTPDisruptionOptions,
+) error {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	d.http = append(d.http, fault)
+	return nil
+}
+
+func (d *fakeProtocolDisruptor) InjectHTTPFaultsWithReport(
+	ctx context.Context,
+	fault HTTPFault,
+	duration time.Duration,
+	options HTTPDisruptionOptions,
+) (FaultReport, error) {
+	return FaultReport{}, d.InjectHTTPFaults(ctx, fault, duration, options)
+}
+
+func (d *fakeProtocolDisruptor) InjectGrpcFaults(
+	_ context.Context,
+	_ GrpcFault,
+	_ time.Duration,
+	_ GrpcDisruptionOptions,
+) error {
+	return nil
+}
+
+func (d *fakeProtocolDisruptor) InjectGrpcFaultsWithReport(
+	_ context.Context,
+	_ GrpcFault,
+	_ time.Duration,
+	_ GrpcDisruptionOptions,
+) (FaultReport, error) {
+	return FaultReport{}, nil
+}
+
+func Test_FaultStep(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		title     string
+		disruptor Disruptor
+		fault     ScenarioFault
+		// path to the invalid field. Empty if the fault is valid
+		expectedField string
+		// number of http faults expected to be injected
+		expectedFaults int
+	}{
+		{
+			title:     "valid fault",
+			disruptor: &fakeProtocolDisruptor{},
+			fault: ScenarioFault{
+				Type:     "http",
+				Params:   map[string]interface{}{"errorCode": int64(500), "errorRate": 0.1},
+				Duration: 10 * time.Millisecond,
+				Repeats:  2,
+				Interval: 10 * time.Millisecond,
+			},
+			expectedFaults: 2,
+		},
+		{
+			title:     "fault not supported by the disruptor",
+			disruptor: &fakeDisruptor{},
+			fault: ScenarioFault{
+				Type:     "http",
+				Duration: time.Second,
+			},
+			expectedField: "type",
+		},
+		{
+			title:     "invalid parameter",
+			disruptor: &fakeProtocolDisruptor{},
+			fault: ScenarioFault{
+				Type:     "http",
+				Params:   map[string]interface{}{"errorCode": "internal error"},
+				Duration: time.Second,
+			},
+			expectedField: "params.errorCode",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			step, err := FaultStep(tc.disruptor, tc.fault)
+			if tc.expectedField != "" {
+				var scenarioErr *ScenarioError
+				if !errors.As(err, &scenarioErr) {
+					t.Fatalf("expected a ScenarioError got %v", err)
+				}
+
+				if scenarioErr.Field != tc.expectedField {
+					t.Errorf("expected error in field %q got %q", tc.expectedField, scenarioErr.Field)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error : %v", err)
+			}
+
+			err = step.Run(context.TODO())
+			if err != nil {
+				t.Fatalf("unexpected error : %v", err)
+			}
+
+			injected := len(tc.disruptor.(*fakeProtocolDisruptor).http)
+			if injected != tc.expectedFaults {
+				t.Errorf("expected %d faults injected got %d", tc.expectedFaults, injected)
+			}
+		})
+	}
+}
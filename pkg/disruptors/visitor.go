@@ -33,6 +33,7 @@ func (i PodHTTPFaultVisitor) Visit(pod corev1.Pod) (VisitCommands, error) {
 	visitCommands := VisitCommands{
 		Exec:    buildHTTPFaultCmd(targetAddress, i.fault, i.duration, i.options),
 		Cleanup: buildCleanupCmd(),
+		Port:    i.fault.Port,
 	}
 
 	return visitCommands, nil
@@ -59,6 +60,7 @@ func (i PodGrpcFaultVisitor) Visit(pod corev1.Pod) (VisitCommands, error) {
 	visitCommands := VisitCommands{
 		Exec:    buildGrpcFaultCmd(targetAddress, i.fault, i.duration, i.options),
 		Cleanup: buildCleanupCmd(),
+		Port:    i.fault.Port,
 	}
 
 	return visitCommands, nil
@@ -95,6 +97,7 @@ func (i ServiceHTTPFaultVisitor) Visit(pod corev1.Pod) (VisitCommands, error) {
 	visitCommands := VisitCommands{
 		Exec:    buildHTTPFaultCmd(targetAddress, podFault, i.duration, i.options),
 		Cleanup: buildCleanupCmd(),
+		Port:    port,
 	}
 
 	return visitCommands, nil
@@ -126,6 +129,438 @@ func (i ServiceGrpcFaultVisitor) Visit(pod corev1.Pod) (VisitCommands, error) {
 	visitCommands := VisitCommands{
 		Exec:    buildGrpcFaultCmd(targetAddress, podFault, i.duration, i.options),
 		Cleanup: buildCleanupCmd(),
+		Port:    port,
+	}
+
+	return visitCommands, nil
+}
+
+// PodSignalFaultVisitor implements the Visitor interface for injecting SignalFaults in a Pod
+type PodSignalFaultVisitor struct {
+	fault SignalFault
+}
+
+// targetContainer returns the name and ID of the container targeted by a process fault. If the name is empty, the
+// first container of the pod is targeted.
+func targetContainer(pod corev1.Pod, container string) (string, string, error) {
+	if container == "" && len(pod.Spec.Containers) > 0 {
+		container = pod.Spec.Containers[0].Name
+	}
+
+	containerID, err := utils.ContainerID(pod, container)
+	if err != nil {
+		return "", "", err
+	}
+
+	return container, containerID, nil
+}
+
+// Visit return the VisitCommands for injecting a SignalFault in a Pod
+func (i PodSignalFaultVisitor) Visit(pod corev1.Pod) (VisitCommands, error) {
+	container, containerID, err := targetContainer(pod, i.fault.Container)
+	if err != nil {
+		return VisitCommands{}, err
+	}
+
+	visitCommands := VisitCommands{
+		Exec:      buildSignalFaultCmd(containerID, i.fault),
+		Cleanup:   buildCleanupCmd(),
+		Container: container,
+	}
+
+	return visitCommands, nil
+}
+
+// PodFreezeFaultVisitor implements the Visitor interface for injecting FreezeFaults in a Pod
+type PodFreezeFaultVisitor struct {
+	fault    FreezeFault
+	duration time.Duration
+}
+
+// Visit return the VisitCommands for injecting a FreezeFault in a Pod
+func (i PodFreezeFaultVisitor) Visit(pod corev1.Pod) (VisitCommands, error) {
+	container, containerID, err := targetContainer(pod, i.fault.Container)
+	if err != nil {
+		return VisitCommands{}, err
+	}
+
+	// the cleanup command ensures the processes are resumed if the execution of the fault is interrupted.
+	// It is executed by the same agent, as it must also see the processes of the container.
+	visitCommands := VisitCommands{
+		Exec:      buildFreezeFaultCmd(containerID, i.duration),
+		Cleanup:   buildFreezeCleanupCmd(containerID),
+		Container: container,
+	}
+
+	return visitCommands, nil
+}
+
+// PodNetworkFaultVisitor implements the Visitor interface for injecting NetworkFaults in a Pod
+type PodNetworkFaultVisitor struct {
+	fault    NetworkFault
+	duration time.Duration
+}
+
+// Visit return the VisitCommands for injecting a NetworkFault in a Pod
+func (i PodNetworkFaultVisitor) Visit(pod corev1.Pod) (VisitCommands, error) {
+	if utils.HasHostNetwork(pod) {
+		return VisitCommands{}, fmt.Errorf("pod %q cannot be safely injected as it has hostNetwork set to true", pod.Name)
+	}
+
+	// the cleanup command ensures the network faults are removed if the execution of the fault is interrupted
+	visitCommands := VisitCommands{
+		Exec:    buildNetworkFaultCmd(i.fault, i.duration),
+		Cleanup: buildNetworkCleanupCmd(i.fault),
+	}
+
+	return visitCommands, nil
+}
+
+// ServiceNetworkFaultVisitor implements the Visitor interface for injecting NetworkFaults in a Service
+type ServiceNetworkFaultVisitor struct {
+	service  corev1.Service
+	fault    NetworkFault
+	duration time.Duration
+}
+
+// Visit return the VisitCommands for injecting a NetworkFault in a Pod that backs a Service
+func (i ServiceNetworkFaultVisitor) Visit(pod corev1.Pod) (VisitCommands, error) {
+	// copy fault to change the port to the target port of the pod
+	podFault := i.fault
+	if i.fault.Port != 0 {
+		port, err := utils.MapPort(i.service, i.fault.Port, pod)
+		if err != nil {
+			return VisitCommands{}, err
+		}
+		podFault.Port = port
+	}
+
+	return PodNetworkFaultVisitor{fault: podFault, duration: i.duration}.Visit(pod)
+}
+
+// NodeNetworkFaultVisitor implements the Visitor interface for injecting NetworkFaults in the agent pod of a Node
+type NodeNetworkFaultVisitor struct {
+	fault    NetworkFault
+	duration time.Duration
+}
+
+// Visit return the VisitCommands for injecting a NetworkFault in the agent pod of a Node
+func (i NodeNetworkFaultVisitor) Visit(_ corev1.Pod) (VisitCommands, error) {
+	// the cleanup command ensures the network faults are removed if the execution of the fault is interrupted
+	visitCommands := VisitCommands{
+		Exec:    buildNetworkFaultCmd(i.fault, i.duration),
+		Cleanup: buildNetworkCleanupCmd(i.fault),
+	}
+
+	return visitCommands, nil
+}
+
+// NodeCPUStressVisitor implements the Visitor interface for generating CPU load in the agent pod of a Node
+type NodeCPUStressVisitor struct {
+	stress   CPUStress
+	duration time.Duration
+}
+
+// Visit return the VisitCommands for generating CPU load in the agent pod of a Node
+func (i NodeCPUStressVisitor) Visit(_ corev1.Pod) (VisitCommands, error) {
+	visitCommands := VisitCommands{
+		Exec:    buildCPUStressCmd(i.stress, i.duration, ""),
+		Cleanup: buildCleanupCmd(),
+	}
+
+	return visitCommands, nil
+}
+
+// PodCPUStressVisitor implements the Visitor interface for generating CPU load in a Pod
+type PodCPUStressVisitor struct {
+	stress   CPUStress
+	duration time.Duration
+}
+
+// Visit return the VisitCommands for generating CPU load in a Pod. The load is generated in the cgroup of the
+// target container, so it competes for the CPU assigned to the container and is subject to its limits.
+func (i PodCPUStressVisitor) Visit(pod corev1.Pod) (VisitCommands, error) {
+	container, containerID, err := targetContainer(pod, i.stress.Container)
+	if err != nil {
+		return VisitCommands{}, err
+	}
+
+	visitCommands := VisitCommands{
+		Exec:      buildCPUStressCmd(i.stress, i.duration, containerID),
+		Cleanup:   buildCleanupCmd(),
+		Container: container,
+		Cgroup:    true,
+	}
+
+	return visitCommands, nil
+}
+
+// PodMemoryStressVisitor implements the Visitor interface for allocating memory in a Pod
+type PodMemoryStressVisitor struct {
+	stress   MemoryStress
+	duration time.Duration
+}
+
+// Visit return the VisitCommands for allocating memory in a Pod. The memory is allocated in the cgroup of the target
+// container and its amount is computed from the memory limit of the container if the stress is expressed as a
+// percentage.
+func (i PodMemoryStressVisitor) Visit(pod corev1.Pod) (VisitCommands, error) {
+	container, containerID, err := targetContainer(pod, i.stress.Container)
+	if err != nil {
+		return VisitCommands{}, err
+	}
+
+	bytes, err := memoryStressBytes(i.stress, pod, container)
+	if err != nil {
+		return VisitCommands{}, err
+	}
+
+	visitCommands := VisitCommands{
+		Exec:      buildMemoryStressCmd(bytes, i.stress, i.duration, containerID),
+		Cleanup:   buildCleanupCmd(),
+		Container: container,
+		Cgroup:    true,
+	}
+
+	return visitCommands, nil
+}
+
+// NodeMemoryStressVisitor implements the Visitor interface for allocating memory in the agent pod of a Node
+type NodeMemoryStressVisitor struct {
+	bytes    uint64
+	stress   MemoryStress
+	duration time.Duration
+}
+
+// Visit return the VisitCommands for allocating memory in the agent pod of a Node
+func (i NodeMemoryStressVisitor) Visit(_ corev1.Pod) (VisitCommands, error) {
+	visitCommands := VisitCommands{
+		Exec:    buildMemoryStressCmd(i.bytes, i.stress, i.duration, ""),
+		Cleanup: buildCleanupCmd(),
+	}
+
+	return visitCommands, nil
+}
+
+// checkEgressTarget verifies the outgoing traffic of the pod can be safely intercepted
+func checkEgressTarget(pod corev1.Pod) error {
+	if utils.HasHostNetwork(pod) {
+		return fmt.Errorf("pod %q cannot be safely injected as it has hostNetwork set to true", pod.Name)
+	}
+
+	return nil
+}
+
+// PodEgressHTTPFaultVisitor implements the Visitor interface for injecting HttpFaults in the requests a Pod
+// sends to a dependency
+type PodEgressHTTPFaultVisitor struct {
+	dependency Dependency
+	fault      HTTPFault
+	duration   time.Duration
+	options    HTTPDisruptionOptions
+}
+
+// Visit return the VisitCommands for injecting a HttpFault in the requests a Pod sends to a dependency
+func (i PodEgressHTTPFaultVisitor) Visit(pod corev1.Pod) (VisitCommands, error) {
+	if err := checkEgressTarget(pod); err != nil {
+		return VisitCommands{}, err
+	}
+
+	visitCommands := VisitCommands{
+		Exec:    buildEgressHTTPFaultCmd(i.dependency, i.fault, i.duration, i.options),
+		Cleanup: buildCleanupCmd(),
+		Port:    i.dependency.Port,
+	}
+
+	return visitCommands, nil
+}
+
+// PodEgressGrpcFaultVisitor implements the Visitor interface for injecting GrpcFaults in the requests a Pod
+// sends to a dependency
+type PodEgressGrpcFaultVisitor struct {
+	dependency Dependency
+	fault      GrpcFault
+	duration   time.Duration
+	options    GrpcDisruptionOptions
+}
+
+// Visit return the VisitCommands for injecting a GrpcFault in the requests a Pod sends to a dependency
+func (i PodEgressGrpcFaultVisitor) Visit(pod corev1.Pod) (VisitCommands, error) {
+	if err := checkEgressTarget(pod); err != nil {
+		return VisitCommands{}, err
+	}
+
+	visitCommands := VisitCommands{
+		Exec:    buildEgressGrpcFaultCmd(i.dependency, i.fault, i.duration, i.options),
+		Cleanup: buildCleanupCmd(),
+		Port:    i.dependency.Port,
+	}
+
+	return visitCommands, nil
+}
+
+// PodEgressTCPFaultVisitor implements the Visitor interface for injecting TCPFaults in the connections a Pod
+// opens to a dependency
+type PodEgressTCPFaultVisitor struct {
+	dependency Dependency
+	fault      TCPFault
+	duration   time.Duration
+	options    TCPDisruptionOptions
+}
+
+// Visit return the VisitCommands for injecting a TCPFault in the connections a Pod opens to a dependency
+func (i PodEgressTCPFaultVisitor) Visit(pod corev1.Pod) (VisitCommands, error) {
+	if err := checkEgressTarget(pod); err != nil {
+		return VisitCommands{}, err
+	}
+
+	visitCommands := VisitCommands{
+		Exec:    buildEgressTCPFaultCmd(i.dependency, i.fault, i.duration, i.options),
+		Cleanup: buildCleanupCmd(),
+	}
+
+	return visitCommands, nil
+}
+
+// partitionPeers returns the peers of a partition excluding the pod itself, which may also match the peers selector
+func partitionPeers(pod corev1.Pod, peers []string) ([]string, error) {
+	if utils.HasHostNetwork(pod) {
+		return nil, fmt.Errorf("pod %q cannot be safely injected as it has hostNetwork set to true", pod.Name)
+	}
+
+	podPeers := []string{}
+	for _, peer := range peers {
+		if peer != pod.Status.PodIP {
+			podPeers = append(podPeers, peer)
+		}
+	}
+
+	if len(podPeers) == 0 {
+		return nil, fmt.Errorf("pod %q does not have any peer other than itself", pod.Name)
+	}
+
+	return podPeers, nil
+}
+
+// PodPartitionFaultVisitor implements the Visitor interface for isolating a Pod from its peers
+type PodPartitionFaultVisitor struct {
+	fault    PartitionFault
+	peers    []string
+	duration time.Duration
+}
+
+// Visit return the VisitCommands for isolating a Pod from its peers
+func (i PodPartitionFaultVisitor) Visit(pod corev1.Pod) (VisitCommands, error) {
+	peers, err := partitionPeers(pod, i.peers)
+	if err != nil {
+		return VisitCommands{}, err
+	}
+
+	// the cleanup command ensures the connectivity is restored if the execution of the fault is interrupted
+	visitCommands := VisitCommands{
+		Exec:    buildPartitionFaultCmd(i.fault, peers, i.duration),
+		Cleanup: buildPartitionCleanupCmd(),
+	}
+
+	return visitCommands, nil
+}
+
+// PodPartitionUpdateVisitor implements the Visitor interface for updating the peers a Pod is isolated from
+type PodPartitionUpdateVisitor struct {
+	peers []string
+}
+
+// Visit return the VisitCommands for updating the peers a Pod is isolated from
+func (i PodPartitionUpdateVisitor) Visit(pod corev1.Pod) (VisitCommands, error) {
+	peers, err := partitionPeers(pod, i.peers)
+	if err != nil {
+		return VisitCommands{}, err
+	}
+
+	visitCommands := VisitCommands{
+		Exec: buildPartitionUpdateCmd(peers),
+	}
+
+	return visitCommands, nil
+}
+
+// PodDNSFaultVisitor implements the Visitor interface for injecting DNSFaults in the queries sent by a Pod
+type PodDNSFaultVisitor struct {
+	fault    DNSFault
+	duration time.Duration
+	options  DNSDisruptionOptions
+}
+
+// Visit return the VisitCommands for injecting a DNSFault in the queries sent by a Pod
+func (i PodDNSFaultVisitor) Visit(pod corev1.Pod) (VisitCommands, error) {
+	if err := checkEgressTarget(pod); err != nil {
+		return VisitCommands{}, err
+	}
+
+	visitCommands := VisitCommands{
+		Exec:    buildDNSFaultCmd(i.fault, i.duration, i.options),
+		Cleanup: buildCleanupCmd(),
+	}
+
+	return visitCommands, nil
+}
+
+// PodDiskFillFaultVisitor implements the Visitor interface for injecting DiskFillFaults in a Pod
+type PodDiskFillFaultVisitor struct {
+	fault    DiskFillFault
+	duration time.Duration
+}
+
+// Visit return the VisitCommands for injecting a DiskFillFault in a Pod
+func (i PodDiskFillFaultVisitor) Visit(pod corev1.Pod) (VisitCommands, error) {
+	container, containerID, err := targetContainer(pod, i.fault.Container)
+	if err != nil {
+		return VisitCommands{}, err
+	}
+
+	// the cleanup command ensures the fill is removed if the execution of the fault is interrupted.
+	// The agent accesses the filesystem of the container through the root of its main process.
+	visitCommands := VisitCommands{
+		Exec:      buildDiskFillFaultCmd(containerID, i.fault, i.duration),
+		Cleanup:   buildDiskFillCleanupCmd(containerID, i.fault),
+		Container: container,
+	}
+
+	return visitCommands, nil
+}
+
+// PodFDExhaustionFaultVisitor implements the Visitor interface for injecting FDExhaustionFaults in a Pod
+type PodFDExhaustionFaultVisitor struct {
+	fault    FDExhaustionFault
+	duration time.Duration
+	// platform (os/architecture) of the nodes. Pods in nodes not included are visited.
+	platforms map[string]string
+}
+
+// Visit return the VisitCommands for injecting a FDExhaustionFault in a Pod
+func (i PodFDExhaustionFaultVisitor) Visit(pod corev1.Pod) (VisitCommands, error) {
+	// the agent can only inject system calls in the processes of linux/amd64 nodes
+	platform, found := i.platforms[pod.Spec.NodeName]
+	if found && platform != fdExhaustionPlatform {
+		return VisitCommands{}, fmt.Errorf(
+			"file descriptor exhaustion is only supported in %s nodes, but node %q is %s",
+			fdExhaustionPlatform,
+			pod.Spec.NodeName,
+			platform,
+		)
+	}
+
+	container, containerID, err := targetContainer(pod, i.fault.Container)
+	if err != nil {
+		return VisitCommands{}, err
+	}
+
+	// the cleanup command ensures the descriptors are closed if the execution of the fault is interrupted.
+	// The agent opens the descriptors by injecting system calls in the main process of the container.
+	visitCommands := VisitCommands{
+		Exec:      buildFDExhaustionFaultCmd(containerID, i.fault, i.duration),
+		Cleanup:   buildFDExhaustionCleanupCmd(),
+		Container: container,
 	}
 
 	return visitCommands, nil
@@ -13,7 +13,9 @@ import (
 	"github.com/grafana/xk6-disruptor/pkg/testutils/kubernetes/builders"
 
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	k8sruntime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/client-go/kubernetes/fake"
 )
 
@@ -294,6 +296,180 @@ func Test_PodGrpcPFaultVisitor(t *testing.T) {
 	}
 }
 
+func Test_PodSignalFaultVisitor(t *testing.T) {
+	t.Parallel()
+
+	buildPod := func(shareProcessNamespace bool) corev1.Pod {
+		return builders.NewPodBuilder("my-app-pod").
+			WithNamespace("test-ns").
+			WithShareProcessNamespace(shareProcessNamespace).
+			WithContainer(builders.NewContainerBuilder("main").Build()).
+			WithContainer(builders.NewContainerBuilder("sidecar").Build()).
+			WithContainerStatus(corev1.ContainerStatus{Name: "main", ContainerID: "containerd://0123"}).
+			WithContainerStatus(corev1.ContainerStatus{Name: "sidecar", ContainerID: "containerd://4567"}).
+			Build()
+	}
+
+	testCases := []struct {
+		title             string
+		target            corev1.Pod
+		fault             SignalFault
+		expectedCmd       string
+		expectedContainer string
+		expectError       bool
+	}{
+		{
+			title:             "default container and signal",
+			target:            buildPod(true),
+			fault:             SignalFault{},
+			expectedCmd:       "xk6-disruptor-agent signal --container-id 0123",
+			expectedContainer: "main",
+		},
+		{
+			title:             "named container",
+			target:            buildPod(true),
+			fault:             SignalFault{Container: "sidecar", Signal: "SIGTERM"},
+			expectedCmd:       "xk6-disruptor-agent signal --container-id 4567 -s SIGTERM",
+			expectedContainer: "sidecar",
+		},
+		{
+			title:       "container not found",
+			target:      buildPod(true),
+			fault:       SignalFault{Container: "other"},
+			expectError: true,
+		},
+		{
+			title:             "process namespace not shared",
+			target:            buildPod(false),
+			fault:             SignalFault{Container: "sidecar"},
+			expectedCmd:       "xk6-disruptor-agent signal --container-id 4567",
+			expectedContainer: "sidecar",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			visitor := PodSignalFaultVisitor{
+				fault: tc.fault,
+			}
+
+			cmds, err := visitor.Visit(tc.target)
+			if tc.expectError && err == nil {
+				t.Errorf("should had failed")
+				return
+			}
+
+			if !tc.expectError && err != nil {
+				t.Errorf("unexpected error : %v", err)
+				return
+			}
+
+			exec := strings.Join(cmds.Exec, " ")
+			if !command.AssertCmdEquals(exec, tc.expectedCmd) {
+				t.Errorf("expected command: %s got: %s", tc.expectedCmd, exec)
+			}
+
+			if cmds.Container != tc.expectedContainer {
+				t.Errorf("expected container: %s got: %s", tc.expectedContainer, cmds.Container)
+			}
+		})
+	}
+}
+
+func Test_PodFreezeFaultVisitor(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		title             string
+		target            corev1.Pod
+		fault             FreezeFault
+		duration          time.Duration
+		expectedCmd       string
+		expectedCleanup   string
+		expectedContainer string
+		expectError       bool
+	}{
+		{
+			title: "freeze default container",
+			target: builders.NewPodBuilder("my-app-pod").
+				WithNamespace("test-ns").
+				WithShareProcessNamespace(true).
+				WithContainer(builders.NewContainerBuilder("main").Build()).
+				WithContainerStatus(corev1.ContainerStatus{Name: "main", ContainerID: "containerd://0123"}).
+				Build(),
+			fault:             FreezeFault{},
+			duration:          60 * time.Second,
+			expectedCmd:       "xk6-disruptor-agent freeze -d 60s --container-id 0123",
+			expectedCleanup:   "xk6-disruptor-agent cleanup --resume-container-id 0123",
+			expectedContainer: "main",
+		},
+		{
+			title: "process namespace not shared",
+			target: builders.NewPodBuilder("my-app-pod").
+				WithNamespace("test-ns").
+				WithContainer(builders.NewContainerBuilder("main").Build()).
+				WithContainerStatus(corev1.ContainerStatus{Name: "main", ContainerID: "containerd://0123"}).
+				Build(),
+			fault:             FreezeFault{Container: "main"},
+			duration:          60 * time.Second,
+			expectedCmd:       "xk6-disruptor-agent freeze -d 60s --container-id 0123",
+			expectedCleanup:   "xk6-disruptor-agent cleanup --resume-container-id 0123",
+			expectedContainer: "main",
+		},
+		{
+			title: "container not found",
+			target: builders.NewPodBuilder("my-app-pod").
+				WithNamespace("test-ns").
+				WithContainer(builders.NewContainerBuilder("main").Build()).
+				WithContainerStatus(corev1.ContainerStatus{Name: "main", ContainerID: "containerd://0123"}).
+				Build(),
+			fault:       FreezeFault{Container: "other"},
+			duration:    60 * time.Second,
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			visitor := PodFreezeFaultVisitor{
+				fault:    tc.fault,
+				duration: tc.duration,
+			}
+
+			cmds, err := visitor.Visit(tc.target)
+			if tc.expectError && err == nil {
+				t.Errorf("should had failed")
+				return
+			}
+
+			if !tc.expectError && err != nil {
+				t.Errorf("unexpected error : %v", err)
+				return
+			}
+
+			exec := strings.Join(cmds.Exec, " ")
+			if !command.AssertCmdEquals(exec, tc.expectedCmd) {
+				t.Errorf("expected command: %s got: %s", tc.expectedCmd, exec)
+			}
+
+			cleanup := strings.Join(cmds.Cleanup, " ")
+			if !command.AssertCmdEquals(cleanup, tc.expectedCleanup) {
+				t.Errorf("expected cleanup command: %s got: %s", tc.expectedCleanup, cleanup)
+			}
+
+			if cmds.Container != tc.expectedContainer {
+				t.Errorf("expected container: %s got: %s", tc.expectedContainer, cmds.Container)
+			}
+		})
+	}
+}
+
 func Test_NewPodDisruptor(t *testing.T) {
 	t.Parallel()
 
@@ -301,6 +477,7 @@ func Test_NewPodDisruptor(t *testing.T) {
 		title       string
 		name        string
 		namespace   string
+		namespaces  []corev1.Namespace
 		pods        []corev1.Pod
 		selector    PodSelector
 		expectError bool
@@ -339,6 +516,127 @@ func Test_NewPodDisruptor(t *testing.T) {
 			},
 			expectError: true,
 		},
+		{
+			title:     "pods of a workload",
+			name:      "test-svc",
+			namespace: "test-ns",
+			pods: []corev1.Pod{
+				builders.NewPodBuilder("db-0").
+					WithNamespace("test-ns").
+					WithOwnerReference("StatefulSet", "db").
+					Build(),
+				builders.NewPodBuilder("cache-0").
+					WithNamespace("test-ns").
+					WithOwnerReference("StatefulSet", "cache").
+					Build(),
+			},
+			selector: PodSelector{
+				Namespace: "test-ns",
+				Owner:     PodOwner{Kind: "StatefulSet", Name: "db"},
+			},
+			expectError: false,
+			expected:    []string{"db-0"},
+		},
+		{
+			title:     "owner without name",
+			name:      "test-svc",
+			namespace: "test-ns",
+			pods: []corev1.Pod{
+				builders.NewPodBuilder("db-0").
+					WithNamespace("test-ns").
+					WithOwnerReference("StatefulSet", "db").
+					Build(),
+			},
+			selector: PodSelector{
+				Namespace: "test-ns",
+				Owner:     PodOwner{Kind: "StatefulSet"},
+			},
+			expectError: true,
+		},
+		{
+			title: "pods in multiple namespaces",
+			name:  "test-svc",
+			pods: []corev1.Pod{
+				builders.NewPodBuilder("pod-1").
+					WithNamespace("ns-1").
+					WithLabel("app", "test").
+					Build(),
+				builders.NewPodBuilder("pod-2").
+					WithNamespace("ns-2").
+					WithLabel("app", "test").
+					Build(),
+				builders.NewPodBuilder("pod-3").
+					WithNamespace("ns-3").
+					WithLabel("app", "test").
+					Build(),
+			},
+			selector: PodSelector{
+				Namespaces: []string{"ns-1", "ns-2"},
+				Select: PodAttributes{Labels: map[string]string{
+					"app": "test",
+				}},
+			},
+			expectError: false,
+			expected:    []string{"ns-1/pod-1", "ns-2/pod-2"},
+		},
+		{
+			title: "pods in labeled namespaces",
+			name:  "test-svc",
+			namespaces: []corev1.Namespace{
+				{ObjectMeta: metav1.ObjectMeta{Name: "ns-1", Labels: map[string]string{"team": "payments"}}},
+				{ObjectMeta: metav1.ObjectMeta{Name: "ns-2", Labels: map[string]string{"team": "payments"}}},
+				{ObjectMeta: metav1.ObjectMeta{Name: "ns-3", Labels: map[string]string{"team": "search"}}},
+			},
+			pods: []corev1.Pod{
+				builders.NewPodBuilder("pod-1").
+					WithNamespace("ns-1").
+					Build(),
+				builders.NewPodBuilder("pod-2").
+					WithNamespace("ns-2").
+					Build(),
+				builders.NewPodBuilder("pod-3").
+					WithNamespace("ns-3").
+					Build(),
+			},
+			selector: PodSelector{
+				NamespaceLabels: map[string]string{"team": "payments"},
+			},
+			expectError: false,
+			expected:    []string{"ns-1/pod-1", "ns-2/pod-2"},
+		},
+		{
+			title: "single labeled namespace",
+			name:  "test-svc",
+			namespaces: []corev1.Namespace{
+				{ObjectMeta: metav1.ObjectMeta{Name: "ns-1", Labels: map[string]string{"team": "payments"}}},
+			},
+			pods: []corev1.Pod{
+				builders.NewPodBuilder("pod-1").
+					WithNamespace("ns-1").
+					Build(),
+			},
+			selector: PodSelector{
+				NamespaceLabels: map[string]string{"team": "payments"},
+			},
+			expectError: false,
+			expected:    []string{"pod-1"},
+		},
+		{
+			title: "no labeled namespaces",
+			name:  "test-svc",
+			namespaces: []corev1.Namespace{
+				{ObjectMeta: metav1.ObjectMeta{Name: "ns-1", Labels: map[string]string{"team": "search"}}},
+			},
+			pods: []corev1.Pod{
+				builders.NewPodBuilder("pod-1").
+					WithNamespace("ns-1").
+					Build(),
+			},
+			selector: PodSelector{
+				NamespaceLabels: map[string]string{"team": "payments"},
+			},
+			expectError: true,
+		},
 	}
 
 	for _, tc := range testCases {
@@ -348,6 +646,9 @@ func Test_NewPodDisruptor(t *testing.T) {
 			t.Parallel()
 
 			var objs []k8sruntime.Object
+			for n := range tc.namespaces {
+				objs = append(objs, &tc.namespaces[n])
+			}
 			for p := range tc.pods {
 				objs = append(objs, &tc.pods[p])
 			}
@@ -402,7 +703,7 @@ func Test_PodSelectorString(t *testing.T) {
 			name: "Only inclusions",
 			selector: PodSelector{
 				Namespace: "testns",
-				Select:    PodAttributes{map[string]string{"foo": "bar"}},
+				Select:    PodAttributes{Labels: map[string]string{"foo": "bar"}},
 			},
 			expected: `pods including(foo=bar) in ns "testns"`,
 		},
@@ -410,7 +711,7 @@ func Test_PodSelectorString(t *testing.T) {
 			name: "Only exclusions",
 			selector: PodSelector{
 				Namespace: "testns",
-				Exclude:   PodAttributes{map[string]string{"foo": "bar"}},
+				Exclude:   PodAttributes{Labels: map[string]string{"foo": "bar"}},
 			},
 			expected: `pods excluding(foo=bar) in ns "testns"`,
 		},
@@ -418,11 +719,67 @@ func Test_PodSelectorString(t *testing.T) {
 			name: "Both inclusions and exclusions",
 			selector: PodSelector{
 				Namespace: "testns",
-				Select:    PodAttributes{map[string]string{"foo": "bar"}},
-				Exclude:   PodAttributes{map[string]string{"boo": "baa"}},
+				Select:    PodAttributes{Labels: map[string]string{"foo": "bar"}},
+				Exclude:   PodAttributes{Labels: map[string]string{"boo": "baa"}},
 			},
 			expected: `pods including(foo=bar), excluding(boo=baa) in ns "testns"`,
 		},
+		{
+			name: "Label expressions",
+			selector: PodSelector{
+				Namespace: "testns",
+				Select: PodAttributes{Expressions: []LabelExpression{
+					{Key: "tier", Operator: "In", Values: []string{"api", "worker"}},
+				}},
+				Exclude: PodAttributes{Expressions: []LabelExpression{
+					{Key: "canary", Operator: "Exists"},
+				}},
+			},
+			expected: `pods including(tier in (api, worker)), excluding(canary) in ns "testns"`,
+		},
+		{
+			name: "Owner",
+			selector: PodSelector{
+				Namespace: "testns",
+				Owner:     PodOwner{Kind: "Deployment", Name: "app"},
+				Exclude:   PodAttributes{Labels: map[string]string{"canary": "true"}},
+			},
+			expected: `pods owned by Deployment/app, excluding(canary=true) in ns "testns"`,
+		},
+		{
+			name: "Only owner",
+			selector: PodSelector{
+				Namespace: "testns",
+				Owner:     PodOwner{Kind: "Deployment", Name: "app"},
+			},
+			expected: `pods owned by Deployment/app in ns "testns"`,
+		},
+		{
+			name: "Annotations, phases and conditions",
+			selector: PodSelector{
+				Namespace: "testns",
+				Select:    PodAttributes{Phases: []string{"Running"}, Conditions: []string{"Ready"}},
+				Exclude:   PodAttributes{Annotations: map[string]string{"chaos": "skip"}},
+			},
+			expected: `pods including(Running, Ready), excluding(chaos=skip) in ns "testns"`,
+		},
+		{
+			name: "Multiple namespaces",
+			selector: PodSelector{
+				Namespace:  "testns",
+				Namespaces: []string{"otherns"},
+				Select:     PodAttributes{Labels: map[string]string{"foo": "bar"}},
+			},
+			expected: `pods including(foo=bar) in ns "testns", "otherns"`,
+		},
+		{
+			name: "Namespace labels",
+			selector: PodSelector{
+				Namespaces:      []string{"testns"},
+				NamespaceLabels: map[string]string{"team": "payments"},
+			},
+			expected: `all pods in ns "testns" and namespaces labeled team=payments`,
+		},
 	} {
 		tc := tc
 		t.Run(tc.name, func(t *testing.T) {
@@ -435,3 +792,365 @@ func Test_PodSelectorString(t *testing.T) {
 		})
 	}
 }
+
+func Test_NodeVisitors(t *testing.T) {
+	t.Parallel()
+
+	agent := builders.NewPodBuilder("xk6-agent-node-1").
+		WithNamespace("default").
+		Build()
+
+	testCases := []struct {
+		title           string
+		visitor         PodVisitor
+		expectedCmd     string
+		expectedCleanup string
+	}{
+		{
+			title: "network delay",
+			visitor: NodeNetworkFaultVisitor{
+				fault: NetworkFault{
+					AverageDelay:   100 * time.Millisecond,
+					DelayVariation: 10 * time.Millisecond,
+				},
+				duration: 60 * time.Second,
+			},
+			expectedCmd:     "xk6-disruptor-agent network -d 60s -a 100ms -v 10ms",
+			expectedCleanup: "xk6-disruptor-agent cleanup --reset-network",
+		},
+		{
+			title: "network loss in interface",
+			visitor: NodeNetworkFaultVisitor{
+				fault: NetworkFault{
+					Interface: "eth1",
+					LossRate:  0.1,
+				},
+				duration: 60 * time.Second,
+			},
+			expectedCmd:     "xk6-disruptor-agent network -d 60s -i eth1 -l 0.1",
+			expectedCleanup: "xk6-disruptor-agent cleanup --reset-network -i eth1",
+		},
+		{
+			title: "cpu stress",
+			visitor: NodeCPUStressVisitor{
+				stress:   CPUStress{Cores: 2},
+				duration: 60 * time.Second,
+			},
+			expectedCmd:     "xk6-disruptor-agent stress cpu -d 60s -c 2",
+			expectedCleanup: "xk6-disruptor-agent cleanup",
+		},
+		{
+			title: "memory stress",
+			visitor: NodeMemoryStressVisitor{
+				bytes:    1048576,
+				duration: 60 * time.Second,
+			},
+			expectedCmd:     "xk6-disruptor-agent stress memory -d 60s -b 1048576",
+			expectedCleanup: "xk6-disruptor-agent cleanup",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			cmds, err := tc.visitor.Visit(agent)
+			if err != nil {
+				t.Errorf("unexpected error : %v", err)
+				return
+			}
+
+			exec := strings.Join(cmds.Exec, " ")
+			if !command.AssertCmdEquals(exec, tc.expectedCmd) {
+				t.Errorf("expected command: %s got: %s", tc.expectedCmd, exec)
+			}
+
+			cleanup := strings.Join(cmds.Cleanup, " ")
+			if !command.AssertCmdEquals(cleanup, tc.expectedCleanup) {
+				t.Errorf("expected cleanup command: %s got: %s", tc.expectedCleanup, cleanup)
+			}
+		})
+	}
+}
+
+func Test_PodEgressFaultVisitors(t *testing.T) {
+	t.Parallel()
+
+	dependency := Dependency{Host: "api.example.com", Port: 443}
+
+	testCases := []struct {
+		title       string
+		target      corev1.Pod
+		visitor     PodVisitor
+		expectedCmd string
+		expectError bool
+	}{
+		{
+			title:  "http errors",
+			target: buildPodWithPort("my-app-pod", "http", 80),
+			visitor: PodEgressHTTPFaultVisitor{
+				dependency: dependency,
+				fault: HTTPFault{
+					ErrorRate: 0.1,
+					ErrorCode: 500,
+				},
+				duration: 60 * time.Second,
+			},
+			//nolint:lll
+			expectedCmd: "xk6-disruptor-agent egress http -d 60s --destination api.example.com --destination-port 443 -e 500 -r 0.1",
+			expectError: false,
+		},
+		{
+			title:  "grpc delay with proxy port",
+			target: buildPodWithPort("my-app-pod", "http", 80),
+			visitor: PodEgressGrpcFaultVisitor{
+				dependency: dependency,
+				fault: GrpcFault{
+					AverageDelay: 100 * time.Millisecond,
+				},
+				duration: 60 * time.Second,
+				options:  GrpcDisruptionOptions{ProxyPort: 9000},
+			},
+			//nolint:lll
+			expectedCmd: "xk6-disruptor-agent egress grpc -d 60s --destination api.example.com --destination-port 443 -a 100ms -v 0ms -p 9000",
+			expectError: false,
+		},
+		{
+			title:  "tcp resets",
+			target: buildPodWithPort("my-app-pod", "http", 80),
+			visitor: PodEgressTCPFaultVisitor{
+				dependency: Dependency{Host: "10.0.0.0/8", Port: 5432},
+				fault: TCPFault{
+					ErrorRate: 0.5,
+				},
+				duration: 60 * time.Second,
+			},
+			expectedCmd: "xk6-disruptor-agent egress tcp -d 60s --destination 10.0.0.0/8 --destination-port 5432 -r 0.5",
+			expectError: false,
+		},
+		{
+			title: "pod with hostNetwork",
+			target: builders.NewPodBuilder("hostnet").
+				WithNamespace("test-ns").
+				WithHostNetwork(true).
+				Build(),
+			visitor: PodEgressTCPFaultVisitor{
+				dependency: dependency,
+				duration:   60 * time.Second,
+			},
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			cmds, err := tc.visitor.Visit(tc.target)
+
+			if tc.expectError && err == nil {
+				t.Errorf("should had failed")
+				return
+			}
+
+			if !tc.expectError && err != nil {
+				t.Errorf("unexpected error : %v", err)
+				return
+			}
+
+			exec := strings.Join(cmds.Exec, " ")
+			if !command.AssertCmdEquals(exec, tc.expectedCmd) {
+				t.Errorf("expected command: %s got: %s", tc.expectedCmd, exec)
+			}
+		})
+	}
+}
+
+func Test_NetworkFaultVisitors(t *testing.T) {
+	t.Parallel()
+
+	service := builders.NewServiceBuilder("my-service").
+		WithNamespace("test-ns").
+		WithPort("http", 8080, intstr.FromString("http")).
+		Build()
+
+	testCases := []struct {
+		title           string
+		target          corev1.Pod
+		visitor         PodVisitor
+		expectedCmd     string
+		expectedCleanup string
+		expectError     bool
+	}{
+		{
+			title:  "pod network faults",
+			target: buildPodWithPort("my-app-pod", "http", 80),
+			visitor: PodNetworkFaultVisitor{
+				fault: NetworkFault{
+					AverageDelay:     100 * time.Millisecond,
+					DelayVariation:   10 * time.Millisecond,
+					DelayCorrelation: 0.25,
+					DuplicationRate:  0.01,
+					CorruptionRate:   0.001,
+					ReorderRate:      0.5,
+					Peer:             "10.0.0.0/8",
+				},
+				duration: 60 * time.Second,
+			},
+			//nolint:lll
+			expectedCmd:     "xk6-disruptor-agent network -d 60s -a 100ms -v 10ms --delay-correlation 0.25 --duplication-rate 0.01 --corruption-rate 0.001 --reorder-rate 0.5 --peer 10.0.0.0/8",
+			expectedCleanup: "xk6-disruptor-agent cleanup --reset-network",
+			expectError:     false,
+		},
+		{
+			title: "pod with hostNetwork",
+			target: builders.NewPodBuilder("hostnet").
+				WithNamespace("test-ns").
+				WithHostNetwork(true).
+				Build(),
+			visitor: PodNetworkFaultVisitor{
+				fault:    NetworkFault{LossRate: 0.1},
+				duration: 60 * time.Second,
+			},
+			expectError: true,
+		},
+		{
+			title:  "service port mapped to target port",
+			target: buildPodWithPort("my-app-pod", "http", 80),
+			visitor: ServiceNetworkFaultVisitor{
+				service: service,
+				fault: NetworkFault{
+					LossRate: 0.1,
+					Port:     8080,
+				},
+				duration: 60 * time.Second,
+			},
+			expectedCmd:     "xk6-disruptor-agent network -d 60s -l 0.1 --port 80",
+			expectedCleanup: "xk6-disruptor-agent cleanup --reset-network",
+			expectError:     false,
+		},
+		{
+			title:  "service without port",
+			target: buildPodWithPort("my-app-pod", "http", 80),
+			visitor: ServiceNetworkFaultVisitor{
+				service: service,
+				fault: NetworkFault{
+					LossRate: 0.1,
+				},
+				duration: 60 * time.Second,
+			},
+			expectedCmd:     "xk6-disruptor-agent network -d 60s -l 0.1",
+			expectedCleanup: "xk6-disruptor-agent cleanup --reset-network",
+			expectError:     false,
+		},
+		{
+			title:  "service port not exposed",
+			target: buildPodWithPort("my-app-pod", "http", 80),
+			visitor: ServiceNetworkFaultVisitor{
+				service: service,
+				fault: NetworkFault{
+					LossRate: 0.1,
+					Port:     9090,
+				},
+				duration: 60 * time.Second,
+			},
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			cmds, err := tc.visitor.Visit(tc.target)
+
+			if tc.expectError && err == nil {
+				t.Errorf("should had failed")
+				return
+			}
+
+			if !tc.expectError && err != nil {
+				t.Errorf("unexpected error : %v", err)
+				return
+			}
+
+			exec := strings.Join(cmds.Exec, " ")
+			if !command.AssertCmdEquals(exec, tc.expectedCmd) {
+				t.Errorf("expected command: %s got: %s", tc.expectedCmd, exec)
+			}
+
+			cleanup := strings.Join(cmds.Cleanup, " ")
+			if !command.AssertCmdEquals(cleanup, tc.expectedCleanup) {
+				t.Errorf("expected cleanup command: %s got: %s", tc.expectedCleanup, cleanup)
+			}
+		})
+	}
+}
+
+func Test_RemainingVisitor(t *testing.T) {
+	t.Parallel()
+
+	pod := builders.NewPodBuilder("my-app-pod").
+		WithNamespace("test-ns").
+		WithContainer(builders.NewContainerBuilder("main").Build()).
+		WithContainerStatus(corev1.ContainerStatus{Name: "main", ContainerID: "containerd://0123"}).
+		Build()
+
+	testCases := []struct {
+		title             string
+		duration          time.Duration
+		elapsed           time.Duration
+		expectedCmd       string
+		expectedRemaining bool
+	}{
+		{
+			title:             "time remaining",
+			duration:          60 * time.Second,
+			elapsed:           15 * time.Second,
+			expectedCmd:       "xk6-disruptor-agent freeze -d 45s --container-id 0123",
+			expectedRemaining: true,
+		},
+		{
+			title:             "fault about to end",
+			duration:          60 * time.Second,
+			elapsed:           59500 * time.Millisecond,
+			expectedRemaining: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			visitor := timedVisitor{
+				duration: tc.duration,
+				build: func(duration time.Duration) PodVisitor {
+					return PodFreezeFaultVisitor{duration: duration}
+				},
+			}
+
+			remaining, ok := visitor.remaining(tc.elapsed)
+			if ok != tc.expectedRemaining {
+				t.Errorf("expected remaining %t got %t", tc.expectedRemaining, ok)
+			}
+
+			if !ok {
+				return
+			}
+
+			cmds, err := remaining.Visit(pod)
+			if err != nil {
+				t.Fatalf("failed: %v", err)
+			}
+
+			exec := strings.Join(cmds.Exec, " ")
+			if !command.AssertCmdEquals(exec, tc.expectedCmd) {
+				t.Errorf("expected command: %s got: %s", tc.expectedCmd, exec)
+			}
+		})
+	}
+}
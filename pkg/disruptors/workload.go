@@ -0,0 +1,358 @@
+package disruptors
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/grafana/xk6-disruptor/pkg/kubernetes"
+
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+)
+
+// originalReplicasAnnotation is the annotation where the WorkloadDisruptor keeps the replicas of a workload while it
+// is scaled down. If the k6 process is killed while the workload is scaled down, the annotation allows the next
+// execution of the disruptor to scale the workload relative to its original replicas and restore them at its end.
+const originalReplicasAnnotation = "xk6-disruptor.grafana.com/original-replicas"
+
+// restoreTimeout is the time allowed for restoring the original replicas of the workload when the fault ends
+const restoreTimeout = 30 * time.Second
+
+// Kinds of workloads supported by the WorkloadDisruptor
+const (
+	KindDeployment  = "Deployment"
+	KindStatefulSet = "StatefulSet"
+	KindReplicaSet  = "ReplicaSet"
+)
+
+// WorkloadDisruptor defines the types of faults that can be injected in a workload
+type WorkloadDisruptor interface {
+	Disruptor
+	ReplicaFaultInjector
+}
+
+// ReplicaFaultInjector defines the methods for disrupting the replicas of a workload
+type ReplicaFaultInjector interface {
+	// ScaleReplicas scales down the disruptor's target workload for the specified duration. The original replicas
+	// are restored afterwards, even if the context is cancelled, as it is when the test is aborted. If the k6 process
+	// is killed before the fault ends, the workload remains scaled down until the fault is injected again in it.
+	ScaleReplicas(ctx context.Context, fault ReplicaFault, duration time.Duration) error
+}
+
+// WorkloadSelector identifies the workload targeted by a WorkloadDisruptor
+type WorkloadSelector struct {
+	// Kind of the workload: "Deployment", "StatefulSet" or "ReplicaSet"
+	Kind string `js:"kind"`
+	// Name of the workload
+	Name string `js:"name"`
+	// Namespace of the workload. If empty, the default namespace is used.
+	Namespace string `js:"namespace"`
+}
+
+// NamespaceOrDefault returns the configured namespace for this selector, and the name of the default namespace if it
+// is not configured.
+func (s WorkloadSelector) NamespaceOrDefault() string {
+	if s.Namespace != "" {
+		return s.Namespace
+	}
+
+	return metav1.NamespaceDefault
+}
+
+// String returns a human-readable description of the workload identified by a WorkloadSelector.
+func (s WorkloadSelector) String() string {
+	return fmt.Sprintf("%s %q in ns %q", s.Kind, s.Name, s.NamespaceOrDefault())
+}
+
+// PodSelector returns a PodSelector for the pods controlled by the workload
+func (s WorkloadSelector) PodSelector() PodSelector {
+	return PodSelector{
+		Namespace: s.NamespaceOrDefault(),
+		Owner:     PodOwner{Kind: s.Kind, Name: s.Name},
+	}
+}
+
+// ReplicaFault specifies the replicas a workload is scaled down to. If Fraction is set, the workload is scaled to
+// that fraction of its original replicas. Otherwise, it is scaled to Replicas, which can be zero.
+type ReplicaFault struct {
+	// Number of replicas the workload is scaled to
+	Replicas uint `js:"replicas"`
+	// Fraction (in the range 0.0 to 1.0) of the original replicas the workload is scaled to, rounded down
+	Fraction float32 `js:"fraction"`
+}
+
+// scaleClient is the client of the scale subresource of a kind of workload
+type scaleClient interface {
+	GetScale(ctx context.Context, name string, options metav1.GetOptions) (*autoscalingv1.Scale, error)
+	UpdateScale(
+		ctx context.Context,
+		name string,
+		scale *autoscalingv1.Scale,
+		opts metav1.UpdateOptions,
+	) (*autoscalingv1.Scale, error)
+}
+
+// workloadDisruptor is an instance of a WorkloadDisruptor initialized with its target workload
+type workloadDisruptor struct {
+	k8s      kubernetes.Kubernetes
+	selector WorkloadSelector
+}
+
+// NewWorkloadDisruptor creates a new instance of a WorkloadDisruptor that acts on the workload identified by the
+// given WorkloadSelector
+func NewWorkloadDisruptor(
+	ctx context.Context,
+	k8s kubernetes.Kubernetes,
+	selector WorkloadSelector,
+) (WorkloadDisruptor, error) {
+	if selector.Name == "" {
+		return nil, fmt.Errorf("workload name must be specified")
+	}
+
+	switch selector.Kind {
+	case KindDeployment, KindStatefulSet, KindReplicaSet:
+	default:
+		return nil, fmt.Errorf("unsupported workload kind %q", selector.Kind)
+	}
+
+	d := &workloadDisruptor{
+		k8s:      k8s,
+		selector: selector,
+	}
+
+	_, err := d.getAnnotations(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("getting %s: %w", selector, err)
+	}
+
+	return d, nil
+}
+
+// Targets returns the name of the target workload
+func (d *workloadDisruptor) Targets(_ context.Context) ([]string, error) {
+	return []string{d.selector.Name}, nil
+}
+
+// validateReplicaFault validates a ReplicaFault
+func validateReplicaFault(fault ReplicaFault) error {
+	if fault.Fraction < 0 || fault.Fraction >= 1 {
+		return fmt.Errorf("fraction must be in the range [0, 1)")
+	}
+
+	if fault.Fraction > 0 && fault.Replicas > 0 {
+		return fmt.Errorf("replicas and fraction cannot both be specified")
+	}
+
+	return nil
+}
+
+// scaledReplicas returns the replicas a workload with the given original replicas is scaled to by the fault
+func scaledReplicas(fault ReplicaFault, original int32) int32 {
+	if fault.Fraction > 0 {
+		return int32(math.Floor(float64(original) * float64(fault.Fraction)))
+	}
+
+	return int32(fault.Replicas)
+}
+
+// ScaleReplicas scales down the target workload for the given duration and restores its original replicas afterwards
+func (d *workloadDisruptor) ScaleReplicas(ctx context.Context, fault ReplicaFault, duration time.Duration) error {
+	if err := validateReplicaFault(fault); err != nil {
+		return err
+	}
+
+	scaling, err := d.scaleDown(ctx, fault)
+	if !scaling {
+		return err
+	}
+
+	if err == nil {
+		select {
+		case <-time.After(duration):
+		case <-ctx.Done():
+			err = ctx.Err()
+		}
+	}
+
+	// the replicas are restored even if scaling down failed, for instance because the context was cancelled when the
+	// test was aborted. A fresh context is used because the context may have been cancelled.
+	restoreCtx, cancel := context.WithTimeout(context.Background(), restoreTimeout)
+	defer cancel()
+
+	//nolint:contextcheck
+	restoreErr := d.restoreReplicas(restoreCtx)
+	if restoreErr != nil && err == nil {
+		err = restoreErr
+	}
+
+	return err
+}
+
+// scaleDown scales the workload down as specified by the fault, keeping its original replicas in the original
+// replicas annotation. It returns whether it started scaling the workload down, in which case the replicas must be
+// restored even if it fails.
+func (d *workloadDisruptor) scaleDown(ctx context.Context, fault ReplicaFault) (bool, error) {
+	annotations, err := d.getAnnotations(ctx)
+	if err != nil {
+		return false, fmt.Errorf("getting %s: %w", d.selector, err)
+	}
+
+	scale, err := d.scales().GetScale(ctx, d.selector.Name, metav1.GetOptions{})
+	if err != nil {
+		return false, fmt.Errorf("getting scale of %s: %w", d.selector, err)
+	}
+
+	original := scale.Spec.Replicas
+	// the workload may have been left scaled down by a disruptor terminated abruptly
+	value, found := annotations[originalReplicasAnnotation]
+	if found {
+		parsed, perr := strconv.ParseInt(value, 10, 32)
+		if perr != nil {
+			return false, fmt.Errorf("invalid %s annotation %q", originalReplicasAnnotation, value)
+		}
+		original = int32(parsed)
+	}
+
+	scaled := scaledReplicas(fault, original)
+	if scaled >= original {
+		return false, fmt.Errorf("%s has %d replicas, cannot be scaled down to %d", d.selector, original, scaled)
+	}
+
+	// the original replicas are kept before scaling down, so they are known if the scaling is interrupted
+	if !found {
+		value = strconv.Itoa(int(original))
+		if err = d.annotateOriginalReplicas(ctx, &value); err != nil {
+			return false, err
+		}
+	}
+
+	return true, d.updateScale(ctx, scaled)
+}
+
+// restoreReplicas restores the original replicas of the workload, if it was scaled down by a disruptor
+func (d *workloadDisruptor) restoreReplicas(ctx context.Context) error {
+	annotations, err := d.getAnnotations(ctx)
+	if err != nil {
+		return fmt.Errorf("getting %s: %w", d.selector, err)
+	}
+
+	value, found := annotations[originalReplicasAnnotation]
+	if !found {
+		return nil
+	}
+
+	original, err := strconv.ParseInt(value, 10, 32)
+	if err != nil {
+		return fmt.Errorf("invalid %s annotation %q", originalReplicasAnnotation, value)
+	}
+
+	if err = d.updateScale(ctx, int32(original)); err != nil {
+		return err
+	}
+
+	return d.annotateOriginalReplicas(ctx, nil)
+}
+
+// scales returns the client of the scale subresource of the workload
+func (d *workloadDisruptor) scales() scaleClient {
+	apps := d.k8s.Client().AppsV1()
+	namespace := d.selector.NamespaceOrDefault()
+
+	switch d.selector.Kind {
+	case KindStatefulSet:
+		return apps.StatefulSets(namespace)
+	case KindReplicaSet:
+		return apps.ReplicaSets(namespace)
+	default:
+		return apps.Deployments(namespace)
+	}
+}
+
+// updateScale sets the replicas of the workload through its scale subresource, retrying if the workload is modified
+// concurrently
+func (d *workloadDisruptor) updateScale(ctx context.Context, replicas int32) error {
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		scale, err := d.scales().GetScale(ctx, d.selector.Name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+
+		scale.Spec.Replicas = replicas
+		_, err = d.scales().UpdateScale(ctx, d.selector.Name, scale, metav1.UpdateOptions{})
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("scaling %s: %w", d.selector, err)
+	}
+
+	return nil
+}
+
+// getAnnotations returns the annotations of the workload
+func (d *workloadDisruptor) getAnnotations(ctx context.Context) (map[string]string, error) {
+	apps := d.k8s.Client().AppsV1()
+	namespace := d.selector.NamespaceOrDefault()
+	name := d.selector.Name
+
+	switch d.selector.Kind {
+	case KindDeployment:
+		workload, err := apps.Deployments(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		return workload.Annotations, nil
+	case KindStatefulSet:
+		workload, err := apps.StatefulSets(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		return workload.Annotations, nil
+	case KindReplicaSet:
+		workload, err := apps.ReplicaSets(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		return workload.Annotations, nil
+	default:
+		return nil, fmt.Errorf("unsupported workload kind %q", d.selector.Kind)
+	}
+}
+
+// annotateOriginalReplicas sets the original replicas annotation of the workload to the given value, or removes it
+// if the value is nil. The annotation is patched so it does not conflict with concurrent changes of the workload.
+func (d *workloadDisruptor) annotateOriginalReplicas(ctx context.Context, value *string) error {
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]*string{originalReplicasAnnotation: value},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	apps := d.k8s.Client().AppsV1()
+	namespace := d.selector.NamespaceOrDefault()
+	name := d.selector.Name
+
+	switch d.selector.Kind {
+	case KindDeployment:
+		_, err = apps.Deployments(namespace).Patch(ctx, name, types.MergePatchType, patch, metav1.PatchOptions{})
+	case KindStatefulSet:
+		_, err = apps.StatefulSets(namespace).Patch(ctx, name, types.MergePatchType, patch, metav1.PatchOptions{})
+	case KindReplicaSet:
+		_, err = apps.ReplicaSets(namespace).Patch(ctx, name, types.MergePatchType, patch, metav1.PatchOptions{})
+	default:
+		err = fmt.Errorf("unsupported workload kind %q", d.selector.Kind)
+	}
+	if err != nil {
+		return fmt.Errorf("annotating %s: %w", d.selector, err)
+	}
+
+	return nil
+}
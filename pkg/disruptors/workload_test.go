@@ -0,0 +1,265 @@
+package disruptors
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/grafana/xk6-disruptor/pkg/kubernetes"
+	"github.com/grafana/xk6-disruptor/pkg/testutils/kubernetes/builders"
+
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func buildDeployment(name string, replicas int32, annotations map[string]string) *appsv1.Deployment {
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			Namespace:   "test-ns",
+			Annotations: annotations,
+		},
+		Spec: appsv1.DeploymentSpec{Replicas: &replicas},
+	}
+}
+
+func buildStatefulSet(name string, replicas int32) *appsv1.StatefulSet {
+	return &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "test-ns"},
+		Spec:       appsv1.StatefulSetSpec{Replicas: &replicas},
+	}
+}
+
+func buildReplicaSet(name string, replicas int32) *appsv1.ReplicaSet {
+	return &appsv1.ReplicaSet{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "test-ns"},
+		Spec:       appsv1.ReplicaSetSpec{Replicas: &replicas},
+	}
+}
+
+func Test_NewWorkloadDisruptor(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		title       string
+		objects     []runtime.Object
+		selector    WorkloadSelector
+		expectError bool
+	}{
+		{
+			title:       "deployment",
+			objects:     []runtime.Object{buildDeployment("app", 3, nil)},
+			selector:    WorkloadSelector{Kind: "Deployment", Name: "app", Namespace: "test-ns"},
+			expectError: false,
+		},
+		{
+			title:       "workload does not exist",
+			objects:     []runtime.Object{buildDeployment("app", 3, nil)},
+			selector:    WorkloadSelector{Kind: "StatefulSet", Name: "app", Namespace: "test-ns"},
+			expectError: true,
+		},
+		{
+			title:       "workload in another namespace",
+			objects:     []runtime.Object{buildDeployment("app", 3, nil)},
+			selector:    WorkloadSelector{Kind: "Deployment", Name: "app"},
+			expectError: true,
+		},
+		{
+			title:       "unsupported kind",
+			objects:     []runtime.Object{buildDeployment("app", 3, nil)},
+			selector:    WorkloadSelector{Kind: "DaemonSet", Name: "app", Namespace: "test-ns"},
+			expectError: true,
+		},
+		{
+			title:       "no name",
+			objects:     []runtime.Object{buildDeployment("app", 3, nil)},
+			selector:    WorkloadSelector{Kind: "Deployment", Namespace: "test-ns"},
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			client := fake.NewSimpleClientset(tc.objects...)
+			k, _ := kubernetes.NewFakeKubernetes(client)
+
+			_, err := NewWorkloadDisruptor(context.TODO(), k, tc.selector)
+			if tc.expectError && err == nil {
+				t.Errorf("should had failed")
+				return
+			}
+
+			if !tc.expectError && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func Test_ScaleReplicas(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		title            string
+		object           runtime.Object
+		kind             string
+		fault            ReplicaFault
+		cancel           bool
+		expectedScaled   int32
+		expectedRestored int32
+		expectedErr      error
+		expectError      bool
+	}{
+		{
+			title:            "scale deployment to replicas",
+			object:           buildDeployment("app", 3, nil),
+			kind:             "Deployment",
+			fault:            ReplicaFault{Replicas: 1},
+			expectedScaled:   1,
+			expectedRestored: 3,
+		},
+		{
+			title:            "scale statefulset to fraction",
+			object:           buildStatefulSet("app", 5),
+			kind:             "StatefulSet",
+			fault:            ReplicaFault{Fraction: 0.5},
+			expectedScaled:   2,
+			expectedRestored: 5,
+		},
+		{
+			title:            "replicaset is restored when cancelled",
+			object:           buildReplicaSet("app", 2),
+			kind:             "ReplicaSet",
+			fault:            ReplicaFault{},
+			cancel:           true,
+			expectedScaled:   0,
+			expectedRestored: 2,
+			expectedErr:      context.Canceled,
+			expectError:      true,
+		},
+		{
+			title: "deployment left scaled down",
+			object: buildDeployment("app", 1, map[string]string{
+				originalReplicasAnnotation: "4",
+			}),
+			kind:             "Deployment",
+			fault:            ReplicaFault{Replicas: 2},
+			expectedScaled:   2,
+			expectedRestored: 4,
+		},
+		{
+			title:            "replicas above original",
+			object:           buildDeployment("app", 3, nil),
+			kind:             "Deployment",
+			fault:            ReplicaFault{Replicas: 3},
+			expectedScaled:   3,
+			expectedRestored: 3,
+			expectError:      true,
+		},
+		{
+			title:            "invalid fraction",
+			object:           buildDeployment("app", 3, nil),
+			kind:             "Deployment",
+			fault:            ReplicaFault{Fraction: 1.5},
+			expectedScaled:   3,
+			expectedRestored: 3,
+			expectError:      true,
+		},
+		{
+			title:            "replicas and fraction",
+			object:           buildDeployment("app", 3, nil),
+			kind:             "Deployment",
+			fault:            ReplicaFault{Replicas: 1, Fraction: 0.5},
+			expectedScaled:   3,
+			expectedRestored: 3,
+			expectError:      true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			client := fake.NewSimpleClientset(tc.object)
+			builders.AddScaleReactors(client)
+			k, _ := kubernetes.NewFakeKubernetes(client)
+
+			disruptor, err := NewWorkloadDisruptor(
+				context.TODO(),
+				k,
+				WorkloadSelector{Kind: tc.kind, Name: "app", Namespace: "test-ns"},
+			)
+			if err != nil {
+				t.Fatalf("failed creating disruptor: %v", err)
+			}
+
+			// reads the replicas and the original replicas annotation of the workload
+			replicas := func() (int32, string) {
+				d := disruptor.(*workloadDisruptor) //nolint:forcetypeassert
+				scale, gerr := d.scales().GetScale(context.TODO(), "app", metav1.GetOptions{})
+				if gerr != nil {
+					return -1, ""
+				}
+				annotations, gerr := d.getAnnotations(context.TODO())
+				if gerr != nil {
+					return -1, ""
+				}
+				return scale.Spec.Replicas, annotations[originalReplicasAnnotation]
+			}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			// check the replicas while the fault is injected
+			scaled := make(chan int32, 1)
+			go func() {
+				time.Sleep(500 * time.Millisecond)
+				current, _ := replicas()
+				scaled <- current
+				if tc.cancel {
+					cancel()
+				}
+			}()
+
+			err = disruptor.ScaleReplicas(ctx, tc.fault, 2*time.Second)
+			if tc.expectError && err == nil {
+				t.Fatalf("should had failed")
+			}
+
+			if !tc.expectError && err != nil {
+				t.Fatalf("failed: %v", err)
+			}
+
+			if tc.expectedErr != nil && !errors.Is(err, tc.expectedErr) {
+				t.Fatalf("expected %v got %v", tc.expectedErr, err)
+			}
+
+			if current := <-scaled; current != tc.expectedScaled {
+				t.Errorf("expected %d replicas while scaled got %d", tc.expectedScaled, current)
+			}
+
+			restored, annotation := replicas()
+			if restored != tc.expectedRestored {
+				t.Errorf("expected %d replicas after restore got %d", tc.expectedRestored, restored)
+			}
+
+			if annotation != "" {
+				t.Errorf("expected original replicas annotation to be removed")
+			}
+
+			// the replicas are only changed through the scale subresource
+			for _, action := range client.Actions() {
+				if action.GetVerb() == "update" && action.GetSubresource() != "scale" {
+					t.Errorf("unexpected update of %s", action.GetResource().Resource)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package iptables
+
+import (
+	"fmt"
+
+	"github.com/grafana/xk6-disruptor/internal/multierr"
+	"github.com/grafana/xk6-disruptor/pkg/agent/protocol"
+	"github.com/grafana/xk6-disruptor/pkg/runtime"
+)
+
+// dnsPort is the port DNS queries are sent to
+const dnsPort = 53
+
+// redirectDNSRule is a netfilter rule that intercepts the DNS queries sent by the target and redirects them to the
+// proxy. Queries forwarded by the proxy to the upstream resolver are excluded by the mark set on them
+// (see protocol.EgressMark). Otherwise, they would be redirected back to the proxy.
+const redirectDNSRule = "OUTPUT " + // For local traffic
+	"-t nat " + // Traversing the nat table
+	"-p %s --dport %d " + // Sent over the given protocol to the DNS port
+	"-m mark ! --mark %d " + // Not coming from the proxy
+	"-j REDIRECT --to-port %d" // Forward it to the proxy address
+
+// DNSRedirectionSpec specifies the redirection of the DNS queries sent by the target
+type DNSRedirectionSpec struct {
+	// RedirectPort is the port where the queries should be redirected to, both for UDP and TCP.
+	// Typically, this would be where a DNS proxy is listening.
+	RedirectPort uint
+}
+
+// dnsRedirector is an instance of a TrafficRedirector for DNS queries
+type dnsRedirector struct {
+	*DNSRedirectionSpec
+	executor runtime.Executor
+}
+
+// NewDNSTrafficRedirector creates instances of an iptables traffic redirector for the DNS queries sent by the target
+func NewDNSTrafficRedirector(
+	tr *DNSRedirectionSpec,
+	executor runtime.Executor,
+) (protocol.TrafficRedirector, error) {
+	if tr.RedirectPort == 0 {
+		return nil, fmt.Errorf("RedirectPort must be specified")
+	}
+
+	if tr.RedirectPort == dnsPort {
+		return nil, fmt.Errorf("RedirectPort must be different from the DNS port (%d)", dnsPort)
+	}
+
+	return &dnsRedirector{
+		DNSRedirectionSpec: tr,
+		executor:           executor,
+	}, nil
+}
+
+func (tr *dnsRedirector) redirectRules() []string {
+	return []string{
+		fmt.Sprintf(redirectDNSRule, "udp", dnsPort, protocol.EgressMark, tr.RedirectPort),
+		fmt.Sprintf(redirectDNSRule, "tcp", dnsPort, protocol.EgressMark, tr.RedirectPort),
+	}
+}
+
+// Start applies the TrafficRedirect
+func (tr *dnsRedirector) Start() error {
+	for _, rule := range tr.redirectRules() {
+		err := execIptables(tr.executor, "-A", rule)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Stop stops the TrafficRedirect.
+// Stop will continue attempting to remove all the rules it deployed even if removing one fails.
+func (tr *dnsRedirector) Stop() error {
+	var errs []error
+
+	for _, rule := range tr.redirectRules() {
+		errs = append(errs, execIptables(tr.executor, "-D", rule))
+	}
+
+	return multierr.Join(errs...)
+}
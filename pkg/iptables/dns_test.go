@@ -0,0 +1,112 @@
+package iptables
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/grafana/xk6-disruptor/pkg/agent/protocol"
+	"github.com/grafana/xk6-disruptor/pkg/runtime"
+)
+
+func Test_validateDNSTrafficRedirect(t *testing.T) {
+	t.Parallel()
+
+	TestCases := []struct {
+		title       string
+		redirect    DNSRedirectionSpec
+		expectError bool
+	}{
+		{
+			title:       "Valid redirect",
+			redirect:    DNSRedirectionSpec{RedirectPort: 8053},
+			expectError: false,
+		},
+		{
+			title:       "Redirect port not specified",
+			redirect:    DNSRedirectionSpec{},
+			expectError: true,
+		},
+		{
+			title:       "Redirect to the DNS port",
+			redirect:    DNSRedirectionSpec{RedirectPort: 53},
+			expectError: true,
+		},
+	}
+
+	for _, tc := range TestCases {
+		tc := tc
+
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			executor := runtime.NewFakeExecutor(nil, nil)
+			_, err := NewDNSTrafficRedirector(&tc.redirect, executor)
+			if tc.expectError && err == nil {
+				t.Errorf("error expected but none returned")
+			}
+
+			if !tc.expectError && err != nil {
+				t.Errorf("failed with error %v", err)
+			}
+		})
+	}
+}
+
+func Test_DNSCommands(t *testing.T) {
+	t.Parallel()
+
+	TestCases := []struct {
+		title        string
+		redirect     DNSRedirectionSpec
+		expectedCmds []string
+		testFunction func(protocol.TrafficRedirector) error
+	}{
+		{
+			title:    "Start valid redirect",
+			redirect: DNSRedirectionSpec{RedirectPort: 8053},
+			testFunction: func(tr protocol.TrafficRedirector) error {
+				return tr.Start()
+			},
+			expectedCmds: []string{
+				"iptables -A OUTPUT -t nat -p udp --dport 53 -m mark ! --mark 3349 -j REDIRECT --to-port 8053",
+				"iptables -A OUTPUT -t nat -p tcp --dport 53 -m mark ! --mark 3349 -j REDIRECT --to-port 8053",
+			},
+		},
+		{
+			title:    "Stop active redirect",
+			redirect: DNSRedirectionSpec{RedirectPort: 8053},
+			testFunction: func(tr protocol.TrafficRedirector) error {
+				return tr.Stop()
+			},
+			expectedCmds: []string{
+				"iptables -D OUTPUT -t nat -p udp --dport 53 -m mark ! --mark 3349 -j REDIRECT --to-port 8053",
+				"iptables -D OUTPUT -t nat -p tcp --dport 53 -m mark ! --mark 3349 -j REDIRECT --to-port 8053",
+			},
+		},
+	}
+
+	for _, tc := range TestCases {
+		tc := tc
+
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			executor := runtime.NewFakeExecutor(nil, nil)
+			redirector, err := NewDNSTrafficRedirector(&tc.redirect, executor)
+			if err != nil {
+				t.Errorf("failed creating traffic redirector with error %v", err)
+				return
+			}
+
+			err = tc.testFunction(redirector)
+			if err != nil {
+				t.Errorf("failed with error: %v", err)
+				return
+			}
+
+			if diff := cmp.Diff(tc.expectedCmds, executor.CmdHistory()); diff != "" {
+				t.Fatalf("Actual commands differ from expected:\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,147 @@
+package iptables
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/grafana/xk6-disruptor/internal/multierr"
+	"github.com/grafana/xk6-disruptor/pkg/agent/protocol"
+	"github.com/grafana/xk6-disruptor/pkg/runtime"
+)
+
+// The rules defined in the constants below intercept the traffic the target sends to an external destination.
+// Locally originated traffic traverses the OUTPUT chain, so rules are added there instead of PREROUTING/INPUT.
+// Connections opened by the proxy to the original destination are excluded from both rules by the mark set on them
+// (see protocol.EgressMark). Otherwise, they would be redirected back to the proxy.
+
+// redirectEgressRule is a netfilter rule that intercepts the traffic sent to the destination and redirects it to
+// the proxy. The proxy recovers the original destination of the connection using the SO_ORIGINAL_DST socket option.
+const redirectEgressRule = "OUTPUT " + // For local traffic
+	"-t nat " + // Traversing the nat table
+	"-d %s " + // Directed to the destination address or CIDR
+	"-p tcp --dport %d " + // Sent to the destination port
+	"-m mark ! --mark %d " + // Not coming from the proxy
+	"-j REDIRECT --to-port %d" // Forward it to the proxy address
+
+// resetEgressRule is a netfilter rule that resets established connections (i.e. that have not been redirected) to
+// the destination, forcing the target to open new connections that will be redirected.
+const resetEgressRule = "OUTPUT " + // For local traffic
+	"-d %s " + // Directed to the destination address or CIDR
+	"-p tcp --dport %d " + // Sent to the destination port
+	"-m mark ! --mark %d " + // Not coming from the proxy
+	"-m state --state ESTABLISHED " + // That are already ESTABLISHED, i.e. not before they are redirected
+	"-j REJECT --reject-with tcp-reset" // Reject it
+
+// EgressRedirectionSpec specifies the redirection of the traffic sent to an external destination
+type EgressRedirectionSpec struct {
+	// Destinations are the IP addresses or CIDRs the traffic is sent to
+	Destinations []string
+	// DestinationPort is the port the traffic is sent to
+	DestinationPort uint
+	// RedirectPort is the port where the traffic should be redirected to.
+	// Typically, this would be where a transparent proxy is listening.
+	RedirectPort uint
+}
+
+// egressRedirector is an instance of a TrafficRedirector for egress traffic
+type egressRedirector struct {
+	*EgressRedirectionSpec
+	executor runtime.Executor
+}
+
+// NewEgressTrafficRedirector creates instances of an iptables traffic redirector for the traffic sent to an
+// external destination
+func NewEgressTrafficRedirector(
+	tr *EgressRedirectionSpec,
+	executor runtime.Executor,
+) (protocol.TrafficRedirector, error) {
+	if len(tr.Destinations) == 0 {
+		return nil, fmt.Errorf("at least one destination must be specified")
+	}
+
+	// only IPv4 is supported, as iptables does not handle IPv6 rules
+	for _, destination := range tr.Destinations {
+		ip := net.ParseIP(destination)
+		if ip == nil {
+			ip, _, _ = net.ParseCIDR(destination)
+		}
+
+		if ip == nil || ip.To4() == nil {
+			return nil, fmt.Errorf("destination %q is not a valid IPv4 address or CIDR", destination)
+		}
+	}
+
+	if tr.DestinationPort == 0 || tr.RedirectPort == 0 {
+		return nil, fmt.Errorf("DestinationPort and RedirectPort must be specified")
+	}
+
+	return &egressRedirector{
+		EgressRedirectionSpec: tr,
+		executor:              executor,
+	}, nil
+}
+
+func (tr *egressRedirector) redirectRules() []string {
+	rules := []string{}
+	for _, destination := range tr.Destinations {
+		rules = append(
+			rules,
+			fmt.Sprintf(redirectEgressRule, destination, tr.DestinationPort, protocol.EgressMark, tr.RedirectPort),
+		)
+	}
+
+	return rules
+}
+
+func (tr *egressRedirector) resetRules() []string {
+	rules := []string{}
+	for _, destination := range tr.Destinations {
+		rules = append(
+			rules,
+			fmt.Sprintf(resetEgressRule, destination, tr.DestinationPort, protocol.EgressMark),
+		)
+	}
+
+	return rules
+}
+
+func (tr *egressRedirector) resetProxyRule() string {
+	return fmt.Sprintf(resetProxyRule, tr.RedirectPort)
+}
+
+// Start applies the TrafficRedirect
+func (tr *egressRedirector) Start() error {
+	// Remove reset rule for the proxy in case it exists from a previous run.
+	_ = execIptables(tr.executor, "-D", tr.resetProxyRule())
+
+	for _, rule := range tr.redirectRules() {
+		err := execIptables(tr.executor, "-A", rule)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, rule := range tr.resetRules() {
+		err := execIptables(tr.executor, "-A", rule)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Stop stops the TrafficRedirect.
+// Stop will continue attempting to remove all the rules it deployed even if removing one fails.
+func (tr *egressRedirector) Stop() error {
+	var errs []error
+
+	for _, rule := range append(tr.redirectRules(), tr.resetRules()...) {
+		errs = append(errs, execIptables(tr.executor, "-D", rule))
+	}
+
+	// Add rule to terminate any remaining traffic directed to the proxy.
+	errs = append(errs, execIptables(tr.executor, "-A", tr.resetProxyRule()))
+
+	return multierr.Join(errs...)
+}
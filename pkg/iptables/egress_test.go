@@ -0,0 +1,157 @@
+package iptables
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/grafana/xk6-disruptor/pkg/agent/protocol"
+	"github.com/grafana/xk6-disruptor/pkg/runtime"
+)
+
+func Test_validateEgressTrafficRedirect(t *testing.T) {
+	t.Parallel()
+
+	TestCases := []struct {
+		title       string
+		redirect    EgressRedirectionSpec
+		expectError bool
+	}{
+		{
+			title: "Valid redirect",
+			redirect: EgressRedirectionSpec{
+				Destinations:    []string{"192.0.2.10", "198.51.100.0/24"},
+				DestinationPort: 443,
+				RedirectPort:    8000,
+			},
+			expectError: false,
+		},
+		{
+			title: "Invalid destination",
+			redirect: EgressRedirectionSpec{
+				Destinations:    []string{"api.example.com"},
+				DestinationPort: 443,
+				RedirectPort:    8000,
+			},
+			expectError: true,
+		},
+		{
+			title: "IPv6 destination",
+			redirect: EgressRedirectionSpec{
+				Destinations:    []string{"2001:db8::1"},
+				DestinationPort: 443,
+				RedirectPort:    8000,
+			},
+			expectError: true,
+		},
+		{
+			title: "Destinations not specified",
+			redirect: EgressRedirectionSpec{
+				DestinationPort: 443,
+				RedirectPort:    8000,
+			},
+			expectError: true,
+		},
+		{
+			title: "Ports not specified",
+			redirect: EgressRedirectionSpec{
+				Destinations: []string{"192.0.2.10"},
+			},
+			expectError: true,
+		},
+	}
+
+	for _, tc := range TestCases {
+		tc := tc
+
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			executor := runtime.NewFakeExecutor(nil, nil)
+			_, err := NewEgressTrafficRedirector(
+				&tc.redirect,
+				executor,
+			)
+			if tc.expectError && err == nil {
+				t.Errorf("error expected but none returned")
+			}
+
+			if !tc.expectError && err != nil {
+				t.Errorf("failed with error %v", err)
+			}
+		})
+	}
+}
+
+func Test_EgressCommands(t *testing.T) {
+	t.Parallel()
+
+	TestCases := []struct {
+		title        string
+		redirect     EgressRedirectionSpec
+		expectedCmds []string
+		testFunction func(protocol.TrafficRedirector) error
+	}{
+		{
+			title: "Start valid redirect",
+			redirect: EgressRedirectionSpec{
+				Destinations:    []string{"192.0.2.10", "198.51.100.0/24"},
+				DestinationPort: 80,
+				RedirectPort:    8000,
+			},
+			testFunction: func(tr protocol.TrafficRedirector) error {
+				return tr.Start()
+			},
+			expectedCmds: []string{
+				"iptables -D INPUT -p tcp --dport 8000 -j REJECT --reject-with tcp-reset",
+				"iptables -A OUTPUT -t nat -d 192.0.2.10 -p tcp --dport 80 -m mark ! --mark 3349 -j REDIRECT --to-port 8000",
+				"iptables -A OUTPUT -t nat -d 198.51.100.0/24 -p tcp --dport 80 -m mark ! --mark 3349 -j REDIRECT --to-port 8000",
+				//nolint:lll
+				"iptables -A OUTPUT -d 192.0.2.10 -p tcp --dport 80 -m mark ! --mark 3349 -m state --state ESTABLISHED -j REJECT --reject-with tcp-reset",
+				//nolint:lll
+				"iptables -A OUTPUT -d 198.51.100.0/24 -p tcp --dport 80 -m mark ! --mark 3349 -m state --state ESTABLISHED -j REJECT --reject-with tcp-reset",
+			},
+		},
+		{
+			title: "Stop active redirect",
+			redirect: EgressRedirectionSpec{
+				Destinations:    []string{"192.0.2.10"},
+				DestinationPort: 80,
+				RedirectPort:    8000,
+			},
+			testFunction: func(tr protocol.TrafficRedirector) error {
+				return tr.Stop()
+			},
+			expectedCmds: []string{
+				"iptables -D OUTPUT -t nat -d 192.0.2.10 -p tcp --dport 80 -m mark ! --mark 3349 -j REDIRECT --to-port 8000",
+				//nolint:lll
+				"iptables -D OUTPUT -d 192.0.2.10 -p tcp --dport 80 -m mark ! --mark 3349 -m state --state ESTABLISHED -j REJECT --reject-with tcp-reset",
+				"iptables -A INPUT -p tcp --dport 8000 -j REJECT --reject-with tcp-reset",
+			},
+		},
+	}
+
+	for _, tc := range TestCases {
+		tc := tc
+
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			executor := runtime.NewFakeExecutor(nil, nil)
+			redirector, err := NewEgressTrafficRedirector(&tc.redirect, executor)
+			if err != nil {
+				t.Errorf("failed creating traffic redirector with error %v", err)
+				return
+			}
+
+			err = tc.testFunction(redirector)
+			if err != nil {
+				t.Errorf("failed with error: %v", err)
+				return
+			}
+
+			if diff := cmp.Diff(tc.expectedCmds, executor.CmdHistory()); diff != "" {
+				t.Fatalf("Actual commands differ from expected:\n%s", diff)
+			}
+		})
+	}
+}
@@ -4,10 +4,10 @@
 package iptables
 
 import (
-	"errors"
 	"fmt"
 	"strings"
 
+	"github.com/grafana/xk6-disruptor/internal/multierr"
 	"github.com/grafana/xk6-disruptor/pkg/agent/protocol"
 	"github.com/grafana/xk6-disruptor/pkg/runtime"
 )
@@ -156,9 +156,9 @@ func (tr *redirector) resetProxyRule() string {
 }
 
 // execIptables runs performs the specified action ("-A" or "-D") for the supplied rule.
-func (tr *redirector) execIptables(action string, rule string) error {
+func execIptables(executor runtime.Executor, action string, rule string) error {
 	cmd := fmt.Sprintf("%s %s", action, rule)
-	out, err := tr.executor.Exec("iptables", strings.Split(cmd, " ")...)
+	out, err := executor.Exec("iptables", strings.Split(cmd, " ")...)
 	if err != nil {
 		return fmt.Errorf("error executing iptables command %q: %w %s", cmd, err, string(out))
 	}
@@ -166,6 +166,11 @@ func (tr *redirector) execIptables(action string, rule string) error {
 	return nil
 }
 
+// execIptables runs performs the specified action ("-A" or "-D") for the supplied rule.
+func (tr *redirector) execIptables(action string, rule string) error {
+	return execIptables(tr.executor, action, rule)
+}
+
 // Start applies the TrafficRedirect
 func (tr *redirector) Start() error {
 	// Remove reset rule for the proxy in case it exists from a previous run.
@@ -190,34 +195,19 @@ func (tr *redirector) Start() error {
 
 // Stop stops the TrafficRedirect.
 // Stop will continue attempting to remove all the rules it deployed even if removing one fails.
-// TODO: The error returned does not wrap original errors.
 func (tr *redirector) Stop() error {
-	var errs []string
+	var errs []error
 
-	// TODO: Replace this homemade error aggregation with errors.Join when we upgrade from Go 1.19 to 1.20.
 	for _, rule := range tr.redirectRules() {
-		err := tr.execIptables("-D", rule)
-		if err != nil {
-			errs = append(errs, err.Error())
-		}
+		errs = append(errs, tr.execIptables("-D", rule))
 	}
 
 	for _, rule := range tr.resetRules() {
-		err := tr.execIptables("-D", rule)
-		if err != nil {
-			errs = append(errs, err.Error())
-		}
+		errs = append(errs, tr.execIptables("-D", rule))
 	}
 
 	// Add rule to terminate any remaining traffic directed to the proxy.
-	err := tr.execIptables("-A", tr.resetProxyRule())
-	if err != nil {
-		errs = append(errs, err.Error())
-	}
+	errs = append(errs, tr.execIptables("-A", tr.resetProxyRule()))
 
-	if len(errs) > 0 {
-		return errors.New(strings.Join(errs, "; "))
-	}
-
-	return nil
+	return multierr.Join(errs...)
 }
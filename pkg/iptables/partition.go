@@ -0,0 +1,190 @@
+package iptables
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+
+	"github.com/grafana/xk6-disruptor/internal/multierr"
+	"github.com/grafana/xk6-disruptor/pkg/runtime"
+)
+
+// PartitionDirection defines the connections blocked by a network partition
+type PartitionDirection string
+
+const (
+	// PartitionBoth blocks all the traffic between the target and the peers
+	PartitionBoth PartitionDirection = "both"
+	// PartitionOutgoing blocks the connections the target opens to the peers
+	PartitionOutgoing PartitionDirection = "outgoing"
+	// PartitionIncoming blocks the connections the peers open to the target
+	PartitionIncoming PartitionDirection = "incoming"
+)
+
+// partitionComment tags the rules added by a partition, allowing them to be found and removed by ResetPartition
+const partitionComment = "xk6-disruptor-partition"
+
+// dropIncomingRule is a netfilter rule that drops the traffic coming from a peer
+const dropIncomingRule = "INPUT " + // For traffic traversing the INPUT chain
+	"-s %s " + // Coming from the peer
+	"-m comment --comment " + partitionComment + " " + // Tagged as part of a partition
+	"-j DROP" // Drop it
+
+// dropOutgoingRule is a netfilter rule that drops the traffic sent to a peer
+const dropOutgoingRule = "OUTPUT " + // For local traffic
+	"-d %s " + // Directed to the peer
+	"-m comment --comment " + partitionComment + " " + // Tagged as part of a partition
+	"-j DROP" // Drop it
+
+// dropNewIncomingRule is a netfilter rule that drops the connections the peer opens to the target.
+// The replies to the connections opened by the target are not affected.
+const dropNewIncomingRule = "INPUT " + // For traffic traversing the INPUT chain
+	"-s %s " + // Coming from the peer
+	"-m state --state NEW " + // That opens a new connection
+	"-m comment --comment " + partitionComment + " " + // Tagged as part of a partition
+	"-j DROP" // Drop it
+
+// dropNewOutgoingRule is a netfilter rule that drops the connections the target opens to the peer.
+// The replies to the connections opened by the peer are not affected.
+const dropNewOutgoingRule = "OUTPUT " + // For local traffic
+	"-d %s " + // Directed to the peer
+	"-m state --state NEW " + // That opens a new connection
+	"-m comment --comment " + partitionComment + " " + // Tagged as part of a partition
+	"-j DROP" // Drop it
+
+// Partition maintains the rules that isolate the target from a set of peers
+type Partition struct {
+	executor  runtime.Executor
+	direction PartitionDirection
+	peers     map[string]bool
+}
+
+// NewPartition returns a Partition that blocks the traffic in the given direction. Peers are added with Update.
+func NewPartition(executor runtime.Executor, direction PartitionDirection) (*Partition, error) {
+	switch direction {
+	case PartitionBoth, PartitionOutgoing, PartitionIncoming:
+	default:
+		return nil, fmt.Errorf("invalid partition direction %q", direction)
+	}
+
+	return &Partition{
+		executor:  executor,
+		direction: direction,
+		peers:     map[string]bool{},
+	}, nil
+}
+
+// rules returns the rules that isolate the target from the peer
+func (p *Partition) rules(peer string) []string {
+	switch p.direction {
+	case PartitionOutgoing:
+		return []string{fmt.Sprintf(dropNewOutgoingRule, peer)}
+	case PartitionIncoming:
+		return []string{fmt.Sprintf(dropNewIncomingRule, peer)}
+	default:
+		return []string{
+			fmt.Sprintf(dropIncomingRule, peer),
+			fmt.Sprintf(dropOutgoingRule, peer),
+		}
+	}
+}
+
+// Peers returns the IP addresses of the peers currently isolated, sorted
+func (p *Partition) Peers() []string {
+	peers := make([]string, 0, len(p.peers))
+	for peer := range p.peers {
+		peers = append(peers, peer)
+	}
+	sort.Strings(peers)
+
+	return peers
+}
+
+// Update changes the set of peers the target is isolated from, adding the rules for the new peers and removing
+// the rules for the peers no longer in the set
+func (p *Partition) Update(peers []string) error {
+	updated := map[string]bool{}
+	for _, peer := range peers {
+		if ip := net.ParseIP(peer); ip == nil || ip.To4() == nil {
+			return fmt.Errorf("peer %q is not an IPv4 address", peer)
+		}
+		updated[peer] = true
+	}
+
+	for peer := range p.peers {
+		if updated[peer] {
+			continue
+		}
+
+		for _, rule := range p.rules(peer) {
+			if err := execIptables(p.executor, "-D", rule); err != nil {
+				return err
+			}
+		}
+		delete(p.peers, peer)
+	}
+
+	// add peers in order to make the sequence of rules predictable
+	added := []string{}
+	for peer := range updated {
+		if !p.peers[peer] {
+			added = append(added, peer)
+		}
+	}
+	sort.Strings(added)
+
+	for _, peer := range added {
+		for _, rule := range p.rules(peer) {
+			if err := execIptables(p.executor, "-A", rule); err != nil {
+				return err
+			}
+		}
+		p.peers[peer] = true
+	}
+
+	return nil
+}
+
+// Stop removes the rules for all the peers, restoring the connectivity.
+// Stop will continue attempting to remove all the rules even if removing one fails.
+func (p *Partition) Stop() error {
+	var errs []error
+
+	for _, peer := range p.Peers() {
+		for _, rule := range p.rules(peer) {
+			errs = append(errs, execIptables(p.executor, "-D", rule))
+		}
+		delete(p.peers, peer)
+	}
+
+	return multierr.Join(errs...)
+}
+
+// ResetPartition removes any rule added by a partition, including those left by an agent that was
+// terminated abruptly. Rules that no longer exist when they are removed are ignored.
+func ResetPartition(executor runtime.Executor) error {
+	out, err := executor.Exec("iptables", "-S")
+	if err != nil {
+		return fmt.Errorf("error listing iptables rules: %w %s", err, string(out))
+	}
+
+	var errs []error
+	for _, line := range strings.Split(string(out), "\n") {
+		line = strings.TrimSpace(line)
+		if !strings.HasPrefix(line, "-A ") || !strings.Contains(line, partitionComment) {
+			continue
+		}
+
+		rule := strings.TrimPrefix(line, "-A ")
+		err := execIptables(executor, "-D", rule)
+		// the rule may have been removed since it was listed, which is not an error
+		if err != nil && execIptables(executor, "-C", rule) != nil {
+			continue
+		}
+
+		errs = append(errs, err)
+	}
+
+	return multierr.Join(errs...)
+}
@@ -0,0 +1,223 @@
+package iptables
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/grafana/xk6-disruptor/pkg/runtime"
+)
+
+func Test_Partition(t *testing.T) {
+	t.Parallel()
+
+	TestCases := []struct {
+		title        string
+		direction    PartitionDirection
+		updates      [][]string
+		stop         bool
+		expectError  bool
+		expectedCmds []string
+	}{
+		{
+			title:     "two-way partition",
+			direction: PartitionBoth,
+			updates:   [][]string{{"192.0.2.11", "192.0.2.10"}},
+			stop:      true,
+			expectedCmds: []string{
+				"iptables -A INPUT -s 192.0.2.10 -m comment --comment xk6-disruptor-partition -j DROP",
+				"iptables -A OUTPUT -d 192.0.2.10 -m comment --comment xk6-disruptor-partition -j DROP",
+				"iptables -A INPUT -s 192.0.2.11 -m comment --comment xk6-disruptor-partition -j DROP",
+				"iptables -A OUTPUT -d 192.0.2.11 -m comment --comment xk6-disruptor-partition -j DROP",
+				"iptables -D INPUT -s 192.0.2.10 -m comment --comment xk6-disruptor-partition -j DROP",
+				"iptables -D OUTPUT -d 192.0.2.10 -m comment --comment xk6-disruptor-partition -j DROP",
+				"iptables -D INPUT -s 192.0.2.11 -m comment --comment xk6-disruptor-partition -j DROP",
+				"iptables -D OUTPUT -d 192.0.2.11 -m comment --comment xk6-disruptor-partition -j DROP",
+			},
+		},
+		{
+			title:     "one-way outgoing partition",
+			direction: PartitionOutgoing,
+			updates:   [][]string{{"192.0.2.10"}},
+			expectedCmds: []string{
+				//nolint:lll
+				"iptables -A OUTPUT -d 192.0.2.10 -m state --state NEW -m comment --comment xk6-disruptor-partition -j DROP",
+			},
+		},
+		{
+			title:     "one-way incoming partition",
+			direction: PartitionIncoming,
+			updates:   [][]string{{"192.0.2.10"}},
+			expectedCmds: []string{
+				//nolint:lll
+				"iptables -A INPUT -s 192.0.2.10 -m state --state NEW -m comment --comment xk6-disruptor-partition -j DROP",
+			},
+		},
+		{
+			title:     "update peers",
+			direction: PartitionOutgoing,
+			updates:   [][]string{{"192.0.2.10", "192.0.2.11"}, {"192.0.2.11", "192.0.2.12"}},
+			expectedCmds: []string{
+				//nolint:lll
+				"iptables -A OUTPUT -d 192.0.2.10 -m state --state NEW -m comment --comment xk6-disruptor-partition -j DROP",
+				//nolint:lll
+				"iptables -A OUTPUT -d 192.0.2.11 -m state --state NEW -m comment --comment xk6-disruptor-partition -j DROP",
+				//nolint:lll
+				"iptables -D OUTPUT -d 192.0.2.10 -m state --state NEW -m comment --comment xk6-disruptor-partition -j DROP",
+				//nolint:lll
+				"iptables -A OUTPUT -d 192.0.2.12 -m state --state NEW -m comment --comment xk6-disruptor-partition -j DROP",
+			},
+		},
+		{
+			title:        "invalid peer",
+			direction:    PartitionBoth,
+			updates:      [][]string{{"my-pod"}},
+			expectError:  true,
+			expectedCmds: nil,
+		},
+	}
+
+	for _, tc := range TestCases {
+		tc := tc
+
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			executor := runtime.NewFakeExecutor(nil, nil)
+			partition, err := NewPartition(executor, tc.direction)
+			if err != nil {
+				t.Fatalf("failed creating partition: %v", err)
+			}
+
+			for _, peers := range tc.updates {
+				err = partition.Update(peers)
+				if err != nil {
+					break
+				}
+			}
+
+			if tc.expectError && err == nil {
+				t.Fatalf("should had failed")
+			}
+
+			if !tc.expectError && err != nil {
+				t.Fatalf("failed: %v", err)
+			}
+
+			if tc.stop {
+				if err = partition.Stop(); err != nil {
+					t.Fatalf("failed stopping partition: %v", err)
+				}
+			}
+
+			if diff := cmp.Diff(tc.expectedCmds, executor.CmdHistory()); diff != "" {
+				t.Errorf("expected commands do not match executed:\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_InvalidPartitionDirection(t *testing.T) {
+	t.Parallel()
+
+	_, err := NewPartition(runtime.NewFakeExecutor(nil, nil), "sideways")
+	if err == nil {
+		t.Errorf("should had failed")
+	}
+}
+
+func Test_ResetPartition(t *testing.T) {
+	t.Parallel()
+
+	rules := "-P INPUT ACCEPT\n" +
+		"-P OUTPUT ACCEPT\n" +
+		"-A INPUT -p tcp -m tcp --dport 8000 -j REJECT --reject-with tcp-reset\n" +
+		"-A INPUT -s 192.0.2.10/32 -m comment --comment xk6-disruptor-partition -j DROP\n" +
+		"-A OUTPUT -d 192.0.2.10/32 -m comment --comment xk6-disruptor-partition -j DROP\n"
+
+	executor := runtime.NewCallbackExecutor(func(cmd string, args ...string) ([]byte, error) {
+		if fmt.Sprint(args) == "[-S]" {
+			return []byte(rules), nil
+		}
+		return nil, nil
+	})
+
+	err := ResetPartition(executor)
+	if err != nil {
+		t.Fatalf("failed: %v", err)
+	}
+
+	expected := []string{
+		"iptables -S",
+		"iptables -D INPUT -s 192.0.2.10/32 -m comment --comment xk6-disruptor-partition -j DROP",
+		"iptables -D OUTPUT -d 192.0.2.10/32 -m comment --comment xk6-disruptor-partition -j DROP",
+	}
+	if diff := cmp.Diff(expected, executor.CmdHistory()); diff != "" {
+		t.Errorf("expected commands do not match executed:\n%s", diff)
+	}
+}
+
+func Test_ResetPartitionFailures(t *testing.T) {
+	t.Parallel()
+
+	rules := "-A INPUT -s 192.0.2.10/32 -m comment --comment xk6-disruptor-partition -j DROP\n" +
+		"-A OUTPUT -d 192.0.2.10/32 -m comment --comment xk6-disruptor-partition -j DROP\n"
+
+	testCases := []struct {
+		title string
+		// true if the rules still exist when they fail to be removed
+		exist       bool
+		expectError bool
+	}{
+		{
+			title:       "rules removed concurrently",
+			exist:       false,
+			expectError: false,
+		},
+		{
+			title:       "rules cannot be removed",
+			exist:       true,
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			executor := runtime.NewCallbackExecutor(func(cmd string, args ...string) ([]byte, error) {
+				switch args[0] {
+				case "-S":
+					return []byte(rules), nil
+				case "-C":
+					if tc.exist {
+						return nil, nil
+					}
+				}
+				return nil, fmt.Errorf("iptables: Bad rule (does a matching rule exist in that chain?)")
+			})
+
+			err := ResetPartition(executor)
+			if tc.expectError && err == nil {
+				t.Fatalf("should had failed")
+			}
+			if !tc.expectError && err != nil {
+				t.Fatalf("failed: %v", err)
+			}
+
+			// every rule is removed even if removing another fails
+			expected := []string{
+				"iptables -S",
+				"iptables -D INPUT -s 192.0.2.10/32 -m comment --comment xk6-disruptor-partition -j DROP",
+				"iptables -C INPUT -s 192.0.2.10/32 -m comment --comment xk6-disruptor-partition -j DROP",
+				"iptables -D OUTPUT -d 192.0.2.10/32 -m comment --comment xk6-disruptor-partition -j DROP",
+				"iptables -C OUTPUT -d 192.0.2.10/32 -m comment --comment xk6-disruptor-partition -j DROP",
+			}
+			if diff := cmp.Diff(expected, executor.CmdHistory()); diff != "" {
+				t.Errorf("expected commands do not match executed:\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,320 @@
+package helpers
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/grafana/xk6-disruptor/internal/multierr"
+
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/util/retry"
+)
+
+// mirrorPodAnnotation identifies the static pods managed directly by the kubelet
+const mirrorPodAnnotation = "kubernetes.io/config.mirror"
+
+// evictionRetryInterval is the interval between attempts to evict a pod protected by a PodDisruptionBudget
+const evictionRetryInterval = 2 * time.Second
+
+// NodeHelper defines helper methods for handling Nodes
+type NodeHelper interface {
+	// List returns a list of nodes that match the given NodeFilter
+	List(ctx context.Context, filter NodeFilter) ([]corev1.Node, error)
+	// Cordon marks the node as unschedulable. Returns false if the node was already unschedulable.
+	Cordon(ctx context.Context, name string) (bool, error)
+	// Uncordon marks the node as schedulable
+	Uncordon(ctx context.Context, name string) error
+	// Drain evicts the pods running in the node
+	Drain(ctx context.Context, name string, options DrainOptions) error
+}
+
+// DrainOptions defines options for draining a node
+type DrainOptions struct {
+	// Delete the pods instead of evicting them, ignoring any PodDisruptionBudget
+	IgnorePDB bool
+	// Pods that match these labels are not evicted
+	Exclude map[string]string
+	// Maximum time to wait for the pods to be evicted. A zero value forces no waiting.
+	Timeout time.Duration
+}
+
+// nodeHelper holds the data required by the NodeHelper
+type nodeHelper struct {
+	client kubernetes.Interface
+}
+
+// NewNodeHelper returns a NodeHelper
+func NewNodeHelper(client kubernetes.Interface) NodeHelper {
+	return &nodeHelper{
+		client: client,
+	}
+}
+
+// NodeFilter defines the criteria for selecting a node for disruption
+type NodeFilter struct {
+	// Select Nodes that match these labels
+	Select map[string]string
+	// Exclude Nodes that match these labels
+	Exclude map[string]string
+	// Select Nodes that match these annotations
+	SelectAnnotations map[string]string
+	// Exclude Nodes that match any of these annotations
+	ExcludeAnnotations map[string]string
+	// Select Nodes that have all these conditions (e.g. "Ready") set to True
+	SelectConditions []string
+	// Exclude Nodes that have any of these conditions (e.g. "MemoryPressure") set to True
+	ExcludeConditions []string
+}
+
+// matches returns whether a node satisfies the annotation and condition criteria of the filter.
+// Labels are not checked as they are filtered by the API server.
+func (f NodeFilter) matches(node corev1.Node) bool {
+	for name, value := range f.SelectAnnotations {
+		if v, found := node.Annotations[name]; !found || v != value {
+			return false
+		}
+	}
+
+	for name, value := range f.ExcludeAnnotations {
+		if v, found := node.Annotations[name]; found && v == value {
+			return false
+		}
+	}
+
+	for _, condition := range f.SelectConditions {
+		if !hasNodeCondition(node, condition) {
+			return false
+		}
+	}
+
+	for _, condition := range f.ExcludeConditions {
+		if hasNodeCondition(node, condition) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// hasNodeCondition returns whether the node has the given condition type set to True
+func hasNodeCondition(node corev1.Node, conditionType string) bool {
+	for _, c := range node.Status.Conditions {
+		if string(c.Type) == conditionType {
+			return c.Status == corev1.ConditionTrue
+		}
+	}
+
+	return false
+}
+
+func (h *nodeHelper) List(ctx context.Context, filter NodeFilter) ([]corev1.Node, error) {
+	labelSelector, err := buildLabelSelector(filter.Select, filter.Exclude)
+	if err != nil {
+		return nil, err
+	}
+
+	listOptions := metav1.ListOptions{
+		LabelSelector: labelSelector.String(),
+	}
+	nodes, err := h.client.CoreV1().Nodes().List(
+		ctx,
+		listOptions,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	matching := []corev1.Node{}
+	for _, node := range nodes.Items {
+		if filter.matches(node) {
+			matching = append(matching, node)
+		}
+	}
+
+	return matching, nil
+}
+
+// setUnschedulable sets the unschedulable property of a node and returns its previous value
+func (h *nodeHelper) setUnschedulable(ctx context.Context, name string, unschedulable bool) (bool, error) {
+	var previous bool
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		node, err := h.client.CoreV1().Nodes().Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+
+		previous = node.Spec.Unschedulable
+		if previous == unschedulable {
+			return nil
+		}
+
+		node.Spec.Unschedulable = unschedulable
+		_, err = h.client.CoreV1().Nodes().Update(ctx, node, metav1.UpdateOptions{})
+		return err
+	})
+	if err != nil {
+		return false, fmt.Errorf("updating node %q: %w", name, err)
+	}
+
+	return previous, nil
+}
+
+func (h *nodeHelper) Cordon(ctx context.Context, name string) (bool, error) {
+	previous, err := h.setUnschedulable(ctx, name, true)
+	if err != nil {
+		return false, err
+	}
+
+	return !previous, nil
+}
+
+func (h *nodeHelper) Uncordon(ctx context.Context, name string) error {
+	_, err := h.setUnschedulable(ctx, name, false)
+	return err
+}
+
+// isEvictable returns whether a pod must be evicted when draining a node. Pods managed by DaemonSets, static pods
+// and pods that already terminated are not evicted, as well as those matching the excluded labels.
+func isEvictable(pod corev1.Pod, exclude map[string]string) bool {
+	if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+		return false
+	}
+
+	if _, found := pod.Annotations[mirrorPodAnnotation]; found {
+		return false
+	}
+
+	owner := metav1.GetControllerOf(&pod)
+	if owner != nil && owner.Kind == "DaemonSet" {
+		return false
+	}
+
+	if len(exclude) > 0 && labels.SelectorFromSet(exclude).Matches(labels.Set(pod.Labels)) {
+		return false
+	}
+
+	return true
+}
+
+func (h *nodeHelper) Drain(ctx context.Context, name string, options DrainOptions) error {
+	listOptions := metav1.ListOptions{
+		FieldSelector: fields.OneTermEqualSelector("spec.nodeName", name).String(),
+	}
+	pods, err := h.client.CoreV1().Pods(metav1.NamespaceAll).List(ctx, listOptions)
+	if err != nil {
+		return fmt.Errorf("listing pods in node %q: %w", name, err)
+	}
+
+	evictable := []corev1.Pod{}
+	for _, pod := range pods.Items {
+		// the field selector may not be supported by all clients, so the node is checked again
+		if pod.Spec.NodeName == name && isEvictable(pod, options.Exclude) {
+			evictable = append(evictable, pod)
+		}
+	}
+
+	// pods are evicted concurrently so a pod protected by a PodDisruptionBudget does not delay the eviction
+	// of the other pods
+	deadline := time.Now().Add(options.Timeout)
+	var wg sync.WaitGroup
+	errs := make([]error, len(evictable))
+	for i, pod := range evictable {
+		wg.Add(1)
+		go func(i int, pod corev1.Pod) {
+			defer wg.Done()
+			errs[i] = h.evict(ctx, pod, options.IgnorePDB, deadline)
+		}(i, pod)
+	}
+
+	wg.Wait()
+
+	if err = multierr.Join(errs...); err != nil {
+		return err
+	}
+
+	if options.Timeout == 0 {
+		return nil
+	}
+
+	return h.waitPodsDeleted(ctx, evictable, deadline)
+}
+
+// evict evicts a pod. If the eviction is blocked by a PodDisruptionBudget, it is retried until the deadline.
+// If ignorePDB is true, the pod is deleted instead.
+func (h *nodeHelper) evict(ctx context.Context, pod corev1.Pod, ignorePDB bool, deadline time.Time) error {
+	var err error
+	for {
+		if ignorePDB {
+			err = h.client.CoreV1().Pods(pod.Namespace).Delete(ctx, pod.Name, metav1.DeleteOptions{})
+		} else {
+			eviction := &policyv1.Eviction{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      pod.Name,
+					Namespace: pod.Namespace,
+				},
+			}
+			err = h.client.PolicyV1().Evictions(pod.Namespace).Evict(ctx, eviction)
+		}
+
+		if err == nil || apierrors.IsNotFound(err) {
+			return nil
+		}
+
+		// eviction is rejected with TooManyRequests when it would violate a PodDisruptionBudget
+		if !apierrors.IsTooManyRequests(err) || time.Now().After(deadline) {
+			return fmt.Errorf("evicting pod %s/%s: %w", pod.Namespace, pod.Name, err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(evictionRetryInterval):
+		}
+	}
+}
+
+// waitPodsDeleted waits until the pods are deleted or the deadline is reached. A pod that was recreated with the
+// same name is considered deleted.
+func (h *nodeHelper) waitPodsDeleted(ctx context.Context, pods []corev1.Pod, deadline time.Time) error {
+	pending := pods
+	for {
+		remaining := []corev1.Pod{}
+		for _, pod := range pending {
+			current, err := h.client.CoreV1().Pods(pod.Namespace).Get(ctx, pod.Name, metav1.GetOptions{})
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			if err != nil {
+				return fmt.Errorf("getting pod %s/%s: %w", pod.Namespace, pod.Name, err)
+			}
+			if current.UID != pod.UID {
+				continue
+			}
+			remaining = append(remaining, pod)
+		}
+
+		if len(remaining) == 0 {
+			return nil
+		}
+
+		if time.Now().After(deadline) {
+			return fmt.Errorf("%d pods were not evicted before timeout", len(remaining))
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(time.Second):
+		}
+
+		pending = remaining
+	}
+}
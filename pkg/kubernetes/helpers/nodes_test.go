@@ -0,0 +1,309 @@
+package helpers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stest "k8s.io/client-go/testing"
+
+	"github.com/grafana/xk6-disruptor/pkg/testutils/assertions"
+	"github.com/grafana/xk6-disruptor/pkg/testutils/kubernetes/builders"
+)
+
+func TestNodes_List(t *testing.T) {
+	t.Parallel()
+
+	nodes := []corev1.Node{
+		builders.NewNodeBuilder("node-a").
+			WithLabel("zone", "a").
+			WithAnnotation("team", "payments").
+			WithCondition(corev1.NodeReady, corev1.ConditionTrue).
+			Build(),
+		builders.NewNodeBuilder("node-b").
+			WithLabel("zone", "b").
+			WithCondition(corev1.NodeReady, corev1.ConditionTrue).
+			WithCondition(corev1.NodeMemoryPressure, corev1.ConditionTrue).
+			Build(),
+		builders.NewNodeBuilder("node-c").
+			WithLabel("zone", "a").
+			WithLabel("node-role.kubernetes.io/control-plane", "").
+			WithCondition(corev1.NodeReady, corev1.ConditionFalse).
+			Build(),
+	}
+
+	testCases := []struct {
+		title         string
+		filter        NodeFilter
+		expectedNodes []string
+	}{
+		{
+			title:         "all nodes",
+			filter:        NodeFilter{},
+			expectedNodes: []string{"node-a", "node-b", "node-c"},
+		},
+		{
+			title: "select labels",
+			filter: NodeFilter{
+				Select: map[string]string{"zone": "a"},
+			},
+			expectedNodes: []string{"node-a", "node-c"},
+		},
+		{
+			title: "exclude labels",
+			filter: NodeFilter{
+				Exclude: map[string]string{"node-role.kubernetes.io/control-plane": ""},
+			},
+			expectedNodes: []string{"node-a", "node-b"},
+		},
+		{
+			title: "select annotations",
+			filter: NodeFilter{
+				SelectAnnotations: map[string]string{"team": "payments"},
+			},
+			expectedNodes: []string{"node-a"},
+		},
+		{
+			title: "exclude annotations",
+			filter: NodeFilter{
+				ExcludeAnnotations: map[string]string{"team": "payments"},
+			},
+			expectedNodes: []string{"node-b", "node-c"},
+		},
+		{
+			title: "select conditions",
+			filter: NodeFilter{
+				SelectConditions: []string{"Ready"},
+			},
+			expectedNodes: []string{"node-a", "node-b"},
+		},
+		{
+			title: "exclude conditions",
+			filter: NodeFilter{
+				SelectConditions:  []string{"Ready"},
+				ExcludeConditions: []string{"MemoryPressure"},
+			},
+			expectedNodes: []string{"node-a"},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			objs := []runtime.Object{}
+			for n := range nodes {
+				objs = append(objs, &nodes[n])
+			}
+			client := fake.NewSimpleClientset(objs...)
+
+			helper := NewNodeHelper(client)
+			nodeList, err := helper.List(context.TODO(), tc.filter)
+			if err != nil {
+				t.Errorf("failed: %v", err)
+				return
+			}
+
+			names := []string{}
+			for _, n := range nodeList {
+				names = append(names, n.Name)
+			}
+			if !assertions.CompareStringArrays(names, tc.expectedNodes) {
+				t.Errorf("result does not match expected value. Expected: %s\nActual: %s\n", tc.expectedNodes, names)
+			}
+		})
+	}
+}
+
+func TestNodes_Cordon(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		title           string
+		node            corev1.Node
+		expectedChanged bool
+	}{
+		{
+			title:           "schedulable node",
+			node:            builders.NewNodeBuilder("node-a").Build(),
+			expectedChanged: true,
+		},
+		{
+			title:           "node already cordoned",
+			node:            builders.NewNodeBuilder("node-a").WithUnschedulable(true).Build(),
+			expectedChanged: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			client := fake.NewSimpleClientset(&tc.node)
+			helper := NewNodeHelper(client)
+
+			changed, err := helper.Cordon(context.TODO(), tc.node.Name)
+			if err != nil {
+				t.Errorf("failed: %v", err)
+				return
+			}
+
+			if changed != tc.expectedChanged {
+				t.Errorf("expected changed to be %t", tc.expectedChanged)
+				return
+			}
+
+			node, _ := client.CoreV1().Nodes().Get(context.TODO(), tc.node.Name, metav1.GetOptions{})
+			if !node.Spec.Unschedulable {
+				t.Errorf("node should be unschedulable")
+				return
+			}
+
+			err = helper.Uncordon(context.TODO(), tc.node.Name)
+			if err != nil {
+				t.Errorf("failed: %v", err)
+				return
+			}
+
+			node, _ = client.CoreV1().Nodes().Get(context.TODO(), tc.node.Name, metav1.GetOptions{})
+			if node.Spec.Unschedulable {
+				t.Errorf("node should be schedulable")
+			}
+		})
+	}
+}
+
+func TestNodes_Drain(t *testing.T) {
+	t.Parallel()
+
+	pods := []corev1.Pod{
+		builders.NewPodBuilder("app").
+			WithNamespace("test-ns").
+			WithNodeName("node-a").
+			WithLabel("app", "app").
+			Build(),
+		builders.NewPodBuilder("protected").
+			WithNamespace("test-ns").
+			WithNodeName("node-a").
+			WithLabel("app", "protected").
+			Build(),
+		builders.NewPodBuilder("daemon").
+			WithNamespace("test-ns").
+			WithNodeName("node-a").
+			WithOwnerReference("DaemonSet", "daemon").
+			Build(),
+		builders.NewPodBuilder("static").
+			WithNamespace("test-ns").
+			WithNodeName("node-a").
+			WithAnnotation("kubernetes.io/config.mirror", "hash").
+			Build(),
+		builders.NewPodBuilder("agent").
+			WithNamespace("test-ns").
+			WithNodeName("node-a").
+			WithLabel("app.kubernetes.io/name", "xk6-disruptor-agent").
+			Build(),
+		builders.NewPodBuilder("other").
+			WithNamespace("test-ns").
+			WithNodeName("node-b").
+			WithLabel("app", "app").
+			Build(),
+	}
+
+	testCases := []struct {
+		title        string
+		options      DrainOptions
+		protected    bool
+		expectError  bool
+		expectedPods []string
+	}{
+		{
+			title: "evict pods",
+			options: DrainOptions{
+				Exclude: map[string]string{"app.kubernetes.io/name": "xk6-disruptor-agent"},
+				Timeout: time.Second,
+			},
+			expectedPods: []string{"daemon", "static", "agent", "other"},
+		},
+		{
+			title: "eviction blocked by disruption budget",
+			options: DrainOptions{
+				Exclude: map[string]string{"app.kubernetes.io/name": "xk6-disruptor-agent"},
+				Timeout: time.Second,
+			},
+			protected:   true,
+			expectError: true,
+			// the pods not protected by the disruption budget are evicted
+			expectedPods: []string{"protected", "daemon", "static", "agent", "other"},
+		},
+		{
+			title: "ignore disruption budget",
+			options: DrainOptions{
+				IgnorePDB: true,
+				Exclude:   map[string]string{"app.kubernetes.io/name": "xk6-disruptor-agent"},
+				Timeout:   time.Second,
+			},
+			protected:    true,
+			expectedPods: []string{"daemon", "static", "agent", "other"},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			objs := []runtime.Object{}
+			for p := range pods {
+				objs = append(objs, &pods[p])
+			}
+			client := fake.NewSimpleClientset(objs...)
+
+			// the fake client does not delete the pods when they are evicted
+			client.PrependReactor("create", "pods", func(action k8stest.Action) (bool, runtime.Object, error) {
+				if action.GetSubresource() != "eviction" {
+					return false, nil, nil
+				}
+
+				eviction, _ := action.(k8stest.CreateAction).GetObject().(*policyv1.Eviction)
+				if tc.protected && eviction.Name == "protected" {
+					return true, nil, apierrors.NewTooManyRequests("disruption budget", 0)
+				}
+
+				gvr := action.GetResource()
+				return true, nil, client.Tracker().Delete(gvr, eviction.Namespace, eviction.Name)
+			})
+
+			helper := NewNodeHelper(client)
+			err := helper.Drain(context.TODO(), "node-a", tc.options)
+			if tc.expectError && err == nil {
+				t.Errorf("should had failed")
+				return
+			}
+
+			if !tc.expectError && err != nil {
+				t.Errorf("failed: %v", err)
+				return
+			}
+
+			podList, _ := client.CoreV1().Pods("test-ns").List(context.TODO(), metav1.ListOptions{})
+			names := []string{}
+			for _, p := range podList.Items {
+				names = append(names, p.Name)
+			}
+			if !assertions.CompareStringArrays(names, tc.expectedPods) {
+				t.Errorf("result does not match expected value. Expected: %s\nActual: %s\n", tc.expectedPods, names)
+			}
+		})
+	}
+}
@@ -0,0 +1,128 @@
+package helpers
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// Owner identifies the workload that controls a pod, either directly or through an intermediate controller
+// (a ReplicaSet for a Deployment or a Job for a CronJob)
+type Owner struct {
+	// Kind of the workload (e.g. "Deployment", "StatefulSet")
+	Kind string
+	// Name of the workload
+	Name string
+}
+
+// podControllers returns the controllers that directly own the pods of the given workload. Deployments and CronJobs
+// own their pods through the ReplicaSets and Jobs they control, which are resolved through their ownerReferences.
+func podControllers(
+	ctx context.Context,
+	client kubernetes.Interface,
+	namespace string,
+	owner Owner,
+) (map[Owner]bool, error) {
+	controllers := map[Owner]bool{}
+
+	switch owner.Kind {
+	case "Deployment":
+		replicaSets, err := client.AppsV1().ReplicaSets(namespace).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("listing replicasets: %w", err)
+		}
+
+		for i := range replicaSets.Items {
+			if isControlledBy(&replicaSets.Items[i], owner) {
+				controllers[Owner{Kind: "ReplicaSet", Name: replicaSets.Items[i].Name}] = true
+			}
+		}
+	case "CronJob":
+		jobs, err := client.BatchV1().Jobs(namespace).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("listing jobs: %w", err)
+		}
+
+		for i := range jobs.Items {
+			if isControlledBy(&jobs.Items[i], owner) {
+				controllers[Owner{Kind: "Job", Name: jobs.Items[i].Name}] = true
+			}
+		}
+	default:
+		controllers[owner] = true
+	}
+
+	return controllers, nil
+}
+
+// isControlledBy returns whether the object is controlled by the given owner
+func isControlledBy(object metav1.Object, owner Owner) bool {
+	controller := metav1.GetControllerOf(object)
+	if controller == nil {
+		return false
+	}
+
+	return controller.Kind == owner.Kind && controller.Name == owner.Name
+}
+
+// listPods returns the pods in the namespace that match the filter, resolving the owner of the pods if the filter
+// specifies one
+func listPods(
+	ctx context.Context,
+	client kubernetes.Interface,
+	namespace string,
+	filter PodFilter,
+) ([]corev1.Pod, error) {
+	labelSelector, err := buildPodLabelSelector(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	listOptions := metav1.ListOptions{
+		LabelSelector: labelSelector.String(),
+	}
+	pods, err := client.CoreV1().Pods(namespace).List(
+		ctx,
+		listOptions,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	var controllers map[Owner]bool
+	if filter.Owner != (Owner{}) {
+		controllers, err = podControllers(ctx, client, namespace, filter.Owner)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	matching := []corev1.Pod{}
+	for i := range pods.Items {
+		pod := pods.Items[i]
+		if !filter.matches(pod) {
+			continue
+		}
+
+		if controllers != nil && !isControlledByAny(&pod, controllers) {
+			continue
+		}
+
+		matching = append(matching, pod)
+	}
+
+	return matching, nil
+}
+
+// isControlledByAny returns whether the object is controlled by any of the given controllers
+func isControlledByAny(object metav1.Object, controllers map[Owner]bool) bool {
+	controller := metav1.GetControllerOf(object)
+	if controller == nil {
+		return false
+	}
+
+	return controllers[Owner{Kind: controller.Kind, Name: controller.Name}]
+}
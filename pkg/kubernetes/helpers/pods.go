@@ -52,12 +52,95 @@ func NewPodHelper(client kubernetes.Interface, executor PodCommandExecutor, name
 	}
 }
 
-// PodFilter defines the criteria for selecting a pod for disruption
+// PodFilter defines the criteria for selecting a pod for disruption.
+// Pods that are being terminated are never selected.
 type PodFilter struct {
 	// Select Pods that match these labels
 	Select map[string]string
 	// Select Pods that match these labels
 	Exclude map[string]string
+	// Select Pods that match all these label expressions
+	SelectExpressions []metav1.LabelSelectorRequirement
+	// Exclude Pods that match any of these label expressions
+	ExcludeExpressions []metav1.LabelSelectorRequirement
+	// Select Pods that match these annotations
+	SelectAnnotations map[string]string
+	// Exclude Pods that match any of these annotations
+	ExcludeAnnotations map[string]string
+	// Select Pods in any of these phases (e.g. "Running"). If empty, pods in any phase are selected.
+	SelectPhases []string
+	// Exclude Pods in any of these phases (e.g. "Pending")
+	ExcludePhases []string
+	// Select Pods that have all these conditions (e.g. "Ready") set to True
+	SelectConditions []string
+	// Exclude Pods that have any of these conditions (e.g. "Ready") set to True
+	ExcludeConditions []string
+	// Select Pods controlled by this workload. If empty, pods are not filtered by their owner.
+	Owner Owner
+}
+
+// matches returns whether a pod satisfies the annotation, phase and condition criteria of the filter and is not
+// being terminated. Labels are not checked as they are filtered by the API server.
+func (f PodFilter) matches(pod corev1.Pod) bool {
+	if pod.DeletionTimestamp != nil {
+		return false
+	}
+
+	for name, value := range f.SelectAnnotations {
+		if v, found := pod.Annotations[name]; !found || v != value {
+			return false
+		}
+	}
+
+	for name, value := range f.ExcludeAnnotations {
+		if v, found := pod.Annotations[name]; found && v == value {
+			return false
+		}
+	}
+
+	if len(f.SelectPhases) > 0 && !hasPodPhase(pod, f.SelectPhases) {
+		return false
+	}
+
+	if hasPodPhase(pod, f.ExcludePhases) {
+		return false
+	}
+
+	for _, condition := range f.SelectConditions {
+		if !hasPodCondition(pod, condition) {
+			return false
+		}
+	}
+
+	for _, condition := range f.ExcludeConditions {
+		if hasPodCondition(pod, condition) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// hasPodPhase returns whether the pod is in any of the given phases
+func hasPodPhase(pod corev1.Pod, phases []string) bool {
+	for _, phase := range phases {
+		if string(pod.Status.Phase) == phase {
+			return true
+		}
+	}
+
+	return false
+}
+
+// hasPodCondition returns whether the pod has the given condition type set to True
+func hasPodCondition(pod corev1.Pod, conditionType string) bool {
+	for _, c := range pod.Status.Conditions {
+		if string(c.Type) == conditionType {
+			return c.Status == corev1.ConditionTrue
+		}
+	}
+
+	return false
 }
 
 // AttachOptions defines options for attaching a container
@@ -231,7 +314,7 @@ func (h *podHelper) AttachEphemeralContainer(
 		h.namespace,
 		podName,
 		options.Timeout,
-		checkEphemeralContainerIsRunning,
+		checkEphemeralContainerIsRunning(container.Name),
 	)
 	if err != nil {
 		return fmt.Errorf("waiting for ephemeral container of %q to start: %w", pod.Name, err)
@@ -242,22 +325,25 @@ func (h *podHelper) AttachEphemeralContainer(
 	return nil
 }
 
-func checkEphemeralContainerIsRunning(pod *corev1.Pod) (bool, error) {
-	if pod.Status.EphemeralContainerStatuses != nil {
+// checkEphemeralContainerIsRunning returns a podConditionChecker that checks if the ephemeral container with the
+// given name is running
+func checkEphemeralContainerIsRunning(name string) podConditionChecker {
+	return func(pod *corev1.Pod) (bool, error) {
 		for _, cs := range pod.Status.EphemeralContainerStatuses {
-			if cs.State.Running != nil {
+			if cs.Name == name && cs.State.Running != nil {
 				return true, nil
 			}
 		}
-	}
 
-	return false, nil
+		return false, nil
+	}
 }
 
-// buildLabelSelector builds a label selector to be used in the k8s api, from a PodSelector
-func buildLabelSelector(f PodFilter) (labels.Selector, error) {
+// buildLabelSelector builds a label selector to be used in the k8s api from a set of labels to be selected
+// and a set of labels to be excluded
+func buildLabelSelector(selectLabels map[string]string, excludeLabels map[string]string) (labels.Selector, error) {
 	labelsSelector := labels.NewSelector()
-	for label, value := range f.Select {
+	for label, value := range selectLabels {
 		req, err := labels.NewRequirement(label, selection.Equals, []string{value})
 		if err != nil {
 			return nil, err
@@ -265,7 +351,7 @@ func buildLabelSelector(f PodFilter) (labels.Selector, error) {
 		labelsSelector = labelsSelector.Add(*req)
 	}
 
-	for label, value := range f.Exclude {
+	for label, value := range excludeLabels {
 		req, err := labels.NewRequirement(label, selection.NotEquals, []string{value})
 		if err != nil {
 			return nil, err
@@ -276,22 +362,48 @@ func buildLabelSelector(f PodFilter) (labels.Selector, error) {
 	return labelsSelector, nil
 }
 
-func (h *podHelper) List(ctx context.Context, filter PodFilter) ([]corev1.Pod, error) {
-	labelSelector, err := buildLabelSelector(filter)
-	if err != nil {
-		return nil, err
-	}
+// negatedOperators maps each label selector operator to the operator that matches the opposite set of labels
+var negatedOperators = map[metav1.LabelSelectorOperator]metav1.LabelSelectorOperator{
+	metav1.LabelSelectorOpIn:           metav1.LabelSelectorOpNotIn,
+	metav1.LabelSelectorOpNotIn:        metav1.LabelSelectorOpIn,
+	metav1.LabelSelectorOpExists:       metav1.LabelSelectorOpDoesNotExist,
+	metav1.LabelSelectorOpDoesNotExist: metav1.LabelSelectorOpExists,
+}
+
+// buildPodLabelSelector builds a label selector to be used in the k8s api from the labels and label expressions of
+// a PodFilter. The selector is built as a metav1.LabelSelector in which the excluded labels and expressions are
+// negated, so pods matching any of them are excluded.
+func buildPodLabelSelector(filter PodFilter) (labels.Selector, error) {
+	expressions := []metav1.LabelSelectorRequirement{}
+	expressions = append(expressions, filter.SelectExpressions...)
 
-	listOptions := metav1.ListOptions{
-		LabelSelector: labelSelector.String(),
+	for label, value := range filter.Exclude {
+		expressions = append(expressions, metav1.LabelSelectorRequirement{
+			Key:      label,
+			Operator: metav1.LabelSelectorOpNotIn,
+			Values:   []string{value},
+		})
 	}
-	pods, err := h.client.CoreV1().Pods(h.namespace).List(
-		ctx,
-		listOptions,
-	)
-	if err != nil {
-		return nil, err
+
+	for _, expression := range filter.ExcludeExpressions {
+		negated, found := negatedOperators[expression.Operator]
+		if !found {
+			return nil, fmt.Errorf("%q is not a valid label selector operator", expression.Operator)
+		}
+
+		expressions = append(expressions, metav1.LabelSelectorRequirement{
+			Key:      expression.Key,
+			Operator: negated,
+			Values:   expression.Values,
+		})
 	}
 
-	return pods.Items, nil
+	return metav1.LabelSelectorAsSelector(&metav1.LabelSelector{
+		MatchLabels:      filter.Select,
+		MatchExpressions: expressions,
+	})
+}
+
+func (h *podHelper) List(ctx context.Context, filter PodFilter) ([]corev1.Pod, error) {
+	return listPods(ctx, h.client, h.namespace, filter)
 }
@@ -5,6 +5,8 @@ import (
 	"testing"
 	"time"
 
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -174,6 +176,21 @@ func TestPods_AddEphemeralContainer(t *testing.T) {
 				IgnoreIfExists: true,
 			},
 		},
+		{
+			test:        "Fail waiting for container when other container is running",
+			podName:     "test-pod",
+			expectError: true,
+			status: corev1.ContainerStatus{
+				Name: "other",
+				State: corev1.ContainerState{
+					Running: &corev1.ContainerStateRunning{},
+				},
+			},
+			options: AttachOptions{
+				Timeout:        1 * time.Second,
+				IgnoreIfExists: true,
+			},
+		},
 	}
 	for _, tc := range testCases {
 		tc := tc
@@ -215,13 +232,22 @@ func TestPods_AddEphemeralContainer(t *testing.T) {
 			err = h.AttachEphemeralContainer(
 				context.TODO(),
 				tc.podName,
-				corev1.EphemeralContainer{},
+				corev1.EphemeralContainer{
+					EphemeralContainerCommon: corev1.EphemeralContainerCommon{
+						Name: "ephemeral",
+					},
+				},
 				tc.options,
 			)
 			if !tc.expectError && err != nil {
 				t.Errorf("failed: %v", err)
 				return
 			}
+
+			if tc.expectError && err == nil {
+				t.Errorf("should had failed")
+				return
+			}
 		})
 	}
 }
@@ -392,6 +418,189 @@ func Test_ListPods(t *testing.T) {
 				"another-pod-in-test-ns",
 			},
 		},
+		{
+			title:     "label expressions",
+			namespace: "test-ns",
+			pods: []corev1.Pod{
+				builders.NewPodBuilder("api-pod").
+					WithNamespace("test-ns").
+					WithLabel("tier", "api").
+					Build(),
+				builders.NewPodBuilder("worker-pod").
+					WithNamespace("test-ns").
+					WithLabel("tier", "worker").
+					Build(),
+				builders.NewPodBuilder("canary-pod").
+					WithNamespace("test-ns").
+					WithLabel("tier", "api").
+					WithLabel("canary", "true").
+					Build(),
+				builders.NewPodBuilder("db-pod").
+					WithNamespace("test-ns").
+					WithLabel("tier", "db").
+					Build(),
+			},
+			filter: PodFilter{
+				SelectExpressions: []metav1.LabelSelectorRequirement{
+					{Key: "tier", Operator: metav1.LabelSelectorOpIn, Values: []string{"api", "worker"}},
+				},
+				ExcludeExpressions: []metav1.LabelSelectorRequirement{
+					{Key: "canary", Operator: metav1.LabelSelectorOpExists},
+				},
+			},
+			expectError: false,
+			expectedPods: []string{
+				"api-pod",
+				"worker-pod",
+			},
+		},
+		{
+			title:     "excluded label expressions",
+			namespace: "test-ns",
+			pods: []corev1.Pod{
+				builders.NewPodBuilder("api-pod").
+					WithNamespace("test-ns").
+					WithLabel("tier", "api").
+					Build(),
+				builders.NewPodBuilder("db-pod").
+					WithNamespace("test-ns").
+					WithLabel("tier", "db").
+					Build(),
+				builders.NewPodBuilder("pod-without-tier").
+					WithNamespace("test-ns").
+					Build(),
+			},
+			filter: PodFilter{
+				ExcludeExpressions: []metav1.LabelSelectorRequirement{
+					{Key: "tier", Operator: metav1.LabelSelectorOpNotIn, Values: []string{"api"}},
+				},
+			},
+			expectError: false,
+			expectedPods: []string{
+				"api-pod",
+			},
+		},
+		{
+			title:     "invalid label expression",
+			namespace: "test-ns",
+			pods:      []corev1.Pod{},
+			filter: PodFilter{
+				SelectExpressions: []metav1.LabelSelectorRequirement{
+					{Key: "tier", Operator: "Equals", Values: []string{"api"}},
+				},
+			},
+			expectError: true,
+		},
+		{
+			title:     "label expression without values",
+			namespace: "test-ns",
+			pods:      []corev1.Pod{},
+			filter: PodFilter{
+				SelectExpressions: []metav1.LabelSelectorRequirement{
+					{Key: "tier", Operator: metav1.LabelSelectorOpIn},
+				},
+			},
+			expectError: true,
+		},
+		{
+			title:     "annotations",
+			namespace: "test-ns",
+			pods: []corev1.Pod{
+				builders.NewPodBuilder("pod-with-annotation").
+					WithNamespace("test-ns").
+					WithAnnotation("team", "payments").
+					Build(),
+				builders.NewPodBuilder("pod-with-excluded-annotation").
+					WithNamespace("test-ns").
+					WithAnnotation("team", "payments").
+					WithAnnotation("chaos", "skip").
+					Build(),
+				builders.NewPodBuilder("pod-without-annotation").
+					WithNamespace("test-ns").
+					Build(),
+			},
+			filter: PodFilter{
+				SelectAnnotations:  map[string]string{"team": "payments"},
+				ExcludeAnnotations: map[string]string{"chaos": "skip"},
+			},
+			expectError: false,
+			expectedPods: []string{
+				"pod-with-annotation",
+			},
+		},
+		{
+			title:     "phases",
+			namespace: "test-ns",
+			pods: []corev1.Pod{
+				builders.NewPodBuilder("running-pod").
+					WithNamespace("test-ns").
+					WithPhase(corev1.PodRunning).
+					Build(),
+				builders.NewPodBuilder("pending-pod").
+					WithNamespace("test-ns").
+					WithPhase(corev1.PodPending).
+					Build(),
+				builders.NewPodBuilder("failed-pod").
+					WithNamespace("test-ns").
+					WithPhase(corev1.PodFailed).
+					Build(),
+			},
+			filter: PodFilter{
+				SelectPhases:  []string{"Running", "Pending"},
+				ExcludePhases: []string{"Pending"},
+			},
+			expectError: false,
+			expectedPods: []string{
+				"running-pod",
+			},
+		},
+		{
+			title:     "readiness",
+			namespace: "test-ns",
+			pods: []corev1.Pod{
+				builders.NewPodBuilder("ready-pod").
+					WithNamespace("test-ns").
+					WithCondition(corev1.PodReady, corev1.ConditionTrue).
+					Build(),
+				builders.NewPodBuilder("not-ready-pod").
+					WithNamespace("test-ns").
+					WithCondition(corev1.PodReady, corev1.ConditionFalse).
+					Build(),
+				builders.NewPodBuilder("pod-without-conditions").
+					WithNamespace("test-ns").
+					Build(),
+			},
+			filter: PodFilter{
+				ExcludeConditions: []string{"Ready"},
+			},
+			expectError: false,
+			expectedPods: []string{
+				"not-ready-pod",
+				"pod-without-conditions",
+			},
+		},
+		{
+			title:     "terminating pods",
+			namespace: "test-ns",
+			pods: []corev1.Pod{
+				builders.NewPodBuilder("ready-pod").
+					WithNamespace("test-ns").
+					WithCondition(corev1.PodReady, corev1.ConditionTrue).
+					Build(),
+				builders.NewPodBuilder("terminating-pod").
+					WithNamespace("test-ns").
+					WithCondition(corev1.PodReady, corev1.ConditionTrue).
+					WithDeletionTimestamp().
+					Build(),
+			},
+			filter: PodFilter{
+				SelectConditions: []string{"Ready"},
+			},
+			expectError: false,
+			expectedPods: []string{
+				"ready-pod",
+			},
+		},
 	}
 
 	for _, tc := range testCases {
@@ -434,3 +643,125 @@ func Test_ListPods(t *testing.T) {
 		})
 	}
 }
+
+func Test_ListPodsByOwner(t *testing.T) {
+	t.Parallel()
+
+	controller := true
+	replicaSet := &appsv1.ReplicaSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "app-5d8f",
+			Namespace: "test-ns",
+			OwnerReferences: []metav1.OwnerReference{
+				{APIVersion: "apps/v1", Kind: "Deployment", Name: "app", Controller: &controller},
+			},
+		},
+	}
+
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "backup-2860",
+			Namespace: "test-ns",
+			OwnerReferences: []metav1.OwnerReference{
+				{APIVersion: "batch/v1", Kind: "CronJob", Name: "backup", Controller: &controller},
+			},
+		},
+	}
+
+	objects := []runtime.Object{replicaSet, job}
+	for _, pod := range []corev1.Pod{
+		builders.NewPodBuilder("app-5d8f-1").
+			WithNamespace("test-ns").
+			WithLabel("tier", "api").
+			WithOwnerReference("ReplicaSet", "app-5d8f").
+			Build(),
+		builders.NewPodBuilder("app-5d8f-2").
+			WithNamespace("test-ns").
+			WithOwnerReference("ReplicaSet", "app-5d8f").
+			Build(),
+		builders.NewPodBuilder("other-7c9a-1").
+			WithNamespace("test-ns").
+			WithLabel("tier", "api").
+			WithOwnerReference("ReplicaSet", "other-7c9a").
+			Build(),
+		builders.NewPodBuilder("db-0").
+			WithNamespace("test-ns").
+			WithOwnerReference("StatefulSet", "db").
+			Build(),
+		builders.NewPodBuilder("backup-2860-1").
+			WithNamespace("test-ns").
+			WithOwnerReference("Job", "backup-2860").
+			Build(),
+		builders.NewPodBuilder("standalone").
+			WithNamespace("test-ns").
+			Build(),
+	} {
+		pod := pod
+		objects = append(objects, &pod)
+	}
+
+	testCases := []struct {
+		title        string
+		filter       PodFilter
+		expectedPods []string
+	}{
+		{
+			title:        "deployment",
+			filter:       PodFilter{Owner: Owner{Kind: "Deployment", Name: "app"}},
+			expectedPods: []string{"app-5d8f-1", "app-5d8f-2"},
+		},
+		{
+			title: "deployment and labels",
+			filter: PodFilter{
+				Select: map[string]string{"tier": "api"},
+				Owner:  Owner{Kind: "Deployment", Name: "app"},
+			},
+			expectedPods: []string{"app-5d8f-1"},
+		},
+		{
+			title:        "statefulset",
+			filter:       PodFilter{Owner: Owner{Kind: "StatefulSet", Name: "db"}},
+			expectedPods: []string{"db-0"},
+		},
+		{
+			title:        "cronjob",
+			filter:       PodFilter{Owner: Owner{Kind: "CronJob", Name: "backup"}},
+			expectedPods: []string{"backup-2860-1"},
+		},
+		{
+			title:        "replicaset",
+			filter:       PodFilter{Owner: Owner{Kind: "ReplicaSet", Name: "other-7c9a"}},
+			expectedPods: []string{"other-7c9a-1"},
+		},
+		{
+			title:        "workload without pods",
+			filter:       PodFilter{Owner: Owner{Kind: "Deployment", Name: "other"}},
+			expectedPods: []string{},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			client := fake.NewSimpleClientset(objects...)
+
+			helper := NewPodHelper(client, nil, "test-ns")
+			pods, err := helper.List(context.TODO(), tc.filter)
+			if err != nil {
+				t.Fatalf("failed: %v", err)
+			}
+
+			names := []string{}
+			for _, pod := range pods {
+				names = append(names, pod.Name)
+			}
+
+			if !assertions.CompareStringArrays(tc.expectedPods, names) {
+				t.Errorf("result does not match expected value. Expected: %s\nActual: %s\n", tc.expectedPods, names)
+			}
+		})
+	}
+}
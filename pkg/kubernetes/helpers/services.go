@@ -9,7 +9,6 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/client-go/kubernetes"
 )
 
@@ -19,7 +18,8 @@ type ServiceHelper interface {
 	WaitServiceReady(ctx context.Context, service string, timeout time.Duration) error
 	// WaitIngressReady waits for the given service to have a load balancer address assigned
 	WaitIngressReady(ctx context.Context, ingress string, timeout time.Duration) error
-	// GetTargets returns the list of pods that match the service selector criteria
+	// GetTargets returns the list of pods that match the service selector criteria. Terminating pods are not
+	// returned, as they no longer receive the traffic of the service.
 	GetTargets(ctx context.Context, service string) ([]corev1.Pod, error)
 }
 
@@ -73,19 +73,13 @@ func (h *serviceHelper) WaitIngressReady(ctx context.Context, name string, timeo
 	})
 }
 
+// GetTargets lists the pods selected by the service as PodHelper.List does, so terminating pods are skipped. Services
+// select their pods by labels only, so the pods are not filtered by their owner.
 func (h *serviceHelper) GetTargets(ctx context.Context, name string) ([]corev1.Pod, error) {
 	service, err := h.client.CoreV1().Services(h.namespace).Get(ctx, name, metav1.GetOptions{})
 	if err != nil {
-		return nil, fmt.Errorf("failed to retrieve target service %s: %w", service, err)
+		return nil, fmt.Errorf("failed to retrieve target service %s: %w", name, err)
 	}
 
-	listOptions := metav1.ListOptions{
-		LabelSelector: labels.SelectorFromSet(service.Spec.Selector).String(),
-	}
-	pods, err := h.client.CoreV1().Pods(h.namespace).List(
-		ctx,
-		listOptions,
-	)
-
-	return pods.Items, err
+	return listPods(ctx, h.client, h.namespace, PodFilter{Select: service.Spec.Selector})
 }
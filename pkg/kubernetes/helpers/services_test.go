@@ -292,6 +292,29 @@ func Test_Targets(t *testing.T) {
 			expectError:  false,
 			expectedPods: []string{},
 		},
+		{
+			title:       "terminating pods",
+			serviceName: "test-svc",
+			namespace:   "test-ns",
+			service: builders.NewServiceBuilder("test-svc").
+				WithNamespace("test-ns").
+				WithSelectorLabel("app", "test").
+				WithPort("http", 8080, intstr.FromInt(80)).
+				Build(),
+			pods: []corev1.Pod{
+				builders.NewPodBuilder("pod-1").
+					WithNamespace("test-ns").
+					WithLabel("app", "test").
+					Build(),
+				builders.NewPodBuilder("pod-2").
+					WithNamespace("test-ns").
+					WithLabel("app", "test").
+					WithDeletionTimestamp().
+					Build(),
+			},
+			expectError:  false,
+			expectedPods: []string{"pod-1"},
+		},
 	}
 
 	for _, tc := range testCases {
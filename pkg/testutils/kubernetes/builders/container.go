@@ -1,6 +1,9 @@
 package builders
 
-import corev1 "k8s.io/api/core/v1"
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
 
 // ContainerBuilder defines the methods for building a Container
 type ContainerBuilder interface {
@@ -21,6 +24,8 @@ type ContainerBuilder interface {
 	// WithEnvVarFromField adds an environment variable to the container referencing a field
 	// Example: "PodName", "metadata.name"
 	WithEnvVarFromField(name string, path string) ContainerBuilder
+	// WithMemoryLimit sets the container's memory limit (e.g. "512Mi")
+	WithMemoryLimit(limit string) ContainerBuilder
 }
 
 // containerBuilder maintains the configuration for building a container
@@ -32,6 +37,7 @@ type containerBuilder struct {
 	ports        []corev1.ContainerPort
 	capabilities []corev1.Capability
 	vars         []corev1.EnvVar
+	limits       corev1.ResourceList
 }
 
 // NewContainerBuilder returns a new ContainerBuilder
@@ -87,6 +93,15 @@ func (b *containerBuilder) WithEnvVarFromField(name string, path string) Contain
 	return b
 }
 
+func (b *containerBuilder) WithMemoryLimit(limit string) ContainerBuilder {
+	if b.limits == nil {
+		b.limits = corev1.ResourceList{}
+	}
+	b.limits[corev1.ResourceMemory] = resource.MustParse(limit)
+
+	return b
+}
+
 func (b *containerBuilder) Build() corev1.Container {
 	return corev1.Container{
 		Name:            b.name,
@@ -100,5 +115,8 @@ func (b *containerBuilder) Build() corev1.Container {
 			},
 		},
 		Env: b.vars,
+		Resources: corev1.ResourceRequirements{
+			Limits: b.limits,
+		},
 	}
 }
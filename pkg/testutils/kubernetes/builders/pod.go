@@ -28,6 +28,18 @@ type PodBuilder interface {
 	WithHostNetwork(hostNetwork bool) PodBuilder
 	// WithContainer add a container to the pod
 	WithContainer(c corev1.Container) PodBuilder
+	// WithContainerStatus adds a container status to the pod
+	WithContainerStatus(status corev1.ContainerStatus) PodBuilder
+	// WithShareProcessNamespace sets the shareProcessNamespace property of the pod to be built
+	WithShareProcessNamespace(share bool) PodBuilder
+	// WithNodeName sets the name of the node the pod is scheduled on
+	WithNodeName(node string) PodBuilder
+	// WithOwnerReference adds a reference to a controller of the given kind and name
+	WithOwnerReference(kind string, name string) PodBuilder
+	// WithCondition adds a condition (e.g. "Ready") with the given status to the pod
+	WithCondition(condition corev1.PodConditionType, status corev1.ConditionStatus) PodBuilder
+	// WithDeletionTimestamp marks the pod as being terminated
+	WithDeletionTimestamp() PodBuilder
 }
 
 // podBuilder defines the attributes for building a pod
@@ -39,7 +51,13 @@ type podBuilder struct {
 	phase       corev1.PodPhase
 	ip          string
 	hostNetwork bool
+	shareProcNs bool
 	containers  []corev1.Container
+	statuses    []corev1.ContainerStatus
+	nodeName    string
+	owners      []metav1.OwnerReference
+	conditions  []corev1.PodCondition
+	deleted     bool
 }
 
 // NewPodBuilder creates a new instance of PodBuilder with the given pod name
@@ -97,6 +115,42 @@ func (b *podBuilder) WithContainer(c corev1.Container) PodBuilder {
 	return b
 }
 
+func (b *podBuilder) WithContainerStatus(status corev1.ContainerStatus) PodBuilder {
+	b.statuses = append(b.statuses, status)
+	return b
+}
+
+func (b *podBuilder) WithShareProcessNamespace(share bool) PodBuilder {
+	b.shareProcNs = share
+	return b
+}
+
+func (b *podBuilder) WithNodeName(node string) PodBuilder {
+	b.nodeName = node
+	return b
+}
+
+func (b *podBuilder) WithOwnerReference(kind string, name string) PodBuilder {
+	controller := true
+	b.owners = append(b.owners, metav1.OwnerReference{
+		APIVersion: "apps/v1",
+		Kind:       kind,
+		Name:       name,
+		Controller: &controller,
+	})
+	return b
+}
+
+func (b *podBuilder) WithCondition(condition corev1.PodConditionType, status corev1.ConditionStatus) PodBuilder {
+	b.conditions = append(b.conditions, corev1.PodCondition{Type: condition, Status: status})
+	return b
+}
+
+func (b *podBuilder) WithDeletionTimestamp() PodBuilder {
+	b.deleted = true
+	return b
+}
+
 func (b *podBuilder) Build() corev1.Pod {
 	pod := corev1.Pod{
 		TypeMeta: metav1.TypeMeta{
@@ -104,21 +158,35 @@ func (b *podBuilder) Build() corev1.Pod {
 			Kind:       "Pod",
 		},
 		ObjectMeta: metav1.ObjectMeta{
-			Name:        b.name,
-			Namespace:   b.namespace,
-			Labels:      b.labels,
-			Annotations: b.annotations,
+			Name:            b.name,
+			Namespace:       b.namespace,
+			Labels:          b.labels,
+			Annotations:     b.annotations,
+			OwnerReferences: b.owners,
 		},
 		Spec: corev1.PodSpec{
+			NodeName:            b.nodeName,
 			Containers:          b.containers,
 			HostNetwork:         b.hostNetwork,
 			EphemeralContainers: nil,
 		},
 		Status: corev1.PodStatus{
-			Phase: b.phase,
+			Phase:             b.phase,
+			ContainerStatuses: b.statuses,
+			Conditions:        b.conditions,
 		},
 	}
 
+	if b.deleted {
+		now := metav1.Now()
+		pod.DeletionTimestamp = &now
+	}
+
+	if b.shareProcNs {
+		share := b.shareProcNs
+		pod.Spec.ShareProcessNamespace = &share
+	}
+
 	// PodIPs is a patchMergeKey field, so it should be nil if no IPs are present. Otherwise, creation of
 	// StrategicMerge patches will fail with:
 	// map: map[] does not contain declared merge key: ip
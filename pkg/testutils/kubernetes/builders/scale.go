@@ -0,0 +1,101 @@
+package builders
+
+import (
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// AddScaleReactors adds to the fake client reactors that emulate the scale subresource of Deployments, StatefulSets
+// and ReplicaSets, which the fake client does not support
+func AddScaleReactors(client *fake.Clientset) {
+	for _, resource := range []string{"deployments", "statefulsets", "replicasets"} {
+		gvr := appsv1.SchemeGroupVersion.WithResource(resource)
+		client.PrependReactor("get", resource, getScaleReactor(client, gvr))
+		client.PrependReactor("update", resource, updateScaleReactor(client, gvr))
+	}
+}
+
+func getScaleReactor(client *fake.Clientset, gvr schema.GroupVersionResource) k8stesting.ReactionFunc {
+	return func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if action.GetSubresource() != "scale" {
+			return false, nil, nil
+		}
+
+		name := action.(k8stesting.GetAction).GetName() //nolint:forcetypeassert
+		obj, err := client.Tracker().Get(gvr, action.GetNamespace(), name)
+		if err != nil {
+			return true, nil, err
+		}
+
+		replicas, err := workloadReplicas(obj)
+		if err != nil {
+			return true, nil, err
+		}
+
+		scale := &autoscalingv1.Scale{}
+		scale.Name = name
+		scale.Namespace = action.GetNamespace()
+		scale.Spec.Replicas = *replicas
+
+		return true, scale, nil
+	}
+}
+
+func updateScaleReactor(client *fake.Clientset, gvr schema.GroupVersionResource) k8stesting.ReactionFunc {
+	return func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if action.GetSubresource() != "scale" {
+			return false, nil, nil
+		}
+
+		scale, ok := action.(k8stesting.UpdateAction).GetObject().(*autoscalingv1.Scale)
+		if !ok {
+			return true, nil, fmt.Errorf("expected a Scale object")
+		}
+
+		obj, err := client.Tracker().Get(gvr, action.GetNamespace(), scale.Name)
+		if err != nil {
+			return true, nil, err
+		}
+
+		replicas, err := workloadReplicas(obj)
+		if err != nil {
+			return true, nil, err
+		}
+		*replicas = scale.Spec.Replicas
+
+		if err = client.Tracker().Update(gvr, obj, action.GetNamespace()); err != nil {
+			return true, nil, err
+		}
+
+		return true, scale, nil
+	}
+}
+
+// workloadReplicas returns a pointer to the replicas in the spec of the workload, setting the default of one replica
+// if they are not set
+func workloadReplicas(obj runtime.Object) (*int32, error) {
+	var replicas **int32
+	switch workload := obj.(type) {
+	case *appsv1.Deployment:
+		replicas = &workload.Spec.Replicas
+	case *appsv1.StatefulSet:
+		replicas = &workload.Spec.Replicas
+	case *appsv1.ReplicaSet:
+		replicas = &workload.Spec.Replicas
+	default:
+		return nil, fmt.Errorf("unsupported workload %T", obj)
+	}
+
+	if *replicas == nil {
+		one := int32(1)
+		*replicas = &one
+	}
+
+	return *replicas, nil
+}
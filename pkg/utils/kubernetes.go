@@ -2,6 +2,7 @@ package utils
 
 import (
 	"fmt"
+	"strings"
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
@@ -83,3 +84,33 @@ func PodIP(pod corev1.Pod) (string, error) {
 
 	return "", fmt.Errorf("pod %s/%s does not have an IP address", pod.Namespace, pod.Name)
 }
+
+// ContainerID returns the runtime ID of the given container in the pod, without the runtime prefix
+// (e.g. "containerd://"). If the container name is empty, the first container of the pod is used.
+func ContainerID(pod corev1.Pod, container string) (string, error) {
+	if container == "" {
+		if len(pod.Spec.Containers) == 0 {
+			return "", fmt.Errorf("pod %s/%s does not have containers", pod.Namespace, pod.Name)
+		}
+		container = pod.Spec.Containers[0].Name
+	}
+
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.Name != container {
+			continue
+		}
+
+		if status.ContainerID == "" {
+			return "", fmt.Errorf("container %q in pod %s/%s is not running", container, pod.Namespace, pod.Name)
+		}
+
+		_, id, found := strings.Cut(status.ContainerID, "://")
+		if !found {
+			return status.ContainerID, nil
+		}
+
+		return id, nil
+	}
+
+	return "", fmt.Errorf("pod %s/%s does not have container %q", pod.Namespace, pod.Name, container)
+}
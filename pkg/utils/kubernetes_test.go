@@ -169,3 +169,68 @@ func Test_ValidatePort(t *testing.T) {
 		})
 	}
 }
+
+func Test_ContainerID(t *testing.T) {
+	t.Parallel()
+
+	pod := builders.NewPodBuilder("test-pod").
+		WithNamespace("testns").
+		WithContainer(builders.NewContainerBuilder("main").Build()).
+		WithContainer(builders.NewContainerBuilder("sidecar").Build()).
+		WithContainer(builders.NewContainerBuilder("waiting").Build()).
+		WithContainerStatus(corev1.ContainerStatus{Name: "main", ContainerID: "containerd://0123"}).
+		WithContainerStatus(corev1.ContainerStatus{Name: "sidecar", ContainerID: "docker://4567"}).
+		WithContainerStatus(corev1.ContainerStatus{Name: "waiting"}).
+		Build()
+
+	testCases := []struct {
+		title       string
+		container   string
+		expected    string
+		expectError bool
+	}{
+		{
+			title:     "default container",
+			container: "",
+			expected:  "0123",
+		},
+		{
+			title:     "named container",
+			container: "sidecar",
+			expected:  "4567",
+		},
+		{
+			title:       "container not running",
+			container:   "waiting",
+			expectError: true,
+		},
+		{
+			title:       "container not found",
+			container:   "other",
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			id, err := ContainerID(pod, tc.container)
+			if !tc.expectError && err != nil {
+				t.Errorf(" failed: %v", err)
+				return
+			}
+
+			if tc.expectError && err == nil {
+				t.Errorf("should had failed")
+				return
+			}
+
+			if id != tc.expected {
+				t.Errorf("expected %q got %q", tc.expected, id)
+			}
+		})
+	}
+}